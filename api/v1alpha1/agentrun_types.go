@@ -192,6 +192,12 @@ type AgentRunStatus struct {
 	// +optional
 	Error string `json:"error,omitempty"`
 
+	// ErrorCode is the agent-runner's classified errorCode from result.json
+	// (e.g. rate_limit_error, timeout_error), populated on failure when the
+	// result could be read.
+	// +optional
+	ErrorCode string `json:"errorCode,omitempty"`
+
 	// ExitCode of the agent container.
 	// +optional
 	ExitCode *int32 `json:"exitCode,omitempty"`
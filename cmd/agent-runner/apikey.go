@@ -0,0 +1,111 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+)
+
+// keyFileSuffix turns an API key variable into the one naming a file that
+// holds the key, e.g. ANTHROPIC_API_KEY_FILE, for Secrets mounted as files
+// rather than exposed as environment variables.
+const keyFileSuffix = "_FILE"
+
+// apiCredential is the API key for a provider and where it came from.
+type apiCredential struct {
+	Key string
+	// Source is the environment variable the key came from, or
+	// "file:<path>". It is what gets logged, never the key.
+	Source string
+	// file is set when the key came from a file.
+	file *apiKeyFile
+}
+
+// readAPIKeyFile reads an API key from path, trimming surrounding
+// whitespace such as the trailing newline of a Secret written by hand.
+func readAPIKeyFile(path string) (string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	key := strings.TrimSpace(string(b))
+	if key == "" {
+		return "", errors.New("file is empty")
+	}
+	return key, nil
+}
+
+// apiKeyFile is an API key read from a mounted file. After an
+// authentication failure the file is re-read, once per run, so a Secret
+// rotated by the kubelet is used without restarting the pod.
+type apiKeyFile struct {
+	path string
+
+	mu     sync.Mutex
+	key    string
+	reread bool
+}
+
+// source is the apiCredential Source of the file.
+func (f *apiKeyFile) source() string { return "file:" + f.path }
+
+// current returns the key as last read.
+func (f *apiKeyFile) current() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.key
+}
+
+// refresh re-reads the file the first time it is called and returns the
+// key and whether it changed. Later calls, and a file that can no longer be
+// read, keep the current key.
+func (f *apiKeyFile) refresh() (string, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.reread {
+		return f.key, false
+	}
+	f.reread = true
+	key, err := readAPIKeyFile(f.path)
+	if err != nil {
+		log.Printf("WARNING: re-reading API key from %s: %v", f.source(), err)
+		return f.key, false
+	}
+	if key == f.key {
+		return f.key, false
+	}
+	registerSecret(key)
+	f.key = key
+	return key, true
+}
+
+// resolveAPIKey returns the API key for provider. For each of
+// apiKeyEnvVars, most specific first, a key file named by its _FILE
+// variant takes precedence over the variable itself, so a mounted Secret
+// wins over one left in the environment. An empty credential means none is
+// set; a key file that cannot be read is an error.
+func resolveAPIKey(provider string) (apiCredential, error) {
+	names := apiKeyEnvVars(provider)
+	for _, name := range names {
+		path := os.Getenv(name + keyFileSuffix)
+		if path == "" {
+			continue
+		}
+		key, err := readAPIKeyFile(path)
+		if err != nil {
+			return apiCredential{}, fmt.Errorf("invalid %s%s: %w", name, keyFileSuffix, err)
+		}
+		registerSecret(key)
+		f := &apiKeyFile{path: path, key: key}
+		return apiCredential{Key: key, Source: f.source(), file: f}, nil
+	}
+	for _, name := range names {
+		if v := os.Getenv(name); v != "" {
+			return apiCredential{Key: v, Source: name}, nil
+		}
+	}
+	return apiCredential{}, nil
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// attachmentsDir holds the images sent with the task, in ipc.Input.
+const attachmentsDir = "attachments"
+
+const (
+	// defaultMaxAttachments is the default ATTACHMENTS_MAX_COUNT.
+	defaultMaxAttachments = 8
+	// defaultMaxAttachmentBytes is the default ATTACHMENTS_MAX_BYTES, the
+	// per-image limit of the Anthropic API.
+	defaultMaxAttachmentBytes = 5 << 20
+	// defaultMaxAttachmentTotalBytes is the default
+	// ATTACHMENTS_MAX_TOTAL_BYTES, the request size the providers accept
+	// comfortably once the images are base64 encoded.
+	defaultMaxAttachmentTotalBytes = 20 << 20
+)
+
+// errAttachmentsUnsupported marks attachments the provider or model cannot
+// accept, or files that are not a supported image.
+var errAttachmentsUnsupported = errors.New("attachments unsupported")
+
+// supportedImageTypes are the image media types accepted by both the
+// Anthropic and OpenAI vision APIs.
+var supportedImageTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// imageAttachment is an image sent to the model alongside the task.
+type imageAttachment struct {
+	Name      string
+	MediaType string
+	// Caption is sent as text just before the image; from ATTACHMENTS.
+	Caption string
+	Data    []byte
+}
+
+// base64Data returns the image encoded for an API request.
+func (a imageAttachment) base64Data() string {
+	return base64.StdEncoding.EncodeToString(a.Data)
+}
+
+// dataURI returns the image as a data: URI for OpenAI image_url parts.
+func (a imageAttachment) dataURI() string {
+	return "data:" + a.MediaType + ";base64," + a.base64Data()
+}
+
+// captionText is the text part sent before a captioned image, empty when
+// the image has no caption.
+func (a imageAttachment) captionText() string {
+	if a.Caption == "" {
+		return ""
+	}
+	return fmt.Sprintf("Attachment %s: %s", a.Name, a.Caption)
+}
+
+// attachmentRecord describes an attachment in result.json; the content is
+// never recorded.
+type attachmentRecord struct {
+	Name      string `json:"name"`
+	MediaType string `json:"mediaType"`
+	Bytes     int    `json:"bytes"`
+}
+
+// attachmentRecords returns the result.json records of images.
+func attachmentRecords(images []imageAttachment) []attachmentRecord {
+	var out []attachmentRecord
+	for _, img := range images {
+		out = append(out, attachmentRecord{Name: img.Name, MediaType: img.MediaType, Bytes: len(img.Data)})
+	}
+	return out
+}
+
+// attachmentEntry is one entry of ATTACHMENTS, a JSON array naming the files
+// of the attachments directory in the order they are sent, e.g.
+//
+//	[{"name": "screenshot.png", "mimeType": "image/png", "caption": "The error dialog"}]
+//
+// mimeType is optional; when given it must match the file's content.
+type attachmentEntry struct {
+	Name     string `json:"name"`
+	MimeType string `json:"mimeType,omitempty"`
+	Caption  string `json:"caption,omitempty"`
+}
+
+// parseAttachmentManifest parses ATTACHMENTS; nil when it is unset.
+func parseAttachmentManifest(raw string) ([]attachmentEntry, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	var entries []attachmentEntry
+	if err := json.Unmarshal([]byte(raw), &entries); err != nil {
+		return nil, fmt.Errorf("invalid ATTACHMENTS: %w", err)
+	}
+	seen := map[string]bool{}
+	for i, e := range entries {
+		if e.Name == "" || e.Name != filepath.Base(e.Name) || strings.HasPrefix(e.Name, ".") {
+			return nil, fmt.Errorf("invalid ATTACHMENTS entry %d: name %q must be a file in the attachments directory", i, e.Name)
+		}
+		if seen[e.Name] {
+			return nil, fmt.Errorf("invalid ATTACHMENTS entry %d: %s is listed twice", i, e.Name)
+		}
+		seen[e.Name] = true
+	}
+	return entries, nil
+}
+
+// attachmentLimits are ATTACHMENTS_MAX_COUNT, ATTACHMENTS_MAX_BYTES (per
+// file) and ATTACHMENTS_MAX_TOTAL_BYTES.
+type attachmentLimits struct {
+	count      int
+	bytes      int64
+	totalBytes int64
+}
+
+// loadAttachments reads the images in dir: in ATTACHMENTS order when a
+// manifest is given, otherwise in name order. A missing directory means no
+// attachments. Anything that is not a supported image, a file the manifest
+// does not list, or a file over the limits is an error rather than being
+// silently dropped, so the task is never run on partial input.
+func loadAttachments(dir string, manifest []attachmentEntry, lim attachmentLimits) ([]imageAttachment, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		if len(manifest) > 0 {
+			return nil, fmt.Errorf("ATTACHMENTS lists %d file(s) but %s does not exist", len(manifest), dir)
+		}
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read attachments: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		// Skip directories and hidden files (projected volumes create
+		// ..data and friends).
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	if manifest != nil {
+		listed := map[string]bool{}
+		for _, e := range manifest {
+			listed[e.Name] = true
+		}
+		for _, name := range names {
+			if !listed[name] {
+				return nil, fmt.Errorf("attachment %s is not listed in ATTACHMENTS", name)
+			}
+		}
+	} else {
+		for _, name := range names {
+			manifest = append(manifest, attachmentEntry{Name: name})
+		}
+	}
+	if len(manifest) > lim.count {
+		return nil, fmt.Errorf("%d attachments in %s exceeds the limit of %d (ATTACHMENTS_MAX_COUNT)", len(manifest), dir, lim.count)
+	}
+
+	var out []imageAttachment
+	var total int64
+	for _, e := range manifest {
+		path := filepath.Join(dir, e.Name)
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("attachment %s: %w", e.Name, err)
+		}
+		if info.Size() > lim.bytes {
+			return nil, fmt.Errorf("attachment %s is %d bytes, over the limit of %d (ATTACHMENTS_MAX_BYTES)", e.Name, info.Size(), lim.bytes)
+		}
+		if total += info.Size(); total > lim.totalBytes {
+			return nil, fmt.Errorf("attachments total more than %d bytes (ATTACHMENTS_MAX_TOTAL_BYTES)", lim.totalBytes)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("attachment %s: %w", e.Name, err)
+		}
+		mediaType := http.DetectContentType(data)
+		if !supportedImageTypes[mediaType] {
+			return nil, fmt.Errorf("%w: attachment %s has unsupported type %s (supported: PNG, JPEG, GIF, WebP)", errAttachmentsUnsupported, e.Name, mediaType)
+		}
+		if e.MimeType != "" && !strings.EqualFold(e.MimeType, mediaType) {
+			return nil, fmt.Errorf("attachment %s is %s, but ATTACHMENTS says %s", e.Name, mediaType, e.MimeType)
+		}
+		out = append(out, imageAttachment{Name: e.Name, MediaType: mediaType, Caption: e.Caption, Data: data})
+	}
+	if len(out) > 0 {
+		log.Printf("loaded %d image attachment(s) from %s (%d bytes)", len(out), dir, total)
+	}
+	return out, nil
+}
+
+// loadAttachmentLimits returns ATTACHMENTS_MAX_COUNT, ATTACHMENTS_MAX_BYTES
+// and ATTACHMENTS_MAX_TOTAL_BYTES.
+func loadAttachmentLimits() (attachmentLimits, error) {
+	lim := attachmentLimits{count: defaultMaxAttachments, bytes: defaultMaxAttachmentBytes, totalBytes: defaultMaxAttachmentTotalBytes}
+	var err error
+	if v := getEnv("ATTACHMENTS_MAX_COUNT", ""); v != "" {
+		if lim.count, err = strconv.Atoi(v); err != nil || lim.count < 0 {
+			return lim, fmt.Errorf("invalid ATTACHMENTS_MAX_COUNT %q", v)
+		}
+	}
+	if v := getEnv("ATTACHMENTS_MAX_BYTES", ""); v != "" {
+		if lim.bytes, err = strconv.ParseInt(v, 10, 64); err != nil || lim.bytes <= 0 {
+			return lim, fmt.Errorf("invalid ATTACHMENTS_MAX_BYTES %q", v)
+		}
+	}
+	if v := getEnv("ATTACHMENTS_MAX_TOTAL_BYTES", ""); v != "" {
+		if lim.totalBytes, err = strconv.ParseInt(v, 10, 64); err != nil || lim.totalBytes <= 0 {
+			return lim, fmt.Errorf("invalid ATTACHMENTS_MAX_TOTAL_BYTES %q", v)
+		}
+	}
+	return lim, nil
+}
+
+// supportsImageInput reports whether provider accepts image content parts.
+// Ollama, GitHub Models and OpenRouter pass images through to
+// vision-capable models; other OpenAI-compatible endpoints are not assumed
+// to.
+func supportsImageInput(provider string) bool {
+	switch provider {
+	case "anthropic", "openai", "azure-openai", "ollama", "github", "openrouter":
+		return true
+	}
+	return false
+}
+
+// textOnlyModelPattern matches the OpenAI models known to reject image
+// input. A vendor prefix such as OpenRouter's "openai/" is ignored.
+var textOnlyModelPattern = regexp.MustCompile(`^(gpt-3\.5|gpt-4-32k|o1-mini|o1-preview|o3-mini)`)
+
+// checkImageInput returns an errAttachmentsUnsupported error when the
+// provider or model cannot accept images.
+func checkImageInput(provider, model string, count int) error {
+	if !supportsImageInput(provider) {
+		return fmt.Errorf("%w: provider %s does not support image input (%d attachment(s) in %s)", errAttachmentsUnsupported, provider, count, ipc.input(attachmentsDir))
+	}
+	if name := strings.ToLower(model[strings.LastIndex(model, "/")+1:]); textOnlyModelPattern.MatchString(name) {
+		return fmt.Errorf("%w: model %s does not support image input (%d attachment(s) in %s)", errAttachmentsUnsupported, model, count, ipc.input(attachmentsDir))
+	}
+	return nil
+}
+
+// attachmentErrorCode is the errorCode for attachments that could not be
+// loaded or sent.
+func attachmentErrorCode(err error) string {
+	if errors.Is(err, errAttachmentsUnsupported) {
+		return "attachments_unsupported"
+	}
+	return "config_error"
+}
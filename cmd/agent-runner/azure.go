@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/azure"
+	openaioption "github.com/openai/openai-go/v3/option"
+)
+
+// defaultAzureAPIVersion is used when AZURE_OPENAI_API_VERSION is unset.
+const defaultAzureAPIVersion = "2024-06-01"
+
+// azureEndpoint returns the Azure OpenAI resource endpoint and API version
+// from AZURE_OPENAI_ENDPOINT (falling back to MODEL_BASE_URL) and
+// AZURE_OPENAI_API_VERSION.
+func azureEndpoint(baseURL string) (endpoint, apiVersion string, err error) {
+	endpoint = strings.TrimRight(firstNonEmpty(getEnv("AZURE_OPENAI_ENDPOINT", ""), baseURL), "/")
+	if endpoint == "" {
+		return "", "", fmt.Errorf("Azure OpenAI requires MODEL_BASE_URL or AZURE_OPENAI_ENDPOINT to be set")
+	}
+	return endpoint, getEnv("AZURE_OPENAI_API_VERSION", defaultAzureAPIVersion), nil
+}
+
+// azureOptions configures the OpenAI client for an Azure OpenAI resource:
+// requests go to /openai/deployments/<model>/... with an api-version query
+// parameter and the key in the api-key header. The model sent is the
+// deployment name (AZURE_OPENAI_DEPLOYMENT, see main).
+func azureOptions(endpoint, apiVersion, apiKey string) []openaioption.RequestOption {
+	return []openaioption.RequestOption{
+		azure.WithEndpoint(endpoint, apiVersion),
+		azure.WithAPIKey(apiKey),
+		openaioption.WithMiddleware(normalizeAzureError),
+	}
+}
+
+// normalizeAzureError rewrites error bodies that are not in OpenAI's
+// {"error": {...}} envelope, which the SDK cannot parse otherwise.
+func normalizeAzureError(r *http.Request, next openaioption.MiddlewareNext) (*http.Response, error) {
+	resp, err := next(r)
+	if err != nil || resp.StatusCode < 400 {
+		return resp, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	body = azureErrorEnvelope(body, resp.StatusCode)
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	return resp, nil
+}
+
+// azureErrorEnvelope returns an error body in OpenAI's shape. The Azure
+// OpenAI service itself uses it, but API Management in front of a resource
+// answers with {"statusCode": 429, "message": "..."} and some gateways with
+// plain text.
+func azureErrorEnvelope(body []byte, status int) []byte {
+	var probe struct {
+		Error   json.RawMessage `json:"error"`
+		Message string          `json:"message"`
+	}
+	msg := strings.TrimSpace(string(body))
+	if err := json.Unmarshal(body, &probe); err == nil {
+		if bytes.HasPrefix(probe.Error, []byte("{")) {
+			return body
+		}
+		msg = probe.Message
+	}
+	if msg == "" {
+		msg = http.StatusText(status)
+	}
+	out, _ := json.Marshal(map[string]map[string]string{
+		"error": {"code": strconv.Itoa(status), "message": msg},
+	})
+	return out
+}
+
+// azureAPIError classifies an Azure OpenAI error. Azure reports a
+// descriptive code (DeploymentNotFound, content_filter, ...) next to the
+// message; it is kept in the message, and content filtering gets its own
+// class since it looks like any other 400 otherwise.
+func azureAPIError(e *openai.Error) *apiError {
+	msg := e.Message
+	if _, numeric := strconv.Atoi(e.Code); e.Code != "" && numeric != nil {
+		msg = e.Code + ": " + msg
+	}
+	apiErr := newAPIError("Azure OpenAI", e.StatusCode, msg)
+	if e.Code == "content_filter" {
+		apiErr.Class = "content_filter_error"
+	}
+	return apiErr
+}
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// batchFile is the batch of tasks, in ipc.Input.
+	batchFile = "tasks.json"
+	// batchResultsDir holds the per-task results, in ipc.Output.
+	batchResultsDir = "results"
+	// maxBatchConcurrency bounds BATCH_CONCURRENCY.
+	maxBatchConcurrency = 32
+)
+
+// batchTask is one entry of tasks.json. Vars are merged over vars.json (or
+// VARS_JSON) to render Task; the system prompt is shared by the batch.
+type batchTask struct {
+	ID   string         `json:"id"`
+	Task string         `json:"task"`
+	Vars map[string]any `json:"vars,omitempty"`
+}
+
+// batchIDPattern keeps task IDs usable as file names under results/.
+var batchIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,127}$`)
+
+// loadBatch reads the task array at path. It returns nil when the file does
+// not exist, which means a single-task run. Every task must have a unique
+// ID and a task of at most maxBytes.
+func loadBatch(path string, maxBytes int64) ([]batchTask, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(bytes.TrimPrefix(data, utf8BOM)))
+	dec.DisallowUnknownFields()
+	var tasks []batchTask
+	if err := dec.Decode(&tasks); err != nil {
+		return nil, fmt.Errorf("%s must be a JSON array of {\"id\", \"task\", \"vars\"} objects: %w", path, err)
+	}
+	if len(tasks) == 0 {
+		return nil, fmt.Errorf("%s holds no tasks", path)
+	}
+	seen := map[string]bool{}
+	for i, t := range tasks {
+		switch {
+		case !batchIDPattern.MatchString(t.ID):
+			return nil, fmt.Errorf("%s: task %d: id %q must be 1-128 letters, digits, '.', '_' or '-', starting with a letter or digit", path, i, t.ID)
+		case seen[t.ID]:
+			return nil, fmt.Errorf("%s: task %d: duplicate id %q", path, i, t.ID)
+		case t.Task == "":
+			return nil, fmt.Errorf("%s: task %q is empty", path, t.ID)
+		case int64(len(t.Task)) > maxBytes:
+			return nil, fmt.Errorf("%w: %s: task %q is %d bytes, over MAX_INPUT_BYTES %d", errInputTooLarge, path, t.ID, len(t.Task), maxBytes)
+		}
+		seen[t.ID] = true
+	}
+	return tasks, nil
+}
+
+// batchSettings parses BATCH_CONCURRENCY (default 1: one task at a time)
+// and BATCH_FAIL_FAST.
+func batchSettings() (concurrency int, failFast bool, err error) {
+	concurrency = 1
+	if v := getEnv("BATCH_CONCURRENCY", ""); v != "" {
+		concurrency, err = strconv.Atoi(v)
+		if err != nil || concurrency < 1 || concurrency > maxBatchConcurrency {
+			return 0, false, fmt.Errorf("invalid BATCH_CONCURRENCY %q: must be 1-%d", v, maxBatchConcurrency)
+		}
+	}
+	return concurrency, getEnv("BATCH_FAIL_FAST", "") == "true", nil
+}
+
+// batchSummary is the batch section of the aggregate result.json.
+type batchSummary struct {
+	Total     int `json:"total"`
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+	// Skipped counts the tasks never started, after BATCH_FAIL_FAST
+	// stopped the batch, the budget ran out or the run was terminated.
+	Skipped int               `json:"skipped,omitempty"`
+	Tasks   []batchTaskStatus `json:"tasks"`
+}
+
+// batchTaskStatus is one task's line in batchSummary; the full result is in
+// results/<id>.json.
+type batchTaskStatus struct {
+	ID           string `json:"id"`
+	Status       string `json:"status"` // success, error, cancelled or skipped
+	ErrorCode    string `json:"errorCode,omitempty"`
+	InputTokens  int    `json:"inputTokens,omitempty"`
+	OutputTokens int    `json:"outputTokens,omitempty"`
+}
+
+// batchRun processes the tasks of tasks.json, writing each result to
+// resultsDir/<id>.json as it completes.
+type batchRun struct {
+	runner       taskCall
+	tasks        []batchTask
+	systemPrompt string
+	images       []imageAttachment
+	// vars are the shared template variables; nil with TEMPLATE=off.
+	vars        map[string]any
+	templates   bool
+	concurrency int
+	failFast    bool
+	resultsDir  string
+	start       time.Time
+
+	mu       sync.Mutex
+	statuses []batchTaskStatus // by task index; Status is empty until run
+	results  []agentResult     // by task index, for the aggregate metrics
+	done     int
+	failed   int
+	stopped  bool
+}
+
+// newBatchRun prepares tasks for runner, loading the shared template
+// variables.
+func newBatchRun(runner taskCall, tasks []batchTask, systemPrompt string, images []imageAttachment) (*batchRun, error) {
+	concurrency, failFast, err := batchSettings()
+	if err != nil {
+		return nil, err
+	}
+	b := &batchRun{
+		runner:       runner,
+		tasks:        tasks,
+		systemPrompt: systemPrompt,
+		images:       images,
+		templates:    getEnv("TEMPLATE", "") != "off",
+		concurrency:  concurrency,
+		failFast:     failFast,
+		resultsDir:   ipc.output(batchResultsDir),
+		start:        time.Now(),
+		statuses:     make([]batchTaskStatus, len(tasks)),
+		results:      make([]agentResult, len(tasks)),
+	}
+	if b.templates {
+		if b.vars, _, err = loadTemplateVars(ipc.input(varsFile)); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// run processes the tasks in order, up to concurrency at a time, and
+// returns the aggregate result. No new task starts once the run is
+// terminated, the budget is spent or, with failFast, a task has failed.
+func (b *batchRun) run(ctx context.Context) agentResult {
+	status.setBatchProgress(0, 0, len(b.tasks))
+	sem := make(chan struct{}, b.concurrency)
+	var wg sync.WaitGroup
+	for i := range b.tasks {
+		sem <- struct{}{}
+		if b.isStopped() || ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			b.runTask(ctx, i)
+		}()
+	}
+	wg.Wait()
+	return b.result()
+}
+
+// renderTask renders t.Task with the shared variables and t.Vars.
+func (b *batchRun) renderTask(t batchTask) (string, error) {
+	if !b.templates || (b.vars == nil && t.Vars == nil) {
+		return t.Task, nil
+	}
+	vars := maps.Clone(b.vars)
+	if vars == nil {
+		vars = map[string]any{}
+	}
+	maps.Copy(vars, t.Vars)
+	return renderPrompt("TASK", t.Task, vars)
+}
+
+// runTask runs task i and records its result.
+func (b *batchRun) runTask(ctx context.Context, i int) {
+	t := b.tasks[i]
+	start := time.Now()
+	var res agentResult
+	if task, err := b.renderTask(t); err != nil {
+		res = agentResult{Provider: b.runner.provider, Model: b.runner.model, Status: "error", Error: err.Error(), ErrorCode: "config_error"}
+	} else {
+		out := b.runner.run(ctx, b.systemPrompt, task, history, b.images)
+		res = out.result(b.runner.provider, b.runner.model)
+		res.Response = stripMemoryMarkers(res.Response)
+		if out.err != nil && terminated.Load() {
+			markTerminated(&res)
+		}
+	}
+	res.TaskID = t.ID
+	res.SchemaVersion = outputSchemaVersion
+	res.StartedAt = start.UTC().Format(time.RFC3339)
+	res.CompletedAt = time.Now().UTC().Format(time.RFC3339)
+	res.Metrics.DurationMs = time.Since(start).Milliseconds()
+	if err := writeFileAtomic(filepath.Join(b.resultsDir, t.ID+".json"), res); err != nil {
+		log.Printf("WARNING: failed to write the result of task %s: %v", t.ID, err)
+	}
+	b.record(i, res)
+}
+
+// record stores task i's result and updates the progress in status.json.
+func (b *batchRun) record(i int, res agentResult) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.results[i] = res
+	b.statuses[i] = batchTaskStatus{
+		ID:           res.TaskID,
+		Status:       res.Status,
+		ErrorCode:    res.ErrorCode,
+		InputTokens:  res.Metrics.InputTokens,
+		OutputTokens: res.Metrics.OutputTokens,
+	}
+	b.done++
+	if res.Status == "success" {
+		log.Printf("batch task %s succeeded (%d/%d done)", res.TaskID, b.done, len(b.tasks))
+	} else {
+		b.failed++
+		log.Printf("batch task %s failed (%d/%d done): %s", res.TaskID, b.done, len(b.tasks), res.Error)
+		if b.failFast || res.ErrorCode == "budget_exceeded" {
+			b.stopped = true
+		}
+	}
+	status.setBatchProgress(b.done, b.failed, len(b.tasks))
+}
+
+func (b *batchRun) isStopped() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.stopped
+}
+
+// result returns the aggregate result.json: counts, per-task statuses and
+// summed usage. It is an error when any task failed or was skipped.
+func (b *batchRun) result() agentResult {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	sum := batchSummary{Total: len(b.tasks), Tasks: make([]batchTaskStatus, len(b.tasks))}
+	res := agentResult{Provider: b.runner.provider, Model: b.runner.model, Batch: &sum}
+	for i, st := range b.statuses {
+		switch st.Status {
+		case "":
+			st = batchTaskStatus{ID: b.tasks[i].ID, Status: "skipped"}
+			sum.Skipped++
+		case "success":
+			sum.Succeeded++
+		default:
+			sum.Failed++
+		}
+		sum.Tasks[i] = st
+		m := b.results[i].Metrics
+		res.Metrics.InputTokens += m.InputTokens
+		res.Metrics.OutputTokens += m.OutputTokens
+		res.Metrics.CacheReadTokens += m.CacheReadTokens
+		res.Metrics.CacheCreationTokens += m.CacheCreationTokens
+		res.Metrics.ReasoningTokens += m.ReasoningTokens
+		res.Metrics.ToolCalls += m.ToolCalls
+		res.Metrics.TokensEstimated = res.Metrics.TokensEstimated || m.TokensEstimated
+	}
+	res.Metrics.DurationMs = time.Since(b.start).Milliseconds()
+	res.Metrics.Cost = costs.breakdown()
+	res.Response = fmt.Sprintf("batch of %d task(s): %d succeeded, %d failed, %d skipped; results in %s",
+		sum.Total, sum.Succeeded, sum.Failed, sum.Skipped, b.resultsDir)
+	res.Status = "success"
+	if sum.Failed > 0 || sum.Skipped > 0 {
+		res.Status = "error"
+		res.ErrorCode = "batch_failed"
+		res.Error = fmt.Sprintf("%d of %d batch task(s) failed and %d were skipped", sum.Failed, sum.Total, sum.Skipped)
+	}
+	if terminated.Load() {
+		markTerminated(&res)
+	}
+	return res
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/document"
+	brtypes "github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+	"github.com/aws/smithy-go"
+)
+
+// callBedrock sends the task to the Bedrock Runtime Converse API. Requests
+// are SigV4-signed with credentials from the default AWS chain: environment
+// variables, the IRSA web identity token mounted into the pod, shared
+// config files or the instance role. The region comes from AWS_REGION, and
+// MODEL_BASE_URL overrides the endpoint (VPC endpoints, tests).
+//
+// Converse requests go through httpClient with the SDK's retryer disabled,
+// like the other providers, so retryTransport retries throttling (429) and
+// ModelNotReady with the usual backoff. Credential requests (STS, IMDS) keep
+// the SDK's own client, which honours AWS_CA_BUNDLE. Bedrock does not
+// stream here; with STREAM=true the text is emitted as one chunk when the
+// call completes.
+func callBedrock(ctx context.Context, baseURL, model, systemPrompt, task string, tools []ToolDef) (llmResult, error) {
+	cfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		return llmResult{}, fmt.Errorf("loading AWS configuration: %w", err)
+	}
+	if cfg.Region == "" {
+		return llmResult{}, fmt.Errorf("Bedrock requires AWS_REGION to be set")
+	}
+	client := bedrockruntime.NewFromConfig(cfg, func(o *bedrockruntime.Options) {
+		o.HTTPClient = httpClient
+		o.Retryer = aws.NopRetryer{}
+		if baseURL != "" {
+			o.BaseEndpoint = aws.String(baseURL)
+		}
+	})
+
+	var toolConfig *brtypes.ToolConfiguration
+	if len(tools) > 0 {
+		toolConfig = &brtypes.ToolConfiguration{}
+		for _, t := range tools {
+			toolConfig.Tools = append(toolConfig.Tools, &brtypes.ToolMemberToolSpec{Value: brtypes.ToolSpecification{
+				Name:        aws.String(t.Name),
+				Description: aws.String(t.Description),
+				InputSchema: &brtypes.ToolInputSchemaMemberJson{Value: document.NewLazyDocument(t.Parameters)},
+			}})
+		}
+	}
+
+	system := []brtypes.SystemContentBlock{&brtypes.SystemContentBlockMemberText{Value: systemPrompt}}
+	msgs := historyFor(ctx)
+	for _, s := range historySystemPrompt(msgs) {
+		system = append(system, &brtypes.SystemContentBlockMemberText{Value: s})
+	}
+	messages := bedrockHistory(msgs)
+	messages = appendBedrockMessage(messages, brtypes.ConversationRoleUser, &brtypes.ContentBlockMemberText{Value: task})
+
+	var res llmResult
+	var guard toolLoopGuard
+	// The call after MAX_ITERATIONS round-trips asks for a final answer.
+	// Converse cannot disable tools, so that is only asked of the model.
+	for i := 0; i <= maxToolIterations; i++ {
+		final := i == maxToolIterations
+		callCtx, span := startLLMCall(ctx, i)
+		out, err := client.Converse(callCtx, &bedrockruntime.ConverseInput{
+			ModelId:         aws.String(model),
+			System:          system,
+			Messages:        messages,
+			InferenceConfig: sampling.bedrockInferenceConfig(),
+			ToolConfig:      toolConfig,
+		})
+		if err != nil {
+			span.end(0, 0, err)
+			return res, bedrockError(err)
+		}
+		var prompt, completion int32
+
+		if out.Usage != nil {
+			res.InputTokens += int(aws.ToInt32(out.Usage.InputTokens))
+			res.OutputTokens += int(aws.ToInt32(out.Usage.OutputTokens))
+			res.CacheReadTokens += int(aws.ToInt32(out.Usage.CacheReadInputTokens))
+			res.CacheCreationTokens += int(aws.ToInt32(out.Usage.CacheWriteInputTokens))
+			prompt = aws.ToInt32(out.Usage.InputTokens) + aws.ToInt32(out.Usage.CacheReadInputTokens) + aws.ToInt32(out.Usage.CacheWriteInputTokens)
+			completion = aws.ToInt32(out.Usage.OutputTokens)
+		}
+		span.end(int(prompt), int(completion), nil)
+		if err := costs.charge(int(prompt), int(completion)); err != nil {
+			return res, err
+		}
+		res.StopReason = string(out.StopReason)
+
+		msg, ok := out.Output.(*brtypes.ConverseOutputMemberMessage)
+		if !ok {
+			return res, fmt.Errorf("no message in Converse response")
+		}
+		var text strings.Builder
+		var toolUses []brtypes.ToolUseBlock
+		for _, block := range msg.Value.Content {
+			switch v := block.(type) {
+			case *brtypes.ContentBlockMemberText:
+				text.WriteString(v.Value)
+			case *brtypes.ContentBlockMemberReasoningContent:
+				if r, ok := v.Value.(*brtypes.ReasoningContentBlockMemberReasoningText); ok {
+					chunkOut.writeChunk("thinking", "", aws.ToString(r.Value.Text))
+				}
+			case *brtypes.ContentBlockMemberToolUse:
+				toolUses = append(toolUses, v.Value)
+			}
+		}
+
+		transcript.response(i, text.String(), res.StopReason, int(prompt), int(completion))
+
+		if out.StopReason != brtypes.StopReasonToolUse || len(toolUses) == 0 || final {
+			res.endIteration(i, int(prompt), int(completion), 0, res.StopReason)
+			res.Text = text.String()
+			if final && res.Text == "" {
+				break
+			}
+			if streamOut != nil && !isSummaryCall(ctx) {
+				streamOut.write(res.Text)
+			}
+			res.IterationLimitReached = final
+			return res, nil
+		}
+
+		messages = append(messages, msg.Value)
+		var results []brtypes.ContentBlock
+		for n, tu := range toolUses {
+			args := "{}"
+			if tu.Input != nil {
+				if b, err := tu.Input.MarshalSmithyDocument(); err == nil {
+					args = string(b)
+				}
+			}
+			if err := guard.check(aws.ToString(tu.Name), args); err != nil {
+				res.endIteration(i, int(prompt), int(completion), n, res.StopReason)
+				return res, err
+			}
+			output, isErr := runToolCall(&res, aws.ToString(tu.ToolUseId), aws.ToString(tu.Name), args)
+			status := brtypes.ToolResultStatusSuccess
+			if isErr {
+				status = brtypes.ToolResultStatusError
+			}
+			results = append(results, &brtypes.ContentBlockMemberToolResult{Value: brtypes.ToolResultBlock{
+				ToolUseId: tu.ToolUseId,
+				Content:   []brtypes.ToolResultContentBlock{&brtypes.ToolResultContentBlockMemberText{Value: output}},
+				Status:    status,
+			}})
+		}
+		res.endIteration(i, int(prompt), int(completion), len(toolUses), res.StopReason)
+		if i == maxToolIterations-1 {
+			log.Printf("MAX_ITERATIONS (%d) reached; asking for a final answer", maxToolIterations)
+			results = append(results, &brtypes.ContentBlockMemberText{Value: fmt.Sprintf(iterationLimitPrompt, maxToolIterations)})
+		}
+		messages = appendBedrockMessage(messages, brtypes.ConversationRoleUser, results...)
+	}
+
+	return res, fmt.Errorf("exceeded maximum tool-call iterations (%d)", maxToolIterations)
+}
+
+// bedrockHistory converts history to Converse messages.
+func bedrockHistory(msgs []historyMessage) []brtypes.Message {
+	var out []brtypes.Message
+	_, conv := splitSystemHistory(msgs)
+	for _, m := range conv {
+		role := brtypes.ConversationRoleUser
+		if m.Role == "assistant" {
+			role = brtypes.ConversationRoleAssistant
+		}
+		out = appendBedrockMessage(out, role, &brtypes.ContentBlockMemberText{Value: m.text()})
+	}
+	return out
+}
+
+// appendBedrockMessage appends blocks as a message from role, merging them
+// into the last message when it has the same role: Converse rejects two
+// consecutive messages from one role.
+func appendBedrockMessage(msgs []brtypes.Message, role brtypes.ConversationRole, blocks ...brtypes.ContentBlock) []brtypes.Message {
+	if n := len(msgs); n > 0 && msgs[n-1].Role == role {
+		msgs[n-1].Content = append(msgs[n-1].Content, blocks...)
+		return msgs
+	}
+	return append(msgs, brtypes.Message{Role: role, Content: blocks})
+}
+
+// bedrockInferenceConfig returns the sampling parameters Converse supports,
+// or nil when none is set.
+func (p samplingParams) bedrockInferenceConfig() *brtypes.InferenceConfiguration {
+	if p.Temperature == nil && p.TopP == nil && p.MaxTokens == nil && len(p.Stop) == 0 {
+		return nil
+	}
+	c := &brtypes.InferenceConfiguration{StopSequences: p.Stop}
+	if p.Temperature != nil {
+		c.Temperature = aws.Float32(float32(*p.Temperature))
+	}
+	if p.TopP != nil {
+		c.TopP = aws.Float32(float32(*p.TopP))
+	}
+	if p.MaxTokens != nil {
+		c.MaxTokens = aws.Int32(int32(min(*p.MaxTokens, 1<<31-1)))
+	}
+	return c
+}
+
+// bedrockError classifies a Converse failure. Bedrock's exceptions map onto
+// HTTP statuses the same way as the other providers' errors:
+// ThrottlingException and ModelNotReadyException are 429s, access and
+// signature failures 403s.
+func bedrockError(err error) error {
+	var respErr *awshttp.ResponseError
+	if !errors.As(err, &respErr) {
+		return fmt.Errorf("Bedrock API error: %w", err)
+	}
+	msg := respErr.Error()
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		msg = apiErr.ErrorCode() + ": " + apiErr.ErrorMessage()
+	}
+	return newAPIError("Bedrock", respErr.HTTPStatusCode(), msg)
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	// defaultCallbackTimeout is the default RESULT_CALLBACK_TIMEOUT, which
+	// bounds every attempt and wait together so an unreachable endpoint
+	// cannot hold the pod.
+	defaultCallbackTimeout = 30 * time.Second
+	// callbackAttemptTimeout bounds a single POST.
+	callbackAttemptTimeout = 10 * time.Second
+)
+
+// resultCallback POSTs the final result to RESULT_CALLBACK_URL, so a
+// controller can collect it without mounting the IPC volume. It is in
+// addition to result.json, which is always written first.
+type resultCallback struct {
+	url     string
+	token   string
+	timeout time.Duration
+	client  *http.Client
+}
+
+// callback is the result callback of this run; nil when
+// RESULT_CALLBACK_URL is unset.
+var callback *resultCallback
+
+// loadResultCallback reads RESULT_CALLBACK_URL, RESULT_CALLBACK_TOKEN and
+// RESULT_CALLBACK_TIMEOUT. It returns nil when no URL is set. Posts go
+// through the same proxy and tlsConfig (CA_CERT_FILE, client certificate)
+// as provider requests, so an in-cluster endpoint behind a private CA
+// works, but without their retries: send has its own.
+func loadResultCallback(tlsConfig *tls.Config) (*resultCallback, error) {
+	raw := getEnv("RESULT_CALLBACK_URL", "")
+	if raw == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid RESULT_CALLBACK_URL: want an http or https URL")
+	}
+	cb := &resultCallback{
+		url:     raw,
+		token:   getEnv("RESULT_CALLBACK_TOKEN", ""),
+		timeout: defaultCallbackTimeout,
+		client:  &http.Client{Transport: newTransport(1, tlsConfig.Clone()), Timeout: callbackAttemptTimeout},
+	}
+	if v := getEnv("RESULT_CALLBACK_TIMEOUT", ""); v != "" {
+		if cb.timeout, err = time.ParseDuration(v); err != nil || cb.timeout <= 0 {
+			return nil, fmt.Errorf("invalid RESULT_CALLBACK_TIMEOUT %q", v)
+		}
+	}
+	return cb, nil
+}
+
+// sign returns the X-Sympozium-Signature of body: an HMAC-SHA256 keyed with
+// RESULT_CALLBACK_TOKEN, so the receiver can check the result was not
+// altered on the way.
+func (c *resultCallback) sign(body []byte) string {
+	mac := hmac.New(sha256.New, []byte(c.token))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// send POSTs res with the run identity headers and, with a token, a bearer
+// token and signature. Transport errors, 408, 429 and 5xx responses are
+// retried with retryCfg's backoff and Retry-After handling, up to
+// MAX_RETRIES and within RESULT_CALLBACK_TIMEOUT. After SIGTERM there is a
+// single attempt within what is left of SHUTDOWN_GRACE_PERIOD, so the
+// runner still exits before the pod is killed. Failures are logged: the
+// result is in result.json either way.
+func (c *resultCallback) send(res agentResult) {
+	if c == nil {
+		return
+	}
+	data, err := json.Marshal(res)
+	if err != nil {
+		log.Printf("WARNING: result callback: %v", err)
+		return
+	}
+	body := redactBytes(data)
+	cfg, timeout := retryCfg, c.timeout
+	if terminated.Load() {
+		left := graceRemaining()
+		if left <= 0 {
+			log.Printf("WARNING: result callback skipped: the shutdown grace period is over")
+			return
+		}
+		cfg.MaxRetries, timeout = 0, min(timeout, left)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	for attempt := 0; ; attempt++ {
+		wait, reason := c.post(ctx, body, attempt)
+		if reason == "" {
+			log.Printf("result callback delivered (attempt %d)", attempt+1)
+			return
+		}
+		if wait < 0 || attempt >= cfg.MaxRetries {
+			log.Printf("WARNING: result callback failed after %d attempt(s): %s", attempt+1, reason)
+			return
+		}
+		if wait == 0 {
+			wait = cfg.backoff(attempt)
+		}
+		wait = min(wait, cfg.BackoffMax)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			log.Printf("WARNING: result callback failed (%s); RESULT_CALLBACK_TIMEOUT %s leaves no time to retry", reason, c.timeout)
+			return
+		}
+		log.Printf("result callback failed (%s), retrying in %s (retry %d/%d)", reason, wait.Round(time.Millisecond), attempt+1, cfg.MaxRetries)
+		select {
+		case <-ctx.Done():
+			log.Printf("WARNING: result callback failed: %v", ctx.Err())
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// post sends one attempt. It returns an empty reason on success; otherwise
+// why it failed and the wait the endpoint asked for, or -1 when the
+// failure is not worth retrying.
+func (c *resultCallback) post(ctx context.Context, body []byte, attempt int) (time.Duration, string) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return -1, err.Error()
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "sympozium-agent-runner")
+	req.Header.Set("X-Sympozium-Agent-Run", getEnv("AGENT_RUN_ID", ""))
+	req.Header.Set("X-Sympozium-Instance", getEnv("INSTANCE_NAME", ""))
+	req.Header.Set("X-Sympozium-Attempt", strconv.Itoa(attempt+1))
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+		req.Header.Set("X-Sympozium-Signature", c.sign(body))
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, redact(err.Error())
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	resp.Body.Close()
+	switch code := resp.StatusCode; {
+	case code < 300:
+		return 0, ""
+	case code == http.StatusRequestTimeout || code == http.StatusTooManyRequests || code >= 500:
+		wait, _ := retryAfter(resp.Header, time.Now())
+		return wait, resp.Status
+	default:
+		return -1, resp.Status
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// CONTEXT_RECOVERY values, in increasing order of what may be changed.
+const (
+	contextRecoveryOff      = "off"
+	contextRecoveryHistory  = "history"
+	contextRecoveryTruncate = "truncate"
+)
+
+// minRecoveredTaskBytes is how much of the task truncation always keeps.
+const minRecoveredTaskBytes = 200
+
+// contextLengthMarkers identify, lowercased, the messages providers send
+// with a 400 when the prompt is over the model's context window: OpenAI and
+// Azure's context_length_exceeded code and wording, Anthropic's "prompt is
+// too long" and Bedrock's "input is too long".
+var contextLengthMarkers = []string{
+	"context_length_exceeded",
+	"maximum context length",
+	"prompt is too long",
+	"input is too long",
+	"too many input tokens",
+	"exceeds the context window",
+}
+
+// contextOverflowPatterns extract the prompt size and the limit from a
+// context-length message, as "208310 tokens > 200000 maximum" (Anthropic)
+// or "maximum context length is 8192 tokens. However, your messages
+// resulted in 9010 tokens" (OpenAI).
+var contextOverflowPatterns = []struct {
+	re              *regexp.Regexp
+	usedIdx, maxIdx int
+}{
+	{regexp.MustCompile(`(\d+) tokens > (\d+) maximum`), 1, 2},
+	{regexp.MustCompile(`maximum context length is (\d+) tokens.*?(?:resulted in|requested) (\d+) tokens`), 2, 1},
+}
+
+// isContextLengthMessage reports whether a provider error message says the
+// prompt did not fit the context window.
+func isContextLengthMessage(msg string) bool {
+	msg = strings.ToLower(msg)
+	for _, m := range contextLengthMarkers {
+		if strings.Contains(msg, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// isContextLengthError reports whether err is a provider's context-length
+// error.
+func isContextLengthError(err error) bool {
+	var apiErr *apiError
+	return errors.As(err, &apiErr) && apiErr.Class == "context_length_exceeded"
+}
+
+// contextRecoveryMode parses CONTEXT_RECOVERY: off, history (the default)
+// to drop the oldest history messages, or truncate to also cut the middle
+// of the task.
+func contextRecoveryMode() (string, error) {
+	switch v := getEnv("CONTEXT_RECOVERY", contextRecoveryHistory); v {
+	case contextRecoveryOff, contextRecoveryHistory, contextRecoveryTruncate:
+		return v, nil
+	default:
+		return "", fmt.Errorf("invalid CONTEXT_RECOVERY %q: must be off, history or truncate", v)
+	}
+}
+
+// contextRecovery records in result.json how the prompt was shortened after
+// a context-length error.
+type contextRecovery struct {
+	// Strategy is the CONTEXT_RECOVERY mode that was applied.
+	Strategy string `json:"strategy"`
+	// Error is the context-length error that triggered the retry.
+	Error string `json:"error"`
+	// HistoryDropped counts the history messages dropped, oldest first.
+	HistoryDropped int `json:"historyDropped,omitempty"`
+	// TaskCharsTruncated is how many bytes were cut from the middle of the
+	// task.
+	TaskCharsTruncated int `json:"taskCharsTruncated,omitempty"`
+}
+
+// recoverContext shortens the prompt after the context-length error err:
+// the oldest conversation history goes first and then, in truncate mode,
+// the middle of the task. System messages and the system prompt are never
+// changed. It returns nil when mode is off or nothing could be dropped.
+func recoverContext(mode string, err error, msgs []historyMessage, task string) ([]historyMessage, string, *contextRecovery) {
+	if mode == contextRecoveryOff {
+		return msgs, task, nil
+	}
+	rec := &contextRecovery{Strategy: mode, Error: err.Error()}
+	need := tokensToFree(err, msgs, task)
+
+	histTokens := historyTokens(msgs)
+	msgs, rec.HistoryDropped = trimHistory(msgs, histTokens-need)
+	need -= histTokens - historyTokens(msgs)
+
+	if need > 0 && mode == contextRecoveryTruncate {
+		if cut := min(need*4, len(task)-minRecoveredTaskBytes); cut > 0 {
+			task, rec.TaskCharsTruncated = truncateMiddle(task, cut)
+		}
+	}
+	if rec.HistoryDropped == 0 && rec.TaskCharsTruncated == 0 {
+		return msgs, task, nil
+	}
+	return msgs, task, rec
+}
+
+// tokensToFree estimates how many prompt tokens must go: the overflow the
+// provider reported plus a 5% margin, or half of the history and task when
+// the message gives no numbers.
+func tokensToFree(err error, msgs []historyMessage, task string) int {
+	msg := err.Error()
+	for _, p := range contextOverflowPatterns {
+		m := p.re.FindStringSubmatch(msg)
+		if m == nil {
+			continue
+		}
+		used, _ := strconv.Atoi(m[p.usedIdx])
+		limit, _ := strconv.Atoi(m[p.maxIdx])
+		if used > limit {
+			return used - limit + limit/20
+		}
+	}
+	return (historyTokens(msgs) + estimateTokens(task)) / 2
+}
+
+// historyTokens estimates the tokens in msgs.
+func historyTokens(msgs []historyMessage) int {
+	total := 0
+	for _, m := range msgs {
+		total += estimateTokens(m.Content)
+	}
+	return total
+}
+
+// truncateMiddle cuts about n bytes from the middle of s, keeping whole
+// runes, and marks the cut. It returns the result and how many bytes were
+// removed.
+func truncateMiddle(s string, n int) (string, int) {
+	if n <= 0 || n >= len(s) {
+		return s, 0
+	}
+	head := (len(s) - n) / 2
+	for head > 0 && !utf8.RuneStart(s[head]) {
+		head--
+	}
+	tail := head + n
+	for tail < len(s) && !utf8.RuneStart(s[tail]) {
+		tail++
+	}
+	return fmt.Sprintf("%s\n[...truncated %d chars...]\n%s", s[:head], tail-head, s[tail:]), tail - head
+}
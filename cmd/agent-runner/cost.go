@@ -0,0 +1,214 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// defaultPricingJSON maps model names (or name prefixes, so dated versions
+// match) to USD per million input and output tokens. PRICING_JSON adds to
+// or overrides it.
+//
+//go:embed pricing.json
+var defaultPricingJSON []byte
+
+// errBudgetExceeded marks a run stopped by MAX_COST_USD. It is permanent:
+// retrying cannot bring the cost back under the budget.
+var errBudgetExceeded = errors.New("cost budget exceeded")
+
+// modelPrice is the price of a model in USD per million tokens.
+type modelPrice struct {
+	Input  float64 `json:"input"`
+	Output float64 `json:"output"`
+}
+
+// picoUSD is an amount in units of 10^-12 USD. Costs are summed and
+// compared in integers so that small budgets are not defeated by float
+// rounding: 20 tokens at $0.15/M are exactly $0.000003.
+type picoUSD int64
+
+func usdToPico(usd float64) picoUSD { return picoUSD(math.Round(usd * 1e12)) }
+
+// perTokenPico converts a price in USD per million tokens to picoUSD per
+// token.
+func perTokenPico(usdPerMillion float64) picoUSD { return picoUSD(math.Round(usdPerMillion * 1e6)) }
+
+func (p picoUSD) usd() float64 { return float64(p) / 1e12 }
+
+func (p picoUSD) String() string { return "$" + strconv.FormatFloat(p.usd(), 'f', -1, 64) }
+
+// costBreakdown is the run's cost in result.json metrics.
+type costBreakdown struct {
+	InputUSD  float64 `json:"inputUsd"`
+	OutputUSD float64 `json:"outputUsd"`
+	TotalUSD  float64 `json:"totalUsd"`
+	// BudgetUSD is MAX_COST_USD, when set.
+	BudgetUSD float64 `json:"budgetUsd,omitempty"`
+}
+
+// costTracker sums the cost of every provider response and enforces the
+// budget. Methods on a nil tracker do nothing: the model's price is unknown.
+type costTracker struct {
+	inputRate  picoUSD // per token
+	outputRate picoUSD // per token
+	budget     picoUSD // 0 means no budget
+
+	mu     sync.Mutex
+	input  picoUSD
+	output picoUSD
+}
+
+// costs tracks this run's spending; main sets it from the environment.
+var costs *costTracker
+
+// loadCostTracker prices model from the embedded table and PRICING_JSON and
+// reads MAX_COST_USD. An unknown model with a budget is an error when
+// PRICING_STRICT=true and otherwise runs without budget enforcement.
+func loadCostTracker(model string) (*costTracker, error) {
+	var budget picoUSD
+	if v := getEnv("MAX_COST_USD", ""); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f <= 0 || math.IsInf(f, 0) {
+			return nil, fmt.Errorf("invalid MAX_COST_USD %q", v)
+		}
+		budget = usdToPico(f)
+	}
+
+	table, err := pricingTable(getEnv("PRICING_JSON", ""))
+	if err != nil {
+		return nil, err
+	}
+	price, ok := lookupPrice(table, model)
+	if !ok {
+		if budget == 0 {
+			return nil, nil
+		}
+		if getEnv("PRICING_STRICT", "") == "true" {
+			return nil, fmt.Errorf("MAX_COST_USD is set but model %q has no price; add it to PRICING_JSON", model)
+		}
+		log.Printf("WARNING: model %q has no price; MAX_COST_USD is not enforced (set PRICING_JSON, or PRICING_STRICT=true to fail instead)", model)
+		return nil, nil
+	}
+	return &costTracker{
+		inputRate:  perTokenPico(price.Input),
+		outputRate: perTokenPico(price.Output),
+		budget:     budget,
+	}, nil
+}
+
+// forModel returns the tracker for a switch to a fallback model: priced
+// like loadCostTracker(model), with what c has spent so far carried over.
+func (c *costTracker) forModel(model string) (*costTracker, error) {
+	next, err := loadCostTracker(model)
+	if err != nil || next == nil || c == nil {
+		return next, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	next.input, next.output = c.input, c.output
+	return next, nil
+}
+
+// pricingTable returns the embedded prices with override, a JSON object in
+// the same format, applied on top.
+func pricingTable(override string) (map[string]modelPrice, error) {
+	table := map[string]modelPrice{}
+	if err := json.Unmarshal(defaultPricingJSON, &table); err != nil {
+		return nil, fmt.Errorf("embedded pricing table: %w", err)
+	}
+	if override == "" {
+		return table, nil
+	}
+	var extra map[string]modelPrice
+	if err := json.Unmarshal([]byte(override), &extra); err != nil {
+		return nil, fmt.Errorf("invalid PRICING_JSON: %w", err)
+	}
+	for model, p := range extra {
+		if p.Input < 0 || p.Output < 0 {
+			return nil, fmt.Errorf("invalid PRICING_JSON: negative price for %q", model)
+		}
+		table[model] = p
+	}
+	return table, nil
+}
+
+// lookupPrice finds model in table by exact name, else by the longest key
+// that is a prefix of it ("gpt-4o-mini-2024-07-18" matches "gpt-4o-mini").
+func lookupPrice(table map[string]modelPrice, model string) (modelPrice, bool) {
+	if p, ok := table[model]; ok {
+		return p, true
+	}
+	best := ""
+	for name := range table {
+		if strings.HasPrefix(model, name) && len(name) > len(best) {
+			best = name
+		}
+	}
+	if best == "" {
+		return modelPrice{}, false
+	}
+	return table[best], true
+}
+
+// checkPrompt fails with errBudgetExceeded when sending a prompt of
+// promptTokens would take the run over budget, before any output is paid
+// for.
+func (c *costTracker) checkPrompt(promptTokens int) error {
+	if c == nil || c.budget == 0 {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if total := c.input + c.output + c.inputRate*picoUSD(promptTokens); total > c.budget {
+		return fmt.Errorf("%w: the next request would bring the cost to about %s, over MAX_COST_USD %s",
+			errBudgetExceeded, total, c.budget)
+	}
+	return nil
+}
+
+// charge adds the cost of a response and fails with errBudgetExceeded once
+// the total is over budget.
+func (c *costTracker) charge(inputTokens, outputTokens int) error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.input += c.inputRate * picoUSD(inputTokens)
+	c.output += c.outputRate * picoUSD(outputTokens)
+	if total := c.input + c.output; c.budget > 0 && total > c.budget {
+		return fmt.Errorf("%w: spent %s of MAX_COST_USD %s", errBudgetExceeded, total, c.budget)
+	}
+	return nil
+}
+
+// cost returns the price in USD of inputTokens and outputTokens, or 0 when
+// the price is unknown.
+func (c *costTracker) cost(inputTokens, outputTokens int) float64 {
+	if c == nil {
+		return 0
+	}
+	return (c.inputRate*picoUSD(inputTokens) + c.outputRate*picoUSD(outputTokens)).usd()
+}
+
+// breakdown returns the cost so far, or nil when the price is unknown.
+func (c *costTracker) breakdown() *costBreakdown {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return &costBreakdown{
+		InputUSD:  c.input.usd(),
+		OutputUSD: c.output.usd(),
+		TotalUSD:  (c.input + c.output).usd(),
+		BudgetUSD: c.budget.usd(),
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// loadExtraRequest parses EXTRA_REQUEST_JSON, a JSON object merged into the
+// body of every provider request after the runner has set its own fields.
+// It is an escape hatch for provider parameters the runner has no setting
+// for yet. It returns nil when the variable is unset.
+func loadExtraRequest(provider string) (map[string]any, error) {
+	v := getEnv("EXTRA_REQUEST_JSON", "")
+	if strings.TrimSpace(v) == "" {
+		return nil, nil
+	}
+	if provider == "bedrock" {
+		// The SDK signs the body before httpClient sees it.
+		return nil, fmt.Errorf("EXTRA_REQUEST_JSON is not supported for provider bedrock")
+	}
+	var extra map[string]any
+	dec := json.NewDecoder(strings.NewReader(v))
+	dec.UseNumber()
+	if err := dec.Decode(&extra); err != nil || extra == nil || dec.More() {
+		return nil, fmt.Errorf("invalid EXTRA_REQUEST_JSON: must be a JSON object")
+	}
+	return extra, nil
+}
+
+// mergeJSON merges patch into dst like a JSON merge patch (RFC 7386):
+// objects are merged key by key, null deletes a key, and anything else
+// replaces the value in dst.
+func mergeJSON(dst, patch map[string]any) {
+	for k, pv := range patch {
+		if pv == nil {
+			delete(dst, k)
+			continue
+		}
+		if po, ok := pv.(map[string]any); ok {
+			if do, ok := dst[k].(map[string]any); ok {
+				mergeJSON(do, po)
+				continue
+			}
+			do := map[string]any{}
+			mergeJSON(do, po)
+			dst[k] = do
+			continue
+		}
+		dst[k] = pv
+	}
+}
+
+// extraRequestKeys lists the fields patch sets as dotted paths with their
+// values, for the log. Values of sensitive-looking keys are redacted.
+func extraRequestKeys(patch map[string]any) []string {
+	var out []string
+	var walk func(prefix string, m map[string]any)
+	walk = func(prefix string, m map[string]any) {
+		for k, v := range m {
+			path := prefix + k
+			if sub, ok := v.(map[string]any); ok {
+				walk(path+".", sub)
+				continue
+			}
+			value := redacted
+			if !isSensitiveKey(k) {
+				b, _ := json.Marshal(v)
+				value = truncate(redact(string(b)), 80)
+			}
+			out = append(out, path+"="+value)
+		}
+	}
+	walk("", patch)
+	sort.Strings(out)
+	return out
+}
+
+// isSensitiveKey reports whether a request field name suggests a credential.
+func isSensitiveKey(name string) bool {
+	name = strings.ToLower(name)
+	for _, s := range []string{"key", "token", "secret", "password", "auth", "credential"} {
+		if strings.Contains(name, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// extraRequestTransport merges EXTRA_REQUEST_JSON into the JSON object body
+// of each request. It is the outermost transport, so SAVE_REQUEST, the
+// transcript and --dry-run show the request as sent.
+type extraRequestTransport struct {
+	base  http.RoundTripper
+	extra map[string]any
+}
+
+func (t *extraRequestTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body == nil {
+		return t.base.RoundTrip(req)
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	var obj map[string]any
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if dec.Decode(&obj) == nil && obj != nil {
+		mergeJSON(obj, t.extra)
+		if merged, err := json.Marshal(obj); err == nil {
+			body = merged
+		}
+	}
+	r := req.Clone(req.Context())
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	r.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(body)), nil }
+	r.ContentLength = int64(len(body))
+	return t.base.RoundTrip(r)
+}
+
+// logExtraRequest logs the fields EXTRA_REQUEST_JSON sets.
+func logExtraRequest(extra map[string]any) {
+	log.Printf("EXTRA_REQUEST_JSON sets %s", strings.Join(extraRequestKeys(extra), ", "))
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+)
+
+// modelTarget is a model and the provider serving it, as listed in
+// MODEL_FALLBACKS.
+type modelTarget struct {
+	Model    string
+	Provider string
+}
+
+func (t modelTarget) String() string { return t.Model + "@" + t.Provider }
+
+// fallbackAttempt is one model a run with MODEL_FALLBACKS tried, in order,
+// in result.json.
+type fallbackAttempt struct {
+	Model    string `json:"model"`
+	Provider string `json:"provider"`
+	// ErrorCode is why the model was given up on; empty for the model that
+	// answered.
+	ErrorCode string `json:"errorCode,omitempty"`
+}
+
+// parseModelFallbacks parses MODEL_FALLBACKS, comma-separated
+// model[@provider] entries tried in order when the primary model fails.
+// The provider defaults to the run's MODEL_PROVIDER.
+func parseModelFallbacks(v, provider string) ([]modelTarget, error) {
+	if strings.TrimSpace(v) == "" {
+		return nil, nil
+	}
+	var targets []modelTarget
+	for _, entry := range strings.Split(v, ",") {
+		entry = strings.TrimSpace(entry)
+		t := modelTarget{Model: entry, Provider: provider}
+		if i := strings.LastIndex(entry, "@"); i >= 0 {
+			t.Model, t.Provider = entry[:i], canonicalProvider(entry[i+1:])
+		}
+		if t.Model == "" || t.Provider == "" {
+			return nil, fmt.Errorf("invalid MODEL_FALLBACKS entry %q: want model or model@provider", entry)
+		}
+		targets = append(targets, t)
+	}
+	return targets, nil
+}
+
+// shouldFallback reports whether out failed in a way the next fallback
+// model may not: the model was not found, the account is out of quota, or
+// retries were exhausted. Authentication errors would fail the same way,
+// and a failure after tool calls is not retried, so no tool runs twice.
+func shouldFallback(ctx context.Context, out taskOutcome) bool {
+	if out.err == nil || out.llm.ToolCalls > 0 || ctx.Err() != nil || terminated.Load() {
+		return false
+	}
+	var apiErr *apiError
+	if errors.As(out.err, &apiErr) {
+		switch {
+		case apiErr.Class == "authentication_error":
+			return false
+		case apiErr.StatusCode == 404 || apiErr.Retryable:
+			return true
+		}
+		msg := strings.ToLower(apiErr.Message)
+		return strings.Contains(msg, "insufficient_quota") || strings.Contains(msg, "model_not_found")
+	}
+	// Transport errors are only returned once retries are exhausted.
+	var netErr net.Error
+	return errors.As(out.err, &netErr)
+}
+
+// withModel returns c calling target instead, with the API key and default
+// base URL of target's provider when it differs. It also switches the
+// run-wide settings that depend on the model (pricing, model family and
+// sampling), so metrics and cost reflect the model that answers; the
+// spending so far carries over. images is the number of attachments.
+func (c taskCall) withModel(target modelTarget, images int) (taskCall, error) {
+	if images > 0 {
+		if err := checkImageInput(target.Provider, target.Model, images); err != nil {
+			return c, err
+		}
+	}
+	if target.Provider == "anthropic" && c.provider != "anthropic" {
+		if err := checkTurnOrder(history); err != nil {
+			return c, fmt.Errorf("history: %w", err)
+		}
+	}
+	var cred apiCredential
+	if target.Provider != c.provider {
+		var err error
+		if cred, err = resolveAPIKey(target.Provider); err != nil {
+			return c, err
+		}
+	}
+	family, err := modelFamily(target.Provider, target.Model)
+	if err != nil {
+		return c, err
+	}
+	params, err := loadSamplingParams(target.Provider)
+	if err != nil {
+		return c, err
+	}
+	tracker, err := costs.forModel(target.Model)
+	if err != nil {
+		return c, err
+	}
+
+	next := c
+	next.provider, next.model = target.Provider, target.Model
+	if target.Provider != c.provider {
+		next.apiKey, next.keyFile, next.baseURL = cred.Key, cred.file, ""
+		if cred.Source != "" {
+			log.Printf("using API key from %s for %s", cred.Source, target)
+		}
+	}
+	costs, sampling, reasoningModel = tracker, params, family == familyReasoning
+	setRunSpanModel(target.Provider, target.Model)
+	return next, nil
+}
+
+// runWithFallbacks runs the task on c and, each time it fails in a way the
+// next model may not (see shouldFallback), again from scratch on the next
+// of fallbacks. It returns the last outcome, the taskCall that produced it
+// and, when more than one model was tried, every model in order. A
+// fallback that cannot serve the run (e.g. a text-only model given images)
+// is recorded and skipped.
+func (c taskCall) runWithFallbacks(ctx context.Context, fallbacks []modelTarget, systemPrompt, task string, msgs []historyMessage, images []imageAttachment) (taskOutcome, taskCall, []fallbackAttempt) {
+	out := c.run(ctx, systemPrompt, task, msgs, images)
+	tried := []fallbackAttempt{{Model: c.model, Provider: c.provider, ErrorCode: outcomeErrorCode(out)}}
+	for i, target := range fallbacks {
+		if !shouldFallback(ctx, out) {
+			break
+		}
+		next, err := c.withModel(target, len(images))
+		if err != nil {
+			log.Printf("skipping fallback %s: %v", target, err)
+			tried = append(tried, fallbackAttempt{Model: target.Model, Provider: target.Provider, ErrorCode: attachmentErrorCode(err)})
+			continue
+		}
+		log.Printf("%s failed (%s); falling back to %s (%d/%d)", modelTarget{c.model, c.provider}, outcomeErrorCode(out), target, i+1, len(fallbacks))
+		c = next
+		out = c.run(ctx, systemPrompt, task, msgs, images)
+		tried = append(tried, fallbackAttempt{Model: c.model, Provider: c.provider, ErrorCode: outcomeErrorCode(out)})
+	}
+	if len(tried) == 1 {
+		tried = nil
+	}
+	return out, c, tried
+}
+
+// outcomeErrorCode is the errorCode of out, or "" when it succeeded.
+func outcomeErrorCode(out taskOutcome) string {
+	if out.err == nil {
+		return ""
+	}
+	return errorCode(out.err)
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+)
+
+var (
+	// printResponse is --print: the response is written to stdout instead
+	// of the result marker the controller reads from the pod logs.
+	printResponse bool
+	// dryRun is --dry-run: the first provider request is printed to stdout
+	// instead of being sent.
+	dryRun bool
+	// taskFromStdin is --task -: stdin is read even from a terminal.
+	taskFromStdin bool
+)
+
+// runnerFlags are the command-line flags for running the agent-runner on a
+// workstation. In a pod it is started without arguments and configured by
+// environment variables and /ipc files alone, which the flags only
+// override.
+type runnerFlags struct {
+	task      string
+	taskFile  string
+	outputDir string
+	envFile   string
+	raw       string
+	print     bool
+	dryRun    bool
+}
+
+const runnerUsage = `Usage: agent-runner [flags]
+
+Runs one agent task. In a pod the runner is configured by environment
+variables and files under /ipc; these flags are for running it locally:
+
+  agent-runner --env-file .env --task "Summarize the README" --print
+  agent-runner --task-file prompt.md --output-dir ./out
+  echo "hello" | agent-runner --task - --dry-run
+  agent-runner --task "hi" --raw '{"reasoning_effort":"high"}' --dry-run
+
+Flags:
+`
+
+func parseRunnerFlags(args []string, stderr io.Writer) (runnerFlags, error) {
+	var f runnerFlags
+	fs := flag.NewFlagSet("agent-runner", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&f.task, "task", "", "the task (TASK); - reads it from stdin")
+	fs.StringVar(&f.taskFile, "task-file", "", "read the task from this file (TASK_FILE)")
+	fs.StringVar(&f.outputDir, "output-dir", "", "write result.json and the other output files here instead of $IPC_DIR/output")
+	fs.StringVar(&f.envFile, "env-file", "", "set the KEY=VALUE lines of this file (e.g. API keys) that are not already in the environment")
+	fs.StringVar(&f.raw, "raw", "", "JSON object merged into every provider request (EXTRA_REQUEST_JSON)")
+	fs.BoolVar(&f.print, "print", false, "print the response to stdout")
+	fs.BoolVar(&f.dryRun, "dry-run", false, "print the first provider request as JSON instead of sending it")
+	fs.Usage = func() {
+		fmt.Fprint(stderr, runnerUsage)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return f, err
+	}
+	if fs.NArg() > 0 {
+		err := fmt.Errorf("unexpected argument %q", fs.Arg(0))
+		fmt.Fprintln(stderr, err)
+		fs.Usage()
+		return f, err
+	}
+	if f.task != "" && f.taskFile != "" {
+		err := errors.New("--task and --task-file are mutually exclusive")
+		fmt.Fprintln(stderr, err)
+		return f, err
+	}
+	return f, nil
+}
+
+// apply sets up the run the flags describe and resolves the IPC layout. The
+// env file is loaded first, so the task flags win over a TASK or TASK_FILE
+// it sets, and it may set IPC_DIR.
+func (f runnerFlags) apply() error {
+	if f.envFile != "" {
+		if err := loadEnvFile(f.envFile); err != nil {
+			return err
+		}
+	}
+	layout, err := loadIPCLayout()
+	if err != nil {
+		return err
+	}
+	ipc = layout
+	switch {
+	case f.task == "-":
+		os.Unsetenv("TASK")
+		os.Unsetenv("TASK_FILE")
+		taskFromStdin = true
+	case f.task != "":
+		os.Setenv("TASK", f.task)
+		os.Unsetenv("TASK_FILE")
+	case f.taskFile != "":
+		os.Setenv("TASK_FILE", f.taskFile)
+	}
+	if f.raw != "" {
+		os.Setenv("EXTRA_REQUEST_JSON", f.raw)
+	}
+	if f.outputDir != "" {
+		ipc.Output, ipc.Done = f.outputDir, ""
+	}
+	printResponse, dryRun = f.print, f.dryRun
+	return nil
+}
+
+// loadEnvFile sets the variables of a .env file that are not already set,
+// so the environment still wins. Lines are KEY=VALUE, optionally prefixed
+// with export and with the value in single or double quotes; blank lines
+// and # comments are skipped.
+func loadEnvFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("--env-file: %w", err)
+	}
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" || strings.ContainsAny(key, " \t") {
+			return fmt.Errorf("--env-file %s:%d: want KEY=VALUE", path, n)
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		if _, set := os.LookupEnv(key); !set {
+			os.Setenv(key, value)
+		}
+	}
+	return sc.Err()
+}
+
+// errDryRun ends a --dry-run at the first provider request.
+var errDryRun = errors.New("dry run: the request was not sent")
+
+// dryRunTransport prints each request body as indented JSON instead of
+// sending it. It replaces the other transports, so nothing is retried or
+// recorded; only EXTRA_REQUEST_JSON is merged in first.
+type dryRunTransport struct {
+	out io.Writer
+}
+
+func (t *dryRunTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	saved, err := captureRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("dry run: %s %s", saved.Method, saved.URL)
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, saved.Body, "", "  "); err != nil {
+		buf.Write(saved.Body)
+	}
+	buf.WriteByte('\n')
+	if _, err := t.out.Write(redactBytes(buf.Bytes())); err != nil {
+		return nil, err
+	}
+	return nil, errDryRun
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/openai/openai-go/v3"
+)
+
+const (
+	// historyFile is the conversation history, in ipc.Input.
+	historyFile = "messages.json"
+	// defaultMaxHistoryTokens is the default MAX_HISTORY_TOKENS.
+	defaultMaxHistoryTokens = 16000
+)
+
+// historyMessage is one prior message of a conversation. Tool messages
+// carry the output of a tool call from an earlier run. Leading system
+// messages add instructions after the system prompt, and assistant
+// messages can prime the model with example answers (few-shot prompting).
+type historyMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+	// Name is the tool that produced a tool message, when known.
+	Name string `json:"name,omitempty"`
+}
+
+// history is the prior conversation sent between the system prompt and the
+// task; empty for single-shot runs.
+var history []historyMessage
+
+// historyKey is the context key for a call's own history.
+type historyKey struct{}
+
+// withHistory returns ctx carrying msgs as the history for the provider
+// calls made with it. runTask uses it so a retry can change the history it
+// sends, and concurrent batch tasks each send their own, without touching
+// the shared history.
+func withHistory(ctx context.Context, msgs []historyMessage) context.Context {
+	return context.WithValue(ctx, historyKey{}, msgs)
+}
+
+// historyFor returns the history set on ctx by withHistory, or the shared
+// history.
+func historyFor(ctx context.Context) []historyMessage {
+	if msgs, ok := ctx.Value(historyKey{}).([]historyMessage); ok {
+		return msgs
+	}
+	return history
+}
+
+// loadHistory reads prior messages from path, either as a JSON array or as
+// {"messages": [...]}. A missing file is not an error.
+func loadHistory(path string) ([]historyMessage, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var msgs []historyMessage
+	if err := json.Unmarshal(data, &msgs); err != nil {
+		var doc struct {
+			Messages []historyMessage `json:"messages"`
+		}
+		if err2 := json.Unmarshal(data, &doc); err2 != nil {
+			return nil, err
+		}
+		msgs = doc.Messages
+	}
+	if len(msgs) == 0 {
+		return nil, fmt.Errorf("no messages")
+	}
+	for i, m := range msgs {
+		switch m.Role {
+		case "system":
+			if i > 0 && msgs[i-1].Role != "system" {
+				return nil, fmt.Errorf("message %d: system messages must come before the conversation", i)
+			}
+		case "user", "assistant", "tool":
+		default:
+			return nil, fmt.Errorf("message %d: unsupported role %q", i, m.Role)
+		}
+		if m.Content == "" {
+			return nil, fmt.Errorf("message %d: content is empty", i)
+		}
+	}
+	return msgs, nil
+}
+
+// trimHistory drops the oldest messages until the rest fit in maxTokens
+// (estimated), then keeps dropping until the conversation starts with a
+// user message so no turn is left half-sent. Leading system messages are
+// always kept and count toward maxTokens. It returns the kept messages and
+// how many were dropped.
+func trimHistory(msgs []historyMessage, maxTokens int) ([]historyMessage, int) {
+	system, conv := splitSystemHistory(msgs)
+	total := 0
+	for _, m := range msgs {
+		total += estimateTokens(m.Content)
+	}
+	dropped := 0
+	for dropped < len(conv) && (total > maxTokens || conv[dropped].Role != "user") {
+		total -= estimateTokens(conv[dropped].Content)
+		dropped++
+	}
+	return append(system[:len(system):len(system)], conv[dropped:]...), dropped
+}
+
+// splitSystemHistory splits msgs into its leading system messages and the
+// conversation that follows.
+func splitSystemHistory(msgs []historyMessage) (system, conv []historyMessage) {
+	n := 0
+	for n < len(msgs) && msgs[n].Role == "system" {
+		n++
+	}
+	return msgs[:n], msgs[n:]
+}
+
+// historySystemPrompt returns the content of the leading system messages,
+// for providers that take instructions in a separate system field.
+func historySystemPrompt(msgs []historyMessage) []string {
+	system, _ := splitSystemHistory(msgs)
+	var out []string
+	for _, m := range system {
+		out = append(out, m.Content)
+	}
+	return out
+}
+
+// checkTurnOrder returns an error unless the conversation in msgs, followed
+// by the task as a user message, alternates between user and assistant
+// turns, as Anthropic requires. Tool messages are sent as user turns.
+func checkTurnOrder(msgs []historyMessage) error {
+	_, conv := splitSystemHistory(msgs)
+	prev := ""
+	for i, m := range conv {
+		role := m.Role
+		if role == "tool" {
+			role = "user"
+		}
+		if role == prev {
+			return fmt.Errorf("history message %d: two %s turns in a row; user and assistant messages must alternate", len(msgs)-len(conv)+i, role)
+		}
+		prev = role
+	}
+	if prev == "user" {
+		return fmt.Errorf("history ends with a user turn; it must end with an assistant message since the task is sent as the next user turn")
+	}
+	return nil
+}
+
+// maxHistoryTokens parses MAX_HISTORY_TOKENS.
+func maxHistoryTokens() (int, error) {
+	v := getEnv("MAX_HISTORY_TOKENS", "")
+	if v == "" {
+		return defaultMaxHistoryTokens, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid MAX_HISTORY_TOKENS %q", v)
+	}
+	return n, nil
+}
+
+// text returns the message content as sent to the model. Tool messages
+// become user text: the tool calls they answered were made in an earlier
+// run and are not part of the request, so they cannot be sent as tool
+// results.
+func (m historyMessage) text() string {
+	if m.Role != "tool" {
+		return m.Content
+	}
+	if m.Name != "" {
+		return fmt.Sprintf("[Output of tool %s from earlier in the conversation]\n%s", m.Name, m.Content)
+	}
+	return "[Tool output from earlier in the conversation]\n" + m.Content
+}
+
+// anthropicHistory converts history to Anthropic messages. System messages
+// are sent in the system field instead (see historySystemPrompt).
+func anthropicHistory(msgs []historyMessage) []anthropic.MessageParam {
+	var out []anthropic.MessageParam
+	_, conv := splitSystemHistory(msgs)
+	for _, m := range conv {
+		block := anthropic.NewTextBlock(m.text())
+		if m.Role == "assistant" {
+			out = append(out, anthropic.NewAssistantMessage(block))
+		} else {
+			out = append(out, anthropic.NewUserMessage(block))
+		}
+	}
+	return out
+}
+
+// openAIHistory converts history to chat completion messages.
+func openAIHistory(msgs []historyMessage) []openai.ChatCompletionMessageParamUnion {
+	var out []openai.ChatCompletionMessageParamUnion
+	for _, m := range msgs {
+		switch m.Role {
+		case "system":
+			out = append(out, openAISystemMessage(m.Content))
+		case "assistant":
+			out = append(out, openai.AssistantMessage(m.text()))
+		default:
+			out = append(out, openai.UserMessage(m.text()))
+		}
+	}
+	return out
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// defaultMaxIdleConns is the default MAX_IDLE_CONNS. The tool-calling loop
+// talks to a single endpoint, so this is also the per-host limit (Go's
+// default of 2 per host would close connections between retries).
+const defaultMaxIdleConns = 16
+
+// httpClient is shared by every provider call, retry and tool-loop
+// iteration so connections (and their TLS sessions) are reused rather than
+// re-established per request. main replaces it when MAX_IDLE_CONNS or a TLS
+// setting is set. Its transport retries transient failures (see
+// retryTransport).
+var httpClient = newHTTPClient(defaultMaxIdleConns, nil)
+
+// newHTTPClient returns a client whose transport keeps up to maxIdle idle
+// connections alive and uses tlsConfig, or Go's defaults when nil. No
+// client-wide timeout is set: requests are bounded by their context, and
+// responses may legitimately take minutes.
+func newHTTPClient(maxIdle int, tlsConfig *tls.Config) *http.Client {
+	return &http.Client{Transport: &retryTransport{base: newTransport(maxIdle, tlsConfig)}}
+}
+
+// newTransport returns the transport under httpClient's retries, with the
+// proxy settings and tlsConfig every connection the runner makes must use.
+func newTransport(maxIdle int, tlsConfig *tls.Config) *http.Transport {
+	return &http.Transport{
+		Proxy: proxyFromEnvironment(),
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          maxIdle,
+		MaxIdleConnsPerHost:   maxIdle,
+		IdleConnTimeout:       90 * time.Second,
+		TLSClientConfig:       tlsConfig,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+}
+
+// proxyFromEnvironment returns the proxy selector every transport built by
+// the runner must use. It honours HTTPS_PROXY, HTTP_PROXY and NO_PROXY (or
+// their lowercase forms), including socks5:// proxies. Unlike
+// http.ProxyFromEnvironment, which caches the variables on first use, they
+// are read when the transport is built.
+func proxyFromEnvironment() func(*http.Request) (*url.URL, error) {
+	proxy := httpproxy.FromEnvironment().ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return proxy(req.URL)
+	}
+}
+
+// loadTLSConfig builds the TLS settings for provider connections, for
+// self-hosted gateways behind an internal CA or requiring mTLS:
+//
+//   - CA_CERT_FILE: a PEM bundle trusted in addition to the system roots
+//   - TLS_CLIENT_CERT_FILE and TLS_CLIENT_KEY_FILE: a client certificate
+//   - TLS_INSECURE_SKIP_VERIFY=true: no certificate verification at all
+//
+// It returns nil when none is set. Files are read here, so a bad path fails
+// the run at startup rather than on the first request.
+func loadTLSConfig() (*tls.Config, error) {
+	caFile := getEnv("CA_CERT_FILE", "")
+	certFile, keyFile := getEnv("TLS_CLIENT_CERT_FILE", ""), getEnv("TLS_CLIENT_KEY_FILE", "")
+	insecure := false
+	if v := getEnv("TLS_INSECURE_SKIP_VERIFY", ""); v != "" {
+		var err error
+		if insecure, err = strconv.ParseBool(v); err != nil {
+			return nil, fmt.Errorf("invalid TLS_INSECURE_SKIP_VERIFY %q", v)
+		}
+	}
+	if caFile == "" && certFile == "" && keyFile == "" && !insecure {
+		return nil, nil
+	}
+
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("CA_CERT_FILE: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("CA_CERT_FILE %s: no PEM certificates found", caFile)
+		}
+		cfg.RootCAs = pool
+		log.Printf("trusting the CA certificates in %s", caFile)
+	}
+	if certFile != "" || keyFile != "" {
+		if certFile == "" || keyFile == "" {
+			return nil, fmt.Errorf("TLS_CLIENT_CERT_FILE and TLS_CLIENT_KEY_FILE must be set together")
+		}
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("TLS client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+		log.Printf("using TLS client certificate %s", certFile)
+	}
+	if insecure {
+		cfg.InsecureSkipVerify = true
+		log.Printf("WARNING: TLS_INSECURE_SKIP_VERIFY=true: provider certificates are NOT verified and the connection can be intercepted; use CA_CERT_FILE instead")
+	}
+	return cfg, nil
+}
+
+// maxIdleConns returns MAX_IDLE_CONNS, or defaultMaxIdleConns if unset.
+func maxIdleConns() (int, error) {
+	v := getEnv("MAX_IDLE_CONNS", "")
+	if v == "" {
+		return defaultMaxIdleConns, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid MAX_IDLE_CONNS %q", v)
+	}
+	return n, nil
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/alexsjones/sympozium/internal/ipcfile"
+)
+
+// defaultIPCDir is the default IPC_DIR, where the pod mounts the volume
+// shared with the IPC bridge.
+const defaultIPCDir = "/ipc"
+
+// ipcLayout is where the runner reads its input and writes its output. All
+// of it derives from IPC_DIR, so two runner containers in one pod can use
+// separate directories.
+type ipcLayout struct {
+	// Root is IPC_DIR. The other directories under it (messages, tools,
+	// schedules) are watched by the bridge.
+	Root string
+	// Input holds task.json, messages.json, vars.json and the other input
+	// files.
+	Input string
+	// Output receives result.json and the other output files; --output-dir
+	// moves it.
+	Output string
+	// Done is the sentinel telling the bridge and sidecars that the run is
+	// over. It is empty with --output-dir, as no bridge is watching.
+	Done string
+}
+
+// ipc is the layout of this run; runnerFlags.apply resolves it at startup.
+var ipc = newIPCLayout(defaultIPCDir)
+
+func newIPCLayout(root string) ipcLayout {
+	return ipcLayout{
+		Root:   root,
+		Input:  filepath.Join(root, "input"),
+		Output: filepath.Join(root, "output"),
+		Done:   filepath.Join(root, "done"),
+	}
+}
+
+// loadIPCLayout returns the layout under IPC_DIR.
+func loadIPCLayout() (ipcLayout, error) {
+	root := getEnv("IPC_DIR", defaultIPCDir)
+	if strings.TrimSpace(root) == "" {
+		return ipcLayout{}, fmt.Errorf("invalid IPC_DIR %q", root)
+	}
+	// The read_file tool allows paths under Root, so it must be absolute.
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return ipcLayout{}, fmt.Errorf("invalid IPC_DIR %q: %w", root, err)
+	}
+	return newIPCLayout(abs), nil
+}
+
+// input returns the path of the input file name.
+func (l ipcLayout) input(name string) string {
+	return filepath.Join(l.Input, name)
+}
+
+// output returns the path of the output file name.
+func (l ipcLayout) output(name string) string {
+	return filepath.Join(l.Output, name)
+}
+
+// dir returns the path of the directory name under Root.
+func (l ipcLayout) dir(name string) string {
+	return filepath.Join(l.Root, name)
+}
+
+// checkWritable creates Output if needed and makes sure a file can be
+// written there, so a run never ends with its result going nowhere.
+func (l ipcLayout) checkWritable() error {
+	if err := os.MkdirAll(l.Output, 0o755); err != nil {
+		return fmt.Errorf("IPC output directory %s is not writable (set IPC_DIR or --output-dir): %w", l.Output, err)
+	}
+	f, err := os.CreateTemp(l.Output, ".write-check-*")
+	if err != nil {
+		return fmt.Errorf("IPC output directory %s is not writable (set IPC_DIR or --output-dir): %w", l.Output, err)
+	}
+	f.Close()
+	os.Remove(f.Name())
+	return nil
+}
+
+// writeDone writes the done sentinel, if any.
+func writeDone() {
+	if ipc.Done != "" {
+		_ = ipcfile.Write(ipc.Done, []byte("done"), 0o644)
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// errToolLoop marks a run stopped because the model repeated a tool call.
+var errToolLoop = errors.New("tool loop detected")
+
+// iterationLimitPrompt asks for a final answer once MAX_ITERATIONS tool-call
+// round-trips are used up; the last request is sent with tools disabled.
+const iterationLimitPrompt = "You have used all %d tool-call rounds allowed for this task. Do not call any more tools. " +
+	"Using what you have gathered so far, give your best final answer now, and say what is left unfinished."
+
+// iterationSummary is one model call of the tool-call loop, in result.json
+// metrics and as an "iteration" stream chunk.
+type iterationSummary struct {
+	// Iteration counts from 1.
+	Iteration int `json:"iteration"`
+	// InputTokens includes prompt tokens served from the provider's cache.
+	InputTokens  int    `json:"inputTokens"`
+	OutputTokens int    `json:"outputTokens"`
+	ToolCalls    int    `json:"toolCalls"`
+	StopReason   string `json:"stopReason,omitempty"`
+	// CostUSD is absent when the model's price is unknown.
+	CostUSD float64 `json:"costUsd,omitempty"`
+}
+
+// endIteration records the usage of model call i (from 0) and the tool
+// calls it led to, and emits them as an iteration chunk.
+func (r *llmResult) endIteration(i, in, out, toolCalls int, stopReason string) {
+	it := iterationSummary{
+		Iteration:    i + 1,
+		InputTokens:  in,
+		OutputTokens: out,
+		ToolCalls:    toolCalls,
+		StopReason:   stopReason,
+		CostUSD:      costs.cost(in, out),
+	}
+	r.Iterations = append(r.Iterations, it)
+	b, _ := json.Marshal(it)
+	chunkOut.writeChunk("iteration", "", string(b))
+}
+
+// toolLoopGuard stops a model that issues the same tool call, with the
+// same arguments, twice in a row: it would get the same result again.
+type toolLoopGuard struct {
+	last string
+}
+
+// check returns an errToolLoop error when name and argsJSON repeat the
+// previous call. Arguments are compared as JSON values, so formatting and
+// key order do not matter.
+func (g *toolLoopGuard) check(name, argsJSON string) error {
+	args := argsJSON
+	var v any
+	if json.Unmarshal([]byte(argsJSON), &v) == nil {
+		if b, err := json.Marshal(v); err == nil {
+			args = string(b)
+		}
+	}
+	call := name + " " + args
+	if call == g.last {
+		return fmt.Errorf("%w: the model called %s with the same arguments twice in a row: %s",
+			errToolLoop, name, truncateRedacted(args, 200))
+	}
+	g.last = call
+	return nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import "fmt"
+
+// apiError is an HTTP error from a provider API, classified the same way
+// for every provider. Retryable errors have already been retried by
+// retryTransport by the time they are returned.
+type apiError struct {
+	Provider   string
+	StatusCode int
+	// Class is a provider-neutral name for the failure, recorded as
+	// errorType in result.json.
+	Class     string
+	Retryable bool
+	Message   string
+	// Hint is optional advice appended to the message.
+	Hint string
+}
+
+func (e *apiError) Error() string {
+	state := "not retryable"
+	if e.Retryable {
+		state = "retries exhausted"
+	}
+	msg := fmt.Sprintf("%s API error (HTTP %d %s, %s): %s", e.Provider, e.StatusCode, e.Class, state, e.Message)
+	if e.Hint != "" {
+		msg += "\nHint: " + e.Hint
+	}
+	return msg
+}
+
+// newAPIError classifies an HTTP failure from provider. A 400 saying the
+// prompt is over the context window is a context_length_exceeded error.
+func newAPIError(provider string, statusCode int, message string) *apiError {
+	class, retryable := classifyStatus(statusCode)
+	if class == "invalid_request_error" && isContextLengthMessage(message) {
+		class = "context_length_exceeded"
+	}
+	return &apiError{
+		Provider:   provider,
+		StatusCode: statusCode,
+		Class:      class,
+		Retryable:  retryable,
+		Message:    truncateRedacted(message, 500),
+	}
+}
+
+// classifyStatus maps an HTTP status to an error class and whether it is
+// transient, which makes retryTransport retry it. The retryable set matches
+// what both SDKs retry: 408, 409, 429 and 5xx, including Anthropic's 529
+// overloaded_error.
+func classifyStatus(code int) (class string, retryable bool) {
+	switch {
+	case code == 401 || code == 403:
+		return "authentication_error", false
+	case code == 404:
+		return "not_found_error", false
+	case code == 408:
+		return "timeout_error", true
+	case code == 409:
+		return "conflict_error", true
+	case code == 413:
+		return "request_too_large", false
+	case code == 429:
+		return "rate_limit_error", true
+	case code == 529:
+		return "overloaded_error", true
+	case code >= 500:
+		return "server_error", true
+	default:
+		return "invalid_request_error", false
+	}
+}
@@ -2,69 +2,365 @@ package main
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/anthropics/anthropic-sdk-go"
 	anthropicoption "github.com/anthropics/anthropic-sdk-go/option"
 	"github.com/openai/openai-go/v3"
-	"github.com/openai/openai-go/v3/azure"
 	openaioption "github.com/openai/openai-go/v3/option"
 	"github.com/openai/openai-go/v3/shared"
+
+	"github.com/alexsjones/sympozium/internal/ipcfile"
 )
 
-// maxToolIterations is the maximum number of tool-call round-trips before
-// the agent stops and returns whatever text it has.
-const maxToolIterations = 25
+// defaultMaxToolIterations is the default MAX_ITERATIONS: the maximum number
+// of tool-call round-trips before the model is asked for a final answer.
+// It is kept low so an unconfigured run cannot rack up many paid calls.
+const defaultMaxToolIterations = 5
+
+// maxToolIterations is the tool-call round-trip limit for this run.
+var maxToolIterations = defaultMaxToolIterations
+
+// loadMaxIterations reads MAX_ITERATIONS, a positive number of tool-call
+// round-trips, defaulting to defaultMaxToolIterations.
+func loadMaxIterations() (int, error) {
+	v := getEnv("MAX_ITERATIONS", "")
+	if v == "" {
+		return defaultMaxToolIterations, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid MAX_ITERATIONS %q", v)
+	}
+	return n, nil
+}
 
 type agentResult struct {
-	Status   string `json:"status"`
-	Response string `json:"response,omitempty"`
-	Error    string `json:"error,omitempty"`
-	Metrics  struct {
+	// SchemaVersion is outputSchemaVersion; see result.go.
+	SchemaVersion int    `json:"schemaVersion"`
+	Status        string `json:"status"` // success, error or cancelled
+	Response      string `json:"response,omitempty"`
+	Error         string `json:"error,omitempty"`
+	// ErrorType classifies provider API failures (rate_limit_error,
+	// overloaded_error, authentication_error, ...).
+	ErrorType string `json:"errorType,omitempty"`
+	// ErrorCode is set on every failure: the ErrorType for API errors,
+	// otherwise config_error, timeout_error, stream_interrupted, canceled,
+	// terminated, schema_validation_failed, budget_exceeded,
+	// input_too_large, task_validation_failed, tls_config_invalid,
+	// batch_failed, preflight_failed, tool_loop_detected,
+	// attachments_unsupported, ipc_unwritable or unknown_error.
+	ErrorCode string `json:"errorCode,omitempty"`
+	Provider  string `json:"provider,omitempty"`
+	Model     string `json:"model,omitempty"`
+	// StopReason is why the model stopped generating, as reported by the
+	// provider (end_turn, max_tokens, stop, length, ...).
+	StopReason string `json:"stopReason,omitempty"`
+	// FinishReason is StopReason normalized to stop, length,
+	// content_filter or tool_calls.
+	FinishReason string `json:"finishReason,omitempty"`
+	// Truncated is set when the model hit its output token limit
+	// (finishReason length), so Response is incomplete.
+	Truncated bool `json:"truncated,omitempty"`
+	// Attempts is the number of HTTP requests sent to the provider,
+	// retries included.
+	Attempts int `json:"attempts"`
+	// StartedAt and CompletedAt are RFC3339 UTC timestamps.
+	StartedAt   string `json:"startedAt,omitempty"`
+	CompletedAt string `json:"completedAt,omitempty"`
+	// IterationLimitReached is set when the tool-call loop used up
+	// MAX_ITERATIONS and Response is the model's best-effort answer,
+	// requested with tools disabled.
+	IterationLimitReached bool `json:"iterationLimitReached,omitempty"`
+	// Partial is set when a streamed response was cut off; Response then
+	// holds the text received before the interruption.
+	Partial bool `json:"partial,omitempty"`
+	// ToolCalls lists every tool the model invoked, in order, with its
+	// (truncated) output.
+	ToolCalls []toolCallRecord `json:"toolCalls,omitempty"`
+	// HistoryMessages and HistoryDropped count the prior messages from
+	// messages.json that were sent, and dropped to fit MAX_HISTORY_TOKENS.
+	HistoryMessages int `json:"historyMessages,omitempty"`
+	HistoryDropped  int `json:"historyDropped,omitempty"`
+	// TaskID is the tasks.json id, on the per-task results of a batch.
+	TaskID string `json:"taskId,omitempty"`
+	// Batch summarizes a tasks.json batch in the aggregate result; the
+	// per-task results are in results/<id>.json.
+	Batch *batchSummary `json:"batch,omitempty"`
+	// ContextRecovery is set when a context-length error was retried with
+	// a shorter prompt, and says what was dropped.
+	ContextRecovery *contextRecovery `json:"contextRecovery,omitempty"`
+	// ContextChunks and ContextDropped count the retrieved documents from
+	// context.json that were sent, and dropped to fit MAX_CONTEXT_TOKENS.
+	ContextChunks  int `json:"contextChunks,omitempty"`
+	ContextDropped int `json:"contextDropped,omitempty"`
+	// Rendered holds SYSTEM_PROMPT and TASK after template rendering; absent
+	// when no template variables were given.
+	Rendered *renderedPrompts `json:"rendered,omitempty"`
+	// Parameters are the sampling parameters sent with the request; absent
+	// when the provider's defaults were used.
+	Parameters *samplingParams `json:"parameters,omitempty"`
+	// MCPServers says which MCP_SERVERS were connected and how many tools
+	// each contributed; a server that failed lists its error.
+	MCPServers []mcpServerStatus `json:"mcpServers,omitempty"`
+	// Attachments are the names, types and sizes of the images sent with
+	// the task.
+	Attachments []attachmentRecord `json:"attachments,omitempty"`
+	// Fallbacks lists every model tried, in order, when MODEL_FALLBACKS
+	// moved the run past the primary model; Provider and Model are the
+	// last one.
+	Fallbacks []fallbackAttempt `json:"fallbacks,omitempty"`
+	Metrics   struct {
 		DurationMs   int64 `json:"durationMs"`
 		InputTokens  int   `json:"inputTokens"`
 		OutputTokens int   `json:"outputTokens"`
 		ToolCalls    int   `json:"toolCalls"`
+		// CacheReadTokens and CacheCreationTokens are the prompt tokens the
+		// provider reported as served from, or written to, its prompt cache.
+		CacheReadTokens     int `json:"cacheReadTokens,omitempty"`
+		CacheCreationTokens int `json:"cacheCreationTokens,omitempty"`
+		// ReasoningTokens are the output tokens a reasoning model spent
+		// thinking, as reported by the provider; they are included in
+		// OutputTokens.
+		ReasoningTokens int `json:"reasoningTokens,omitempty"`
+		// PreSummary is the PRE_SUMMARIZE call that condensed an oversized
+		// task; its tokens are included in the counts above.
+		PreSummary *preSummary `json:"preSummary,omitempty"`
+		// Iterations breaks the usage down by model call of the tool-call
+		// loop.
+		Iterations []iterationSummary `json:"iterations,omitempty"`
+		// TokensEstimated is set when the provider reported no usage, or
+		// only part of it, and the missing counts were estimated from the
+		// text.
+		TokensEstimated bool `json:"tokensEstimated,omitempty"`
+		// Cost is the run's cost from the pricing table; absent when the
+		// model's price is unknown.
+		Cost *costBreakdown `json:"cost,omitempty"`
+		// SystemFingerprint identifies the backend configuration that
+		// served an OpenAI-compatible request; with SEED and temperature 0
+		// it tells whether outputs are expected to be reproducible.
+		SystemFingerprint string `json:"systemFingerprint,omitempty"`
 	} `json:"metrics"`
 }
 
+// llmResult is the outcome of a provider call, with usage summed over every
+// tool-call round-trip.
+type llmResult struct {
+	Text                string
+	StopReason          string
+	TokensEstimated     bool
+	SystemFingerprint   string
+	InputTokens         int
+	OutputTokens        int
+	ToolCalls           int
+	CacheReadTokens     int
+	CacheCreationTokens int
+	ReasoningTokens     int
+	ToolInvocations     []toolCallRecord
+	Iterations          []iterationSummary
+	// IterationLimitReached is set when Text is the best-effort answer
+	// requested after MAX_ITERATIONS.
+	IterationLimitReached bool
+}
+
+// add folds a follow-up call into r: usage and tool calls are summed, and
+// the text and stop reason are replaced by the follow-up's.
+func (r *llmResult) add(next llmResult) {
+	r.Text = next.Text
+	r.StopReason = next.StopReason
+	r.TokensEstimated = r.TokensEstimated || next.TokensEstimated
+	r.SystemFingerprint = firstNonEmpty(next.SystemFingerprint, r.SystemFingerprint)
+	r.InputTokens += next.InputTokens
+	r.OutputTokens += next.OutputTokens
+	r.ToolCalls += next.ToolCalls
+	r.CacheReadTokens += next.CacheReadTokens
+	r.CacheCreationTokens += next.CacheCreationTokens
+	r.ReasoningTokens += next.ReasoningTokens
+	r.ToolInvocations = append(r.ToolInvocations, next.ToolInvocations...)
+	r.Iterations = append(r.Iterations, next.Iterations...)
+	r.IterationLimitReached = next.IterationLimitReached
+}
+
+// streamChunk is one stream-<n>.json file. Type is one of:
+//
+//   - "text": part of the response; result.json holds the concatenation
+//   - "thinking": the model's reasoning, when the provider exposes it
+//   - "tool_use": a tool call, as "<name> <arguments JSON>"
+//   - "tool_result": the tool's output
+//   - "iteration": an iterationSummary of one model call, as JSON
+//
+// Tool chunks carry the provider's tool call ID. Index orders chunks of all
+// types. SchemaVersion is outputSchemaVersion.
 type streamChunk struct {
-	Type    string `json:"type"`
-	Content string `json:"content"`
-	Index   int    `json:"index"`
+	SchemaVersion int    `json:"schemaVersion"`
+	Type          string `json:"type"`
+	Content       string `json:"content"`
+	ToolID        string `json:"toolId,omitempty"`
+	Index         int    `json:"index"`
 }
 
 func main() {
+	runStartedAt = time.Now()
+	// Without arguments, as in a pod, the flags change nothing.
+	flags, err := parseRunnerFlags(os.Args[1:], os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		os.Exit(2)
+	}
+	if err := flags.apply(); err != nil {
+		fmt.Fprintln(os.Stderr, "agent-runner:", err)
+		os.Exit(2)
+	}
 	log.SetFlags(log.Ltime | log.Lmicroseconds)
+	registerSecretEnv()
+	log.SetOutput(redactingWriter{os.Stderr})
 	log.Println("agent-runner starting")
-
-	task := getEnv("TASK", "")
-	if task == "" {
-		if b, err := os.ReadFile("/ipc/input/task.json"); err == nil {
-			var input struct {
-				Task string `json:"task"`
-			}
-			if json.Unmarshal(b, &input) == nil && input.Task != "" {
-				task = input.Task
-			}
+	if err := setupTracing(context.Background()); err != nil {
+		log.Printf("tracing disabled: %v", err)
+	}
+	runCtx := startRunSpan(context.Background())
+	metricsServer = startMetricsServer()
+
+	// Fail before the provider is called rather than lose the result.
+	// --print and --dry-run put what matters on stdout.
+	log.Printf("IPC directory: %s (output: %s)", ipc.Root, ipc.Output)
+	if err := ipc.checkWritable(); err != nil {
+		if !printResponse && !dryRun {
+			fatalCode("ipc_unwritable", err.Error())
 		}
+		log.Printf("warning: %v", err)
+	}
+	// The TLS settings also apply to RESULT_CALLBACK_URL, so they are read
+	// before it.
+	tlsConfig, err := loadTLSConfig()
+	if err != nil {
+		fatalCode("tls_config_invalid", err.Error())
+	}
+	if cb, err := loadResultCallback(tlsConfig); err != nil {
+		fatal(err.Error())
+	} else if cb != nil && !dryRun {
+		callback = cb
+		log.Printf("posting the result to RESULT_CALLBACK_URL (timeout %s)", cb.timeout)
+	}
+
+	maxInput, err := maxInputBytes()
+	if err != nil {
+		fatal(err.Error())
 	}
-	if task == "" {
-		fatal("TASK env var is empty and no /ipc/input/task.json found")
+	if taskSchema, err = loadTaskSchema(); err != nil {
+		fatal(err.Error())
+	}
+	// A tasks.json batch replaces the single task.
+	batch, err := loadBatch(ipc.input(batchFile), maxInput)
+	if err != nil {
+		fatalCode(inputErrorCode(err), err.Error())
+	}
+	var task string
+	if batch != nil && dryRun {
+		fatal("--dry-run does not support a " + ipc.input(batchFile) + " batch")
+	}
+	if batch != nil {
+		log.Printf("batch mode: %d task(s) from %s", len(batch), ipc.input(batchFile))
+	} else {
+		log.Printf("task sources: TASK_FILE, then TASK env, then %s, then stdin (when piped)", ipc.input(taskFile))
+		var taskSource string
+		task, taskSource, err = resolveInput("task", "TASK_FILE", maxInput, func() (string, string, error) {
+			return resolveTask(getEnv("TASK", ""), ipc.input(taskFile), os.Stdin, taskFromStdin || stdinIsPiped())
+		})
+		if err != nil {
+			fatalCode(inputErrorCode(err), err.Error())
+		}
+		if task == "" {
+			fatal("no task: TASK_FILE and TASK env vars are empty, no " + ipc.input(taskFile) + " found and nothing was piped to stdin")
+		}
+		log.Printf("task read from %s", taskSource)
 	}
 
-	systemPrompt := getEnv("SYSTEM_PROMPT", "You are a helpful AI assistant.")
-	provider := strings.ToLower(getEnv("MODEL_PROVIDER", "openai"))
-	modelName := getEnv("MODEL_NAME", "gpt-4o-mini")
+	systemPrompt, systemPromptSource, err := resolveInput("system prompt", "SYSTEM_PROMPT_FILE", maxInput, func() (string, string, error) {
+		if v := getEnv("SYSTEM_PROMPT", ""); v != "" {
+			return v, "SYSTEM_PROMPT env", nil
+		}
+		return "You are a helpful AI assistant.", "default", nil
+	})
+	if err != nil {
+		fatalCode(inputErrorCode(err), err.Error())
+	}
+	log.Printf("system prompt read from %s", systemPromptSource)
+	systemPrompt, task, rendered, err := renderPrompts(systemPrompt, task, ipc.input(varsFile))
+	if err != nil {
+		fatal(err.Error())
+	}
+	provider := canonicalProvider(getEnv("MODEL_PROVIDER", "openai"))
+	requestedModel := getEnv("MODEL_NAME", "")
+	if provider == "azure-openai" {
+		// Azure routes by deployment, which is sent as the model.
+		requestedModel = firstNonEmpty(getEnv("AZURE_OPENAI_DEPLOYMENT", ""), requestedModel)
+	}
+	modelName, substituted := resolveModel(provider, requestedModel)
+	if substituted {
+		log.Printf("MODEL_NAME not set for provider %s; using its default model %s", provider, modelName)
+	}
+	setRunSpanModel(provider, modelName)
+	fallbacks, err := parseModelFallbacks(getEnv("MODEL_FALLBACKS", ""), provider)
+	if err != nil {
+		fatal(err.Error())
+	}
+	if len(fallbacks) > 0 && batch != nil {
+		// Tasks run concurrently and share the model-dependent settings.
+		log.Printf("MODEL_FALLBACKS is ignored in batch mode")
+		fallbacks = nil
+	}
+	if len(fallbacks) > 0 {
+		names := make([]string, len(fallbacks))
+		for i, t := range fallbacks {
+			names[i] = t.String()
+		}
+		log.Printf("model fallbacks: %s", strings.Join(names, ", "))
+	}
+	if costs, err = loadCostTracker(modelName); err != nil {
+		fatal(err.Error())
+	}
+	if b := costs.breakdown(); b != nil && b.BudgetUSD > 0 {
+		log.Printf("cost budget: MAX_COST_USD=%g", b.BudgetUSD)
+	}
 	baseURL := strings.TrimRight(getEnv("MODEL_BASE_URL", ""), "/")
+	family, err := modelFamily(provider, modelName)
+	if err != nil {
+		fatal(err.Error())
+	}
+	if reasoningModel = family == familyReasoning; reasoningModel {
+		log.Printf("model family: %s (developer role, max_completion_tokens)", family)
+	}
+	if sampling, err = loadSamplingParams(provider); err != nil {
+		fatal(err.Error())
+	}
+	logSampling(provider, sampling)
+	if respFormat, err = loadResponseFormat(); err != nil {
+		fatal(err.Error())
+	}
+	if respFormat.JSON {
+		log.Printf("response format: %s", respFormat)
+	}
 	memoryEnabled := getEnv("MEMORY_ENABLED", "") == "true"
+	if memoryEnabled && batch != nil {
+		// One memory update per task would overwrite each other.
+		log.Printf("MEMORY_ENABLED is ignored in batch mode")
+		memoryEnabled = false
+	}
 	toolsEnabled := getEnv("TOOLS_ENABLED", "") == "true"
 
 	// Load skill files and build enhanced system prompt.
@@ -88,12 +384,41 @@ func main() {
 		log.Printf("channel context injected: channel=%s chatId=%s", sourceChannel, sourceChatID)
 	}
 
-	// Resolve tool definitions.
+	// Resolve tool definitions: the built-in tools plus any tools mounted
+	// by SkillPacks as /skills/*.json and those of the MCP_SERVERS, limited
+	// by TOOLS_ALLOWLIST.
 	var tools []ToolDef
 	if toolsEnabled {
-		tools = defaultTools()
+		skillTools = loadSkillTools(defaultSkillsDir)
+		toolAllowlist = parseToolAllowlist(getEnv("TOOLS_ALLOWLIST", ""))
+		all := append(defaultTools(), skillToolDefs()...)
+		if v := getEnv("MCP_SERVERS", ""); v != "" {
+			configs, err := parseMCPServers(v)
+			if err != nil {
+				fatal(err.Error())
+			}
+			mcpTools, mcpStatus = connectMCPServers(configs, tlsConfig)
+			all = append(all, mcpToolDefs()...)
+		}
+		if getEnv("TOOLS_SHELL", "") == "true" {
+			if shellPol, err = loadShellPolicy(defaultToolPolicyFile); err != nil {
+				fatal(err.Error())
+			}
+			all = append(all, shellPol.toolDef())
+			log.Printf("shell tool enabled: %d allowed command(s), timeout %s", len(shellPol.Allow), shellPol.timeout)
+		}
+		tools = filterAllowedTools(all)
 		log.Printf("tools enabled: %d tool(s) registered", len(tools))
 	}
+	if !toolsEnabled && getEnv("TOOLS_SHELL", "") == "true" {
+		log.Printf("WARNING: TOOLS_SHELL=true has no effect without TOOLS_ENABLED=true")
+	}
+	if !toolsEnabled && getEnv("MCP_SERVERS", "") != "" {
+		log.Printf("WARNING: MCP_SERVERS has no effect without TOOLS_ENABLED=true")
+	}
+	if maxToolIterations, err = loadMaxIterations(); err != nil {
+		fatal(err.Error())
+	}
 
 	// Read existing memory if available.
 	var memoryContent string
@@ -119,63 +444,277 @@ func main() {
 		systemPrompt += memoryInstruction
 	}
 
-	apiKey := firstNonEmpty(
-		os.Getenv("API_KEY"),
-		os.Getenv("OPENAI_API_KEY"),
-		os.Getenv("ANTHROPIC_API_KEY"),
-		os.Getenv("AZURE_OPENAI_API_KEY"),
-	)
+	// Add retrieved documents; like history, a bad file only loses context.
+	contextBudget, err := maxContextTokens()
+	if err != nil {
+		fatal(err.Error())
+	}
+	contextPath := getEnv("CONTEXT_FILE", ipc.input(contextFile))
+	var contextChunks, contextDropped int
+	if chunks, err := loadContextChunks(contextPath); err != nil {
+		log.Printf("ignoring retrieval context %s: %v", contextPath, err)
+	} else if len(chunks) > 0 {
+		kept, dropped := trimContext(chunks, contextBudget)
+		systemPrompt += formatContext(kept)
+		contextChunks, contextDropped = len(kept), dropped
+		log.Printf("loaded %d context chunk(s) from %s (%d dropped to fit %d tokens)",
+			len(kept), contextPath, dropped, contextBudget)
+	}
+	systemPrompt += respFormat.systemInstruction()
+
+	// Load prior conversation turns; a bad history file only loses context.
+	historyBudget, err := maxHistoryTokens()
+	if err != nil {
+		fatal(err.Error())
+	}
+	recoveryMode, err := contextRecoveryMode()
+	if err != nil {
+		fatal(err.Error())
+	}
+	summarizer, err := loadPreSummarizer()
+	if err != nil {
+		fatal(err.Error())
+	}
+	if summarizer != nil {
+		log.Printf("pre-summarization: tasks over ~%d tokens are summarized to ~%d first", summarizer.threshold, summarizer.target)
+	}
+	var historyDropped int
+	historyPath := ipc.input(historyFile)
+	if msgs, err := loadHistory(historyPath); err != nil {
+		log.Printf("ignoring conversation history %s: %v", historyPath, err)
+	} else if len(msgs) > 0 {
+		history, historyDropped = trimHistory(msgs, historyBudget)
+		log.Printf("loaded %d history message(s) from %s (%d dropped to fit %d tokens)",
+			len(history), historyPath, historyDropped, historyBudget)
+		// Unlike a malformed file, a wrong turn order is reported: the
+		// history is usually few-shot examples the task depends on.
+		if provider == "anthropic" {
+			if err := checkTurnOrder(history); err != nil {
+				fatal(fmt.Sprintf("%s: %v", historyPath, err))
+			}
+		}
+	}
+
+	// Load image attachments for vision-capable providers.
+	attachLimits, err := loadAttachmentLimits()
+	if err != nil {
+		fatal(err.Error())
+	}
+	manifest, err := parseAttachmentManifest(getEnv("ATTACHMENTS", ""))
+	if err != nil {
+		fatal(err.Error())
+	}
+	images, err := loadAttachments(ipc.input(attachmentsDir), manifest, attachLimits)
+	if err != nil {
+		fatalCode(attachmentErrorCode(err), err.Error())
+	}
+	if len(images) > 0 {
+		if err := checkImageInput(provider, modelName, len(images)); err != nil {
+			fatalCode(attachmentErrorCode(err), err.Error())
+		}
+	}
+
+	idleConns, err := maxIdleConns()
+	if err != nil {
+		fatal(err.Error())
+	}
+	if idleConns != defaultMaxIdleConns || tlsConfig != nil {
+		httpClient = newHTTPClient(idleConns, tlsConfig)
+	}
+	// SAVE_REQUEST is opt-in: the saved request holds the full prompt.
+	if getEnv("SAVE_REQUEST", "") == "true" {
+		httpClient.Transport = &requestRecorder{base: httpClient.Transport, path: ipc.output(requestFile)}
+		log.Printf("saving provider requests to %s", ipc.output(requestFile))
+	}
+	// TRANSCRIPT is opt-in for the same reason.
+	if transcript, err = loadTranscript(ipc.output(transcriptFile)); err != nil {
+		fatal(err.Error())
+	}
+	if transcript != nil {
+		httpClient.Transport = &transcriptRecorder{base: httpClient.Transport}
+		log.Printf("writing a transcript to %s (at most %d bytes)", ipc.output(transcriptFile), transcript.maxBytes)
+	}
+	if dryRun {
+		httpClient.Transport = &dryRunTransport{out: os.Stdout}
+	}
+	extraRequest, err := loadExtraRequest(provider)
+	if err != nil {
+		fatal(err.Error())
+	}
+	if extraRequest != nil {
+		httpClient.Transport = &extraRequestTransport{base: httpClient.Transport, extra: extraRequest}
+		logExtraRequest(extraRequest)
+	}
+
+	retryCfg = loadRetryConfig()
+	log.Printf("retry settings: %s", retryCfg)
+	if requestLimiter, err = loadRequestLimiter(); err != nil {
+		fatal(err.Error())
+	}
+	if requestLimiter != nil {
+		log.Printf("rate limit: %s requests per minute", getEnv("REQUESTS_PER_MINUTE", ""))
+	}
+
+	cred, err := resolveAPIKey(provider)
+	if err != nil {
+		fatal(err.Error())
+	}
+	if cred.Source != "" {
+		log.Printf("using API key from %s", cred.Source)
+	} else if provider == "bedrock" {
+		log.Printf("using AWS credentials from the default chain")
+	} else {
+		log.Printf("no API key set for provider %s", provider)
+	}
 
 	log.Printf("provider=%s model=%s baseURL=%s tools=%v task=%q",
-		provider, modelName, baseURL, toolsEnabled, truncate(task, 80))
+		provider, modelName, baseURL, toolsEnabled, truncateRedacted(task, 80))
 
-	_ = os.MkdirAll("/ipc/output", 0o755)
+	if timeout, err := preflightTimeout(); err != nil {
+		fatal(err.Error())
+	} else if timeout > 0 {
+		endpoint, err := providerEndpoint(provider, baseURL)
+		if err != nil {
+			fatal(err.Error())
+		}
+		reached, err := preflight(runCtx, endpoint, timeout)
+		if err != nil {
+			fatalCode("preflight_failed", "preflight: "+err.Error())
+		}
+		log.Printf("preflight: %s", reached)
+	}
+
+	streaming, flushInterval, flushBytes, err := streamSettings()
+	if err != nil {
+		fatal(err.Error())
+	}
+	if streaming && batch != nil {
+		// Concurrent tasks would interleave their text in one stream.
+		log.Printf("STREAM is ignored in batch mode")
+		streaming = false
+	}
+	interval, err := statusInterval()
+	if err != nil {
+		fatal(err.Error())
+	}
+	status = newStatusReporter(ipc.output(statusFile), interval, runStartedAt)
+	status.run()
+	if streaming {
+		streamOut = newStreamEmitter(ipc.Output, flushInterval, flushBytes)
+		streamOut.start()
+		chunkOut = streamOut
+		log.Printf("streaming enabled (flush every %s or %d bytes)", flushInterval, flushBytes)
+	} else {
+		// Tool chunks are still written as they happen; the text follows
+		// as a single chunk at the end.
+		chunkOut = newStreamEmitter(ipc.Output, flushInterval, flushBytes)
+	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	grace, err := shutdownGracePeriod()
+	if err != nil {
+		fatal(err.Error())
+	}
+	ctx, cancel := context.WithTimeout(runCtx, retryCfg.TotalDeadline)
 	defer cancel()
 
-	start := time.Now()
+	runner := taskCall{
+		provider:     provider,
+		apiKey:       cred.Key,
+		keyFile:      cred.file,
+		baseURL:      baseURL,
+		model:        modelName,
+		tools:        tools,
+		recoveryMode: recoveryMode,
+		summarizer:   summarizer,
+	}
+	var br *batchRun
+	if batch != nil {
+		if br, err = newBatchRun(runner, batch, systemPrompt, images); err != nil {
+			fatal(err.Error())
+		}
+		log.Printf("batch: %d task(s), concurrency %d, fail fast %v", len(batch), br.concurrency, br.failFast)
+	}
 
-	var (
-		responseText string
-		inputTokens  int
-		outputTokens int
-		toolCalls    int
-		err          error
-	)
+	start := time.Now()
+	handleTermination(cancel, grace, func() agentResult {
+		if br != nil {
+			return br.result()
+		}
+		res := agentResult{Provider: provider, Model: modelName}
+		if streamOut != nil {
+			res.Response = streamOut.text()
+		}
+		res.Metrics.DurationMs = time.Since(start).Milliseconds()
+		return res
+	})
+	configReady.Store(true)
+	status.setPhase(phaseCallingLLM)
+
+	if br != nil {
+		res := br.run(ctx)
+		status.setPhase(phaseFinalizing)
+		if sent := sampling.sent(provider); !sent.isZero() {
+			res.Parameters = &sent
+		}
+		res.MCPServers = mcpStatus
+		res.Attachments = attachmentRecords(images)
+		if res.Status != "success" {
+			transcript.fail(res.ErrorCode, res.Error)
+		}
+		finishRun(res)
+		return
+	}
 
-	switch provider {
-	case "anthropic":
-		responseText, inputTokens, outputTokens, toolCalls, err = callAnthropic(ctx, apiKey, baseURL, modelName, systemPrompt, task, tools)
-	default:
-		// OpenAI, Azure OpenAI, Ollama, and any OpenAI-compatible provider
-		responseText, inputTokens, outputTokens, toolCalls, err = callOpenAI(ctx, provider, apiKey, baseURL, modelName, systemPrompt, task, tools)
+	out, runner, tried := runner.runWithFallbacks(ctx, fallbacks, systemPrompt, task, history, images)
+	llm, err := out.llm, out.err
+	if errors.Is(err, errDryRun) {
+		log.Println("dry run: request printed, provider not called")
+		status.finish(phaseDone)
+		return
 	}
 
 	elapsed := time.Since(start)
+	if streamOut != nil {
+		streamOut.close()
+	}
+	status.setTokens(llm.OutputTokens)
+	status.setPhase(phaseFinalizing)
 
-	var res agentResult
+	res := out.result(runner.provider, runner.model)
+	res.Fallbacks = tried
 	res.Metrics.DurationMs = elapsed.Milliseconds()
-	res.Metrics.ToolCalls = toolCalls
+	res.HistoryDropped = historyDropped
+	res.ContextChunks = contextChunks
+	res.ContextDropped = contextDropped
+	res.Rendered = rendered
+	res.Metrics.Cost = costs.breakdown()
+	if sent := sampling.sent(runner.provider); !sent.isZero() {
+		res.Parameters = &sent
+	}
+	res.MCPServers = mcpStatus
+	res.Attachments = attachmentRecords(images)
 
 	debugMode := getEnv("DEBUG", "") == "true"
 
 	if err != nil {
 		log.Printf("LLM call failed: %v", err)
-		res.Status = "error"
-		res.Error = err.Error()
+		transcript.fail(res.ErrorCode, res.Error)
+		if terminated.Load() {
+			res.Response = llm.Text
+			markTerminated(&res)
+		}
 	} else {
-		log.Printf("LLM call succeeded (tokens: in=%d out=%d, tool_calls=%d)", inputTokens, outputTokens, toolCalls)
-		res.Status = "success"
-		res.Response = responseText
-		res.Metrics.InputTokens = inputTokens
-		res.Metrics.OutputTokens = outputTokens
+		log.Printf("LLM call succeeded (tokens: in=%d out=%d cache_read=%d cache_write=%d, tool_calls=%d)",
+			llm.InputTokens, llm.OutputTokens, llm.CacheReadTokens, llm.CacheCreationTokens, llm.ToolCalls)
+		if respFormat.JSON {
+			writeJSON(ipc.output(structuredFile), out.structured)
+		}
 	}
 
 	// Extract and emit memory update before stripping markers from the response.
 	if memoryEnabled && res.Response != "" {
 		if memUpdate := extractMemoryUpdate(res.Response); memUpdate != "" {
-			fmt.Fprintf(os.Stdout, "\n__SYMPOZIUM_MEMORY__%s__SYMPOZIUM_MEMORY_END__\n", memUpdate)
+			fmt.Fprintf(os.Stdout, "\n__SYMPOZIUM_MEMORY__%s__SYMPOZIUM_MEMORY_END__\n", redact(memUpdate))
 			log.Printf("emitted memory update (%d bytes)", len(memUpdate))
 		}
 	}
@@ -186,29 +725,29 @@ func main() {
 		res.Response = stripMemoryMarkers(res.Response)
 	}
 
-	if res.Response != "" {
-		writeJSON("/ipc/output/stream-0.json", streamChunk{
-			Type:    "text",
-			Content: res.Response,
-			Index:   0,
-		})
+	// With STREAM=true the text chunks were written as they arrived.
+	if res.Response != "" && streamOut == nil {
+		chunkOut.writeChunk("text", "", res.Response)
 	}
 
-	writeJSON("/ipc/output/result.json", res)
+	finishRun(res)
+}
 
-	// Signal sidecars (tool-executor, etc.) to exit by writing a done sentinel.
-	_ = os.WriteFile("/ipc/done", []byte("done"), 0o644)
+// finishRun publishes res and exits with the code for its status.
+func finishRun(res agentResult) {
+	emitResult(res)
 
-	// Print a structured marker to stdout so the controller can extract
-	// the result from pod logs even after the IPC volume is gone.
-	if markerBytes, err := json.Marshal(res); err == nil {
-		fmt.Fprintf(os.Stdout, "\n__SYMPOZIUM_RESULT__%s__SYMPOZIUM_END__\n", string(markerBytes))
+	if res.Status == "cancelled" {
+		status.finish(phaseCancelled)
+		log.Printf("agent-runner terminated: %s", res.Error)
+		os.Exit(exitTerminated)
 	}
-
 	if res.Status == "error" {
+		status.finish(phaseError)
 		log.Printf("agent-runner finished with error: %s", res.Error)
 		os.Exit(1)
 	}
+	status.finish(phaseDone)
 	log.Println("agent-runner finished successfully")
 }
 
@@ -216,9 +755,14 @@ func main() {
 // When tools is non-empty, the function enters a loop: call the LLM, execute
 // any tool_use blocks, feed results back, and repeat until the model produces
 // a final text response or the iteration limit is reached.
-func callAnthropic(ctx context.Context, apiKey, baseURL, model, systemPrompt, task string, tools []ToolDef) (string, int, int, int, error) {
+//
+// With PROMPT_CACHE=true the system prompt is marked with an ephemeral
+// cache_control breakpoint so repeated runs reuse it.
+func callAnthropic(ctx context.Context, apiKey, baseURL, model, systemPrompt, task string, images []imageAttachment, tools []ToolDef) (llmResult, error) {
 	opts := []anthropicoption.RequestOption{
-		anthropicoption.WithMaxRetries(5),
+		// Retries are done by httpClient's retryTransport.
+		anthropicoption.WithMaxRetries(0),
+		anthropicoption.WithHTTPClient(httpClient),
 	}
 	if apiKey != "" {
 		opts = append(opts, anthropicoption.WithAPIKey(apiKey))
@@ -243,40 +787,79 @@ func callAnthropic(ctx context.Context, apiKey, baseURL, model, systemPrompt, ta
 		anthropicTools = append(anthropicTools, tool)
 	}
 
-	messages := []anthropic.MessageParam{
-		anthropic.NewUserMessage(anthropic.NewTextBlock(task)),
+	userBlocks := []anthropic.ContentBlockParamUnion{anthropic.NewTextBlock(task)}
+	for _, img := range images {
+		if caption := img.captionText(); caption != "" {
+			userBlocks = append(userBlocks, anthropic.NewTextBlock(caption))
+		}
+		userBlocks = append(userBlocks, anthropic.NewImageBlockBase64(img.MediaType, img.base64Data()))
 	}
+	msgs := historyFor(ctx)
+	messages := append(anthropicHistory(msgs), anthropic.NewUserMessage(userBlocks...))
 
-	totalInputTokens := 0
-	totalOutputTokens := 0
-	totalToolCalls := 0
+	system := []anthropic.TextBlockParam{{Text: systemPrompt}}
+	for _, s := range historySystemPrompt(msgs) {
+		system = append(system, anthropic.TextBlockParam{Text: s})
+	}
+	if promptCacheEnabled() {
+		system[len(system)-1].CacheControl = anthropic.NewCacheControlEphemeralParam()
+	}
 
-	for i := 0; i < maxToolIterations; i++ {
+	stream := streamOut != nil && !isSummaryCall(ctx)
+	var res llmResult
+	var guard toolLoopGuard
+	// The call after MAX_ITERATIONS round-trips asks for a final answer.
+	for i := 0; i <= maxToolIterations; i++ {
+		final := i == maxToolIterations
 		params := anthropic.MessageNewParams{
-			Model:     anthropic.Model(model),
-			MaxTokens: int64(8192),
-			System: []anthropic.TextBlockParam{
-				{Text: systemPrompt},
-			},
+			Model:    anthropic.Model(model),
+			System:   system,
 			Messages: messages,
 		}
+		sampling.applyAnthropic(&params)
 		if len(anthropicTools) > 0 {
 			params.Tools = anthropicTools
+			if final {
+				none := anthropic.NewToolChoiceNoneParam()
+				params.ToolChoice = anthropic.ToolChoiceUnionParam{OfNone: &none}
+			}
 		}
 
-		message, err := client.Messages.New(ctx, params)
+		var message *anthropic.Message
+		var err error
+		callCtx, span := startLLMCall(ctx, i)
+		if stream {
+			message, err = streamAnthropic(callCtx, client, params)
+		} else {
+			message, err = client.Messages.New(callCtx, params)
+		}
+		if err != nil {
+			span.end(0, 0, err)
+		}
+		if errors.Is(err, errStreamInterrupted) {
+			res.Text = streamOut.text()
+			return res, err
+		}
 		if err != nil {
 			var apiErr *anthropic.Error
 			if errors.As(err, &apiErr) {
-				return "", totalInputTokens, totalOutputTokens, totalToolCalls,
-					fmt.Errorf("Anthropic API error (HTTP %d): %s", apiErr.StatusCode, truncate(apiErr.Error(), 500))
+				return res, newAPIError("Anthropic", apiErr.StatusCode, apiErr.Error())
 			}
-			return "", totalInputTokens, totalOutputTokens, totalToolCalls,
-				fmt.Errorf("Anthropic API error: %w", err)
+			return res, fmt.Errorf("Anthropic API error: %w", err)
 		}
 
-		totalInputTokens += int(message.Usage.InputTokens)
-		totalOutputTokens += int(message.Usage.OutputTokens)
+		res.InputTokens += int(message.Usage.InputTokens)
+		res.OutputTokens += int(message.Usage.OutputTokens)
+		res.CacheReadTokens += int(message.Usage.CacheReadInputTokens)
+		res.CacheCreationTokens += int(message.Usage.CacheCreationInputTokens)
+		res.StopReason = string(message.StopReason)
+		// Cached prompt tokens are charged at the full input rate, which
+		// errs on the side of the budget.
+		prompt := message.Usage.InputTokens + message.Usage.CacheReadInputTokens + message.Usage.CacheCreationInputTokens
+		span.end(int(prompt), int(message.Usage.OutputTokens), nil)
+		if err := costs.charge(int(prompt), int(message.Usage.OutputTokens)); err != nil {
+			return res, err
+		}
 
 		// Separate text blocks and tool-use blocks.
 		var textContent strings.Builder
@@ -285,14 +868,27 @@ func callAnthropic(ctx context.Context, apiKey, baseURL, model, systemPrompt, ta
 			switch v := block.AsAny().(type) {
 			case anthropic.TextBlock:
 				textContent.WriteString(v.Text)
+			case anthropic.ThinkingBlock:
+				// Streamed as it arrived with STREAM=true.
+				if streamOut == nil {
+					chunkOut.writeChunk("thinking", "", v.Thinking)
+				}
 			case anthropic.ToolUseBlock:
 				toolUseBlocks = append(toolUseBlocks, v)
 			}
 		}
 
+		transcript.response(i, textContent.String(), string(message.StopReason), int(prompt), int(message.Usage.OutputTokens))
+
 		// If no tool calls, return the text.
-		if message.StopReason != anthropic.StopReasonToolUse || len(toolUseBlocks) == 0 {
-			return textContent.String(), totalInputTokens, totalOutputTokens, totalToolCalls, nil
+		if message.StopReason != anthropic.StopReasonToolUse || len(toolUseBlocks) == 0 || final {
+			res.endIteration(i, int(prompt), int(message.Usage.OutputTokens), 0, res.StopReason)
+			res.Text = textContent.String()
+			if final && res.Text == "" {
+				break
+			}
+			res.IterationLimitReached = final
+			return res, nil
 		}
 
 		// Build the assistant message with all content blocks (text + tool_use).
@@ -310,49 +906,58 @@ func callAnthropic(ctx context.Context, apiKey, baseURL, model, systemPrompt, ta
 
 		// Execute each tool call and build tool_result blocks.
 		var resultBlocks []anthropic.ContentBlockParamUnion
-		for _, tu := range toolUseBlocks {
-			totalToolCalls++
-			log.Printf("tool_use [%d]: %s id=%s", totalToolCalls, tu.Name, tu.ID)
-
-			result := executeToolCall(tu.Name, string(tu.Input))
-			isErr := strings.HasPrefix(result, "Error:")
+		for n, tu := range toolUseBlocks {
+			if err := guard.check(tu.Name, string(tu.Input)); err != nil {
+				res.endIteration(i, int(prompt), int(message.Usage.OutputTokens), n, res.StopReason)
+				return res, err
+			}
+			result, isErr := runToolCall(&res, tu.ID, tu.Name, string(tu.Input))
 			resultBlocks = append(resultBlocks, anthropic.NewToolResultBlock(tu.ID, result, isErr))
 		}
+		res.endIteration(i, int(prompt), int(message.Usage.OutputTokens), len(toolUseBlocks), res.StopReason)
+		if i == maxToolIterations-1 {
+			log.Printf("MAX_ITERATIONS (%d) reached; asking for a final answer", maxToolIterations)
+			resultBlocks = append(resultBlocks, anthropic.NewTextBlock(fmt.Sprintf(iterationLimitPrompt, maxToolIterations)))
+		}
 		messages = append(messages, anthropic.NewUserMessage(resultBlocks...))
 	}
 
-	return "", totalInputTokens, totalOutputTokens, totalToolCalls,
-		fmt.Errorf("exceeded maximum tool-call iterations (%d)", maxToolIterations)
+	return res, fmt.Errorf("exceeded maximum tool-call iterations (%d)", maxToolIterations)
 }
 
 // callOpenAI uses the official OpenAI Go SDK with optional tool calling.
 // When tools is non-empty, the function enters a loop: call the LLM, execute
 // any tool_calls, feed results back, and repeat until the model produces a
 // final text response or the iteration limit is reached.
-func callOpenAI(ctx context.Context, provider, apiKey, baseURL, model, systemPrompt, task string, tools []ToolDef) (string, int, int, int, error) {
+//
+// OpenAI caches long prompt prefixes automatically; with PROMPT_CACHE=true a
+// prompt_cache_key derived from the system prompt is sent so runs sharing a
+// system prompt are routed to the same cache.
+func callOpenAI(ctx context.Context, provider, apiKey, baseURL, model, systemPrompt, task string, images []imageAttachment, tools []ToolDef) (llmResult, error) {
 	opts := []openaioption.RequestOption{
-		openaioption.WithMaxRetries(5),
+		openaioption.WithMaxRetries(0),
+		openaioption.WithHTTPClient(httpClient),
 	}
 
 	switch provider {
 	case "azure-openai":
-		if baseURL == "" {
-			return "", 0, 0, 0, fmt.Errorf("Azure OpenAI requires MODEL_BASE_URL to be set")
+		endpoint, apiVersion, err := azureEndpoint(baseURL)
+		if err != nil {
+			return llmResult{}, err
 		}
-		apiVersion := getEnv("AZURE_OPENAI_API_VERSION", "2024-06-01")
-		opts = append(opts,
-			azure.WithEndpoint(baseURL, apiVersion),
-			azure.WithAPIKey(apiKey),
-		)
+		opts = append(opts, azureOptions(endpoint, apiVersion, apiKey)...)
 	default:
 		if apiKey != "" {
 			opts = append(opts, openaioption.WithAPIKey(apiKey))
 		}
-		if baseURL != "" {
-			opts = append(opts, openaioption.WithBaseURL(baseURL))
-		} else if provider == "ollama" {
-			opts = append(opts, openaioption.WithBaseURL("http://ollama.default.svc:11434/v1"))
+		if u := firstNonEmpty(baseURL, providerBaseURLs[provider]); u != "" {
+			opts = append(opts, openaioption.WithBaseURL(u))
 		}
+		opts = append(opts, presetOptions(provider)...)
+	}
+
+	if promptCacheEnabled() && provider == "openai" {
+		opts = append(opts, openaioption.WithJSONSet("prompt_cache_key", promptCacheKey(systemPrompt)))
 	}
 
 	client := openai.NewClient(opts...)
@@ -367,69 +972,152 @@ func callOpenAI(ctx context.Context, provider, apiKey, baseURL, model, systemPro
 		}))
 	}
 
-	messages := []openai.ChatCompletionMessageParamUnion{
-		openai.SystemMessage(systemPrompt),
-		openai.UserMessage(task),
+	user := openai.UserMessage(task)
+	if len(images) > 0 {
+		parts := []openai.ChatCompletionContentPartUnionParam{openai.TextContentPart(task)}
+		for _, img := range images {
+			if caption := img.captionText(); caption != "" {
+				parts = append(parts, openai.TextContentPart(caption))
+			}
+			parts = append(parts, openai.ImageContentPart(openai.ChatCompletionContentPartImageImageURLParam{URL: img.dataURI()}))
+		}
+		user = openai.UserMessage(parts)
 	}
-
-	totalInputTokens := 0
-	totalOutputTokens := 0
-	totalToolCalls := 0
-
-	for i := 0; i < maxToolIterations; i++ {
+	messages := []openai.ChatCompletionMessageParamUnion{openAISystemMessage(systemPrompt)}
+	messages = append(messages, openAIHistory(historyFor(ctx))...)
+	messages = append(messages, user)
+
+	stream := streamOut != nil && !isSummaryCall(ctx)
+	var res llmResult
+	var guard toolLoopGuard
+	// The call after MAX_ITERATIONS round-trips asks for a final answer.
+	for i := 0; i <= maxToolIterations; i++ {
+		final := i == maxToolIterations
 		params := openai.ChatCompletionNewParams{
 			Model:    openai.ChatModel(model),
 			Messages: messages,
 		}
+		sampling.applyOpenAI(&params)
+		if !isSummaryCall(ctx) {
+			respFormat.applyOpenAI(provider, &params)
+		}
 		if len(oaiTools) > 0 {
 			params.Tools = oaiTools
+			if final {
+				params.ToolChoice = openai.ChatCompletionToolChoiceOptionUnionParam{OfAuto: openai.String("none")}
+			}
 		}
 
-		completion, err := client.Chat.Completions.New(ctx, params)
+		var completion *openai.ChatCompletion
+		var err error
+		callCtx, span := startLLMCall(ctx, i)
+		if stream {
+			if provider == "openai" || provider == "azure-openai" {
+				params.StreamOptions = openai.ChatCompletionStreamOptionsParam{IncludeUsage: openai.Bool(true)}
+			}
+			completion, err = streamOpenAI(callCtx, client, params)
+		} else {
+			completion, err = client.Chat.Completions.New(callCtx, params)
+		}
+		if err != nil {
+			span.end(0, 0, err)
+		}
+		if errors.Is(err, errStreamInterrupted) {
+			res.Text = streamOut.text()
+			return res, err
+		}
 		if err != nil {
 			var apiErr *openai.Error
 			if errors.As(err, &apiErr) {
-				return "", totalInputTokens, totalOutputTokens, totalToolCalls,
-					fmt.Errorf("OpenAI API error (HTTP %d): %s", apiErr.StatusCode, truncate(apiErr.Error(), 500))
+				switch provider {
+				case "azure-openai":
+					return res, azureAPIError(apiErr)
+				case "github":
+					return res, githubAPIError(apiErr)
+				case "openrouter":
+					return res, openRouterAPIError(apiErr)
+				}
+				return res, newAPIError("OpenAI", apiErr.StatusCode, apiErr.Error())
 			}
-			return "", totalInputTokens, totalOutputTokens, totalToolCalls,
-				fmt.Errorf("OpenAI API error: %w", err)
+			return res, fmt.Errorf("OpenAI API error: %w", err)
 		}
 
-		totalInputTokens += int(completion.Usage.PromptTokens)
-		totalOutputTokens += int(completion.Usage.CompletionTokens)
+		in, out, estimated := openAIUsage(completion, params.Messages)
+		span.end(in, out, nil)
+		res.InputTokens += in
+		res.OutputTokens += out
+		res.TokensEstimated = res.TokensEstimated || estimated
+		if err := costs.charge(in, out); err != nil {
+			return res, err
+		}
+		res.CacheReadTokens += int(completion.Usage.PromptTokensDetails.CachedTokens)
+		res.ReasoningTokens += int(completion.Usage.CompletionTokensDetails.ReasoningTokens)
+		if completion.SystemFingerprint != "" {
+			res.SystemFingerprint = completion.SystemFingerprint
+		}
 
 		if len(completion.Choices) == 0 {
-			return "", totalInputTokens, totalOutputTokens, totalToolCalls,
-				fmt.Errorf("no choices in completion response")
+			return res, fmt.Errorf("no choices in completion response")
 		}
 		choice := completion.Choices[0]
+		res.StopReason = string(choice.FinishReason)
+		transcript.response(i, choice.Message.Content, res.StopReason, in, out)
+		if streamOut == nil {
+			if reasoning := reasoningContent(choice.Message.JSON.ExtraFields); reasoning != "" {
+				chunkOut.writeChunk("thinking", "", reasoning)
+			}
+		}
 
 		// If model made tool calls, execute them and loop.
-		if choice.FinishReason == "tool_calls" && len(choice.Message.ToolCalls) > 0 {
+		if choice.FinishReason == "tool_calls" && len(choice.Message.ToolCalls) > 0 && !final {
 			// Add the assistant message (with tool calls) to history.
 			messages = append(messages, choice.Message.ToParam())
 
 			// Execute each tool call and add results.
-			for _, tc := range choice.Message.ToolCalls {
+			for n, tc := range choice.Message.ToolCalls {
 				fc := tc.AsFunction()
-				totalToolCalls++
-				log.Printf("tool_call [%d]: %s id=%s", totalToolCalls, fc.Function.Name, fc.ID)
-
-				result := executeToolCall(fc.Function.Name, fc.Function.Arguments)
+				if err := guard.check(fc.Function.Name, fc.Function.Arguments); err != nil {
+					res.endIteration(i, in, out, n, res.StopReason)
+					return res, err
+				}
+				result, _ := runToolCall(&res, fc.ID, fc.Function.Name, fc.Function.Arguments)
 				messages = append(messages, openai.ToolMessage(result, fc.ID))
 			}
+			res.endIteration(i, in, out, len(choice.Message.ToolCalls), res.StopReason)
+			if i == maxToolIterations-1 {
+				log.Printf("MAX_ITERATIONS (%d) reached; asking for a final answer", maxToolIterations)
+				messages = append(messages, openai.UserMessage(fmt.Sprintf(iterationLimitPrompt, maxToolIterations)))
+			}
 			continue
 		}
 
 		// No tool calls — return the text response.
-		return choice.Message.Content, totalInputTokens, totalOutputTokens, totalToolCalls, nil
+		res.endIteration(i, in, out, 0, res.StopReason)
+		res.Text = choice.Message.Content
+		if final && res.Text == "" {
+			break
+		}
+		res.IterationLimitReached = final
+		return res, nil
 	}
 
-	return "", totalInputTokens, totalOutputTokens, totalToolCalls,
-		fmt.Errorf("exceeded maximum tool-call iterations (%d)", maxToolIterations)
+	return res, fmt.Errorf("exceeded maximum tool-call iterations (%d)", maxToolIterations)
+}
+
+// promptCacheEnabled reports whether PROMPT_CACHE=true asks for the system
+// prompt to be cached by the provider.
+func promptCacheEnabled() bool {
+	return getEnv("PROMPT_CACHE", "") == "true"
 }
 
+// promptCacheKey derives a stable cache routing key from the system prompt.
+func promptCacheKey(systemPrompt string) string {
+	sum := sha256.Sum256([]byte(systemPrompt))
+	return "sympozium-" + hex.EncodeToString(sum[:8])
+}
+
+// writeJSON writes v as indented JSON to path, atomically and with
+// credentials redacted. Failures are logged.
 func writeJSON(path string, v any) {
 	dir := filepath.Dir(path)
 	_ = os.MkdirAll(dir, 0o755)
@@ -438,11 +1126,47 @@ func writeJSON(path string, v any) {
 		log.Printf("WARNING: failed to marshal JSON for %s: %v", path, err)
 		return
 	}
-	if err := os.WriteFile(path, data, 0o644); err != nil {
+	if err := ipcfile.Write(path, redactBytes(data), 0o644); err != nil {
 		log.Printf("WARNING: failed to write %s: %v", path, err)
 	}
 }
 
+// writeResult writes result.json and fsyncs its directory, so the outcome
+// survives the pod being killed right after. It comes before the done
+// sentinel, which tells readers the result is there.
+func writeResult(res agentResult) {
+	writeJSON(ipc.output(resultFile), res)
+	if err := ipcfile.SyncDir(ipc.Output); err != nil {
+		log.Printf("WARNING: failed to sync %s: %v", ipc.Output, err)
+	}
+}
+
+var emitOnce sync.Once
+
+// emitResult publishes the final result: result.json, the done sentinel for
+// sidecars, RESULT_CALLBACK_URL if set, and a marker on stdout so the
+// controller can extract the result from pod logs after the IPC volume is
+// gone; with --print, the response replaces the marker. Only the first
+// call has an effect, so a grace-period timeout and main cannot both write
+// it.
+func emitResult(res agentResult) {
+	emitOnce.Do(func() {
+		res.finish()
+		writeResult(res)
+		writeDone()
+		callback.send(res)
+		if printResponse {
+			if res.Response != "" {
+				fmt.Fprintln(os.Stdout, redact(res.Response))
+			}
+		} else if markerBytes, err := json.Marshal(res); err == nil {
+			fmt.Fprintf(os.Stdout, "\n__SYMPOZIUM_RESULT__%s__SYMPOZIUM_END__\n", redactBytes(markerBytes))
+		}
+		endTracing(res)
+		stopMetricsServer(metricsServer)
+	})
+}
+
 func getEnv(key, fallback string) string {
 	if v := os.Getenv(key); v != "" {
 		return v
@@ -459,6 +1183,31 @@ func firstNonEmpty(vals ...string) string {
 	return ""
 }
 
+// apiKeyEnvVars returns the environment variables that may hold the API key
+// for provider, most specific first. Another provider's key is never
+// considered, so a pod carrying both OPENAI_API_KEY and ANTHROPIC_API_KEY
+// cannot send the wrong one.
+func apiKeyEnvVars(provider string) []string {
+	switch provider {
+	case "anthropic":
+		return []string{"ANTHROPIC_API_KEY", "API_KEY"}
+	case "azure-openai":
+		return []string{"AZURE_OPENAI_API_KEY", "API_KEY"}
+	case "bedrock":
+		// Requests are signed with AWS credentials instead.
+		return nil
+	case "openai":
+		return []string{"OPENAI_API_KEY", "API_KEY"}
+	case "github":
+		return []string{"GITHUB_TOKEN", "API_KEY"}
+	case "openrouter":
+		return []string{"OPENROUTER_API_KEY", "API_KEY"}
+	default:
+		// Ollama and other OpenAI-compatible endpoints.
+		return []string{"API_KEY", "OPENAI_API_KEY"}
+	}
+}
+
 func truncate(s string, n int) string {
 	if len(s) <= n {
 		return s
@@ -467,13 +1216,26 @@ func truncate(s string, n int) string {
 }
 
 func fatal(msg string) {
+	fatalCode("config_error", msg)
+}
+
+// fatalCode is fatal with an errorCode other than config_error.
+func fatalCode(code, msg string) {
 	log.Println("FATAL: " + msg)
-	_ = os.MkdirAll("/ipc/output", 0o755)
-	_ = os.WriteFile("/ipc/done", []byte("done"), 0o644)
-	writeJSON("/ipc/output/result.json", agentResult{
-		Status: "error",
-		Error:  msg,
-	})
+	_ = os.MkdirAll(ipc.Output, 0o755)
+	res := agentResult{
+		Status:    "error",
+		Error:     msg,
+		ErrorCode: code,
+	}
+	res.finish()
+	transcript.fail(code, msg)
+	writeResult(res)
+	writeDone()
+	callback.send(res)
+	endTracing(res)
+	stopMetricsServer(metricsServer)
+	status.finish(phaseError)
 	os.Exit(1)
 }
 
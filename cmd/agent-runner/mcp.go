@@ -0,0 +1,652 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// mcpProtocolVersion is the Model Context Protocol revision the runner
+	// speaks; servers answer with the revision they will use.
+	mcpProtocolVersion = "2024-11-05"
+	// defaultMCPTimeout bounds connecting to a server and each tool call
+	// unless the server's timeout is set.
+	defaultMCPTimeout = 30 * time.Second
+	// defaultMCPMaxResultBytes matches the output limit of HTTP skill tools.
+	defaultMCPMaxResultBytes = 50_000
+)
+
+// mcpServerNamePattern keeps server names usable in tool names, which
+// providers limit to letters, digits, _ and -.
+var mcpServerNamePattern = regexp.MustCompile(`^[a-zA-Z0-9-]{1,32}$`)
+
+// mcpServerConfig is one entry of MCP_SERVERS, a JSON array. A server is
+// either a command speaking MCP over stdio or the URL of an SSE endpoint:
+//
+//	[
+//	  {"name": "jira", "command": ["jira-mcp", "--stdio"], "env": {"JIRA_URL": "https://jira.internal"}},
+//	  {"name": "search", "url": "http://search-mcp.tools.svc:8080/sse", "headers": {"Authorization": "Bearer ..."}, "timeout": "10s"}
+//	]
+//
+// Its tools are offered to the model as <name>__<tool>.
+type mcpServerConfig struct {
+	Name string `json:"name"`
+	// Command and Env start a stdio server. It inherits only PATH, HOME and
+	// the proxy variables, so the provider credentials stay in the runner.
+	Command []string          `json:"command,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`
+	URL     string            `json:"url,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	// Timeout bounds connecting and each tool call, capped like skill
+	// tools at two minutes.
+	Timeout string `json:"timeout,omitempty"`
+	// MaxResultBytes truncates tool output sent back to the model.
+	MaxResultBytes int `json:"maxResultBytes,omitempty"`
+
+	timeout time.Duration
+}
+
+func (c *mcpServerConfig) transport() string {
+	if c.URL != "" {
+		return "sse"
+	}
+	return "stdio"
+}
+
+// parseMCPServers parses MCP_SERVERS. A malformed entry is a configuration
+// error; a server that cannot be reached is not, see connectMCPServers.
+func parseMCPServers(v string) ([]mcpServerConfig, error) {
+	var configs []mcpServerConfig
+	dec := json.NewDecoder(strings.NewReader(v))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&configs); err != nil {
+		return nil, fmt.Errorf("invalid MCP_SERVERS: %w", err)
+	}
+	seen := map[string]bool{}
+	for i := range configs {
+		c := &configs[i]
+		var err error
+		switch {
+		case !mcpServerNamePattern.MatchString(c.Name):
+			err = fmt.Errorf("name %q must be 1-32 letters, digits or dashes", c.Name)
+		case seen[c.Name]:
+			err = fmt.Errorf("duplicate name %q", c.Name)
+		case (len(c.Command) == 0) == (c.URL == ""):
+			err = fmt.Errorf("exactly one of command or url is required")
+		case c.URL != "" && !strings.HasPrefix(c.URL, "http://") && !strings.HasPrefix(c.URL, "https://"):
+			err = fmt.Errorf("url %q must be http or https", c.URL)
+		case c.MaxResultBytes < 0:
+			err = fmt.Errorf("maxResultBytes must not be negative")
+		}
+		if err == nil {
+			c.timeout = defaultMCPTimeout
+			if c.Timeout != "" {
+				if c.timeout, err = time.ParseDuration(c.Timeout); err != nil || c.timeout <= 0 {
+					err = fmt.Errorf("invalid timeout %q", c.Timeout)
+				}
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid MCP_SERVERS entry %d: %w", i, err)
+		}
+		c.timeout = min(c.timeout, maxSkillToolTimeout)
+		if c.MaxResultBytes == 0 {
+			c.MaxResultBytes = defaultMCPMaxResultBytes
+		}
+		for _, h := range c.Headers {
+			registerSecret(h)
+		}
+		for _, e := range c.Env {
+			registerSecret(e)
+		}
+		seen[c.Name] = true
+	}
+	return configs, nil
+}
+
+// mcpServerStatus reports in result.json whether a server's tools were
+// available to the model.
+type mcpServerStatus struct {
+	Name      string `json:"name"`
+	Transport string `json:"transport"` // stdio or sse
+	Connected bool   `json:"connected"`
+	// Server is the name and version the server reported.
+	Server string `json:"server,omitempty"`
+	Tools  int    `json:"tools"`
+	Error  string `json:"error,omitempty"`
+}
+
+// mcpTool is a tool of an MCP server, offered to the model under a name
+// prefixed with the server's.
+type mcpTool struct {
+	Name        string
+	Description string
+	Parameters  map[string]any
+
+	remote string
+	client *mcpClient
+}
+
+// mcpTools are the tools of the connected MCP servers, by prefixed name.
+var mcpTools = map[string]*mcpTool{}
+
+// mcpStatus is the outcome of connecting to each MCP server, in
+// MCP_SERVERS order.
+var mcpStatus []mcpServerStatus
+
+// connectMCPServers connects to the servers in parallel and lists their
+// tools. A server that fails is logged and recorded in its status, and the
+// run goes on without its tools. tlsConfig is the runner's TLS settings,
+// used for servers reached over HTTPS.
+func connectMCPServers(configs []mcpServerConfig, tlsConfig *tls.Config) (map[string]*mcpTool, []mcpServerStatus) {
+	statuses := make([]mcpServerStatus, len(configs))
+	toolLists := make([][]*mcpTool, len(configs))
+	var wg sync.WaitGroup
+	for i := range configs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			cfg := &configs[i]
+			statuses[i] = mcpServerStatus{Name: cfg.Name, Transport: cfg.transport()}
+			server, list, err := connectMCPServer(cfg, tlsConfig)
+			if err != nil {
+				log.Printf("MCP server %s unavailable, continuing without its tools: %v", cfg.Name, err)
+				statuses[i].Error = err.Error()
+				return
+			}
+			statuses[i].Connected, statuses[i].Server = true, server
+			toolLists[i] = list
+		}(i)
+	}
+	wg.Wait()
+
+	tools := map[string]*mcpTool{}
+	for i, list := range toolLists {
+		for _, t := range list {
+			if isBuiltinTool(t.Name) || skillTools[t.Name] != nil || tools[t.Name] != nil {
+				log.Printf("skipping MCP tool %s of server %s: duplicate tool name %q", t.remote, configs[i].Name, t.Name)
+				continue
+			}
+			tools[t.Name] = t
+			statuses[i].Tools++
+		}
+		if statuses[i].Connected {
+			log.Printf("MCP server %s (%s): %d tool(s)", configs[i].Name, statuses[i].Server, statuses[i].Tools)
+		}
+	}
+	return tools, statuses
+}
+
+// connectMCPServer starts or dials the server, performs the initialize
+// handshake and lists its tools. It returns the server's name and version.
+func connectMCPServer(cfg *mcpServerConfig, tlsConfig *tls.Config) (string, []*mcpTool, error) {
+	var c *mcpClient
+	var err error
+	if cfg.URL != "" {
+		c, err = dialMCPSSE(cfg, tlsConfig)
+	} else {
+		c, err = startMCPStdio(cfg)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.timeout)
+	defer cancel()
+
+	var init struct {
+		ProtocolVersion string `json:"protocolVersion"`
+		ServerInfo      struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		} `json:"serverInfo"`
+	}
+	err = c.call(ctx, "initialize", map[string]any{
+		"protocolVersion": mcpProtocolVersion,
+		"capabilities":    map[string]any{},
+		"clientInfo":      map[string]string{"name": "sympozium-agent-runner", "version": "1.0"},
+	}, &init)
+	if err == nil {
+		err = c.notify(ctx, "notifications/initialized")
+	}
+	var tools []*mcpTool
+	cursor := ""
+	for err == nil {
+		var page struct {
+			Tools []struct {
+				Name        string         `json:"name"`
+				Description string         `json:"description"`
+				InputSchema map[string]any `json:"inputSchema"`
+			} `json:"tools"`
+			NextCursor string `json:"nextCursor"`
+		}
+		params := map[string]any{}
+		if cursor != "" {
+			params["cursor"] = cursor
+		}
+		if err = c.call(ctx, "tools/list", params, &page); err != nil {
+			break
+		}
+		for _, t := range page.Tools {
+			tools = append(tools, newMCPTool(c, t.Name, t.Description, t.InputSchema))
+		}
+		if cursor = page.NextCursor; cursor == "" {
+			break
+		}
+	}
+	if err != nil {
+		c.close()
+		if errors.Is(err, context.DeadlineExceeded) {
+			err = fmt.Errorf("no answer within %s", cfg.timeout)
+		}
+		return "", nil, err
+	}
+	server := strings.TrimSpace(init.ServerInfo.Name + " " + init.ServerInfo.Version)
+	return server, tools, nil
+}
+
+// mcpToolNameChars matches the characters providers reject in tool names.
+var mcpToolNameChars = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+
+func newMCPTool(c *mcpClient, remote, description string, schema map[string]any) *mcpTool {
+	name := c.name + "__" + mcpToolNameChars.ReplaceAllString(remote, "_")
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	if description == "" {
+		description = fmt.Sprintf("%s from the %s MCP server", remote, c.name)
+	}
+	if schema == nil {
+		schema = map[string]any{"type": "object", "properties": map[string]any{}}
+	}
+	return &mcpTool{Name: name, Description: description, Parameters: schema, remote: remote, client: c}
+}
+
+// mcpToolDefs returns the definitions of the MCP tools, sorted by name so
+// the request is stable across runs.
+func mcpToolDefs() []ToolDef {
+	names := make([]string, 0, len(mcpTools))
+	for name := range mcpTools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	defs := make([]ToolDef, 0, len(names))
+	for _, name := range names {
+		t := mcpTools[name]
+		defs = append(defs, ToolDef{Name: t.Name, Description: t.Description, Parameters: t.Parameters})
+	}
+	return defs
+}
+
+// run calls the tool on its server with the model's arguments. Text content
+// is returned as is; other content is summarized, as the model cannot use
+// it through a tool result.
+func (t *mcpTool) run(args map[string]any) string {
+	if args == nil {
+		args = map[string]any{}
+	}
+	c := t.client
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+	var result struct {
+		Content []struct {
+			Type     string `json:"type"`
+			Text     string `json:"text"`
+			MimeType string `json:"mimeType"`
+			Resource struct {
+				URI  string `json:"uri"`
+				Text string `json:"text"`
+			} `json:"resource"`
+		} `json:"content"`
+		IsError bool `json:"isError"`
+	}
+	err := c.call(ctx, "tools/call", map[string]any{"name": t.remote, "arguments": args}, &result)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Sprintf("Error: MCP server %s did not answer within %s", c.name, c.timeout)
+	}
+	if err != nil {
+		return fmt.Sprintf("Error: MCP server %s: %v", c.name, err)
+	}
+	var sb strings.Builder
+	for i, item := range result.Content {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		switch {
+		case item.Type == "text":
+			sb.WriteString(item.Text)
+		case item.Type == "resource" && item.Resource.Text != "":
+			sb.WriteString(item.Resource.Text)
+		case item.Type == "resource":
+			fmt.Fprintf(&sb, "[resource %s]", item.Resource.URI)
+		default:
+			fmt.Fprintf(&sb, "[%s content (%s) omitted]", item.Type, item.MimeType)
+		}
+	}
+	out := sb.String()
+	if len(out) > c.maxResult {
+		// Cut at a rune boundary so the model is not sent invalid UTF-8.
+		n := c.maxResult
+		for n > 0 && !utf8.RuneStart(out[n]) {
+			n--
+		}
+		out = out[:n] + fmt.Sprintf("\n[output truncated to %d bytes]", c.maxResult)
+	}
+	if result.IsError {
+		return "Error: " + out
+	}
+	return out
+}
+
+// mcpClient is a JSON-RPC 2.0 connection to an MCP server. The transport
+// supplies send and feeds each message it receives to dispatch.
+type mcpClient struct {
+	name      string
+	timeout   time.Duration
+	maxResult int
+
+	send    func(ctx context.Context, msg []byte) error
+	closeFn func()
+
+	mu      sync.Mutex
+	nextID  int64
+	pending map[int64]chan mcpMessage
+	done    chan struct{} // closed when the connection ends
+	readErr error
+}
+
+// mcpMessage is a request, notification or response.
+type mcpMessage struct {
+	ID     *int64          `json:"id,omitempty"`
+	Method string          `json:"method,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+func newMCPClient(cfg *mcpServerConfig) *mcpClient {
+	return &mcpClient{
+		name:      cfg.Name,
+		timeout:   cfg.timeout,
+		maxResult: cfg.MaxResultBytes,
+		pending:   map[int64]chan mcpMessage{},
+		done:      make(chan struct{}),
+	}
+}
+
+// call sends a request and decodes its result into result.
+func (c *mcpClient) call(ctx context.Context, method string, params, result any) error {
+	c.mu.Lock()
+	c.nextID++
+	id := c.nextID
+	ch := make(chan mcpMessage, 1)
+	c.pending[id] = ch
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	msg, err := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": id, "method": method, "params": params})
+	if err != nil {
+		return err
+	}
+	if err := c.send(ctx, msg); err != nil {
+		return err
+	}
+	select {
+	case resp := <-ch:
+		if resp.Error != nil {
+			return fmt.Errorf("%s failed: %s (code %d)", method, resp.Error.Message, resp.Error.Code)
+		}
+		if result == nil {
+			return nil
+		}
+		return json.Unmarshal(resp.Result, result)
+	case <-c.done:
+		return fmt.Errorf("connection closed: %v", c.readErr)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// notify sends a notification, which gets no response.
+func (c *mcpClient) notify(ctx context.Context, method string) error {
+	return c.send(ctx, []byte(`{"jsonrpc":"2.0","method":"`+method+`"}`))
+}
+
+// dispatch routes a received message: responses to the waiting call, and
+// server requests to a reply, since the runner offers no client features
+// beyond answering ping.
+func (c *mcpClient) dispatch(data []byte) {
+	var msg mcpMessage
+	if err := json.Unmarshal(data, &msg); err != nil || msg.ID == nil {
+		return
+	}
+	if msg.Method != "" {
+		reply := map[string]any{"jsonrpc": "2.0", "id": *msg.ID, "result": map[string]any{}}
+		if msg.Method != "ping" {
+			delete(reply, "result")
+			reply["error"] = map[string]any{"code": -32601, "message": "method not found"}
+		}
+		b, _ := json.Marshal(reply)
+		go c.send(context.Background(), b)
+		return
+	}
+	c.mu.Lock()
+	ch := c.pending[*msg.ID]
+	c.mu.Unlock()
+	if ch != nil {
+		ch <- msg
+	}
+}
+
+// closed ends the connection after the transport stopped reading.
+func (c *mcpClient) closed(err error) {
+	c.readErr = err
+	close(c.done)
+}
+
+func (c *mcpClient) close() {
+	if c.closeFn != nil {
+		c.closeFn()
+	}
+}
+
+// mcpInheritedEnv are the variables a stdio server inherits from the runner.
+var mcpInheritedEnv = []string{
+	"PATH", "HOME", "HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY", "http_proxy", "https_proxy", "no_proxy",
+}
+
+// startMCPStdio starts the server's command and speaks newline-delimited
+// JSON-RPC over its stdin and stdout. Its stderr is logged.
+func startMCPStdio(cfg *mcpServerConfig) (*mcpClient, error) {
+	c := newMCPClient(cfg)
+	cmd := exec.Command(cfg.Command[0], cfg.Command[1:]...)
+	for _, name := range mcpInheritedEnv {
+		if v, ok := os.LookupEnv(name); ok {
+			cmd.Env = append(cmd.Env, name+"="+v)
+		}
+	}
+	for k, v := range cfg.Env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("starting %s: %w", cfg.Command[0], err)
+	}
+
+	stderrDone := make(chan struct{})
+	go func() {
+		defer close(stderrDone)
+		sc := bufio.NewScanner(stderr)
+		for sc.Scan() {
+			log.Printf("mcp %s: %s", cfg.Name, sc.Text())
+		}
+	}()
+	go func() {
+		r := bufio.NewReaderSize(stdout, 64*1024)
+		for {
+			line, err := r.ReadBytes('\n')
+			if len(bytes.TrimSpace(line)) > 0 {
+				c.dispatch(line)
+			}
+			if err != nil {
+				<-stderrDone
+				c.closed(fmt.Errorf("%s exited: %v", cfg.Command[0], cmd.Wait()))
+				return
+			}
+		}
+	}()
+
+	var writeMu sync.Mutex
+	c.send = func(_ context.Context, msg []byte) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		_, err := stdin.Write(append(msg, '\n'))
+		return err
+	}
+	c.closeFn = func() {
+		stdin.Close()
+		cmd.Process.Kill()
+	}
+	return c, nil
+}
+
+// dialMCPSSE opens the server's event stream (the HTTP+SSE transport). The
+// first event names the endpoint requests are POSTed to; responses arrive
+// as message events on the stream. Connections use the same proxy and TLS
+// settings as provider requests, so a server behind the private CA they
+// trust works too.
+func dialMCPSSE(cfg *mcpServerConfig, tlsConfig *tls.Config) (*mcpClient, error) {
+	c := newMCPClient(cfg)
+	transport := newTransport(2, tlsConfig.Clone())
+	transport.ResponseHeaderTimeout = cfg.timeout
+	client := &http.Client{Transport: transport}
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, cfg.URL, nil)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	for k, v := range cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		cancel()
+		return nil, fmt.Errorf("GET %s: HTTP %d", cfg.URL, resp.StatusCode)
+	}
+
+	endpoint := make(chan string, 1)
+	go func() {
+		defer resp.Body.Close()
+		err := readSSE(resp.Body, func(event, data string) {
+			switch event {
+			case "endpoint":
+				if u, err := req.URL.Parse(data); err == nil {
+					select {
+					case endpoint <- u.String():
+					default:
+					}
+				}
+			case "", "message":
+				c.dispatch([]byte(data))
+			}
+		})
+		c.closed(fmt.Errorf("event stream ended: %v", err))
+	}()
+
+	var postURL string
+	select {
+	case postURL = <-endpoint:
+	case <-c.done:
+		cancel()
+		return nil, fmt.Errorf("connection closed: %v", c.readErr)
+	case <-time.After(cfg.timeout):
+		cancel()
+		return nil, fmt.Errorf("no endpoint event within %s", cfg.timeout)
+	}
+
+	c.send = func(ctx context.Context, msg []byte) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, postURL, bytes.NewReader(msg))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		for k, v := range cfg.Headers {
+			req.Header.Set(k, v)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+		if resp.StatusCode >= 300 {
+			return fmt.Errorf("POST %s: HTTP %d", req.URL.Path, resp.StatusCode)
+		}
+		return nil
+	}
+	c.closeFn = cancel
+	return c, nil
+}
+
+// readSSE calls fn with each event of a text/event-stream until r ends.
+func readSSE(r io.Reader, fn func(event, data string)) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	var event string
+	var data []string
+	for sc.Scan() {
+		line := sc.Text()
+		switch {
+		case line == "":
+			if len(data) > 0 {
+				fn(event, strings.Join(data, "\n"))
+			}
+			event, data = "", nil
+		case strings.HasPrefix(line, ":"):
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	return sc.Err()
+}
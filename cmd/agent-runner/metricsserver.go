@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	// defaultMetricsAddr is the default METRICS_ADDR.
+	defaultMetricsAddr = ":8090"
+	// metricsShutdownTimeout bounds waiting for in-flight scrapes at exit.
+	metricsShutdownTimeout = 2 * time.Second
+	// healthStallIntervals is how many STATUS_INTERVALs may pass without a
+	// status heartbeat before /healthz fails.
+	healthStallIntervals = 3
+)
+
+// configReady is set once the run's configuration has been validated and
+// the first provider request is about to be sent; /readyz reports it.
+var configReady atomic.Bool
+
+// statusPhases lists every phase status.json can report, for the phase
+// gauge.
+var statusPhases = []string{
+	phaseStarting, phaseCallingLLM, phaseStreaming, phaseExecutingTool,
+	phaseFinalizing, phaseDone, phaseError, phaseCancelled,
+}
+
+// metricsServer serves /healthz, /readyz and /metrics while the run is in
+// progress; nil when disabled or when its port was unavailable.
+var metricsServer *http.Server
+
+// newMetricsRegistry returns the runner's metrics, read from the status
+// reporter and the attempt counter at scrape time.
+func newMetricsRegistry() *prometheus.Registry {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "agent_runner_provider_attempts_total",
+			Help: "HTTP requests sent to the model provider, retries included.",
+		}, func() float64 { return float64(providerAttempts.Load()) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "agent_runner_tokens_received_total",
+			Help: "Output tokens received from the model provider, estimated while streaming.",
+		}, func() float64 {
+			_, tokens := status.snapshot()
+			return float64(tokens)
+		}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "agent_runner_elapsed_seconds",
+			Help: "Seconds since the runner started.",
+		}, func() float64 { return time.Since(runStartedAt).Seconds() }),
+	)
+	for _, phase := range statusPhases {
+		reg.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name:        "agent_runner_phase",
+			Help:        "1 for the run's current phase, 0 for the others.",
+			ConstLabels: prometheus.Labels{"phase": phase},
+		}, func() float64 {
+			if current, _ := status.snapshot(); current == phase {
+				return 1
+			}
+			return 0
+		}))
+	}
+	return reg
+}
+
+// newMetricsHandler serves /healthz (the status heartbeat is still
+// advancing), /readyz (the configuration is valid) and /metrics, so
+// liveness and readiness probes can target the runner during long runs.
+func newMetricsHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		if since, stalled := status.stalled(healthStallIntervals); stalled {
+			http.Error(w, fmt.Sprintf("no status heartbeat for %s", since.Round(time.Second)), http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("ok\n"))
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		if !configReady.Load() {
+			http.Error(w, "configuration not validated yet", http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("ok\n"))
+	})
+	mux.Handle("/metrics", promhttp.HandlerFor(newMetricsRegistry(), promhttp.HandlerOpts{}))
+	return mux
+}
+
+// startMetricsServer serves newMetricsHandler on METRICS_ADDR, unless it is
+// "off". A port that cannot be bound is logged and the run continues
+// without the server.
+func startMetricsServer() *http.Server {
+	addr := getEnv("METRICS_ADDR", defaultMetricsAddr)
+	if addr == "off" {
+		return nil
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		log.Printf("metrics server disabled: %v", err)
+		return nil
+	}
+	srv := &http.Server{Handler: newMetricsHandler(), ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("metrics server stopped: %v", err)
+		}
+	}()
+	log.Printf("serving /healthz, /readyz and /metrics on %s", ln.Addr())
+	return srv
+}
+
+// stopMetricsServer shuts the server down, waiting briefly for in-flight
+// scrapes.
+func stopMetricsServer(srv *http.Server) {
+	if srv == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), metricsShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Printf("metrics server shutdown: %v", err)
+	}
+}
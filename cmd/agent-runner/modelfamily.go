@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/openai/openai-go/v3"
+)
+
+// Model families select the chat completion request shape. Reasoning models
+// (OpenAI's o-series) take the system prompt in the developer role, reject
+// the sampling parameters and take max_completion_tokens for max_tokens.
+const (
+	familyStandard  = "standard"
+	familyReasoning = "reasoning"
+)
+
+// reasoningModel is set when the run's model is in the reasoning family;
+// main sets it from MODEL_FAMILY or the model name.
+var reasoningModel bool
+
+// reasoningModelPattern matches the o-series model names: o1, o3-mini,
+// o4-mini-2025-04-16, ...
+var reasoningModelPattern = regexp.MustCompile(`^o[1-9][0-9]*(-|$)`)
+
+// modelFamily returns MODEL_FAMILY (standard or reasoning) or, when it is
+// unset, the family of model. A vendor prefix such as OpenRouter's
+// "openai/o3-mini" is ignored. Azure deployment names need not follow the
+// model's, so an Azure reasoning deployment is named with MODEL_FAMILY.
+// The reasoning family exists only for OpenAI-compatible providers.
+func modelFamily(provider, model string) (string, error) {
+	openAICompatible := provider != "anthropic" && provider != "bedrock"
+	switch family := getEnv("MODEL_FAMILY", ""); family {
+	case "":
+		name := strings.ToLower(model[strings.LastIndex(model, "/")+1:])
+		if openAICompatible && reasoningModelPattern.MatchString(name) {
+			return familyReasoning, nil
+		}
+		return familyStandard, nil
+	case familyStandard:
+		return family, nil
+	case familyReasoning:
+		if !openAICompatible {
+			return "", fmt.Errorf("MODEL_FAMILY=reasoning is for OpenAI-compatible providers, not %s", provider)
+		}
+		return family, nil
+	default:
+		return "", fmt.Errorf("invalid MODEL_FAMILY %q: must be standard or reasoning", family)
+	}
+}
+
+// openAISystemMessage returns a system message, in the developer role for
+// reasoning models.
+func openAISystemMessage(content string) openai.ChatCompletionMessageParamUnion {
+	if reasoningModel {
+		return openai.DeveloperMessage(content)
+	}
+	return openai.SystemMessage(content)
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// genericDefaultModel is the MODEL_NAME used when none is set. It is only
+// valid for OpenAI-style providers.
+const genericDefaultModel = "gpt-4o-mini"
+
+// knownModels lists a few valid model names per provider, default first.
+// It mirrors the CLI's model suggestions and is used for defaults and hints,
+// never to reject a model.
+var knownModels = map[string][]string{
+	"openai":       {"gpt-4o-mini", "gpt-4o", "gpt-4.1", "gpt-4.1-mini", "o4-mini"},
+	"azure-openai": {"gpt-4o-mini", "gpt-4o", "gpt-4.1", "o3-mini"},
+	"anthropic":    {"claude-sonnet-4-20250514", "claude-opus-4-20250514", "claude-haiku-3-5-20241022"},
+	"ollama":       {"llama3", "llama3.3", "qwen3", "mistral", "gemma3"},
+	"github":       {"gpt-4o-mini", "gpt-4o", "Meta-Llama-3.1-405B-Instruct", "Mistral-large-2407"},
+	"openrouter":   {"openai/gpt-4o-mini", "openai/gpt-4o", "anthropic/claude-sonnet-4", "meta-llama/llama-3.3-70b-instruct"},
+	"bedrock":      {"anthropic.claude-3-5-sonnet-20240620-v1:0", "amazon.nova-pro-v1:0", "meta.llama3-1-70b-instruct-v1:0"},
+}
+
+// providerAliases maps alternative MODEL_PROVIDER values to the name used
+// throughout the runner.
+var providerAliases = map[string]string{
+	"azure":         "azure-openai",
+	"github-models": "github",
+}
+
+// canonicalProvider lower-cases provider and resolves aliases.
+func canonicalProvider(provider string) string {
+	provider = strings.ToLower(provider)
+	if canonical, ok := providerAliases[provider]; ok {
+		return canonical
+	}
+	return provider
+}
+
+// resolveModel returns the model to use for provider. When MODEL_NAME is
+// unset or still the generic OpenAI default and provider has its own
+// default, that default is used instead and reported as substituted.
+func resolveModel(provider, modelName string) (model string, substituted bool) {
+	if modelName != "" && modelName != genericDefaultModel {
+		return modelName, false
+	}
+	models := knownModels[provider]
+	if len(models) == 0 {
+		return genericDefaultModel, false
+	}
+	for _, m := range models {
+		if m == genericDefaultModel {
+			return genericDefaultModel, false
+		}
+	}
+	return models[0], true
+}
+
+// modelHint suggests valid models after a model-not-found error, or
+// returns "" when there is no bundled list for provider.
+func modelHint(provider, model string) string {
+	if provider == "azure-openai" {
+		return fmt.Sprintf("deployment %q was not found on the Azure OpenAI resource; set AZURE_OPENAI_DEPLOYMENT to the name of a deployment", model)
+	}
+	models := knownModels[provider]
+	if len(models) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("model %q was not found for provider %s; valid models include %s (set MODEL_NAME)",
+		model, provider, strings.Join(models, ", "))
+}
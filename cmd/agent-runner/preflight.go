@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// defaultPreflightTimeout bounds the PREFLIGHT lookup and dial together
+// unless PREFLIGHT_TIMEOUT is set.
+const defaultPreflightTimeout = 5 * time.Second
+
+// preflightTimeout returns the timeout of the PREFLIGHT check, or zero when
+// PREFLIGHT is not true.
+func preflightTimeout() (time.Duration, error) {
+	if getEnv("PREFLIGHT", "") != "true" {
+		return 0, nil
+	}
+	v := getEnv("PREFLIGHT_TIMEOUT", "")
+	if v == "" {
+		return defaultPreflightTimeout, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid PREFLIGHT_TIMEOUT %q: must be a positive duration such as 5s", v)
+	}
+	return d, nil
+}
+
+// providerEndpoint returns the URL provider's requests are sent to, with
+// the same defaults the provider calls use.
+func providerEndpoint(provider, baseURL string) (string, error) {
+	switch provider {
+	case "anthropic":
+		return firstNonEmpty(baseURL, "https://api.anthropic.com"), nil
+	case "azure-openai":
+		endpoint, _, err := azureEndpoint(baseURL)
+		return endpoint, err
+	case "bedrock":
+		if baseURL != "" {
+			return baseURL, nil
+		}
+		region := firstNonEmpty(getEnv("AWS_REGION", ""), getEnv("AWS_DEFAULT_REGION", ""))
+		if region == "" {
+			return "", fmt.Errorf("Bedrock requires AWS_REGION to be set")
+		}
+		return "https://bedrock-runtime." + region + ".amazonaws.com", nil
+	default:
+		return firstNonEmpty(baseURL, providerBaseURLs[provider], "https://api.openai.com/v1"), nil
+	}
+}
+
+// preflight resolves and dials the host of endpoint, or the proxy that
+// requests to it go through, so a blocked egress or a wrong base URL fails
+// the run at once with the host named, instead of after every retry.
+// Nothing is sent over the connection.
+func preflight(ctx context.Context, endpoint string, timeout time.Duration) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return "", fmt.Errorf("invalid provider endpoint %q", endpoint)
+	}
+	target, via := u, ""
+	if proxy, err := proxyFromEnvironment()(&http.Request{URL: u}); err == nil && proxy != nil {
+		target, via = proxy, " (the proxy for "+u.Host+")"
+	}
+	host, port := target.Hostname(), target.Port()
+	if port == "" {
+		port = map[string]string{"http": "80", "socks5": "1080", "socks5h": "1080"}[target.Scheme]
+	}
+	if port == "" {
+		port = "443"
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupHost(ctx, host)
+	if err != nil {
+		return "", fmt.Errorf("cannot reach %s%s: DNS lookup failed: %v", host, via, err)
+	}
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", net.JoinHostPort(host, port))
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "", fmt.Errorf("cannot reach %s%s: connecting to port %s timed out after %s (is egress blocked by a NetworkPolicy?)",
+			host, via, port, timeout)
+	}
+	if err != nil {
+		return "", fmt.Errorf("cannot reach %s%s: connection to port %s failed: %v", host, via, port, err)
+	}
+	addr := conn.RemoteAddr().String()
+	conn.Close()
+	return fmt.Sprintf("%s%s resolved to %v, connected to %s", host, via, addrs, addr), nil
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/openai/openai-go/v3"
+	openaioption "github.com/openai/openai-go/v3/option"
+)
+
+// providerBaseURLs are the endpoints of OpenAI-compatible providers used
+// when MODEL_BASE_URL is unset.
+var providerBaseURLs = map[string]string{
+	"ollama":     "http://ollama.default.svc:11434/v1",
+	"github":     "https://models.inference.ai.azure.com",
+	"openrouter": "https://openrouter.ai/api/v1",
+}
+
+// presetOptions returns the provider-specific request options of the
+// OpenAI-compatible presets. OpenRouter attributes requests to an app with
+// the optional HTTP-Referer and X-Title headers, set from
+// OPENROUTER_SITE_URL and OPENROUTER_APP_NAME.
+func presetOptions(provider string) []openaioption.RequestOption {
+	var opts []openaioption.RequestOption
+	if provider == "openrouter" {
+		if v := getEnv("OPENROUTER_SITE_URL", ""); v != "" {
+			opts = append(opts, openaioption.WithHeader("HTTP-Referer", v))
+		}
+		if v := getEnv("OPENROUTER_APP_NAME", ""); v != "" {
+			opts = append(opts, openaioption.WithHeader("X-Title", v))
+		}
+	}
+	return opts
+}
+
+// githubAPIError classifies a GitHub Models error. Its error codes follow
+// Azure AI: a 429 with a per-day window (UserByModelByDay, "per 86400s")
+// is a quota that no retry within the run can outlast, and an unknown model
+// comes back as a 400 rather than a 404.
+func githubAPIError(e *openai.Error) *apiError {
+	msg := e.Message
+	if e.Code != "" {
+		msg = e.Code + ": " + msg
+	}
+	apiErr := newAPIError("GitHub Models", e.StatusCode, msg)
+	switch {
+	case e.StatusCode == 429 && (strings.Contains(e.Message, "ByDay") || strings.Contains(e.Message, "86400s")):
+		apiErr.Class, apiErr.Retryable = "quota_exceeded", false
+	case e.Code == "unknown_model" || e.Code == "unavailable_model":
+		apiErr.Class = "not_found_error"
+	case e.Code == "content_filter":
+		apiErr.Class = "content_filter_error"
+	}
+	return apiErr
+}
+
+// openRouterAPIError classifies an OpenRouter error. Running out of credits
+// is a 402; a 403 carrying moderation reasons in its metadata is a content
+// filter; and a failure of the upstream provider names it in the metadata,
+// which is kept in the message.
+func openRouterAPIError(e *openai.Error) *apiError {
+	var body struct {
+		Metadata struct {
+			Reasons      []string `json:"reasons"`
+			ProviderName string   `json:"provider_name"`
+		} `json:"metadata"`
+	}
+	_ = json.Unmarshal([]byte(e.RawJSON()), &body)
+	msg := e.Message
+	if p := body.Metadata.ProviderName; p != "" {
+		msg += " (upstream provider " + p + ")"
+	}
+	apiErr := newAPIError("OpenRouter", e.StatusCode, msg)
+	switch {
+	case e.StatusCode == 402:
+		apiErr.Class = "quota_exceeded"
+	case e.StatusCode == 403 && len(body.Metadata.Reasons) > 0:
+		apiErr.Class = "content_filter_error"
+	}
+	return apiErr
+}
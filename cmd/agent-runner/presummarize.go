@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+)
+
+// Defaults for PRE_SUMMARIZE_THRESHOLD and PRE_SUMMARIZE_TARGET_TOKENS.
+const (
+	defaultPreSummarizeThreshold = 50000
+	defaultPreSummarizeTarget    = 4000
+)
+
+// defaultPreSummarizePrompt is the system prompt of the summarization call
+// unless PRE_SUMMARIZE_PROMPT replaces it.
+const defaultPreSummarizePrompt = "The user's message is the input of a task that is too long to process in full. " +
+	"Rewrite it as a faithful, condensed version that another assistant will work from instead of the original. " +
+	"Keep every instruction, question and requirement, and keep names, identifiers, numbers, error messages and " +
+	"code that matter verbatim. Drop repetition, boilerplate and detail the task does not need. " +
+	"Reply with the condensed input only, without commentary."
+
+// preSummarizer condenses tasks over threshold estimated tokens with an
+// extra call before the real one, so an oversized input is shortened
+// rather than failing on the context window.
+type preSummarizer struct {
+	threshold int
+	target    int
+	prompt    string
+}
+
+// preSummary is the pre-summarization pass in the result's metrics. Its
+// usage is also included in the run's inputTokens and outputTokens.
+type preSummary struct {
+	// TaskTokens and SummaryTokens are the estimated sizes of the task and
+	// of the summary sent in its place.
+	TaskTokens    int `json:"taskTokens"`
+	SummaryTokens int `json:"summaryTokens"`
+	InputTokens   int `json:"inputTokens"`
+	OutputTokens  int `json:"outputTokens"`
+}
+
+// loadPreSummarizer reads PRE_SUMMARIZE, PRE_SUMMARIZE_THRESHOLD,
+// PRE_SUMMARIZE_TARGET_TOKENS and PRE_SUMMARIZE_PROMPT. It returns nil
+// unless PRE_SUMMARIZE=true.
+func loadPreSummarizer() (*preSummarizer, error) {
+	if getEnv("PRE_SUMMARIZE", "") != "true" {
+		return nil, nil
+	}
+	s := &preSummarizer{
+		threshold: defaultPreSummarizeThreshold,
+		target:    defaultPreSummarizeTarget,
+		prompt:    getEnv("PRE_SUMMARIZE_PROMPT", defaultPreSummarizePrompt),
+	}
+	for _, f := range []struct {
+		key string
+		dst *int
+	}{{"PRE_SUMMARIZE_THRESHOLD", &s.threshold}, {"PRE_SUMMARIZE_TARGET_TOKENS", &s.target}} {
+		if v := getEnv(f.key, ""); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("invalid %s %q: must be a positive number of tokens", f.key, v)
+			}
+			*f.dst = n
+		}
+	}
+	if s.target >= s.threshold {
+		return nil, fmt.Errorf("PRE_SUMMARIZE_TARGET_TOKENS %d must be below PRE_SUMMARIZE_THRESHOLD %d", s.target, s.threshold)
+	}
+	return s, nil
+}
+
+// summaryCallKey marks the context of a summarization call.
+type summaryCallKey struct{}
+
+// isSummaryCall reports whether ctx is that of a summarization call, which
+// streams nothing and is sent without the response format: its reply is
+// an input, not the run's response.
+func isSummaryCall(ctx context.Context) bool {
+	return ctx.Value(summaryCallKey{}) != nil
+}
+
+// summarize returns task unchanged when it is within the threshold.
+// Otherwise it asks the model for a summary of at most target tokens, sent
+// without history, images or tools, and returns that with the call's usage.
+// A summary still over the threshold is an input_too_large error.
+func (s *preSummarizer) summarize(ctx context.Context, c taskCall, task string) (string, llmResult, *preSummary, error) {
+	taskTokens := estimateTokens(task)
+	if taskTokens <= s.threshold {
+		return task, llmResult{}, nil, nil
+	}
+	log.Printf("task is ~%d tokens, over PRE_SUMMARIZE_THRESHOLD %d; summarizing it first", taskTokens, s.threshold)
+	ctx = withHistory(context.WithValue(ctx, summaryCallKey{}, true), nil)
+	prompt := fmt.Sprintf("%s\n\nKeep it under %d tokens.", s.prompt, s.target)
+	llm, err := c.call(ctx, prompt, task, nil, nil)
+	if err != nil {
+		return task, llm, nil, fmt.Errorf("pre-summarization: %w", err)
+	}
+	sum := &preSummary{
+		TaskTokens:    taskTokens,
+		SummaryTokens: estimateTokens(llm.Text),
+		InputTokens:   llm.InputTokens,
+		OutputTokens:  llm.OutputTokens,
+	}
+	switch {
+	case llm.Text == "":
+		return task, llm, sum, fmt.Errorf("pre-summarization returned an empty summary")
+	case sum.SummaryTokens > s.threshold:
+		return task, llm, sum, fmt.Errorf("%w: the pre-summary is still ~%d tokens, over PRE_SUMMARIZE_THRESHOLD %d",
+			errInputTooLarge, sum.SummaryTokens, s.threshold)
+	}
+	log.Printf("summarized the task from ~%d to ~%d tokens", taskTokens, sum.SummaryTokens)
+	return llm.Text, llm, sum, nil
+}
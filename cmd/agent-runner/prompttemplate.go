@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/template"
+)
+
+// varsFile holds the template variables, in ipc.Input.
+const varsFile = "vars.json"
+
+// renderedPrompts records the prompts after template rendering, so an audit
+// of result.json shows what was actually sent.
+type renderedPrompts struct {
+	SystemPrompt string `json:"systemPrompt"`
+	Task         string `json:"task"`
+}
+
+// loadTemplateVars reads the template variables from path, falling back to
+// the VARS_JSON env var. Both hold a JSON object. It returns nil and an
+// empty source when neither is set.
+func loadTemplateVars(path string) (vars map[string]any, source string, err error) {
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		source = path
+	case errors.Is(err, os.ErrNotExist):
+		v := getEnv("VARS_JSON", "")
+		if v == "" {
+			return nil, "", nil
+		}
+		data, source = []byte(v), "VARS_JSON"
+	default:
+		return nil, "", err
+	}
+	if err := json.Unmarshal(data, &vars); err != nil {
+		return nil, "", fmt.Errorf("template variables in %s must be a JSON object: %w", source, err)
+	}
+	return vars, source, nil
+}
+
+// renderPrompt executes text as a Go text/template with vars. A reference
+// to a variable that is not set is an error naming the key.
+func renderPrompt(name, text string, vars map[string]any) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("parsing %s template: %w", name, err)
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, vars); err != nil {
+		return "", fmt.Errorf("rendering %s template: %w", name, err)
+	}
+	return b.String(), nil
+}
+
+// renderPrompts renders the task and system prompt with the variables from
+// vars.json or VARS_JSON. Without variables, or with TEMPLATE=off for
+// prompts that contain literal "{{", both are returned unchanged and
+// rendered is nil.
+func renderPrompts(systemPrompt, task, varsFile string) (string, string, *renderedPrompts, error) {
+	if getEnv("TEMPLATE", "") == "off" {
+		return systemPrompt, task, nil, nil
+	}
+	vars, source, err := loadTemplateVars(varsFile)
+	if err != nil || vars == nil {
+		return systemPrompt, task, nil, err
+	}
+	if systemPrompt, err = renderPrompt("SYSTEM_PROMPT", systemPrompt, vars); err != nil {
+		return "", "", nil, err
+	}
+	if task, err = renderPrompt("TASK", task, vars); err != nil {
+		return "", "", nil, err
+	}
+	log.Printf("rendered prompts with %d variable(s) from %s: task=%q", len(vars), source, truncateRedacted(task, 200))
+	return systemPrompt, task, &renderedPrompts{SystemPrompt: systemPrompt, Task: task}, nil
+}
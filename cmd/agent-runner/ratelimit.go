@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// requestLimiter spaces out provider requests to REQUESTS_PER_MINUTE; nil
+// means unlimited.
+var requestLimiter *rate.Limiter
+
+// loadRequestLimiter reads REQUESTS_PER_MINUTE. The bucket holds a single
+// token, so requests are spread evenly over the minute instead of being
+// sent in a burst. It returns nil when the variable is unset.
+func loadRequestLimiter() (*rate.Limiter, error) {
+	v := getEnv("REQUESTS_PER_MINUTE", "")
+	if v == "" {
+		return nil, nil
+	}
+	rpm, err := strconv.ParseFloat(v, 64)
+	if err != nil || rpm <= 0 {
+		return nil, fmt.Errorf("invalid REQUESTS_PER_MINUTE %q", v)
+	}
+	return rate.NewLimiter(rate.Limit(rpm/60), 1), nil
+}
+
+// waitForRequestSlot blocks until requestLimiter allows another request,
+// or ctx is done.
+func waitForRequestSlot(ctx context.Context) error {
+	if requestLimiter == nil {
+		return nil
+	}
+	start := time.Now()
+	if err := requestLimiter.Wait(ctx); err != nil {
+		return err
+	}
+	if waited := time.Since(start); waited >= 100*time.Millisecond {
+		log.Printf("waited %s for REQUESTS_PER_MINUTE", waited.Round(time.Millisecond))
+	}
+	return nil
+}
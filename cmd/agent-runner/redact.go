@@ -0,0 +1,113 @@
+package main
+
+import (
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// secretEnvVars hold credentials whose values are masked wherever they
+// appear in logs and output files.
+var secretEnvVars = []string{
+	"ANTHROPIC_API_KEY", "OPENAI_API_KEY", "AZURE_OPENAI_API_KEY", "API_KEY",
+	"GITHUB_TOKEN", "OPENROUTER_API_KEY",
+	"AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN", "RESULT_CALLBACK_TOKEN",
+}
+
+// minSecretLen keeps short values, which would mask unrelated text, out of
+// the known secrets.
+const minSecretLen = 8
+
+// knownSecrets are the credential values redact masks verbatim. Most are
+// registered at startup; an API key file re-read after an authentication
+// failure adds one mid-run, hence secretsMu.
+var (
+	knownSecrets []string
+	secretsMu    sync.RWMutex
+)
+
+// registerSecret adds a credential value for redact to mask.
+func registerSecret(v string) {
+	if len(v) < minSecretLen {
+		return
+	}
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+	for _, s := range knownSecrets {
+		if s == v {
+			return
+		}
+	}
+	knownSecrets = append(knownSecrets, v)
+}
+
+// registerSecretEnv registers the values of secretEnvVars and of the API
+// key files named by their _FILE variants.
+func registerSecretEnv() {
+	for _, name := range secretEnvVars {
+		registerSecret(os.Getenv(name))
+		if path := os.Getenv(name + keyFileSuffix); path != "" {
+			if key, err := readAPIKeyFile(path); err == nil {
+				registerSecret(key)
+			}
+		}
+	}
+}
+
+// secretPatterns match credentials by shape, for keys the runner was never
+// given, such as ones echoed back in a provider error or printed by a tool.
+// Each replacement keeps the header name or scheme so the reader still sees
+// what was removed.
+var secretPatterns = []struct {
+	re   *regexp.Regexp
+	repl string
+}{
+	// Credential headers in dumped requests, as `X-Api-Key: ...` or
+	// `"authorization": "..."`.
+	{regexp.MustCompile(`(?i)((?:proxy-)?authorization|x-api-key|api-key)("?\s*[:=]\s*"?)(?:(?:Bearer|Basic)\s+)?[^\s"',;]+`), "${1}${2}" + redacted},
+	{regexp.MustCompile(`(Bearer\s+)[A-Za-z0-9._~+/=-]+`), "${1}" + redacted},
+	{regexp.MustCompile(`sk-[A-Za-z0-9_-]{20,}`), redacted},
+	{regexp.MustCompile(`gh[pousr]_[A-Za-z0-9]{20,}`), redacted},
+	{regexp.MustCompile(`github_pat_[A-Za-z0-9_]{20,}`), redacted},
+}
+
+// redact masks known credential values and anything shaped like an API key
+// or bearer token in s. Truncate after redacting, never before, so a cut
+// cannot leave a partial secret that no longer matches.
+func redact(s string) string {
+	secretsMu.RLock()
+	for _, secret := range knownSecrets {
+		s = strings.ReplaceAll(s, secret, redacted)
+	}
+	secretsMu.RUnlock()
+	for _, p := range secretPatterns {
+		s = p.re.ReplaceAllString(s, p.repl)
+	}
+	return s
+}
+
+// redactBytes is redact for encoded output such as result.json.
+func redactBytes(b []byte) []byte {
+	return []byte(redact(string(b)))
+}
+
+// truncateRedacted redacts s and then truncates it to n bytes.
+func truncateRedacted(s string, n int) string {
+	return truncate(redact(s), n)
+}
+
+// redactingWriter redacts everything written through it. The log package
+// writes each line with a single call, so a secret is never split across
+// writes.
+type redactingWriter struct {
+	w io.Writer
+}
+
+func (r redactingWriter) Write(p []byte) (int, error) {
+	if _, err := r.w.Write(redactBytes(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
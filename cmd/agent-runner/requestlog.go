@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const requestFile = "request.json"
+
+// redacted replaces credentials in the saved request.
+const redacted = "REDACTED"
+
+// savedRequest is the request.json artifact written with SAVE_REQUEST=true.
+type savedRequest struct {
+	// SchemaVersion is outputSchemaVersion in request.json; it is omitted
+	// where the request is embedded in a transcript record.
+	SchemaVersion int               `json:"schemaVersion,omitempty"`
+	Time          time.Time         `json:"time"`
+	Method        string            `json:"method"`
+	URL           string            `json:"url"`
+	Headers       map[string]string `json:"headers"`
+	Body          json.RawMessage   `json:"body,omitempty"`
+}
+
+// requestRecorder writes each provider request to path before sending it,
+// overwriting the previous one, so after the run the file holds the final
+// request of the tool-call loop. Credentials are redacted.
+type requestRecorder struct {
+	base http.RoundTripper
+	path string
+}
+
+func (t *requestRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	saved, err := captureRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	saved.SchemaVersion = outputSchemaVersion
+	if err := writeFileAtomic(t.path, saved); err != nil {
+		log.Printf("failed to save request to %s: %v", t.path, err)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// captureRequest returns req as a savedRequest with credentials redacted.
+// The body is read and replaced, so req can still be sent.
+func captureRequest(req *http.Request) (savedRequest, error) {
+	saved := savedRequest{
+		Time:    time.Now().UTC(),
+		Method:  req.Method,
+		URL:     redactURL(req.URL),
+		Headers: map[string]string{},
+	}
+	for name, values := range req.Header {
+		v := strings.Join(values, ", ")
+		if isCredentialHeader(name) {
+			v = redacted
+		}
+		saved.Headers[name] = v
+	}
+	if req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return saved, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		if json.Valid(body) {
+			saved.Body = body
+		} else {
+			saved.Body, _ = json.Marshal(string(body))
+		}
+	}
+	return saved, nil
+}
+
+// isCredentialHeader reports whether a header carries an API key or token.
+func isCredentialHeader(name string) bool {
+	switch strings.ToLower(name) {
+	case "authorization", "x-api-key", "api-key", "proxy-authorization", "cookie":
+		return true
+	}
+	return false
+}
+
+// redactURL returns u with credential query parameters and user info
+// removed.
+func redactURL(u *url.URL) string {
+	c := *u
+	if c.User != nil {
+		c.User = url.User(redacted)
+	}
+	q := c.Query()
+	changed := false
+	for k := range q {
+		switch strings.ToLower(k) {
+		case "key", "api-key", "api_key", "access_token":
+			q.Set(k, redacted)
+			changed = true
+		}
+	}
+	if changed {
+		c.RawQuery = q.Encode()
+	}
+	return c.String()
+}
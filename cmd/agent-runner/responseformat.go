@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/shared"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+// structuredFile receives the parsed response when RESPONSE_FORMAT or
+// RESPONSE_SCHEMA_FILE asks for structured output.
+const structuredFile = "structured.json"
+
+// errSchemaValidation marks a response that is not valid JSON or does not
+// match RESPONSE_SCHEMA_FILE, even after the corrective retry.
+var errSchemaValidation = errors.New("response failed schema validation")
+
+// responseFormat is the structured output requested for this run. The zero
+// value asks for plain text.
+type responseFormat struct {
+	// JSON is set when the final response must be a JSON value.
+	JSON bool
+	// Schema is the parsed RESPONSE_SCHEMA_FILE, sent to providers that
+	// support json_schema response formats; nil without a schema.
+	Schema    map[string]any
+	validator *jsonschema.Schema
+}
+
+// respFormat is the response format for this run; main sets it from the
+// environment.
+var respFormat responseFormat
+
+// loadResponseFormat reads RESPONSE_FORMAT (text or json) and
+// RESPONSE_SCHEMA_FILE. A schema implies RESPONSE_FORMAT=json.
+func loadResponseFormat() (responseFormat, error) {
+	var f responseFormat
+	switch v := getEnv("RESPONSE_FORMAT", "text"); v {
+	case "text":
+	case "json":
+		f.JSON = true
+	default:
+		return f, fmt.Errorf("invalid RESPONSE_FORMAT %q: must be text or json", v)
+	}
+
+	path := getEnv("RESPONSE_SCHEMA_FILE", "")
+	if path == "" {
+		return f, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return f, fmt.Errorf("reading RESPONSE_SCHEMA_FILE: %w", err)
+	}
+	if f.validator, err = compileSchema("RESPONSE_SCHEMA_FILE", path, data); err != nil {
+		return f, err
+	}
+	if err := json.Unmarshal(data, &f.Schema); err != nil {
+		return f, fmt.Errorf("RESPONSE_SCHEMA_FILE %s: schema must be a JSON object", path)
+	}
+	f.JSON = true
+	return f, nil
+}
+
+func (f responseFormat) String() string {
+	switch {
+	case f.Schema != nil:
+		return "json (with schema)"
+	case f.JSON:
+		return "json"
+	}
+	return "text"
+}
+
+// systemInstruction returns the text appended to the system prompt. Every
+// provider gets it: Anthropic and Bedrock have no response format parameter,
+// and OpenAI's json_object mode requires the prompt to mention JSON.
+func (f responseFormat) systemInstruction() string {
+	if !f.JSON {
+		return ""
+	}
+	s := "\n\n## Response Format\n\nRespond with a single JSON value and nothing else: no prose and no markdown code fences."
+	if f.Schema != nil {
+		schema, _ := json.MarshalIndent(f.Schema, "", "  ")
+		s += " The JSON must validate against this JSON Schema:\n\n" + string(schema)
+	}
+	return s
+}
+
+// applyOpenAI sets response_format: json_schema for providers known to
+// support it, json_object otherwise.
+func (f responseFormat) applyOpenAI(provider string, params *openai.ChatCompletionNewParams) {
+	if !f.JSON {
+		return
+	}
+	if f.Schema != nil && supportsJSONSchemaFormat(provider) {
+		params.ResponseFormat = openai.ChatCompletionNewParamsResponseFormatUnion{
+			OfJSONSchema: &shared.ResponseFormatJSONSchemaParam{
+				JSONSchema: shared.ResponseFormatJSONSchemaJSONSchemaParam{Name: "response", Schema: f.Schema},
+			},
+		}
+		return
+	}
+	params.ResponseFormat = openai.ChatCompletionNewParamsResponseFormatUnion{
+		OfJSONObject: &shared.ResponseFormatJSONObjectParam{},
+	}
+}
+
+// supportsJSONSchemaFormat reports whether provider accepts a json_schema
+// response format. Other OpenAI-compatible endpoints only get json_object.
+func supportsJSONSchemaFormat(provider string) bool {
+	switch provider {
+	case "openai", "azure-openai", "ollama", "github", "openrouter":
+		return true
+	}
+	return false
+}
+
+// parse decodes text as JSON, tolerating a surrounding markdown code fence,
+// and validates it against the schema. Errors wrap errSchemaValidation.
+func (f responseFormat) parse(text string) (any, error) {
+	text = stripCodeFence(text)
+	v, err := jsonschema.UnmarshalJSON(strings.NewReader(text))
+	if err != nil {
+		return nil, fmt.Errorf("%w: response is not valid JSON: %v", errSchemaValidation, err)
+	}
+	if f.validator != nil {
+		if err := f.validator.Validate(v); err != nil {
+			return nil, fmt.Errorf("%w: %v", errSchemaValidation, err)
+		}
+	}
+	return v, nil
+}
+
+// correction is the message sent for the one guided retry after parse
+// failed.
+func (f responseFormat) correction(err error) string {
+	return "Your previous response could not be accepted:\n\n" +
+		strings.TrimPrefix(err.Error(), errSchemaValidation.Error()+": ") +
+		"\n\nReply again with only the corrected JSON value."
+}
+
+// compileSchema compiles the JSON Schema data read from path, naming env
+// in errors.
+func compileSchema(env, path string, data []byte) (*jsonschema.Schema, error) {
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s %s: %w", env, path, err)
+	}
+	c := jsonschema.NewCompiler()
+	if err := c.AddResource(path, doc); err != nil {
+		return nil, fmt.Errorf("loading %s %s: %w", env, path, err)
+	}
+	schema, err := c.Compile(path)
+	if err != nil {
+		return nil, fmt.Errorf("compiling %s %s: %w", env, path, err)
+	}
+	return schema, nil
+}
+
+// stripCodeFence removes a ``` or ```json fence wrapping the whole text.
+func stripCodeFence(text string) string {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "```") || !strings.HasSuffix(text, "```") || len(text) < 6 {
+		return text
+	}
+	body := text[3 : len(text)-3]
+	if i := strings.IndexByte(body, '\n'); i >= 0 && !strings.ContainsAny(body[:i], "{[\"") {
+		body = body[i+1:]
+	}
+	return strings.TrimSpace(body)
+}
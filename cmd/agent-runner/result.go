@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync/atomic"
+	"time"
+)
+
+// outputSchemaVersion is written as schemaVersion in every file the runner
+// writes to /ipc/output: result.json and the per-task results of a batch,
+// status.json, request.json, each stream chunk and each transcript.jsonl
+// record. Bump it whenever a field of any of them is added, removed or
+// changes meaning, and record the change here and in
+// docs/agent-runner-output.md.
+//
+// Files without the field are version 1. Version 2 added model, provider,
+// finishReason, attempts, startedAt, completedAt and errorCode to
+// result.json. Version 3 stamped the other files, added the iteration
+// stream chunk, and added truncated, contextRecovery, contextChunks,
+// contextDropped, rendered, iterationLimitReached, mcpServers, taskId,
+// batch and metrics.reasoningTokens, preSummary, iterations, cost and
+// systemFingerprint to result.json. Version 4 added attachments, fallbacks
+// and the attachments_unsupported and ipc_unwritable errorCodes to
+// result.json.
+const outputSchemaVersion = 4
+
+// resultFile is the result of the run, in outputDir.
+const resultFile = "result.json"
+
+// runStartedAt is when the runner started; main sets it first thing.
+var runStartedAt time.Time
+
+// providerAttempts counts the HTTP requests sent to the provider, retries
+// included; retryTransport increments it per attempt.
+var providerAttempts atomic.Int64
+
+// finish stamps the fields every result.json carries, including the ones
+// written by fatal.
+func (r *agentResult) finish() {
+	r.SchemaVersion = outputSchemaVersion
+	if !runStartedAt.IsZero() {
+		r.StartedAt = runStartedAt.UTC().Format(time.RFC3339)
+	}
+	r.CompletedAt = time.Now().UTC().Format(time.RFC3339)
+	r.Attempts = int(providerAttempts.Load())
+}
+
+// normalizeFinishReason maps a provider stop reason onto the OpenAI
+// vocabulary the controller understands: stop, length, content_filter or
+// tool_calls. Unknown reasons are passed through unchanged.
+func normalizeFinishReason(stopReason string) string {
+	switch stopReason {
+	case "end_turn", "stop_sequence", "pause_turn":
+		return "stop"
+	case "max_tokens", "model_context_window_exceeded":
+		return "length"
+	case "refusal", "guardrail_intervened", "content_filtered":
+		return "content_filter"
+	case "tool_use", "function_call":
+		return "tool_calls"
+	}
+	return stopReason
+}
+
+// setStopReason records the provider's stop reason, flagging the result as
+// truncated when generation stopped at the output token limit.
+func (r *agentResult) setStopReason(stopReason string) {
+	r.StopReason = stopReason
+	r.FinishReason = normalizeFinishReason(stopReason)
+	r.Truncated = r.FinishReason == "length"
+	if r.Truncated {
+		log.Printf("WARNING: response truncated: the model stopped at its output token limit (stop reason %q); raise MAX_TOKENS for a complete answer", stopReason)
+	}
+}
+
+// errorCode returns the machine-readable errorCode for a failed run: the
+// classifyStatus class for provider API errors, otherwise a code naming
+// how the call failed.
+func errorCode(err error) string {
+	var apiErr *apiError
+	switch {
+	case errors.As(err, &apiErr):
+		return apiErr.Class
+	case errors.Is(err, errStreamInterrupted):
+		return "stream_interrupted"
+	case errors.Is(err, errSchemaValidation):
+		return "schema_validation_failed"
+	case errors.Is(err, errBudgetExceeded):
+		return "budget_exceeded"
+	case errors.Is(err, errInputTooLarge):
+		return "input_too_large"
+	case errors.Is(err, errToolLoop):
+		return "tool_loop_detected"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout_error"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	}
+	return "unknown_error"
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// contextFile holds the retrieved documents, in ipc.Input; CONTEXT_FILE
+	// overrides it.
+	contextFile = "context.json"
+	// defaultMaxContextTokens is the default MAX_CONTEXT_TOKENS.
+	defaultMaxContextTokens = 8000
+)
+
+// contextChunk is one retrieved document passed in context.json, for
+// example by a RAG SkillPack.
+type contextChunk struct {
+	Source string `json:"source"`
+	Text   string `json:"text"`
+	// Priority ranks chunks when they do not all fit: lower priorities are
+	// dropped first. Chunks without one are priority 0.
+	Priority int `json:"priority,omitempty"`
+}
+
+// loadContextChunks reads retrieved documents from path, either as a JSON
+// array or as {"chunks": [...]}. A missing file is not an error.
+func loadContextChunks(path string) ([]contextChunk, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var chunks []contextChunk
+	if err := json.Unmarshal(data, &chunks); err != nil {
+		var doc struct {
+			Chunks []contextChunk `json:"chunks"`
+		}
+		if err2 := json.Unmarshal(data, &doc); err2 != nil {
+			return nil, err
+		}
+		chunks = doc.Chunks
+	}
+	for i, c := range chunks {
+		if strings.TrimSpace(c.Text) == "" {
+			return nil, fmt.Errorf("chunk %d: text is empty", i)
+		}
+	}
+	return chunks, nil
+}
+
+// trimContext drops chunks until the rest fit in maxTokens (estimated):
+// lowest priority first and, among equal priorities, the oldest (earliest
+// in the file) first. The kept chunks stay in file order. It returns them
+// and how many were dropped.
+func trimContext(chunks []contextChunk, maxTokens int) ([]contextChunk, int) {
+	total := 0
+	for _, c := range chunks {
+		total += estimateTokens(c.Text)
+	}
+	order := make([]int, len(chunks))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return chunks[order[a]].Priority < chunks[order[b]].Priority })
+
+	drop := make(map[int]bool)
+	for _, i := range order {
+		if total <= maxTokens {
+			break
+		}
+		total -= estimateTokens(chunks[i].Text)
+		drop[i] = true
+	}
+	kept := make([]contextChunk, 0, len(chunks)-len(drop))
+	for i, c := range chunks {
+		if !drop[i] {
+			kept = append(kept, c)
+		}
+	}
+	return kept, len(drop)
+}
+
+// maxContextTokens parses MAX_CONTEXT_TOKENS.
+func maxContextTokens() (int, error) {
+	v := getEnv("MAX_CONTEXT_TOKENS", "")
+	if v == "" {
+		return defaultMaxContextTokens, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid MAX_CONTEXT_TOKENS %q", v)
+	}
+	return n, nil
+}
+
+// formatContext renders chunks as a system prompt section, numbered so the
+// model can cite them. It returns "" when there are none.
+func formatContext(chunks []contextChunk) string {
+	if len(chunks) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("\n\n## Retrieved Context\n\n" +
+		"The following documents were retrieved for this task. Use them when " +
+		"they are relevant and cite them by number; they may be incomplete.")
+	for i, c := range chunks {
+		source := c.Source
+		if source == "" {
+			source = "unknown source"
+		}
+		fmt.Fprintf(&b, "\n\n### [%d] %s\n\n%s", i+1, source, strings.TrimSpace(c.Text))
+	}
+	return b.String()
+}
@@ -0,0 +1,313 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// retryConfig controls how provider requests are retried. Every field has
+// an environment variable; see loadRetryConfig.
+type retryConfig struct {
+	// MaxRetries is the number of retries after the first attempt.
+	MaxRetries int
+	// RequestTimeout bounds a single attempt, including reading the
+	// response; zero disables it.
+	RequestTimeout time.Duration
+	// TotalDeadline bounds the whole run across all attempts and tool-call
+	// round-trips.
+	TotalDeadline time.Duration
+	// BackoffBase is the cap on the wait before the first retry; it
+	// doubles per retry up to BackoffMax. The actual wait is random below
+	// the cap.
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+	// RetryBudget bounds the time spent on retries over the whole run:
+	// failed attempts that are retried and the waits between them. Zero
+	// disables it.
+	RetryBudget time.Duration
+}
+
+var defaultRetryConfig = retryConfig{
+	MaxRetries:     5,
+	RequestTimeout: 5 * time.Minute,
+	TotalDeadline:  10 * time.Minute,
+	BackoffBase:    1 * time.Second,
+	BackoffMax:     30 * time.Second,
+	RetryBudget:    2 * time.Minute,
+}
+
+// retryCfg is the retry configuration in effect; main replaces it with the
+// values from the environment.
+var retryCfg = defaultRetryConfig
+
+// retrySpent is the time spent on retries so far, counted against
+// RetryBudget.
+var retrySpent atomic.Int64
+
+// jitterRand returns a random number in [0, n). Tests replace it to make
+// backoff deterministic.
+var jitterRand = rand.Int64N
+
+func (c retryConfig) String() string {
+	return fmt.Sprintf("max_retries=%d request_timeout=%s total_deadline=%s backoff_base=%s backoff_max=%s retry_budget=%s",
+		c.MaxRetries, c.RequestTimeout, c.TotalDeadline, c.BackoffBase, c.BackoffMax, c.RetryBudget)
+}
+
+// loadRetryConfig reads MAX_RETRIES, REQUEST_TIMEOUT, TOTAL_DEADLINE,
+// BACKOFF_BASE, BACKOFF_MAX and RETRY_BUDGET. An invalid value keeps its
+// default and is logged: a typo should not cost the run.
+func loadRetryConfig() retryConfig {
+	c := defaultRetryConfig
+	if v := getEnv("MAX_RETRIES", ""); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			c.MaxRetries = n
+		} else {
+			log.Printf("warning: invalid MAX_RETRIES %q, using %d", v, c.MaxRetries)
+		}
+	}
+	envDuration := func(key string, d *time.Duration, allowZero bool) {
+		v := getEnv(key, "")
+		if v == "" {
+			return
+		}
+		parsed, err := time.ParseDuration(v)
+		if err != nil || parsed < 0 || (parsed == 0 && !allowZero) {
+			log.Printf("warning: invalid %s %q, using %s", key, v, *d)
+			return
+		}
+		*d = parsed
+	}
+	envDuration("REQUEST_TIMEOUT", &c.RequestTimeout, true)
+	envDuration("TOTAL_DEADLINE", &c.TotalDeadline, false)
+	envDuration("BACKOFF_BASE", &c.BackoffBase, false)
+	envDuration("BACKOFF_MAX", &c.BackoffMax, false)
+	envDuration("RETRY_BUDGET", &c.RetryBudget, true)
+	if c.BackoffMax < c.BackoffBase {
+		log.Printf("warning: BACKOFF_MAX %s is below BACKOFF_BASE %s, using %s", c.BackoffMax, c.BackoffBase, c.BackoffBase)
+		c.BackoffMax = c.BackoffBase
+	}
+	return c
+}
+
+// backoffCap returns the longest wait before retry number attempt
+// (0-based): BackoffBase doubled per attempt, capped at BackoffMax.
+func (c retryConfig) backoffCap(attempt int) time.Duration {
+	d := c.BackoffBase
+	for i := 0; i < attempt && d < c.BackoffMax; i++ {
+		d *= 2
+	}
+	return min(d, c.BackoffMax)
+}
+
+// backoff returns the wait before retry number attempt: a random duration
+// in [0, backoffCap(attempt)], so runners that were rate limited together
+// do not all retry at the same moment.
+func (c retryConfig) backoff(attempt int) time.Duration {
+	return time.Duration(jitterRand(int64(c.backoffCap(attempt)) + 1))
+}
+
+// retryBudgetLeft returns how much of RetryBudget is unspent, and false
+// when there is no budget.
+func (c retryConfig) retryBudgetLeft() (time.Duration, bool) {
+	if c.RetryBudget <= 0 {
+		return 0, false
+	}
+	return max(c.RetryBudget-time.Duration(retrySpent.Load()), 0), true
+}
+
+// rateLimitResetHeaders pair each provider header that says when a rate
+// limit resets with the header counting what is left of that limit. A
+// reset only applies when its limit is used up, or when the provider does
+// not say how much is left. OpenAI and Azure send durations such as 6m0s;
+// Anthropic sends RFC 3339 timestamps.
+var rateLimitResetHeaders = []struct{ reset, remaining string }{
+	{"x-ratelimit-reset-requests", "x-ratelimit-remaining-requests"},
+	{"x-ratelimit-reset-tokens", "x-ratelimit-remaining-tokens"},
+	{"anthropic-ratelimit-requests-reset", "anthropic-ratelimit-requests-remaining"},
+	{"anthropic-ratelimit-tokens-reset", "anthropic-ratelimit-tokens-remaining"},
+	{"anthropic-ratelimit-input-tokens-reset", "anthropic-ratelimit-input-tokens-remaining"},
+	{"anthropic-ratelimit-output-tokens-reset", "anthropic-ratelimit-output-tokens-remaining"},
+}
+
+// retryAfter returns the longest wait a provider asked for, and the header
+// that asked for it, or zero. It reads retry-after-ms, Retry-After as
+// seconds (what Anthropic sends) or an HTTP-date (OpenAI and Azure, at
+// times), and the rate limit reset headers of exhausted limits. Times are
+// relative to now.
+func retryAfter(h http.Header, now time.Time) (time.Duration, string) {
+	var wait time.Duration
+	var source string
+	consider := func(d time.Duration, header string) {
+		if d > wait {
+			wait, source = d, header
+		}
+	}
+	if ms, err := strconv.ParseFloat(h.Get("retry-after-ms"), 64); err == nil && ms > 0 {
+		consider(time.Duration(ms*float64(time.Millisecond)), "retry-after-ms")
+	}
+	if v := strings.TrimSpace(h.Get("Retry-After")); v != "" {
+		if s, err := strconv.ParseFloat(v, 64); err == nil {
+			if s > 0 {
+				consider(time.Duration(s*float64(time.Second)), "retry-after")
+			}
+		} else if t, err := http.ParseTime(v); err == nil {
+			consider(t.Sub(now), "retry-after")
+		}
+	}
+	for _, rl := range rateLimitResetHeaders {
+		v := strings.TrimSpace(h.Get(rl.reset))
+		if v == "" {
+			continue
+		}
+		if left := h.Get(rl.remaining); left != "" && left != "0" {
+			continue
+		}
+		if d, err := time.ParseDuration(v); err == nil {
+			consider(d, rl.reset)
+		} else if t, err := time.Parse(time.RFC3339, v); err == nil {
+			consider(t.Sub(now), rl.reset)
+		}
+	}
+	return wait, source
+}
+
+// retryTransport retries provider requests that failed with a transport
+// error or a transient status (see classifyStatus) according to retryCfg.
+// The SDKs' own retries are disabled so this is the only retry loop. It
+// never sleeps past the request context's deadline (TOTAL_DEADLINE) or
+// beyond RETRY_BUDGET: when the next wait would not fit, the last response
+// is returned instead. Every attempt first waits for REQUESTS_PER_MINUTE.
+type retryTransport struct {
+	base http.RoundTripper
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	cfg := retryCfg
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	ctx := req.Context()
+	// Failed attempts are not billed, so one check covers the retries.
+	if err := costs.checkPrompt(estimateTokens(string(body))); err != nil {
+		return nil, err
+	}
+
+	for attempt := 0; ; attempt++ {
+		attemptCtx, cancel := ctx, context.CancelFunc(func() {})
+		if cfg.RequestTimeout > 0 {
+			attemptCtx, cancel = context.WithTimeout(ctx, cfg.RequestTimeout)
+		}
+		spanCtx, span := startAttemptSpan(attemptCtx, req, attempt)
+		r := req.Clone(spanCtx)
+		if body != nil {
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			r.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(body)), nil }
+		}
+
+		if err := waitForRequestSlot(ctx); err != nil {
+			endAttemptSpan(span, nil, err)
+			cancel()
+			return nil, err
+		}
+		providerAttempts.Add(1)
+		attemptStart := time.Now()
+		resp, err := t.base.RoundTrip(r)
+		endAttemptSpan(span, resp, err)
+		reason := ""
+		switch {
+		case err != nil && ctx.Err() == nil:
+			reason = err.Error()
+		case err == nil:
+			if _, retryable := classifyStatus(resp.StatusCode); retryable && resp.StatusCode >= 400 {
+				reason = resp.Status
+			}
+		}
+		if reason == "" || attempt >= cfg.MaxRetries {
+			if err != nil {
+				cancel()
+				return nil, err
+			}
+			// The attempt's timeout must outlive this call: it also
+			// bounds reading the body.
+			resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+			return resp, nil
+		}
+
+		wait := cfg.backoff(attempt)
+		if err == nil {
+			if ra, header := retryAfter(resp.Header, time.Now()); ra > 0 {
+				wait = min(ra, cfg.BackoffMax)
+				capped := ""
+				if ra > wait {
+					capped = fmt.Sprintf(", capped at BACKOFF_MAX %s", cfg.BackoffMax)
+				}
+				log.Printf("wait of %s set by the %s header%s", ra.Round(time.Millisecond), header, capped)
+			}
+		}
+		retrySpent.Add(int64(time.Since(attemptStart)))
+		budgetLeft, hasBudget := cfg.retryBudgetLeft()
+		giveUp := ""
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			giveUp = fmt.Sprintf("total deadline leaves %s", time.Until(deadline).Round(time.Millisecond))
+		} else if hasBudget && budgetLeft < wait {
+			giveUp = fmt.Sprintf("RETRY_BUDGET %s leaves %s", cfg.RetryBudget, budgetLeft.Round(time.Millisecond))
+		}
+		if giveUp != "" {
+			log.Printf("not retrying %s: %s, next wait is %s", reason, giveUp, wait.Round(time.Millisecond))
+			if err != nil {
+				cancel()
+				return nil, err
+			}
+			resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+			return resp, nil
+		}
+		transcript.retry(attempt+1, resp, reason, wait)
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+			resp.Body.Close()
+		}
+		cancel()
+		budget := "no retry budget"
+		if hasBudget {
+			budget = fmt.Sprintf("retry budget %s left", (budgetLeft - wait).Round(time.Millisecond))
+		}
+		log.Printf("request failed (%s), retrying in %s (retry %d/%d, %s)", reason, wait.Round(time.Millisecond), attempt+1, cfg.MaxRetries, budget)
+
+		sleepStart := time.Now()
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+		retrySpent.Add(int64(time.Since(sleepStart)))
+	}
+}
+
+// cancelOnClose releases an attempt's context once its body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+)
+
+// taskCall holds the run-wide settings of every provider call: a single
+// run makes one taskCall.run, a batch one per task.
+type taskCall struct {
+	provider string
+	apiKey   string
+	// keyFile is set when apiKey came from a file; its current key is
+	// used, and it is re-read once after an authentication failure.
+	keyFile      *apiKeyFile
+	baseURL      string
+	model        string
+	tools        []ToolDef
+	recoveryMode string
+	// summarizer, with PRE_SUMMARIZE=true, condenses oversized tasks.
+	summarizer *preSummarizer
+}
+
+// taskOutcome is what taskCall.run produced for one task.
+type taskOutcome struct {
+	llm        llmResult
+	err        error
+	structured any
+	// history is the history as last sent, after any context recovery or
+	// structured-output correction.
+	history    []historyMessage
+	recovery   *contextRecovery
+	preSummary *preSummary
+}
+
+// run sends task after msgs, the prior conversation. With a summarizer, a
+// task over its threshold is replaced by a summary first. A context-length
+// error gets one retry with a shorter prompt, and a response that fails
+// structured-output validation one guided retry. msgs itself is never
+// modified, so concurrent tasks can share it.
+func (c taskCall) run(ctx context.Context, systemPrompt, task string, msgs []historyMessage, images []imageAttachment) taskOutcome {
+	out := taskOutcome{history: msgs}
+	call := func(task string, images []imageAttachment) (llmResult, error) {
+		return c.call(withHistory(ctx, out.history), systemPrompt, task, images, c.tools)
+	}
+
+	// An oversized task is first condensed by a summarization call, whose
+	// usage is folded into the task's.
+	var summary llmResult
+	if c.summarizer != nil {
+		var err error
+		if task, summary, out.preSummary, err = c.summarizer.summarize(ctx, c, task); err != nil {
+			out.llm, out.err = summary, err
+			return out
+		}
+	}
+	out.llm, out.err = call(task, images)
+
+	// A prompt over the context window gets one retry with the oldest
+	// history dropped and, with CONTEXT_RECOVERY=truncate, a shorter task.
+	// A failure after tool calls is not retried, so no tool runs twice.
+	if out.err != nil && out.llm.ToolCalls == 0 && isContextLengthError(out.err) && !terminated.Load() {
+		if h, t, rec := recoverContext(c.recoveryMode, out.err, out.history, task); rec != nil {
+			log.Printf("retrying once after a context-length error: dropped %d history message(s), truncated %d task chars",
+				rec.HistoryDropped, rec.TaskCharsTruncated)
+			out.history, task, out.recovery = h, t, rec
+			out.llm, out.err = call(task, images)
+		}
+	}
+
+	// Structured output gets one guided retry: the rejected response and
+	// the validation errors are sent back as conversation history.
+	if out.err == nil && respFormat.JSON && !terminated.Load() {
+		var perr error
+		if out.structured, perr = respFormat.parse(stripMemoryMarkers(out.llm.Text)); perr != nil {
+			log.Printf("retrying once: %v", perr)
+			n := len(out.history)
+			out.history = append(out.history[:n:n],
+				historyMessage{Role: "user", Content: task},
+				historyMessage{Role: "assistant", Content: out.llm.Text})
+			retry, err := call(respFormat.correction(perr), nil)
+			out.llm.add(retry)
+			out.err = err
+			if err == nil {
+				out.structured, out.err = respFormat.parse(stripMemoryMarkers(out.llm.Text))
+			}
+		}
+	}
+	if out.preSummary != nil {
+		summary.add(out.llm)
+		out.llm = summary
+	}
+	return out
+}
+
+// call sends one provider request with the history set on ctx. With a key
+// file, a 401 before any tool ran is retried once if re-reading the file
+// gives a new key, e.g. after the Secret was rotated.
+func (c taskCall) call(ctx context.Context, systemPrompt, task string, images []imageAttachment, tools []ToolDef) (llmResult, error) {
+	if c.keyFile == nil {
+		return c.send(ctx, c.apiKey, systemPrompt, task, images, tools)
+	}
+	res, err := c.send(ctx, c.keyFile.current(), systemPrompt, task, images, tools)
+	var apiErr *apiError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == 401 && res.ToolCalls == 0 && !terminated.Load() {
+		if key, changed := c.keyFile.refresh(); changed {
+			log.Printf("authentication failed; retrying once with the key re-read from %s", c.keyFile.source())
+			res, err = c.send(ctx, key, systemPrompt, task, images, tools)
+		}
+	}
+	return res, err
+}
+
+// send sends one provider request with apiKey.
+func (c taskCall) send(ctx context.Context, apiKey, systemPrompt, task string, images []imageAttachment, tools []ToolDef) (llmResult, error) {
+	switch c.provider {
+	case "anthropic":
+		return callAnthropic(ctx, apiKey, c.baseURL, c.model, systemPrompt, task, images, tools)
+	case "bedrock":
+		return callBedrock(ctx, c.baseURL, c.model, systemPrompt, task, tools)
+	default:
+		// OpenAI, Azure OpenAI, Ollama, and any OpenAI-compatible provider
+		return callOpenAI(ctx, c.provider, apiKey, c.baseURL, c.model, systemPrompt, task, images, tools)
+	}
+}
+
+// result turns the outcome into the fields of agentResult that describe the
+// provider call: status, response or error, stop reason, tokens and tool
+// calls.
+func (out taskOutcome) result(provider, model string) agentResult {
+	llm := out.llm
+	res := agentResult{Provider: provider, Model: model}
+	res.Metrics.ToolCalls = llm.ToolCalls
+	res.ToolCalls = llm.ToolInvocations
+	res.HistoryMessages = len(out.history)
+	res.ContextRecovery = out.recovery
+	res.Metrics.PreSummary = out.preSummary
+	res.Metrics.Iterations = llm.Iterations
+
+	if err := out.err; err != nil {
+		var apiErr *apiError
+		if errors.As(err, &apiErr) {
+			res.ErrorType = apiErr.Class
+			if apiErr.StatusCode == 404 {
+				apiErr.Hint = modelHint(provider, model)
+			}
+		}
+		res.Status = "error"
+		res.Error = err.Error()
+		res.ErrorCode = errorCode(err)
+		if errors.Is(err, errStreamInterrupted) || errors.Is(err, errSchemaValidation) {
+			// Keep the partial or rejected response for debugging.
+			res.Partial = errors.Is(err, errStreamInterrupted)
+			res.Response = llm.Text
+			res.Metrics.InputTokens = llm.InputTokens
+			res.Metrics.OutputTokens = llm.OutputTokens
+		}
+		return res
+	}
+	res.Status = "success"
+	res.Response = llm.Text
+	res.IterationLimitReached = llm.IterationLimitReached
+	res.setStopReason(llm.StopReason)
+	res.Metrics.InputTokens = llm.InputTokens
+	res.Metrics.OutputTokens = llm.OutputTokens
+	res.Metrics.CacheReadTokens = llm.CacheReadTokens
+	res.Metrics.CacheCreationTokens = llm.CacheCreationTokens
+	res.Metrics.ReasoningTokens = llm.ReasoningTokens
+	res.Metrics.TokensEstimated = llm.TokensEstimated
+	res.Metrics.SystemFingerprint = llm.SystemFingerprint
+	return res
+}
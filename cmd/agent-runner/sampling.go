@@ -0,0 +1,285 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/openai/openai-go/v3"
+)
+
+// defaultAnthropicMaxTokens is sent when MAX_TOKENS is unset; the Messages
+// API requires max_tokens.
+const defaultAnthropicMaxTokens = 8192
+
+// samplingParams are the optional generation settings from the environment.
+// Unset fields are left out of the request entirely, so providers that reject
+// unknown or unsupported fields keep working.
+type samplingParams struct {
+	Temperature      *float64 `json:"temperature,omitempty"`
+	TopP             *float64 `json:"topP,omitempty"`
+	MaxTokens        *int64   `json:"maxTokens,omitempty"`
+	Stop             []string `json:"stop,omitempty"`
+	PresencePenalty  *float64 `json:"presencePenalty,omitempty"`
+	FrequencyPenalty *float64 `json:"frequencyPenalty,omitempty"`
+	Seed             *int64   `json:"seed,omitempty"`
+}
+
+// sampling holds the parameters for this run; main sets it from the
+// environment.
+var sampling samplingParams
+
+// isZero reports whether no parameter is set.
+func (p samplingParams) isZero() bool {
+	return p.Temperature == nil && p.TopP == nil && p.MaxTokens == nil && len(p.Stop) == 0 &&
+		p.PresencePenalty == nil && p.FrequencyPenalty == nil && p.Seed == nil
+}
+
+func (p samplingParams) String() string {
+	if p.isZero() {
+		return "provider defaults"
+	}
+	var parts []string
+	addFloat := func(name string, v *float64) {
+		if v != nil {
+			parts = append(parts, fmt.Sprintf("%s=%g", name, *v))
+		}
+	}
+	addFloat("temperature", p.Temperature)
+	addFloat("top_p", p.TopP)
+	if p.MaxTokens != nil {
+		parts = append(parts, fmt.Sprintf("max_tokens=%d", *p.MaxTokens))
+	}
+	if len(p.Stop) > 0 {
+		parts = append(parts, fmt.Sprintf("stop=%q", p.Stop))
+	}
+	addFloat("presence_penalty", p.PresencePenalty)
+	addFloat("frequency_penalty", p.FrequencyPenalty)
+	if p.Seed != nil {
+		parts = append(parts, fmt.Sprintf("seed=%d", *p.Seed))
+	}
+	return strings.Join(parts, " ")
+}
+
+// loadSamplingParams reads TEMPERATURE, TOP_P, MAX_TOKENS, STOP_SEQUENCES
+// (or STOP), PRESENCE_PENALTY, FREQUENCY_PENALTY and SEED, checking each
+// against the range the provider accepts.
+func loadSamplingParams(provider string) (samplingParams, error) {
+	var p samplingParams
+	var err error
+	maxTemperature := 2.0
+	if provider == "anthropic" {
+		maxTemperature = 1.0
+	}
+	if p.Temperature, err = envFloat("TEMPERATURE", 0, maxTemperature); err != nil {
+		return p, err
+	}
+	if p.TopP, err = envFloat("TOP_P", 0, 1); err != nil {
+		return p, err
+	}
+	if p.PresencePenalty, err = envFloat("PRESENCE_PENALTY", -2, 2); err != nil {
+		return p, err
+	}
+	if p.FrequencyPenalty, err = envFloat("FREQUENCY_PENALTY", -2, 2); err != nil {
+		return p, err
+	}
+	if v := getEnv("MAX_TOKENS", ""); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n <= 0 {
+			return p, fmt.Errorf("invalid MAX_TOKENS %q: must be a positive integer", v)
+		}
+		p.MaxTokens = &n
+	}
+	if v := getEnv("SEED", ""); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return p, fmt.Errorf("invalid SEED %q: must be an integer", v)
+		}
+		p.Seed = &n
+	}
+	key, stop, err := stopSequences()
+	if err != nil {
+		return p, err
+	}
+	if limit := maxStopSequences(provider); limit > 0 && len(stop) > limit {
+		return p, fmt.Errorf("invalid %s: provider %s allows at most %d stop sequences, got %d", key, provider, limit, len(stop))
+	}
+	p.Stop = stop
+	return p, nil
+}
+
+// stopSequences reads STOP_SEQUENCES, falling back to STOP, and returns
+// the variable it used. The value is a JSON array of strings, which keeps
+// sequences with commas or surrounding whitespace such as "\n\n", or else
+// a comma-separated list.
+func stopSequences() (key string, stop []string, err error) {
+	key = "STOP_SEQUENCES"
+	v := getEnv(key, "")
+	if v == "" {
+		key, v = "STOP", getEnv("STOP", "")
+	}
+	if strings.HasPrefix(strings.TrimSpace(v), "[") {
+		if err := json.Unmarshal([]byte(v), &stop); err != nil {
+			return key, nil, fmt.Errorf("invalid %s: must be a JSON array of strings: %w", key, err)
+		}
+		for i, s := range stop {
+			if s == "" {
+				return key, nil, fmt.Errorf("invalid %s: stop sequence %d is empty", key, i)
+			}
+		}
+		return key, stop, nil
+	}
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			stop = append(stop, s)
+		}
+	}
+	return key, stop, nil
+}
+
+// maxStopSequences returns how many stop sequences provider accepts, or 0
+// when it sets no limit. OpenAI-compatible APIs and Bedrock's Converse API
+// take at most four.
+func maxStopSequences(provider string) int {
+	if provider == "anthropic" {
+		return 0
+	}
+	return 4
+}
+
+// envFloat parses the float env var key, which must lie in [lo, hi]. It
+// returns nil when the variable is unset.
+func envFloat(key string, lo, hi float64) (*float64, error) {
+	v := getEnv(key, "")
+	if v == "" {
+		return nil, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || f < lo || f > hi {
+		return nil, fmt.Errorf("invalid %s %q: must be a number between %g and %g", key, v, lo, hi)
+	}
+	return &f, nil
+}
+
+// applyAnthropic sets the parameters the Messages API supports on params.
+// It has no penalties or seed; those are logged and left out.
+func (p samplingParams) applyAnthropic(params *anthropic.MessageNewParams) {
+	params.MaxTokens = defaultAnthropicMaxTokens
+	if p.MaxTokens != nil {
+		params.MaxTokens = *p.MaxTokens
+	}
+	if p.Temperature != nil {
+		params.Temperature = anthropic.Float(*p.Temperature)
+	}
+	if p.TopP != nil {
+		params.TopP = anthropic.Float(*p.TopP)
+	}
+	if len(p.Stop) > 0 {
+		params.StopSequences = p.Stop
+	}
+}
+
+// lacksPenaltiesAndSeed reports whether provider's API has no presence or
+// frequency penalty and no seed.
+func lacksPenaltiesAndSeed(provider string) bool {
+	return provider == "anthropic" || provider == "bedrock"
+}
+
+// unsupportedByAnthropic lists the set parameters the Messages API (and
+// Bedrock's Converse API) lacks.
+func (p samplingParams) unsupportedByAnthropic() []string {
+	var names []string
+	if p.PresencePenalty != nil {
+		names = append(names, "PRESENCE_PENALTY")
+	}
+	if p.FrequencyPenalty != nil {
+		names = append(names, "FREQUENCY_PENALTY")
+	}
+	if p.Seed != nil {
+		names = append(names, "SEED")
+	}
+	return names
+}
+
+// unsupportedByReasoning lists the set parameters OpenAI reasoning models
+// reject.
+func (p samplingParams) unsupportedByReasoning() []string {
+	var names []string
+	if p.Temperature != nil {
+		names = append(names, "TEMPERATURE")
+	}
+	if p.TopP != nil {
+		names = append(names, "TOP_P")
+	}
+	if len(p.Stop) > 0 {
+		names = append(names, "STOP_SEQUENCES")
+	}
+	if p.PresencePenalty != nil {
+		names = append(names, "PRESENCE_PENALTY")
+	}
+	if p.FrequencyPenalty != nil {
+		names = append(names, "FREQUENCY_PENALTY")
+	}
+	return names
+}
+
+// applyOpenAI sets the parameters on a chat completion request. For
+// reasoning models those they reject are left out and MAX_TOKENS is sent as
+// max_completion_tokens.
+func (p samplingParams) applyOpenAI(params *openai.ChatCompletionNewParams) {
+	if reasoningModel {
+		p = p.sent("openai")
+		if p.MaxTokens != nil {
+			params.MaxCompletionTokens = openai.Int(*p.MaxTokens)
+			p.MaxTokens = nil
+		}
+	}
+	if p.Temperature != nil {
+		params.Temperature = openai.Float(*p.Temperature)
+	}
+	if p.TopP != nil {
+		params.TopP = openai.Float(*p.TopP)
+	}
+	if p.MaxTokens != nil {
+		params.MaxTokens = openai.Int(*p.MaxTokens)
+	}
+	if len(p.Stop) > 0 {
+		params.Stop = openai.ChatCompletionNewParamsStopUnion{OfStringArray: p.Stop}
+	}
+	if p.PresencePenalty != nil {
+		params.PresencePenalty = openai.Float(*p.PresencePenalty)
+	}
+	if p.FrequencyPenalty != nil {
+		params.FrequencyPenalty = openai.Float(*p.FrequencyPenalty)
+	}
+	if p.Seed != nil {
+		params.Seed = openai.Int(*p.Seed)
+	}
+}
+
+// sent returns the parameters actually included in requests to provider.
+func (p samplingParams) sent(provider string) samplingParams {
+	if lacksPenaltiesAndSeed(provider) {
+		p.PresencePenalty, p.FrequencyPenalty, p.Seed = nil, nil, nil
+	} else if reasoningModel {
+		p.Temperature, p.TopP, p.Stop, p.PresencePenalty, p.FrequencyPenalty = nil, nil, nil, nil, nil
+	}
+	return p
+}
+
+// logSampling logs the effective sampling settings once at startup.
+func logSampling(provider string, p samplingParams) {
+	log.Printf("sampling: %s", p)
+	if lacksPenaltiesAndSeed(provider) {
+		if names := p.unsupportedByAnthropic(); len(names) > 0 {
+			log.Printf("warning: %s not supported by provider %s; ignored", strings.Join(names, ", "), provider)
+		}
+	} else if reasoningModel {
+		if names := p.unsupportedByReasoning(); len(names) > 0 {
+			log.Printf("note: %s not supported by reasoning models; ignored", strings.Join(names, ", "))
+		}
+	}
+}
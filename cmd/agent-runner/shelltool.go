@@ -0,0 +1,377 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	defaultToolPolicyFile = "/policy/tools.yaml"
+	// defaultShellTimeout and defaultShellMaxOutput apply when the policy
+	// does not set timeout or maxOutputBytes.
+	defaultShellTimeout   = 30 * time.Second
+	defaultShellMaxOutput = 16 << 10
+)
+
+// shellPolicy is the shell section of the mounted tool policy:
+//
+//	shell:
+//	  allow:
+//	    - kubectl get
+//	    - curl
+//	  timeout: 30s
+//	  maxOutputBytes: 16384
+//	  allowShellSyntax: false
+//
+// An allow entry matches a command whose leading words are the entry's
+// words, so "kubectl get" allows `kubectl get pods` but not `kubectl
+// delete pods`. Without allowShellSyntax commands are run directly, never
+// through a shell, and shell syntax is refused.
+type shellPolicy struct {
+	Allow          []string `json:"allow"`
+	Timeout        string   `json:"timeout,omitempty"`
+	MaxOutputBytes int      `json:"maxOutputBytes,omitempty"`
+	// AllowShellSyntax runs commands with sh -c so pipes and command lists
+	// work. Every command in a list or pipeline must still be allowed;
+	// redirects, parameter expansion and command substitution are always
+	// refused, since they reach files and values the allowlist never sees.
+	AllowShellSyntax bool `json:"allowShellSyntax,omitempty"`
+
+	timeout time.Duration
+	allow   [][]string
+}
+
+// shellWorkDir is where shell commands run.
+var shellWorkDir = "/workspace"
+
+// shellPol is the loaded policy when TOOLS_SHELL=true, otherwise nil.
+var shellPol *shellPolicy
+
+// loadShellPolicy reads the shell section of the tool policy at path.
+func loadShellPolicy(path string) (*shellPolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("TOOLS_SHELL=true requires a tool policy: %w", err)
+	}
+	var doc struct {
+		Shell *shellPolicy `json:"shell"`
+	}
+	if err := yaml.UnmarshalStrict(data, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	p := doc.Shell
+	if p == nil || len(p.Allow) == 0 {
+		return nil, fmt.Errorf("%s: shell.allow lists no commands", path)
+	}
+	for i, entry := range p.Allow {
+		words := strings.Fields(entry)
+		if len(words) == 0 {
+			return nil, fmt.Errorf("%s: shell.allow[%d] is empty", path, i)
+		}
+		p.allow = append(p.allow, words)
+	}
+	p.timeout = defaultShellTimeout
+	if p.Timeout != "" {
+		if p.timeout, err = time.ParseDuration(p.Timeout); err != nil || p.timeout <= 0 {
+			return nil, fmt.Errorf("%s: invalid shell.timeout %q", path, p.Timeout)
+		}
+	}
+	if p.MaxOutputBytes < 0 {
+		return nil, fmt.Errorf("%s: shell.maxOutputBytes must not be negative", path)
+	}
+	if p.MaxOutputBytes == 0 {
+		p.MaxOutputBytes = defaultShellMaxOutput
+	}
+	return p, nil
+}
+
+// toolDef describes the shell tool, listing the allowed commands so the
+// model does not have to guess.
+func (p *shellPolicy) toolDef() ToolDef {
+	return ToolDef{
+		Name: ToolShell,
+		Description: "Run a command in the agent container, in " + shellWorkDir + ". Only these commands (and their " +
+			"arguments) are allowed: " + strings.Join(p.Allow, ", ") + ". " +
+			"Returns JSON with exitCode, stdout and stderr; a command that is not allowed is refused without running.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"command": map[string]any{
+					"type":        "string",
+					"description": "The command line to run, e.g. 'kubectl get pods -n default'.",
+				},
+			},
+			"required": []string{"command"},
+		},
+	}
+}
+
+// shellResult is the shell tool's output as returned to the model.
+type shellResult struct {
+	Refused   bool     `json:"refused,omitempty"`
+	Reason    string   `json:"reason,omitempty"`
+	Allowed   []string `json:"allowedCommands,omitempty"`
+	ExitCode  int      `json:"exitCode"`
+	Stdout    string   `json:"stdout"`
+	Stderr    string   `json:"stderr"`
+	Truncated bool     `json:"truncated,omitempty"`
+	TimedOut  bool     `json:"timedOut,omitempty"`
+}
+
+func (r shellResult) String() string {
+	b, _ := json.Marshal(r)
+	return string(b)
+}
+
+// shellTool runs args["command"] if the policy allows it. Refusals and
+// failures are reported to the model; they never end the run.
+func shellTool(args map[string]any) string {
+	command, _ := args["command"].(string)
+	if strings.TrimSpace(command) == "" {
+		return "Error: 'command' is required"
+	}
+	if shellPol == nil {
+		return "Error: the shell tool is not enabled"
+	}
+	argv, err := shellPol.check(command)
+	if err != nil {
+		return shellResult{Refused: true, Reason: err.Error(), Allowed: shellPol.Allow}.String()
+	}
+	return shellPol.run(argv).String()
+}
+
+// shellSubstitution matches command substitution, which would run commands
+// the allowlist never sees.
+var shellSubstitution = regexp.MustCompile("`|\\$\\(|<\\(|>\\(")
+
+// shellSeparators split a command line into the commands of its lists and
+// pipelines.
+var shellSeparators = regexp.MustCompile(`\|\||&&|[|;&\n]`)
+
+// check returns the argv to execute for command, or why it is refused.
+func (p *shellPolicy) check(command string) ([]string, error) {
+	if !p.AllowShellSyntax {
+		argv, err := splitCommandLine(command)
+		if err != nil {
+			return nil, err
+		}
+		if !p.allowed(argv) {
+			return nil, fmt.Errorf("%q is not in the allowlist", strings.Join(argv, " "))
+		}
+		return argv, nil
+	}
+	if shellSubstitution.MatchString(command) {
+		return nil, fmt.Errorf("command substitution is not allowed")
+	}
+	if err := checkShellExpansion(command); err != nil {
+		return nil, err
+	}
+	for _, part := range shellSeparators.Split(command, -1) {
+		words := strings.Fields(part)
+		if len(words) == 0 {
+			continue
+		}
+		if !p.allowed(words) {
+			return nil, fmt.Errorf("%q is not in the allowlist", strings.TrimSpace(part))
+		}
+	}
+	return []string{"sh", "-c", command}, nil
+}
+
+// checkShellExpansion refuses the redirects (<, >) and parameter expansion
+// ($VAR, ${VAR}) sh would act on in command: outside quotes, and for $ also
+// inside double quotes. Single-quoted and backslash-escaped characters are
+// literal to sh and allowed.
+func checkShellExpansion(command string) error {
+	var quote rune
+	escaped := false
+	for _, r := range command {
+		switch {
+		case escaped:
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			}
+		case r == '\\':
+			escaped = true
+		case quote == '"':
+			switch r {
+			case '"':
+				quote = 0
+			case '$':
+				return fmt.Errorf("shell syntax %q is not allowed", r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '<' || r == '>' || r == '$':
+			return fmt.Errorf("shell syntax %q is not allowed", r)
+		}
+	}
+	return nil
+}
+
+// allowed reports whether argv starts with the words of an allow entry.
+func (p *shellPolicy) allowed(argv []string) bool {
+	for _, entry := range p.allow {
+		if len(argv) < len(entry) {
+			continue
+		}
+		match := true
+		for i, w := range entry {
+			if argv[i] != w {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+// splitCommandLine splits a command line into words the way a POSIX shell
+// would for a simple command: single and double quotes group words and a
+// backslash escapes the next character. Anything a shell would interpret
+// (pipes, redirects, variables, globs, ...) outside quotes is an error.
+func splitCommandLine(s string) ([]string, error) {
+	var (
+		words   []string
+		cur     strings.Builder
+		inWord  bool
+		quote   rune
+		escaped bool
+	)
+	for _, r := range s {
+		switch {
+		case escaped:
+			cur.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				cur.WriteRune(r)
+			}
+		case quote == '"':
+			switch r {
+			case '"':
+				quote = 0
+			case '\\':
+				escaped = true
+			case '$', '`':
+				return nil, fmt.Errorf("shell syntax %q is not allowed", r)
+			default:
+				cur.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inWord = r, true
+		case r == '\\':
+			escaped, inWord = true, true
+		case r == ' ' || r == '\t':
+			if inWord {
+				words = append(words, cur.String())
+				cur.Reset()
+				inWord = false
+			}
+		case strings.ContainsRune("|&;<>()$`*?[]{}~#!\n", r):
+			return nil, fmt.Errorf("shell syntax %q is not allowed", r)
+		default:
+			cur.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, fmt.Errorf("unterminated quote or escape")
+	}
+	if inWord {
+		words = append(words, cur.String())
+	}
+	if len(words) == 0 {
+		return nil, fmt.Errorf("empty command")
+	}
+	return words, nil
+}
+
+// run executes argv in shellWorkDir with the policy's timeout, without the
+// runner's credentials in its environment.
+func (p *shellPolicy) run(argv []string) shellResult {
+	ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	cmd.Dir = shellWorkDir
+	cmd.Env = shellEnv()
+	stdout := &cappedBuffer{max: p.MaxOutputBytes}
+	stderr := &cappedBuffer{max: p.MaxOutputBytes}
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+	cmd.WaitDelay = time.Second
+
+	err := cmd.Run()
+	res := shellResult{
+		Stdout:    stdout.String(),
+		Stderr:    stderr.String(),
+		Truncated: stdout.truncated || stderr.truncated,
+		TimedOut:  errors.Is(ctx.Err(), context.DeadlineExceeded),
+	}
+	var exitErr *exec.ExitError
+	switch {
+	case errors.As(err, &exitErr):
+		res.ExitCode = exitErr.ExitCode()
+	case err != nil:
+		res.ExitCode = -1
+		res.Stderr += err.Error()
+	}
+	return res
+}
+
+// shellEnv is the runner's environment without the variables holding
+// credentials, so a command cannot print or send them.
+func shellEnv() []string {
+	var env []string
+	for _, kv := range os.Environ() {
+		name, _, _ := strings.Cut(kv, "=")
+		secret := false
+		for _, s := range secretEnvVars {
+			secret = secret || name == s
+		}
+		if !secret {
+			env = append(env, kv)
+		}
+	}
+	return env
+}
+
+// cappedBuffer keeps the first max bytes written to it and discards the
+// rest, so a chatty command cannot exhaust memory or the model's context.
+type cappedBuffer struct {
+	buf       bytes.Buffer
+	max       int
+	truncated bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if room := b.max - b.buf.Len(); room < len(p) {
+		b.truncated = true
+		b.buf.Write(p[:max(room, 0)])
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+func (b *cappedBuffer) String() string {
+	if b.truncated {
+		return b.buf.String() + "\n... (output truncated)"
+	}
+	return b.buf.String()
+}
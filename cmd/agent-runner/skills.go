@@ -30,6 +30,10 @@ func loadSkills(skillsDir string) string {
 		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
 			continue
 		}
+		// *.json files are tool definitions, loaded by loadSkillTools.
+		if strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
 		path := filepath.Join(skillsDir, entry.Name())
 		data, err := os.ReadFile(path)
 		if err != nil {
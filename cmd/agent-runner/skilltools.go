@@ -0,0 +1,308 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const (
+	// defaultSkillToolTimeout applies when a tool definition sets none.
+	defaultSkillToolTimeout = 30 * time.Second
+	// maxSkillToolTimeout caps per-tool timeouts, matching execute_command.
+	maxSkillToolTimeout = 120 * time.Second
+)
+
+// skillTool is a tool contributed by a SkillPack as /skills/<name>.json.
+// Exactly one of Exec and HTTP says how it runs.
+//
+//	{
+//	  "name": "get_pod_logs",
+//	  "description": "Fetch recent logs of a pod",
+//	  "parameters": {"type": "object", "properties": {...}, "required": [...]},
+//	  "exec": {"command": "kubectl logs -n {{.namespace}} {{.pod}} --tail=100"},
+//	  "timeout": "20s"
+//	}
+//
+// Template values are escaped for where they are used: shell-quoted in
+// exec commands, query-escaped in URLs and JSON-encoded in HTTP bodies.
+type skillTool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Parameters  map[string]any `json:"parameters"`
+	Exec        *skillToolExec `json:"exec,omitempty"`
+	HTTP        *skillToolHTTP `json:"http,omitempty"`
+	Timeout     string         `json:"timeout,omitempty"`
+
+	timeout time.Duration
+}
+
+// skillToolExec runs a command in the skill sidecar, like execute_command.
+type skillToolExec struct {
+	Command string `json:"command"`
+}
+
+// skillToolHTTP makes an HTTP request from the agent container.
+type skillToolHTTP struct {
+	Method  string            `json:"method,omitempty"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+}
+
+// skillTools are the tools loaded from the skills directory, by name.
+var skillTools = map[string]*skillTool{}
+
+// toolAllowlist limits which tools the model may call (TOOLS_ALLOWLIST);
+// nil allows every tool.
+var toolAllowlist map[string]bool
+
+// loadSkillTools reads the *.json tool definitions in dir. Invalid
+// definitions are skipped with a log line so one bad SkillPack cannot stop
+// the run.
+func loadSkillTools(dir string) map[string]*skillTool {
+	tools := map[string]*skillTool{}
+	paths, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	sort.Strings(paths)
+	for _, path := range paths {
+		t, err := parseSkillTool(path)
+		if err != nil {
+			log.Printf("skipping skill tool %s: %v", path, err)
+			continue
+		}
+		if isBuiltinTool(t.Name) || tools[t.Name] != nil {
+			log.Printf("skipping skill tool %s: duplicate tool name %q", path, t.Name)
+			continue
+		}
+		tools[t.Name] = t
+	}
+	if len(tools) > 0 {
+		log.Printf("loaded %d skill tool(s) from %s", len(tools), dir)
+	}
+	return tools
+}
+
+func parseSkillTool(path string) (*skillTool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var t skillTool
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&t); err != nil {
+		return nil, err
+	}
+	switch {
+	case t.Name == "":
+		return nil, fmt.Errorf("name is required")
+	case t.Description == "":
+		return nil, fmt.Errorf("description is required")
+	case (t.Exec == nil) == (t.HTTP == nil):
+		return nil, fmt.Errorf("exactly one of exec or http is required")
+	case t.Exec != nil && t.Exec.Command == "":
+		return nil, fmt.Errorf("exec.command is required")
+	case t.HTTP != nil && t.HTTP.URL == "":
+		return nil, fmt.Errorf("http.url is required")
+	}
+	if t.Parameters == nil {
+		t.Parameters = map[string]any{"type": "object", "properties": map[string]any{}}
+	}
+	t.timeout = defaultSkillToolTimeout
+	if t.Timeout != "" {
+		if t.timeout, err = time.ParseDuration(t.Timeout); err != nil || t.timeout <= 0 {
+			return nil, fmt.Errorf("invalid timeout %q", t.Timeout)
+		}
+	}
+	t.timeout = min(t.timeout, maxSkillToolTimeout)
+	return &t, nil
+}
+
+// skillToolDefs returns the definitions of the loaded skill tools, sorted
+// by name so the request is stable across runs.
+func skillToolDefs() []ToolDef {
+	names := make([]string, 0, len(skillTools))
+	for name := range skillTools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	defs := make([]ToolDef, 0, len(names))
+	for _, name := range names {
+		t := skillTools[name]
+		defs = append(defs, ToolDef{Name: t.Name, Description: t.Description, Parameters: t.Parameters})
+	}
+	return defs
+}
+
+// run executes the tool with the model's arguments.
+func (t *skillTool) run(args map[string]any) string {
+	if t.Exec != nil {
+		command, err := renderToolTemplate(t.Exec.Command, args, shellQuote)
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err)
+		}
+		return executeCommand(map[string]any{"command": command, "timeout": t.timeout.Seconds()})
+	}
+	return t.runHTTP(args)
+}
+
+func (t *skillTool) runHTTP(args map[string]any) string {
+	rawURL, err := renderToolTemplate(t.HTTP.URL, args, url.QueryEscape)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+	var body io.Reader
+	if t.HTTP.Body != "" {
+		b, err := renderToolTemplate(t.HTTP.Body, args, jsonValue)
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err)
+		}
+		body = strings.NewReader(b)
+	}
+	method := t.HTTP.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	req, err := http.NewRequest(method, rawURL, body)
+	if err != nil {
+		return fmt.Sprintf("Error creating request: %v", err)
+	}
+	req.Header.Set("User-Agent", "Sympozium/1.0 (agent-runner)")
+	for k, v := range t.HTTP.Headers {
+		req.Header.Set(k, v)
+	}
+	client := &http.Client{Timeout: t.timeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 2*1024*1024))
+	if err != nil {
+		return fmt.Sprintf("Error reading response body: %v", err)
+	}
+	out := truncateStr(string(data), 50_000)
+	if resp.StatusCode >= 400 {
+		return fmt.Sprintf("Error: HTTP %d\n%s", resp.StatusCode, out)
+	}
+	return out
+}
+
+// renderToolTemplate fills {{.arg}} placeholders with escaped argument
+// values. Referencing an argument the model did not supply is an error.
+func renderToolTemplate(text string, args map[string]any, escape func(string) string) (string, error) {
+	tmpl, err := template.New("tool").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid tool template: %w", err)
+	}
+	values := make(map[string]string, len(args))
+	for k, v := range args {
+		s, ok := v.(string)
+		if !ok {
+			b, _ := json.Marshal(v)
+			s = string(b)
+		}
+		values[k] = escape(s)
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, values); err != nil {
+		return "", fmt.Errorf("missing tool argument: %w", err)
+	}
+	return sb.String(), nil
+}
+
+// shellQuote quotes s as a single POSIX shell word.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// jsonValue encodes s as a JSON string without the surrounding quotes, so
+// templates control the quoting: {"q": "{{.query}}"}.
+func jsonValue(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b[1 : len(b)-1])
+}
+
+// parseToolAllowlist parses TOOLS_ALLOWLIST, a comma-separated list of tool
+// names. Empty means every tool is allowed.
+func parseToolAllowlist(v string) map[string]bool {
+	if strings.TrimSpace(v) == "" {
+		return nil
+	}
+	allowed := map[string]bool{}
+	for _, name := range strings.Split(v, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			allowed[name] = true
+		}
+	}
+	return allowed
+}
+
+// toolAllowed reports whether the allowlist permits name.
+func toolAllowed(name string) bool {
+	return toolAllowlist == nil || toolAllowlist[name]
+}
+
+// filterAllowedTools drops the tools the allowlist does not permit, so the
+// model is never offered them.
+func filterAllowedTools(tools []ToolDef) []ToolDef {
+	var out []ToolDef
+	for _, t := range tools {
+		if toolAllowed(t.Name) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// toolCallRecord is one tool invocation, listed in result.json's toolCalls.
+type toolCallRecord struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Arguments  string `json:"arguments"`
+	Output     string `json:"output"`
+	IsError    bool   `json:"isError,omitempty"`
+	DurationMs int64  `json:"durationMs"`
+}
+
+// runToolCall executes one tool call for the model, records it in res and
+// writes tool_use and tool_result stream chunks.
+func runToolCall(res *llmResult, id, name, argsJSON string) (output string, isErr bool) {
+	res.ToolCalls++
+	log.Printf("tool_use [%d]: %s id=%s", res.ToolCalls, name, id)
+	chunkOut.writeChunk("tool_use", id, name+" "+argsJSON)
+	transcript.toolCall(id, name, argsJSON)
+	status.setTokens(res.OutputTokens)
+	status.setPhase(phaseExecutingTool)
+	defer status.setPhase(phaseCallingLLM)
+	span := startToolSpan(name, id)
+	start := time.Now()
+	if toolAllowed(name) {
+		output = executeToolCall(name, argsJSON)
+	} else {
+		output = fmt.Sprintf("Error: tool %q is not in TOOLS_ALLOWLIST", name)
+	}
+	isErr = strings.HasPrefix(output, "Error")
+	endToolSpan(span, output, isErr)
+	res.ToolInvocations = append(res.ToolInvocations, toolCallRecord{
+		ID:         id,
+		Name:       name,
+		Arguments:  argsJSON,
+		Output:     truncateRedacted(output, 4000),
+		IsError:    isErr,
+		DurationMs: time.Since(start).Milliseconds(),
+	})
+	chunkOut.writeChunk("tool_result", id, output)
+	transcript.toolResult(id, name, output, isErr)
+	return output, isErr
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	statusFile = "status.json"
+	// defaultStatusInterval is the default STATUS_INTERVAL.
+	defaultStatusInterval = 10 * time.Second
+)
+
+// Phases reported in status.json.
+const (
+	phaseStarting      = "starting"
+	phaseCallingLLM    = "calling_llm"
+	phaseStreaming     = "streaming"
+	phaseExecutingTool = "executing_tool"
+	phaseFinalizing    = "finalizing"
+	phaseDone          = "done"
+	phaseError         = "error"
+	phaseCancelled     = "cancelled"
+)
+
+// runStatus is the content of status.json. Heartbeat increases with every
+// write, so a reader can tell a slow run from a hung one even when nothing
+// else changed.
+type runStatus struct {
+	// SchemaVersion is outputSchemaVersion.
+	SchemaVersion  int    `json:"schemaVersion"`
+	Phase          string `json:"phase"`
+	Attempt        int    `json:"attempt"`
+	ElapsedMs      int64  `json:"elapsedMs"`
+	TokensReceived int    `json:"tokensReceived"`
+	Heartbeat      int64  `json:"heartbeat"`
+	UpdatedAt      string `json:"updatedAt"`
+	// Batch is the progress of a tasks.json batch.
+	Batch *batchProgress `json:"batch,omitempty"`
+}
+
+// batchProgress counts the finished tasks of a batch; Failed is included
+// in Done.
+type batchProgress struct {
+	Done   int `json:"done"`
+	Failed int `json:"failed"`
+	Total  int `json:"total"`
+}
+
+// statusReporter writes status.json on every phase change and at least
+// every interval until finish. Methods on a nil reporter do nothing.
+type statusReporter struct {
+	path     string
+	interval time.Duration
+	start    time.Time
+
+	mu        sync.Mutex
+	phase     string
+	tokens    int // output tokens reported by the provider so far
+	streamed  int // bytes of streamed output
+	heartbeat int64
+	batch     *batchProgress
+	lastBeat  time.Time // time of the last write
+	running   bool      // between run and finish
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+// status reports this run's progress; main starts it before the first
+// provider request.
+var status *statusReporter
+
+func newStatusReporter(path string, interval time.Duration, start time.Time) *statusReporter {
+	return &statusReporter{path: path, interval: interval, start: start, phase: phaseStarting}
+}
+
+// statusInterval returns STATUS_INTERVAL, or defaultStatusInterval if unset.
+func statusInterval() (time.Duration, error) {
+	v := getEnv("STATUS_INTERVAL", "")
+	if v == "" {
+		return defaultStatusInterval, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid STATUS_INTERVAL %q", v)
+	}
+	return d, nil
+}
+
+// run writes status.json once, then again every interval until finish.
+func (s *statusReporter) run() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.running = true
+	s.writeLocked()
+	s.mu.Unlock()
+	s.stop, s.done = make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(s.done)
+		t := time.NewTicker(s.interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-s.stop:
+				return
+			case <-t.C:
+				s.mu.Lock()
+				s.writeLocked()
+				s.mu.Unlock()
+			}
+		}
+	}()
+}
+
+// setPhase records phase, writing status.json if it changed.
+func (s *statusReporter) setPhase(phase string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.phase == phase {
+		return
+	}
+	s.phase = phase
+	s.writeLocked()
+}
+
+// setTokens records the output tokens the provider has reported so far.
+func (s *statusReporter) setTokens(n int) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens = n
+}
+
+// streamedBytes records a streamed delta of n bytes and switches to the
+// streaming phase.
+func (s *statusReporter) streamedBytes(n int) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.streamed += n
+	if s.phase != phaseStreaming {
+		s.phase = phaseStreaming
+		s.writeLocked()
+	}
+}
+
+// setBatchProgress records the progress of a batch and writes status.json.
+func (s *statusReporter) setBatchProgress(done, failed, total int) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batch = &batchProgress{Done: done, Failed: failed, Total: total}
+	s.writeLocked()
+}
+
+// snapshot returns the current phase and tokens received, for /metrics.
+func (s *statusReporter) snapshot() (phase string, tokens int) {
+	if s == nil {
+		return phaseStarting, 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.phase, s.tokensLocked()
+}
+
+// tokensLocked returns the output tokens received so far. Streamed text is
+// counted at roughly four bytes per token, like estimateTokens, until the
+// provider reports usage.
+func (s *statusReporter) tokensLocked() int {
+	return max(s.tokens, (s.streamed+3)/4)
+}
+
+// finish stops the periodic writes and writes the final phase.
+func (s *statusReporter) finish(phase string) {
+	if s == nil {
+		return
+	}
+	if s.stop != nil {
+		close(s.stop)
+		<-s.done
+		s.stop = nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.running = false
+	s.phase = phase
+	s.writeLocked()
+}
+
+// stalled reports whether the periodic writes have stopped while the run is
+// in progress: no heartbeat for more than intervals status intervals. It
+// returns the time since the last heartbeat.
+func (s *statusReporter) stalled(intervals int) (time.Duration, bool) {
+	if s == nil {
+		return 0, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.running {
+		return 0, false
+	}
+	since := time.Since(s.lastBeat)
+	return since, since > time.Duration(intervals)*s.interval
+}
+
+// writeLocked writes the current status atomically, so readers never see a
+// partial file.
+func (s *statusReporter) writeLocked() {
+	s.heartbeat++
+	s.lastBeat = time.Now()
+	st := runStatus{
+		SchemaVersion:  outputSchemaVersion,
+		Phase:          s.phase,
+		Attempt:        int(providerAttempts.Load()),
+		ElapsedMs:      time.Since(s.start).Milliseconds(),
+		TokensReceived: s.tokensLocked(),
+		Heartbeat:      s.heartbeat,
+		UpdatedAt:      time.Now().UTC().Format(time.RFC3339Nano),
+		Batch:          s.batch,
+	}
+	if err := writeFileAtomic(s.path, st); err != nil {
+		log.Printf("failed to write %s: %v", s.path, err)
+	}
+}
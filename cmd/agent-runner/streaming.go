@@ -0,0 +1,322 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/packages/respjson"
+
+	"github.com/alexsjones/sympozium/internal/ipcfile"
+)
+
+const (
+	// defaultStreamFlushInterval is the default STREAM_FLUSH_INTERVAL.
+	defaultStreamFlushInterval = 500 * time.Millisecond
+	// defaultStreamFlushBytes is the default STREAM_FLUSH_BYTES.
+	defaultStreamFlushBytes = 512
+	// maxStreamRestarts bounds how often a stream that failed before
+	// producing any output is restarted from scratch.
+	maxStreamRestarts = 2
+)
+
+// errStreamInterrupted marks a stream that failed after output had already
+// been emitted. The result is then partial: restarting would duplicate the
+// chunks a watcher has already seen.
+var errStreamInterrupted = errors.New("stream interrupted after output was emitted")
+
+// streamOut receives text and thinking deltas when STREAM=true; nil
+// disables streaming.
+var streamOut *streamEmitter
+
+// chunkOut receives typed chunks (tool calls and their results). It is
+// streamOut when streaming, so indexes stay in order across chunk types.
+var chunkOut *streamEmitter
+
+// streamEmitter batches deltas into numbered stream-<n>.json chunk files,
+// flushing when flushBytes are buffered, every flushInterval, or when the
+// delta type changes, whichever comes first. Each file is written atomically
+// so the IPC bridge never reads a partial chunk.
+type streamEmitter struct {
+	dir           string
+	flushInterval time.Duration
+	flushBytes    int
+
+	mu       sync.Mutex
+	buf      strings.Builder
+	bufType  string // chunk type of the buffered deltas
+	next     int
+	accepted int             // bytes accepted during the current call
+	callText strings.Builder // text of the current call, for partial results
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+func newStreamEmitter(dir string, flushInterval time.Duration, flushBytes int) *streamEmitter {
+	return &streamEmitter{dir: dir, flushInterval: flushInterval, flushBytes: flushBytes}
+}
+
+// start flushes the buffer every flushInterval until close is called, so
+// output shows up even while the model pauses between deltas.
+func (e *streamEmitter) start() {
+	e.stop, e.done = make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(e.done)
+		t := time.NewTicker(e.flushInterval)
+		defer t.Stop()
+		for {
+			select {
+			case <-e.stop:
+				return
+			case <-t.C:
+				e.mu.Lock()
+				e.flushLocked()
+				e.mu.Unlock()
+			}
+		}
+	}()
+}
+
+// close stops the flush loop and writes any buffered text.
+func (e *streamEmitter) close() {
+	if e.stop != nil {
+		close(e.stop)
+		<-e.done
+		e.stop = nil
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.flushLocked()
+}
+
+// beginCall resets the per-call counters before a provider request.
+func (e *streamEmitter) beginCall() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.accepted = 0
+	e.callText.Reset()
+}
+
+// emitted reports whether the current call has produced any output.
+func (e *streamEmitter) emitted() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.accepted > 0
+}
+
+// text returns the output of the current call so far.
+func (e *streamEmitter) text() string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.callText.String()
+}
+
+// write buffers a delta of the user-visible response.
+func (e *streamEmitter) write(delta string) {
+	e.writeDelta("text", delta)
+}
+
+// writeThinking buffers a delta of the model's reasoning. It is streamed as
+// "thinking" chunks and never becomes part of the response.
+func (e *streamEmitter) writeThinking(delta string) {
+	e.writeDelta("thinking", delta)
+}
+
+func (e *streamEmitter) writeDelta(typ, delta string) {
+	if delta == "" {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.bufType != typ {
+		e.flushLocked()
+		if e.buf.Len() > 0 {
+			// The flush failed. Chunks hold a single type, so the pending
+			// deltas cannot wait for the next flush.
+			log.Printf("dropping %d bytes of %s output that could not be written", e.buf.Len(), e.bufType)
+			e.buf.Reset()
+		}
+		e.bufType = typ
+	}
+	e.buf.WriteString(delta)
+	if typ == "text" {
+		e.callText.WriteString(delta)
+	}
+	e.accepted += len(delta)
+	status.streamedBytes(len(delta))
+	if e.buf.Len() >= e.flushBytes {
+		e.flushLocked()
+	}
+}
+
+// writeChunk writes a chunk of the given type right away, after any
+// buffered text so chunks stay in order. A nil emitter discards it.
+func (e *streamEmitter) writeChunk(typ, toolID, content string) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.flushLocked()
+	chunk := streamChunk{Type: typ, Content: content, ToolID: toolID, Index: e.next}
+	if err := e.writeLocked(chunk); err != nil {
+		log.Printf("failed to write %s chunk: %v", typ, err)
+	}
+}
+
+func (e *streamEmitter) flushLocked() {
+	if e.buf.Len() == 0 {
+		return
+	}
+	// On error the text stays buffered and the next flush retries.
+	if e.writeLocked(streamChunk{Type: e.bufType, Content: e.buf.String(), Index: e.next}) == nil {
+		e.buf.Reset()
+	}
+}
+
+// writeLocked writes chunk as the next stream-<n>.json file.
+func (e *streamEmitter) writeLocked(chunk streamChunk) error {
+	chunk.SchemaVersion = outputSchemaVersion
+	name := fmt.Sprintf("stream-%d.json", e.next)
+	if err := writeFileAtomic(filepath.Join(e.dir, name), chunk); err != nil {
+		return err
+	}
+	e.next++
+	return nil
+}
+
+// writeFileAtomic writes v as JSON with ipcfile.Write: a reader never sees a
+// partly written file.
+func writeFileAtomic(path string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return ipcfile.Write(path, redactBytes(data), 0o644)
+}
+
+// streamSettings returns whether STREAM=true and the flush settings.
+func streamSettings() (enabled bool, interval time.Duration, size int, err error) {
+	enabled = getEnv("STREAM", "") == "true"
+	interval, size = defaultStreamFlushInterval, defaultStreamFlushBytes
+	if v := getEnv("STREAM_FLUSH_INTERVAL", ""); v != "" {
+		if interval, err = time.ParseDuration(v); err != nil || interval <= 0 {
+			return false, 0, 0, fmt.Errorf("invalid STREAM_FLUSH_INTERVAL %q", v)
+		}
+	}
+	if v := getEnv("STREAM_FLUSH_BYTES", ""); v != "" {
+		if size, err = strconv.Atoi(v); err != nil || size <= 0 {
+			return false, 0, 0, fmt.Errorf("invalid STREAM_FLUSH_BYTES %q", v)
+		}
+	}
+	return enabled, interval, size, nil
+}
+
+// streamWithRestart runs call, restarting it from scratch when it fails
+// before emitting any output. A failure after output was emitted is
+// returned wrapped in errStreamInterrupted.
+func streamWithRestart[T any](ctx context.Context, call func() (T, error)) (T, error) {
+	for attempt := 0; ; attempt++ {
+		streamOut.beginCall()
+		res, err := call()
+		if err == nil {
+			return res, nil
+		}
+		if streamOut.emitted() {
+			return res, fmt.Errorf("%w: %w", errStreamInterrupted, err)
+		}
+		// HTTP errors were already retried by retryTransport; only dropped
+		// connections and malformed streams are worth starting over.
+		var anthropicErr *anthropic.Error
+		var openaiErr *openai.Error
+		if errors.As(err, &anthropicErr) || errors.As(err, &openaiErr) {
+			return res, err
+		}
+		if attempt >= maxStreamRestarts || ctx.Err() != nil {
+			return res, err
+		}
+		log.Printf("stream failed before any output, restarting (%d/%d): %v", attempt+1, maxStreamRestarts, err)
+	}
+}
+
+// streamAnthropic sends params as a streaming Messages request, emitting
+// text deltas and returning the accumulated message.
+func streamAnthropic(ctx context.Context, client anthropic.Client, params anthropic.MessageNewParams) (*anthropic.Message, error) {
+	return streamWithRestart(ctx, func() (*anthropic.Message, error) {
+		stream := client.Messages.NewStreaming(ctx, params)
+		defer stream.Close()
+		message := &anthropic.Message{}
+		for stream.Next() {
+			event := stream.Current()
+			if err := message.Accumulate(event); err != nil {
+				return message, err
+			}
+			if delta, ok := event.AsAny().(anthropic.ContentBlockDeltaEvent); ok {
+				switch d := delta.Delta.AsAny().(type) {
+				case anthropic.TextDelta:
+					streamOut.write(d.Text)
+				case anthropic.ThinkingDelta:
+					streamOut.writeThinking(d.Thinking)
+				}
+			}
+		}
+		return message, stream.Err()
+	})
+}
+
+// streamOpenAI sends params as a streaming chat completion, emitting content
+// deltas and returning the accumulated completion. Usage comes from the
+// final usage chunk when the endpoint sends one.
+func streamOpenAI(ctx context.Context, client openai.Client, params openai.ChatCompletionNewParams) (*openai.ChatCompletion, error) {
+	return streamWithRestart(ctx, func() (*openai.ChatCompletion, error) {
+		stream := client.Chat.Completions.NewStreaming(ctx, params)
+		defer stream.Close()
+		acc := openai.ChatCompletionAccumulator{}
+		var usage openai.CompletionUsage
+		for stream.Next() {
+			chunk := stream.Current()
+			acc.AddChunk(chunk)
+			if chunk.Usage.TotalTokens > 0 {
+				usage = chunk.Usage
+			}
+			if len(chunk.Choices) > 0 {
+				delta := chunk.Choices[0].Delta
+				streamOut.writeThinking(reasoningContent(delta.JSON.ExtraFields))
+				streamOut.write(delta.Content)
+			}
+		}
+		completion := acc.ChatCompletion
+		completion.Usage = usage
+		return &completion, stream.Err()
+	})
+}
+
+// reasoningContent returns the reasoning text OpenAI-compatible servers
+// send outside the standard schema: reasoning_content (DeepSeek, vLLM) or
+// reasoning (OpenRouter, Ollama).
+func reasoningContent(extra map[string]respjson.Field) string {
+	for _, key := range []string{"reasoning_content", "reasoning"} {
+		f, ok := extra[key]
+		if !ok {
+			continue
+		}
+		var text string
+		if json.Unmarshal([]byte(f.Raw()), &text) == nil && text != "" {
+			return text
+		}
+	}
+	return ""
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// taskFile is the task, in ipc.Input.
+	taskFile = "task.json"
+	// defaultMaxInputBytes is the default MAX_INPUT_BYTES.
+	defaultMaxInputBytes = 256 << 10
+)
+
+// errInputTooLarge marks a task or system prompt over MAX_INPUT_BYTES.
+var errInputTooLarge = errors.New("input too large")
+
+var utf8BOM = []byte("\ufeff")
+
+// maxInputBytes parses MAX_INPUT_BYTES, the size limit for the task and
+// the system prompt.
+func maxInputBytes() (int64, error) {
+	v := getEnv("MAX_INPUT_BYTES", "")
+	if v == "" {
+		return defaultMaxInputBytes, nil
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid MAX_INPUT_BYTES %q", v)
+	}
+	return n, nil
+}
+
+// resolveInput returns the contents of the file named by the fileEnv env
+// var when it is set, and otherwise calls fallback. Either way the result
+// must fit in maxBytes, so an oversized input fails here rather than as a
+// 400 from the provider.
+func resolveInput(name, fileEnv string, maxBytes int64, fallback func() (string, string, error)) (text, source string, err error) {
+	if path := getEnv(fileEnv, ""); path != "" {
+		text, err = readInputFile(path, maxBytes)
+		source = fileEnv + " " + path
+	} else {
+		text, source, err = fallback()
+	}
+	if err != nil {
+		return "", "", err
+	}
+	if int64(len(text)) > maxBytes {
+		return "", "", fmt.Errorf("%w: %s from %s is %d bytes, over MAX_INPUT_BYTES %d", errInputTooLarge, name, source, len(text), maxBytes)
+	}
+	return text, source, nil
+}
+
+// readInputFile reads a mounted prompt file, checking its size before
+// reading it. A UTF-8 byte order mark is dropped; any other invalid UTF-8
+// is an error.
+func readInputFile(path string, maxBytes int64) (string, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if fi.Size() > maxBytes {
+		return "", fmt.Errorf("%w: %s is %d bytes, over MAX_INPUT_BYTES %d", errInputTooLarge, path, fi.Size(), maxBytes)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	b = bytes.TrimPrefix(b, utf8BOM)
+	if !utf8.Valid(b) {
+		return "", fmt.Errorf("%s is not valid UTF-8", path)
+	}
+	return string(b), nil
+}
+
+// inputErrorCode is the errorCode for a task or system prompt that could
+// not be loaded.
+func inputErrorCode(err error) string {
+	switch {
+	case errors.Is(err, errInputTooLarge):
+		return "input_too_large"
+	case errors.Is(err, errTaskValidation):
+		return "task_validation_failed"
+	}
+	return "config_error"
+}
+
+// resolveTask returns the task and where it came from. The TASK env var
+// wins, then the task file, then stdin when it is not a terminal. With
+// TASK_SCHEMA_FILE, a task file or stdin that does not match the schema is
+// an error rather than skipped. The runner can be tested locally with
+//
+//	echo '{"task":"..."}' | agent-runner
+//	echo "plain text task" | agent-runner
+func resolveTask(env, taskFile string, stdin io.Reader, stdinPiped bool) (task, source string, err error) {
+	if env != "" {
+		return env, "TASK env", nil
+	}
+	if b, err := os.ReadFile(taskFile); err == nil {
+		if err := validateTaskInput(b, taskFile); err != nil {
+			return "", "", err
+		}
+		if t := parseTaskInput(b, false); t != "" {
+			return t, taskFile, nil
+		}
+	}
+	if !stdinPiped {
+		return "", "", nil
+	}
+	b, err := io.ReadAll(stdin)
+	if err != nil {
+		return "", "", fmt.Errorf("read task from stdin: %w", err)
+	}
+	if err := validateTaskInput(b, "stdin"); err != nil {
+		return "", "", err
+	}
+	return parseTaskInput(b, true), "stdin", nil
+}
+
+// parseTaskInput extracts the task from {"task": "..."} JSON. With
+// allowPlain, input that is not such a document is the task itself.
+func parseTaskInput(b []byte, allowPlain bool) string {
+	var input struct {
+		Task string `json:"task"`
+	}
+	b = bytes.TrimPrefix(b, utf8BOM)
+	if json.Unmarshal(b, &input) == nil && input.Task != "" {
+		return input.Task
+	}
+	if allowPlain {
+		return strings.TrimSpace(string(b))
+	}
+	return ""
+}
+
+// stdinIsPiped reports whether stdin is a pipe or file rather than a
+// terminal (or closed, as in a pod without stdin).
+func stdinIsPiped() bool {
+	fi, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	mode := fi.Mode()
+	return mode&os.ModeCharDevice == 0 && (mode&os.ModeNamedPipe != 0 || mode.IsRegular())
+}
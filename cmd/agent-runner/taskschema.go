@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+// builtinTaskSchema is the schema TASK_SCHEMA_FILE=builtin selects: the
+// {"task": "..."} document the controller writes, with nothing else in it.
+const builtinTaskSchema = `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "type": "object",
+  "properties": {
+    "task": {"type": "string", "minLength": 1}
+  },
+  "required": ["task"],
+  "additionalProperties": false
+}`
+
+// errTaskValidation marks a task document that does not match
+// TASK_SCHEMA_FILE.
+var errTaskValidation = errors.New("task failed schema validation")
+
+// taskSchema validates task documents read from the task file or stdin when
+// TASK_SCHEMA_FILE is set; nil otherwise. The TASK env var and TASK_FILE
+// hold the task text itself, not a document, and are not validated.
+var taskSchema *jsonschema.Schema
+
+// loadTaskSchema compiles TASK_SCHEMA_FILE: a path to a JSON Schema, or
+// "builtin" for builtinTaskSchema. It returns nil when the variable is unset.
+func loadTaskSchema() (*jsonschema.Schema, error) {
+	path := getEnv("TASK_SCHEMA_FILE", "")
+	switch path {
+	case "":
+		return nil, nil
+	case "builtin":
+		return compileSchema("TASK_SCHEMA_FILE", "builtin-task-schema.json", []byte(builtinTaskSchema))
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading TASK_SCHEMA_FILE: %w", err)
+	}
+	return compileSchema("TASK_SCHEMA_FILE", path, data)
+}
+
+// validateTaskInput checks the task document read from source against
+// taskSchema. Errors wrap errTaskValidation and say which field failed.
+func validateTaskInput(b []byte, source string) error {
+	if taskSchema == nil {
+		return nil
+	}
+	v, err := jsonschema.UnmarshalJSON(bytes.NewReader(bytes.TrimPrefix(b, utf8BOM)))
+	if err != nil {
+		return fmt.Errorf("%w: %s is not valid JSON: %v", errTaskValidation, source, err)
+	}
+	if err := taskSchema.Validate(v); err != nil {
+		return fmt.Errorf("%w: %s: %v", errTaskValidation, source, err)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+const (
+	// exitTerminated is the exit code after SIGTERM (128+15), so the
+	// controller can tell a cancelled run from a failed one.
+	exitTerminated = 143
+	// defaultShutdownGracePeriod is the default SHUTDOWN_GRACE_PERIOD. It
+	// stays well inside the pod's default 30s terminationGracePeriodSeconds.
+	defaultShutdownGracePeriod = 10 * time.Second
+)
+
+// terminated is set once SIGTERM or SIGINT has been received.
+var terminated atomic.Bool
+
+// shutdownDeadline is when the grace period after the signal ends, in Unix
+// nanoseconds; set before terminated.
+var shutdownDeadline atomic.Int64
+
+// graceRemaining returns how much of the shutdown grace period is left.
+func graceRemaining() time.Duration {
+	return time.Until(time.Unix(0, shutdownDeadline.Load()))
+}
+
+// shutdownGracePeriod returns SHUTDOWN_GRACE_PERIOD, or
+// defaultShutdownGracePeriod if unset. Set it below the pod's
+// terminationGracePeriodSeconds so the result is written before SIGKILL.
+func shutdownGracePeriod() (time.Duration, error) {
+	v := getEnv("SHUTDOWN_GRACE_PERIOD", "")
+	if v == "" {
+		return defaultShutdownGracePeriod, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid SHUTDOWN_GRACE_PERIOD %q", v)
+	}
+	return d, nil
+}
+
+// handleTermination cancels the in-flight provider request on SIGTERM or
+// SIGINT, after which main writes a cancelled result as usual. If main has
+// not done so within grace (a tool may be blocking), the result is written
+// from partial instead and the process exits.
+func handleTermination(cancel context.CancelFunc, grace time.Duration, partial func() agentResult) {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGTERM, os.Interrupt)
+	go func() {
+		sig := <-sigs
+		log.Printf("received %s; cancelling the run (grace period %s)", sig, grace)
+		shutdownDeadline.Store(time.Now().Add(grace).UnixNano())
+		terminated.Store(true)
+		cancel()
+		time.AfterFunc(grace, func() {
+			log.Printf("run did not stop within %s; writing the partial result", grace)
+			res := partial()
+			markTerminated(&res)
+			emitResult(res)
+			status.finish(phaseCancelled)
+			os.Exit(exitTerminated)
+		})
+	}()
+}
+
+// markTerminated turns res into the result of a terminated run, keeping
+// any response text received so far.
+func markTerminated(res *agentResult) {
+	res.Status = "cancelled"
+	res.Error = "run terminated by signal"
+	res.ErrorCode = "terminated"
+	res.ErrorType = ""
+	res.Partial = res.Response != ""
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+
+	"github.com/openai/openai-go/v3"
+)
+
+// tokenCounter approximates how many tokens a text takes. It is used when a
+// provider reports no usage, and to size conversation history.
+type tokenCounter interface {
+	CountTokens(text string) int
+}
+
+// charTokenCounter assumes roughly four characters per token, which is close
+// enough for English text with BPE tokenizers.
+type charTokenCounter struct{}
+
+func (charTokenCounter) CountTokens(text string) int {
+	return (len(text) + 3) / 4
+}
+
+// tokenizer is the tokenCounter behind estimateTokens; a real tokenizer can
+// be swapped in here.
+var tokenizer tokenCounter = charTokenCounter{}
+
+// estimateTokens approximates the token count of s.
+func estimateTokens(s string) int {
+	return tokenizer.CountTokens(s)
+}
+
+// openAIUsage returns the token usage of one chat completion. Ollama and
+// many gateways omit usage or report zeros; each missing count is then
+// estimated, from the request messages for input and the reply for output,
+// and estimated is set.
+func openAIUsage(completion *openai.ChatCompletion, messages []openai.ChatCompletionMessageParamUnion) (in, out int, estimated bool) {
+	in = int(completion.Usage.PromptTokens)
+	out = int(completion.Usage.CompletionTokens)
+	if in == 0 {
+		if b, err := json.Marshal(messages); err == nil {
+			in = estimateTokens(string(b))
+			estimated = true
+		}
+	}
+	if out == 0 && len(completion.Choices) > 0 {
+		if text := openAIOutputText(completion.Choices[0].Message); text != "" {
+			out = estimateTokens(text)
+			estimated = true
+		}
+	}
+	if estimated {
+		log.Printf("provider reported incomplete token usage (prompt=%d completion=%d); estimated in=%d out=%d",
+			completion.Usage.PromptTokens, completion.Usage.CompletionTokens, in, out)
+	}
+	return in, out, estimated
+}
+
+// openAIOutputText is the text a completion generated: its content and the
+// name and arguments of every tool call.
+func openAIOutputText(msg openai.ChatCompletionMessage) string {
+	text := msg.Content
+	for _, tc := range msg.ToolCalls {
+		fc := tc.AsFunction()
+		text += fc.Function.Name + fc.Function.Arguments
+	}
+	return text
+}
@@ -24,6 +24,10 @@ const (
 	ToolSendChannelMessage = "send_channel_message"
 	ToolFetchURL           = "fetch_url"
 	ToolScheduleTask       = "schedule_task"
+	// ToolShell runs an allowlisted command in the agent container itself,
+	// unlike execute_command, which runs in the skill sidecar. It is only
+	// offered with TOOLS_SHELL=true.
+	ToolShell = "shell"
 )
 
 // ToolDef describes a tool for LLM function calling.
@@ -194,7 +198,7 @@ func defaultTools() []ToolDef {
 
 // executeToolCall dispatches a tool call and returns the result string.
 func executeToolCall(name string, argsJSON string) string {
-	log.Printf("tool call: %s args=%s", name, truncateStr(argsJSON, 200))
+	log.Printf("tool call: %s args=%s", name, truncateRedacted(argsJSON, 200))
 
 	var args map[string]any
 	if err := json.Unmarshal([]byte(argsJSON), &args); err != nil {
@@ -216,11 +220,33 @@ func executeToolCall(name string, argsJSON string) string {
 		return fetchURLTool(args)
 	case ToolScheduleTask:
 		return scheduleTaskTool(args)
+	case ToolShell:
+		return shellTool(args)
 	default:
+		if t, ok := skillTools[name]; ok {
+			return t.run(args)
+		}
+		if t, ok := mcpTools[name]; ok {
+			return t.run(args)
+		}
 		return fmt.Sprintf("Unknown tool: %s", name)
 	}
 }
 
+// isBuiltinTool reports whether name is one of the tools in defaultTools
+// or the shell tool.
+func isBuiltinTool(name string) bool {
+	if name == ToolShell {
+		return true
+	}
+	for _, t := range defaultTools() {
+		if t.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
 // --- Native tools (run in the agent container) ---
 
 func readFileTool(args map[string]any) string {
@@ -230,7 +256,7 @@ func readFileTool(args map[string]any) string {
 	}
 
 	// Security: restrict to allowed paths.
-	allowed := []string{"/workspace", "/skills", "/tmp", "/ipc"}
+	allowed := []string{"/workspace", "/skills", "/tmp", ipc.Root}
 	ok := false
 	for _, prefix := range allowed {
 		if strings.HasPrefix(filepath.Clean(path), prefix) {
@@ -310,7 +336,7 @@ func sendChannelMessageTool(args map[string]any) string {
 		return fmt.Sprintf("Error marshalling message: %v", err)
 	}
 
-	dir := "/ipc/messages"
+	dir := ipc.dir("messages")
 	_ = os.MkdirAll(dir, 0o755)
 	id := fmt.Sprintf("%d", time.Now().UnixNano())
 	path := filepath.Join(dir, fmt.Sprintf("send-%s.json", id))
@@ -646,7 +672,7 @@ func executeCommand(args map[string]any) string {
 		Timeout: timeoutSec,
 	}
 
-	toolsDir := "/ipc/tools"
+	toolsDir := ipc.dir("tools")
 	reqPath := filepath.Join(toolsDir, fmt.Sprintf("exec-request-%s.json", id))
 	resPath := filepath.Join(toolsDir, fmt.Sprintf("exec-result-%s.json", id))
 
@@ -660,7 +686,7 @@ func executeCommand(args map[string]any) string {
 		return fmt.Sprintf("Error writing exec request: %v", err)
 	}
 
-	log.Printf("Wrote exec request %s: %s", id, truncateStr(command, 120))
+	log.Printf("Wrote exec request %s: %s", id, truncateRedacted(command, 120))
 
 	// Poll for result with a deadline.
 	deadline := time.Now().Add(time.Duration(timeoutSec+10) * time.Second)
@@ -783,7 +809,7 @@ func scheduleTaskTool(args map[string]any) string {
 		return fmt.Sprintf("Error marshalling schedule request: %v", err)
 	}
 
-	dir := "/ipc/schedules"
+	dir := ipc.dir("schedules")
 	_ = os.MkdirAll(dir, 0o755)
 	id := fmt.Sprintf("%d", time.Now().UnixNano())
 	path := filepath.Join(dir, fmt.Sprintf("schedule-%s.json", id))
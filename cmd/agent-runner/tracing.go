@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const (
+	tracerName = "github.com/alexsjones/sympozium/cmd/agent-runner"
+	// tracingFlushTimeout bounds exporting the remaining spans at exit.
+	tracingFlushTimeout = 5 * time.Second
+)
+
+var (
+	// tracer creates this run's spans; it records nothing until
+	// setupTracing finds an OTLP endpoint.
+	tracer trace.Tracer = noop.NewTracerProvider().Tracer(tracerName)
+	// runSpan is the root span of the run; traceCtx carries it, as the
+	// parent of tool execution spans.
+	runSpan  trace.Span      = noop.Span{}
+	traceCtx context.Context = context.Background()
+	// shutdownTracing flushes and stops the exporter.
+	shutdownTracing = func(context.Context) error { return nil }
+	endTracingOnce  sync.Once
+)
+
+// setupTracing exports spans over OTLP/HTTP when OTEL_EXPORTER_OTLP_ENDPOINT
+// (or OTEL_EXPORTER_OTLP_TRACES_ENDPOINT) is set. The exporter reads the
+// other standard OTEL_EXPORTER_OTLP_* variables itself. Without an endpoint
+// tracing stays a no-op.
+func setupTracing(ctx context.Context) error {
+	endpoint := firstNonEmpty(getEnv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", ""), getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", ""))
+	if endpoint == "" {
+		return nil
+	}
+	exp, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return err
+	}
+	// OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES override the defaults.
+	res, err := resource.New(ctx,
+		resource.WithAttributes(attribute.String("service.name", "sympozium-agent-runner")),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		return err
+	}
+	tp := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exp), sdktrace.WithResource(res))
+	tracer = tp.Tracer(tracerName)
+	shutdownTracing = tp.Shutdown
+	log.Printf("tracing: exporting spans to %s", endpoint)
+	return nil
+}
+
+// startRunSpan starts the root span of the run, as a child of the trace
+// context in TRACEPARENT (and TRACESTATE) when the controller set one.
+func startRunSpan(ctx context.Context) context.Context {
+	if tp := getEnv("TRACEPARENT", ""); tp != "" {
+		carrier := propagation.MapCarrier{"traceparent": tp, "tracestate": getEnv("TRACESTATE", "")}
+		ctx = propagation.TraceContext{}.Extract(ctx, carrier)
+	}
+	ctx, runSpan = tracer.Start(ctx, "agent-runner.run", trace.WithAttributes(
+		attribute.String("sympozium.instance", getEnv("INSTANCE_NAME", "")),
+		attribute.String("sympozium.agent_run", getEnv("AGENT_RUN_ID", "")),
+	))
+	traceCtx = ctx
+	return ctx
+}
+
+// setRunSpanModel records the provider and model on the run span once they
+// are resolved.
+func setRunSpanModel(provider, model string) {
+	runSpan.SetAttributes(
+		attribute.String("gen_ai.system", provider),
+		attribute.String("gen_ai.request.model", model),
+	)
+}
+
+// endTracing ends the run span with the outcome in res and flushes every
+// span to the exporter. Only the first call has any effect, so both the
+// normal exit and the fatal and termination paths can call it.
+func endTracing(res agentResult) {
+	endTracingOnce.Do(func() {
+		runSpan.SetAttributes(
+			attribute.String("sympozium.status", res.Status),
+			attribute.Int("gen_ai.usage.input_tokens", res.Metrics.InputTokens),
+			attribute.Int("gen_ai.usage.output_tokens", res.Metrics.OutputTokens),
+			attribute.Int("sympozium.attempts", res.Attempts),
+		)
+		if res.Status != "success" {
+			runSpan.SetAttributes(attribute.String("error.type", res.ErrorCode))
+			runSpan.SetStatus(codes.Error, res.Error)
+		}
+		runSpan.End()
+
+		ctx, cancel := context.WithTimeout(context.Background(), tracingFlushTimeout)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			log.Printf("failed to flush traces: %v", err)
+		}
+	})
+}
+
+// llmCallSpan covers one provider request, retries included. Its
+// llm.http_attempt children are started by retryTransport.
+type llmCallSpan struct {
+	span          trace.Span
+	startAttempts int64
+}
+
+// startLLMCall starts the span for a provider request in round-trip
+// iteration of the tool-call loop. The request must be sent with the
+// returned context.
+func startLLMCall(ctx context.Context, iteration int) (context.Context, *llmCallSpan) {
+	ctx, span := tracer.Start(ctx, "llm.request", trace.WithAttributes(attribute.Int("sympozium.iteration", iteration)))
+	return ctx, &llmCallSpan{span: span, startAttempts: providerAttempts.Load()}
+}
+
+// end records the tokens the request used, or its error, and ends the
+// span.
+func (s *llmCallSpan) end(inputTokens, outputTokens int, err error) {
+	attempts := providerAttempts.Load() - s.startAttempts
+	s.span.SetAttributes(
+		attribute.Int("gen_ai.usage.input_tokens", inputTokens),
+		attribute.Int("gen_ai.usage.output_tokens", outputTokens),
+		attribute.Int64("sympozium.retry_count", max(attempts-1, 0)),
+	)
+	if err != nil {
+		s.span.RecordError(err)
+		s.span.SetStatus(codes.Error, err.Error())
+	}
+	s.span.End()
+}
+
+// startAttemptSpan starts the span for one HTTP attempt of a provider
+// request; retry is 0 for the first attempt.
+func startAttemptSpan(ctx context.Context, req *http.Request, retry int) (context.Context, trace.Span) {
+	return tracer.Start(ctx, "llm.http_attempt", trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attribute.String("http.request.method", req.Method),
+		attribute.String("server.address", req.URL.Host),
+		attribute.Int("sympozium.retry_count", retry),
+	))
+}
+
+// endAttemptSpan records the attempt's status code or transport error and
+// ends its span.
+func endAttemptSpan(span trace.Span, resp *http.Response, err error) {
+	switch {
+	case err != nil:
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	case resp != nil:
+		span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+		if resp.StatusCode >= 400 {
+			span.SetStatus(codes.Error, resp.Status)
+		}
+	}
+	span.End()
+}
+
+// startToolSpan starts the span for executing a tool call.
+func startToolSpan(name, id string) trace.Span {
+	_, span := tracer.Start(traceCtx, "tool.execute", trace.WithAttributes(
+		attribute.String("gen_ai.tool.name", name),
+		attribute.String("gen_ai.tool.call.id", id),
+	))
+	return span
+}
+
+// endToolSpan marks a failed tool call's span as an error and ends it.
+func endToolSpan(span trace.Span, output string, isErr bool) {
+	if isErr {
+		span.SetStatus(codes.Error, truncateRedacted(output, 200))
+	}
+	span.End()
+}
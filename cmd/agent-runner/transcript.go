@@ -0,0 +1,244 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/alexsjones/sympozium/internal/ipcfile"
+)
+
+const (
+	transcriptFile = "transcript.jsonl"
+	// defaultTranscriptMaxBytes is the default TRANSCRIPT_MAX_BYTES.
+	defaultTranscriptMaxBytes = 10 << 20
+)
+
+// Record types in transcript.jsonl.
+const (
+	transcriptRequest    = "request"
+	transcriptResponse   = "response"
+	transcriptToolCall   = "tool_call"
+	transcriptToolResult = "tool_result"
+	transcriptRetry      = "retry"
+	transcriptError      = "error"
+	// transcriptTruncated is the first record once older records were
+	// dropped to stay under TRANSCRIPT_MAX_BYTES.
+	transcriptTruncated = "truncated"
+)
+
+// transcriptRecord is one line of transcript.jsonl. Type says which of the
+// other fields are set.
+type transcriptRecord struct {
+	// SchemaVersion is outputSchemaVersion.
+	SchemaVersion int       `json:"schemaVersion"`
+	Type          string    `json:"type"`
+	Time          time.Time `json:"time"`
+
+	// request: the provider request as sent, messages and parameters
+	// included, with credential headers redacted.
+	Request *savedRequest `json:"request,omitempty"`
+
+	// response: one model turn of the tool-call loop.
+	Iteration    *int   `json:"iteration,omitempty"`
+	Content      string `json:"content,omitempty"`
+	FinishReason string `json:"finishReason,omitempty"`
+	InputTokens  int    `json:"inputTokens,omitempty"`
+	OutputTokens int    `json:"outputTokens,omitempty"`
+
+	// tool_call and tool_result.
+	ToolID    string `json:"toolId,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Arguments string `json:"arguments,omitempty"`
+	Output    string `json:"output,omitempty"`
+	IsError   bool   `json:"isError,omitempty"`
+
+	// retry: the failed attempt and the wait before the next one.
+	Retry      int    `json:"retry,omitempty"`
+	StatusCode int    `json:"statusCode,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+	Wait       string `json:"wait,omitempty"`
+
+	// error.
+	ErrorCode string `json:"errorCode,omitempty"`
+	Error     string `json:"error,omitempty"`
+
+	// truncated: how much was dropped so far.
+	DroppedRecords int `json:"droppedRecords,omitempty"`
+	DroppedBytes   int `json:"droppedBytes,omitempty"`
+}
+
+// transcriptWriter appends records to transcript.jsonl as they happen, each
+// line with a single write. Once the file would exceed maxBytes the oldest
+// records are dropped and the file is rewritten, starting with a truncated
+// record. Every line is redacted before it is written. Methods on a nil
+// writer do nothing.
+type transcriptWriter struct {
+	path     string
+	maxBytes int
+
+	mu             sync.Mutex
+	lines          [][]byte // the records in the file, newline included
+	size           int
+	droppedRecords int
+	droppedBytes   int
+	failed         bool
+}
+
+// transcript records this run when TRANSCRIPT=true.
+var transcript *transcriptWriter
+
+// loadTranscript returns the transcript writer for TRANSCRIPT=true, or nil.
+func loadTranscript(path string) (*transcriptWriter, error) {
+	if getEnv("TRANSCRIPT", "") != "true" {
+		return nil, nil
+	}
+	maxBytes := defaultTranscriptMaxBytes
+	if v := getEnv("TRANSCRIPT_MAX_BYTES", ""); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid TRANSCRIPT_MAX_BYTES %q", v)
+		}
+		maxBytes = n
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		return nil, err
+	}
+	return &transcriptWriter{path: path, maxBytes: maxBytes}, nil
+}
+
+// record appends rec, stamping its time.
+func (t *transcriptWriter) record(rec transcriptRecord) {
+	if t == nil {
+		return
+	}
+	rec.SchemaVersion, rec.Time = outputSchemaVersion, time.Now().UTC()
+	data, err := json.Marshal(rec)
+	if err != nil {
+		log.Printf("failed to encode %s transcript record: %v", rec.Type, err)
+		return
+	}
+	line := append(redactBytes(data), '\n')
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.lines = append(t.lines, line)
+	t.size += len(line)
+	if t.droppedRecords == 0 && t.size <= t.maxBytes {
+		t.appendLocked(line)
+		return
+	}
+	// Drop the oldest records until the rest fit after the marker. A record
+	// larger than maxBytes on its own is dropped too.
+	marker := t.markerLocked()
+	for len(t.lines) > 0 && t.size+len(marker) > t.maxBytes {
+		t.droppedRecords++
+		t.droppedBytes += len(t.lines[0])
+		t.size -= len(t.lines[0])
+		t.lines = t.lines[1:]
+		marker = t.markerLocked()
+	}
+	t.rewriteLocked(marker)
+}
+
+// markerLocked encodes the truncated record for what was dropped so far.
+func (t *transcriptWriter) markerLocked() []byte {
+	data, _ := json.Marshal(transcriptRecord{
+		SchemaVersion:  outputSchemaVersion,
+		Type:           transcriptTruncated,
+		Time:           time.Now().UTC(),
+		DroppedRecords: t.droppedRecords,
+		DroppedBytes:   t.droppedBytes,
+	})
+	return append(data, '\n')
+}
+
+// appendLocked appends line to the file with a single write.
+func (t *transcriptWriter) appendLocked(line []byte) {
+	f, err := os.OpenFile(t.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err == nil {
+		_, err = f.Write(line)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}
+	t.warnLocked(err)
+}
+
+// rewriteLocked atomically replaces the file with marker followed by the
+// records still kept.
+func (t *transcriptWriter) rewriteLocked(marker []byte) {
+	data := append(marker, bytes.Join(t.lines, nil)...)
+	t.warnLocked(ipcfile.Write(t.path, data, 0o644))
+}
+
+// warnLocked logs the first write failure; the run goes on without a
+// complete transcript.
+func (t *transcriptWriter) warnLocked(err error) {
+	if err != nil && !t.failed {
+		t.failed = true
+		log.Printf("WARNING: failed to write %s: %v", t.path, err)
+	}
+}
+
+// response records one model turn.
+func (t *transcriptWriter) response(iteration int, content, finishReason string, inputTokens, outputTokens int) {
+	t.record(transcriptRecord{
+		Type:         transcriptResponse,
+		Iteration:    &iteration,
+		Content:      content,
+		FinishReason: finishReason,
+		InputTokens:  inputTokens,
+		OutputTokens: outputTokens,
+	})
+}
+
+// toolCall records a tool call the model made.
+func (t *transcriptWriter) toolCall(id, name, arguments string) {
+	t.record(transcriptRecord{Type: transcriptToolCall, ToolID: id, Name: name, Arguments: arguments})
+}
+
+// toolResult records a tool call's output as sent back to the model.
+func (t *transcriptWriter) toolResult(id, name, output string, isErr bool) {
+	t.record(transcriptRecord{Type: transcriptToolResult, ToolID: id, Name: name, Output: output, IsError: isErr})
+}
+
+// retry records a failed attempt that retryTransport is about to retry.
+func (t *transcriptWriter) retry(retry int, resp *http.Response, reason string, wait time.Duration) {
+	rec := transcriptRecord{Type: transcriptRetry, Retry: retry, Reason: reason, Wait: wait.Round(time.Millisecond).String()}
+	if resp != nil {
+		rec.StatusCode = resp.StatusCode
+	}
+	t.record(rec)
+}
+
+// fail records the error the run ended with.
+func (t *transcriptWriter) fail(code, msg string) {
+	t.record(transcriptRecord{Type: transcriptError, ErrorCode: code, Error: msg})
+}
+
+// transcriptRecorder records each provider request in the transcript
+// before sending it. It wraps retryTransport, so retries of a request are
+// recorded as retry records rather than repeated requests.
+type transcriptRecorder struct {
+	base http.RoundTripper
+}
+
+func (t *transcriptRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	saved, err := captureRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	transcript.record(transcriptRecord{Type: transcriptRequest, Request: &saved})
+	return t.base.RoundTrip(req)
+}
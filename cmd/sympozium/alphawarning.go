@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeversion "k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+
+	sympoziumv1alpha1 "github.com/alexsjones/sympozium/api/v1alpha1"
+)
+
+// noAlphaWarning suppresses the alpha API notices; SYMPOZIUM_NO_ALPHA_WARNING
+// does the same.
+var noAlphaWarning bool
+
+// alphaWarningsEnabled reports whether the alpha API notices may be printed.
+func alphaWarningsEnabled() bool {
+	return !noAlphaWarning && os.Getenv("SYMPOZIUM_NO_ALPHA_WARNING") == ""
+}
+
+// printAlphaNotice prints the one-time reminder that the API is alpha, then
+// records it under the user config dir so later commands stay quiet.
+func printAlphaNotice() {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return
+	}
+	alphaNotice(os.Stderr, filepath.Join(dir, "sympozium", "alpha-notice"))
+}
+
+// alphaNotice writes the notice to out unless markerPath exists, and creates
+// the marker. The notice is skipped, rather than repeated, when the marker
+// cannot be written.
+func alphaNotice(out io.Writer, markerPath string) {
+	if _, err := os.Stat(markerPath); err == nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(markerPath), 0o755); err != nil {
+		return
+	}
+	if err := os.WriteFile(markerPath, []byte(time.Now().UTC().Format(time.RFC3339)+"\n"), 0o644); err != nil {
+		return
+	}
+	fmt.Fprintf(out, "Note: the Sympozium API (%s) is alpha and may change incompatibly between releases.\n", sympoziumv1alpha1.GroupVersion)
+	fmt.Fprintln(out, "      This notice is shown once; use --no-alpha-warning or SYMPOZIUM_NO_ALPHA_WARNING=1 to silence API warnings.")
+}
+
+// warnOnNewerAPIVersion warns when the cluster serves a newer version of the
+// sympozium.ai API than the one this CLI is built against. Discovery failures
+// are ignored; the warning is best-effort like warnOnVersionSkew.
+func warnOnNewerAPIVersion(config *rest.Config) {
+	if config == nil {
+		return
+	}
+	cfg := rest.CopyConfig(config)
+	cfg.Timeout = 3 * time.Second
+	dc, err := discovery.NewDiscoveryClientForConfig(cfg)
+	if err != nil {
+		return
+	}
+	groups, err := dc.ServerGroups()
+	if err != nil {
+		return
+	}
+	if msg := newerAPIVersionWarning(groups, sympoziumv1alpha1.GroupVersion.Group, sympoziumv1alpha1.GroupVersion.Version); msg != "" {
+		fmt.Fprintln(os.Stderr, msg)
+	}
+}
+
+// newerAPIVersionWarning returns a warning naming the newest version of
+// group served by the cluster when it is newer than current, or "".
+func newerAPIVersionWarning(groups *metav1.APIGroupList, group, current string) string {
+	if groups == nil {
+		return ""
+	}
+	newest := current
+	for _, g := range groups.Groups {
+		if g.Name != group {
+			continue
+		}
+		for _, v := range g.Versions {
+			if kubeversion.CompareKubeAwareVersionStrings(v.Version, newest) > 0 {
+				newest = v.Version
+			}
+		}
+	}
+	if newest == current {
+		return ""
+	}
+	return fmt.Sprintf("Warning: the cluster serves %s/%s, newer than this CLI's %s; fields added since %s may be missing or dropped. Upgrade the CLI.",
+		group, newest, current, current)
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAlphaNoticeShownOnce(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "sympozium", "alpha-notice")
+
+	var out bytes.Buffer
+	alphaNotice(&out, marker)
+	if !strings.Contains(out.String(), "sympozium.ai/v1alpha1) is alpha") {
+		t.Errorf("first call printed %q", out.String())
+	}
+	if _, err := os.Stat(marker); err != nil {
+		t.Fatalf("marker not written: %v", err)
+	}
+
+	out.Reset()
+	alphaNotice(&out, marker)
+	if out.Len() != 0 {
+		t.Errorf("second call printed %q", out.String())
+	}
+}
+
+func TestAlphaWarningsEnabled(t *testing.T) {
+	t.Cleanup(func() { noAlphaWarning = false })
+
+	t.Setenv("SYMPOZIUM_NO_ALPHA_WARNING", "")
+	if !alphaWarningsEnabled() {
+		t.Error("warnings disabled by default")
+	}
+	noAlphaWarning = true
+	if alphaWarningsEnabled() {
+		t.Error("--no-alpha-warning did not disable warnings")
+	}
+	noAlphaWarning = false
+	t.Setenv("SYMPOZIUM_NO_ALPHA_WARNING", "1")
+	if alphaWarningsEnabled() {
+		t.Error("SYMPOZIUM_NO_ALPHA_WARNING did not disable warnings")
+	}
+}
+
+func TestNewerAPIVersionWarning(t *testing.T) {
+	groups := func(versions ...string) *metav1.APIGroupList {
+		g := metav1.APIGroup{Name: "sympozium.ai"}
+		for _, v := range versions {
+			g.Versions = append(g.Versions, metav1.GroupVersionForDiscovery{Version: v})
+		}
+		return &metav1.APIGroupList{Groups: []metav1.APIGroup{{Name: "apps", Versions: []metav1.GroupVersionForDiscovery{{Version: "v1"}}}, g}}
+	}
+
+	for _, tc := range []struct {
+		name     string
+		groups   *metav1.APIGroupList
+		wantNewV string
+	}{
+		{"same version", groups("v1alpha1"), ""},
+		{"no group", &metav1.APIGroupList{}, ""},
+		{"nil list", nil, ""},
+		{"newer alpha", groups("v1alpha1", "v1alpha2"), "v1alpha2"},
+		{"beta and ga", groups("v1beta1", "v1alpha1", "v1"), "v1"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := newerAPIVersionWarning(tc.groups, "sympozium.ai", "v1alpha1")
+			if tc.wantNewV == "" {
+				if got != "" {
+					t.Errorf("unexpected warning %q", got)
+				}
+				return
+			}
+			if !strings.Contains(got, "sympozium.ai/"+tc.wantNewV+",") {
+				t.Errorf("warning %q does not name %s", got, tc.wantNewV)
+			}
+		})
+	}
+}
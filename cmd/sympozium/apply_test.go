@@ -0,0 +1,120 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const testInstanceYAML = `apiVersion: sympozium.ai/v1alpha1
+kind: SympoziumInstance
+metadata:
+  name: alpha
+spec:
+  agents:
+    default:
+      model: gpt-4o
+`
+
+const testPolicyYAML = `apiVersion: sympozium.ai/v1alpha1
+kind: SympoziumPolicy
+metadata:
+  name: strict
+  namespace: team-a
+`
+
+func testScheme(t *testing.T) *runtime.Scheme {
+	t.Helper()
+	s, err := newCLIScheme()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestLoadApplyObjects_FilesDirsAndStdin(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a-instance.yaml"), []byte(testInstanceYAML), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "b-policy.yml"), []byte(testPolicyYAML), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("not a manifest"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	stdin := strings.NewReader("---\n" + testPolicyYAML + "---\n" + testInstanceYAML)
+	objs, err := loadApplyObjects([]string{dir, "-"}, stdin, testScheme(t))
+	if err != nil {
+		t.Fatalf("loadApplyObjects: %v", err)
+	}
+
+	var got []string
+	for _, o := range objs {
+		got = append(got, o.obj.GetKind()+"/"+o.obj.GetName())
+	}
+	want := []string{
+		"SympoziumInstance/alpha",
+		"SympoziumPolicy/strict",
+		"SympoziumPolicy/strict",
+		"SympoziumInstance/alpha",
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("objects = %v, want %v", got, want)
+	}
+	if !strings.HasPrefix(objs[2].source, "stdin") {
+		t.Errorf("source = %q, want stdin prefix", objs[2].source)
+	}
+	if ns := objs[1].obj.GetNamespace(); ns != "team-a" {
+		t.Errorf("explicit namespace not preserved: %q", ns)
+	}
+}
+
+func TestDecodeSympoziumDocuments_Rejects(t *testing.T) {
+	tests := map[string]struct {
+		doc     string
+		wantErr string
+	}{
+		"foreign group": {
+			doc:     "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\n",
+			wantErr: "is not a Sympozium resource",
+		},
+		"core kind": {
+			doc:     "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cfg\n",
+			wantErr: "is not a Sympozium resource",
+		},
+		"unknown kind": {
+			doc:     "apiVersion: sympozium.ai/v1alpha1\nkind: Widget\nmetadata:\n  name: w\n",
+			wantErr: "unknown kind Widget",
+		},
+		"unknown field": {
+			doc:     "apiVersion: sympozium.ai/v1alpha1\nkind: SympoziumPolicy\nmetadata:\n  name: p\nspec:\n  notAField: true\n",
+			wantErr: "invalid SympoziumPolicy",
+		},
+		"missing kind": {
+			doc:     "metadata:\n  name: x\n",
+			wantErr: "apiVersion and kind are required",
+		},
+		"missing name": {
+			doc:     "apiVersion: sympozium.ai/v1alpha1\nkind: SympoziumPolicy\nmetadata: {}\n",
+			wantErr: "missing metadata.name",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// A valid document first ensures one bad document fails the batch.
+			stream := testPolicyYAML + "---\n" + tt.doc
+			_, err := decodeSympoziumDocuments("test.yaml", []byte(stream), testScheme(t))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err = %v, want containing %q", err, tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), "document 2") {
+				t.Errorf("error does not identify the document: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/types"
+
+	sympoziumv1alpha1 "github.com/alexsjones/sympozium/api/v1alpha1"
+)
+
+// ── Shell completion for finite flag and argument values ────────────────────
+
+// agentRunPhases are the phases an AgentRun moves through, in order.
+var agentRunPhases = []suggestion{
+	{string(sympoziumv1alpha1.AgentRunPhasePending), "Waiting for the agent pod to start"},
+	{string(sympoziumv1alpha1.AgentRunPhaseRunning), "The agent is working on the task"},
+	{string(sympoziumv1alpha1.AgentRunPhaseSucceeded), "The agent finished the task"},
+	{string(sympoziumv1alpha1.AgentRunPhaseFailed), "The agent or its pod failed"},
+}
+
+// featureGateCatalog lists the feature gates Sympozium knows about. Policies
+// may carry other gates; those are completed from the policy itself.
+var featureGateCatalog = []suggestion{
+	{"browser-automation", "Allow agents to drive a headless browser"},
+	{"code-execution", "Allow agents to run code in the sandbox"},
+	{"file-access", "Allow agents to read and write workspace files"},
+	{"sub-agents", "Allow agents to spawn sub-agent runs"},
+}
+
+// completeSuggestions returns the suggestions matching toComplete in Cobra's
+// "value\tdescription" form.
+func completeSuggestions(items []suggestion, toComplete string) []string {
+	var out []string
+	for _, s := range items {
+		if strings.HasPrefix(s.text, toComplete) {
+			out = append(out, s.text+"\t"+s.desc)
+		}
+	}
+	return out
+}
+
+// completeRunPhases completes --phase.
+func completeRunPhases(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return completeSuggestions(agentRunPhases, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeProviders completes --provider.
+func completeProviders(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return completeSuggestions(providerSuggestions, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeFeatureGates completes the feature argument of features
+// enable/disable. With --policy already on the command line, gates set on
+// that policy are offered too, annotated with their current value. Cluster
+// errors are ignored so completion degrades to the catalog.
+func completeFeatureGates(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	gates := append([]suggestion(nil), featureGateCatalog...)
+	if policyName, _ := cmd.Flags().GetString("policy"); policyName != "" && completionClientReady() {
+		var pol sympoziumv1alpha1.SympoziumPolicy
+		if err := k8sClient.Get(context.Background(), types.NamespacedName{Name: policyName, Namespace: namespace}, &pol); err == nil {
+			gates = mergeFeatureGates(gates, pol.Spec.FeatureGates)
+		}
+	}
+	return completeSuggestions(gates, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// mergeFeatureGates adds the gates set on a policy to the catalog, noting
+// each one's current value, and returns the result sorted by name.
+func mergeFeatureGates(catalog []suggestion, set map[string]bool) []suggestion {
+	byName := map[string]int{}
+	for i, s := range catalog {
+		byName[s.text] = i
+	}
+	for name, enabled := range set {
+		state := fmt.Sprintf(" (currently %s)", onOff(enabled))
+		if i, ok := byName[name]; ok {
+			catalog[i].desc += state
+			continue
+		}
+		catalog = append(catalog, suggestion{name, "Set on this policy" + state})
+	}
+	sort.Slice(catalog, func(i, j int) bool { return catalog[i].text < catalog[j].text })
+	return catalog
+}
+
+func onOff(b bool) string {
+	if b {
+		return "on"
+	}
+	return "off"
+}
+
+// completionClientReady makes sure k8sClient is set. Completion requests
+// (`__complete`) skip PersistentPreRunE, so the client is created here.
+func completionClientReady() bool {
+	if k8sClient != nil {
+		return true
+	}
+	return initClient() == nil
+}
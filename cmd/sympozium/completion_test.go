@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sympoziumv1alpha1 "github.com/alexsjones/sympozium/api/v1alpha1"
+)
+
+// runCompletion invokes Cobra's hidden __complete command and returns the
+// completion values (descriptions stripped) and the directive line.
+func runCompletion(t *testing.T, args ...string) ([]string, string) {
+	t.Helper()
+	root := &cobra.Command{Use: "sympozium"}
+	root.PersistentFlags().StringVarP(&namespace, "namespace", "n", "default", "Kubernetes namespace")
+	root.AddCommand(newInstancesCmd(), newRunsCmd(), newFeaturesCmd())
+
+	var out bytes.Buffer
+	root.SetOut(&out)
+	root.SetErr(&bytes.Buffer{})
+	root.SetArgs(append([]string{cobra.ShellCompRequestCmd}, args...))
+	if err := root.Execute(); err != nil {
+		t.Fatalf("__complete %v: %v", args, err)
+	}
+
+	var values []string
+	var directive string
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		if strings.HasPrefix(line, ":") {
+			directive = line
+			continue
+		}
+		value, _, _ := strings.Cut(line, "\t")
+		values = append(values, value)
+	}
+	return values, directive
+}
+
+func withCompletionClient(t *testing.T, objs ...*sympoziumv1alpha1.SympoziumPolicy) {
+	t.Helper()
+	b := fake.NewClientBuilder().WithScheme(testScheme(t))
+	for _, o := range objs {
+		b = b.WithObjects(o)
+	}
+	prevClient, prevNS := k8sClient, namespace
+	k8sClient = b.Build()
+	t.Cleanup(func() { k8sClient, namespace = prevClient, prevNS })
+}
+
+func TestCompletion_Phase(t *testing.T) {
+	got, directive := runCompletion(t, "runs", "list", "--phase", "")
+	want := []string{"Pending", "Running", "Succeeded", "Failed"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("phases = %v, want %v", got, want)
+	}
+	if directive != ":4" { // ShellCompDirectiveNoFileComp
+		t.Errorf("directive = %q, want :4", directive)
+	}
+
+	if got, _ := runCompletion(t, "runs", "list", "--phase", "S"); strings.Join(got, ",") != "Succeeded" {
+		t.Errorf("prefix S = %v, want [Succeeded]", got)
+	}
+}
+
+func TestCompletion_Provider(t *testing.T) {
+	got, _ := runCompletion(t, "instances", "list", "--provider", "a")
+	if strings.Join(got, ",") != "anthropic,azure-openai" {
+		t.Errorf("providers = %v, want [anthropic azure-openai]", got)
+	}
+}
+
+func TestCompletion_FeatureGates(t *testing.T) {
+	withCompletionClient(t, &sympoziumv1alpha1.SympoziumPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "strict", Namespace: "default"},
+		Spec: sympoziumv1alpha1.SympoziumPolicySpec{
+			FeatureGates: map[string]bool{"code-execution": false, "gpu-access": true},
+		},
+	})
+
+	got, _ := runCompletion(t, "features", "enable", "")
+	if strings.Join(got, ",") != "browser-automation,code-execution,file-access,sub-agents" {
+		t.Errorf("without --policy = %v, want the catalog", got)
+	}
+
+	got, _ = runCompletion(t, "features", "disable", "--policy", "strict", "")
+	if strings.Join(got, ",") != "browser-automation,code-execution,file-access,gpu-access,sub-agents" {
+		t.Errorf("with --policy = %v, want the catalog plus gpu-access", got)
+	}
+
+	// A policy that does not exist falls back to the catalog.
+	got, _ = runCompletion(t, "features", "enable", "--policy", "missing", "s")
+	if strings.Join(got, ",") != "sub-agents" {
+		t.Errorf("missing policy = %v, want [sub-agents]", got)
+	}
+
+	// Only the first argument is completed.
+	if got, _ := runCompletion(t, "features", "enable", "sub-agents", ""); len(got) != 0 {
+		t.Errorf("second argument = %v, want nothing", got)
+	}
+}
+
+func TestMergeFeatureGates_AnnotatesState(t *testing.T) {
+	got := mergeFeatureGates([]suggestion{{"code-execution", "Run code"}}, map[string]bool{"code-execution": true, "alpha": false})
+	if len(got) != 2 || got[0].text != "alpha" || got[0].desc != "Set on this policy (currently off)" ||
+		got[1].desc != "Run code (currently on)" {
+		t.Errorf("merged = %+v", got)
+	}
+}
+
+func TestFilterRunsByPhase(t *testing.T) {
+	runs := []sympoziumv1alpha1.AgentRun{
+		{ObjectMeta: metav1.ObjectMeta{Name: "a"}, Status: sympoziumv1alpha1.AgentRunStatus{Phase: sympoziumv1alpha1.AgentRunPhaseFailed}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "b"}, Status: sympoziumv1alpha1.AgentRunStatus{Phase: sympoziumv1alpha1.AgentRunPhaseRunning}},
+	}
+	got, err := filterRunsByPhase(runs, "failed")
+	if err != nil || len(got) != 1 || got[0].Name != "a" {
+		t.Errorf("filter failed = %v, %v", got, err)
+	}
+	if _, err := filterRunsByPhase(runs, "Done"); err == nil || !strings.Contains(err.Error(), "Succeeded") {
+		t.Errorf("unknown phase error = %v, want the known phases listed", err)
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"sigs.k8s.io/yaml"
+)
+
+// ── CLI configuration file ──────────────────────────────────────────────────
+
+// cliConfig is the optional user configuration read from
+// $XDG_CONFIG_HOME/sympozium/config.yaml (or SYMPOZIUM_CONFIG).
+type cliConfig struct {
+	// SkillIndex is the URL of the SkillPack registry index used by
+	// `skills search` and `skills install`.
+	SkillIndex string `json:"skillIndex,omitempty"`
+}
+
+// cliConfigPath returns the path of the CLI config file.
+func cliConfigPath() (string, error) {
+	if p := os.Getenv("SYMPOZIUM_CONFIG"); p != "" {
+		return p, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "sympozium", "config.yaml"), nil
+}
+
+// loadCLIConfig reads the CLI config file. A missing file yields an empty
+// config.
+func loadCLIConfig() (cliConfig, error) {
+	var cfg cliConfig
+	path, err := cliConfigPath()
+	if err != nil {
+		return cfg, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+	if err := yaml.UnmarshalStrict(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return cfg, nil
+}
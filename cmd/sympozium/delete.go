@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	sympoziumv1alpha1 "github.com/alexsjones/sympozium/api/v1alpha1"
+)
+
+// ── delete (instances, runs, policies, skills) ──────────────────────────────
+
+func newDeleteCmd(kind, plural string) *cobra.Command {
+	var (
+		selector string
+		yes      bool
+	)
+	cmd := &cobra.Command{
+		Use:   "delete [name...] | -l selector",
+		Short: "Delete " + kind + "s by name or label selector",
+		Long: `Delete ` + kind + `s in --namespace by name, or every ` + kind + ` matching a
+label selector with -l. Selector deletes list the matching objects and ask
+for confirmation first (skip with --yes). Every object is attempted; failures
+are reported together at the end.`,
+		Example: "  sympozium " + plural + " delete my-" + strings.ToLower(kind) + "\n  sympozium " + plural + " delete -l team=foo\n  sympozium " + plural + " delete -l 'team in (foo,bar)' --yes",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if (selector == "") == (len(args) == 0) {
+				return fmt.Errorf("give one or more names or a -l/--selector, not both")
+			}
+			ctx := context.Background()
+			gvk := sympoziumv1alpha1.GroupVersion.WithKind(kind)
+			out := cmd.OutOrStdout()
+			names := args
+			if selector != "" {
+				var err error
+				names, err = listNamesBySelector(ctx, k8sClient, gvk, namespace, selector)
+				if err != nil {
+					return err
+				}
+				if len(names) == 0 {
+					fmt.Fprintf(out, "No %s in namespace %s match %q.\n", plural, namespace, selector)
+					return nil
+				}
+				fmt.Fprintf(out, "%d %s in namespace %s match %q:\n", len(names), plural, namespace, selector)
+				for _, n := range names {
+					fmt.Fprintf(out, "  %s\n", n)
+				}
+				if !yes && !promptYNFrom(bufio.NewReader(cmd.InOrStdin()), out, "Delete them?", false) {
+					return fmt.Errorf("delete aborted")
+				}
+			}
+			return deleteObjects(ctx, k8sClient, gvk, namespace, names, out)
+		},
+	}
+	cmd.Flags().StringVarP(&selector, "selector", "l", "", "Delete every "+kind+" matching this label selector (e.g. team=foo)")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Do not ask for confirmation before a selector delete")
+	return cmd
+}
+
+// listNamesBySelector returns the names of the objects of gvk in ns that
+// match selector.
+func listNamesBySelector(ctx context.Context, c client.Client, gvk schema.GroupVersionKind, ns, selector string) ([]string, error) {
+	sel, err := labels.Parse(selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector %q: %w", selector, err)
+	}
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+	if err := c.List(ctx, list, client.InNamespace(ns), client.MatchingLabelsSelector{Selector: sel}); err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(list.Items))
+	for _, item := range list.Items {
+		names = append(names, item.GetName())
+	}
+	return names, nil
+}
+
+// deleteObjects deletes each named object of gvk in ns, printing one line
+// per deletion. It attempts every name and returns the failures joined.
+func deleteObjects(ctx context.Context, c client.Client, gvk schema.GroupVersionKind, ns string, names []string, out io.Writer) error {
+	var errs []error
+	for _, name := range names {
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(gvk)
+		obj.SetNamespace(ns)
+		obj.SetName(name)
+		if err := c.Delete(ctx, obj); err != nil {
+			errs = append(errs, fmt.Errorf("%s/%s: %w", strings.ToLower(gvk.Kind), name, err))
+			continue
+		}
+		fmt.Fprintf(out, "%s/%s deleted\n", strings.ToLower(gvk.Kind), name)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%d of %d deletion(s) failed:\n%w", len(errs), len(names), errors.Join(errs...))
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	sympoziumv1alpha1 "github.com/alexsjones/sympozium/api/v1alpha1"
+)
+
+func deleteTestInstance(name string, labels map[string]string) *sympoziumv1alpha1.SympoziumInstance {
+	return &sympoziumv1alpha1.SympoziumInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: labels},
+	}
+}
+
+func TestListNamesBySelector(t *testing.T) {
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(
+		deleteTestInstance("a", map[string]string{"team": "foo"}),
+		deleteTestInstance("b", map[string]string{"team": "bar"}),
+		deleteTestInstance("c", map[string]string{"team": "foo", "tier": "gold"}),
+	).Build()
+	gvk := sympoziumv1alpha1.GroupVersion.WithKind("SympoziumInstance")
+
+	for selector, want := range map[string]string{
+		"team=foo":           "a,c",
+		"team in (foo,bar)":  "a,b,c",
+		"team=foo,tier=gold": "c",
+		"team=baz":           "",
+	} {
+		names, err := listNamesBySelector(context.Background(), c, gvk, "default", selector)
+		if err != nil {
+			t.Fatalf("%s: %v", selector, err)
+		}
+		if got := strings.Join(names, ","); got != want {
+			t.Errorf("%s: names = %q, want %q", selector, got, want)
+		}
+	}
+
+	if _, err := listNamesBySelector(context.Background(), c, gvk, "default", "team in foo"); err == nil {
+		t.Error("expected an error for an invalid selector")
+	}
+}
+
+func TestDeleteObjects_AggregatesErrors(t *testing.T) {
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(
+		deleteTestInstance("a", nil),
+		deleteTestInstance("b", nil),
+		deleteTestInstance("c", nil),
+	).WithInterceptorFuncs(interceptor.Funcs{
+		Delete: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.DeleteOption) error {
+			if obj.GetName() == "b" {
+				return errors.New("forbidden")
+			}
+			return c.Delete(ctx, obj, opts...)
+		},
+	}).Build()
+	gvk := sympoziumv1alpha1.GroupVersion.WithKind("SympoziumInstance")
+
+	var out bytes.Buffer
+	err := deleteObjects(context.Background(), c, gvk, "default", []string{"a", "b", "c", "missing"}, &out)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, want := range []string{"2 of 4 deletion(s) failed", "sympoziuminstance/b: forbidden", "sympoziuminstance/missing:"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+	if got, want := out.String(), "sympoziuminstance/a deleted\nsympoziuminstance/c deleted\n"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+
+	var left sympoziumv1alpha1.SympoziumInstanceList
+	if err := c.List(context.Background(), &left); err != nil {
+		t.Fatal(err)
+	}
+	if len(left.Items) != 1 || left.Items[0].Name != "b" {
+		t.Errorf("remaining instances = %+v", left.Items)
+	}
+}
+
+func TestDeleteCmd_SelectorConfirmation(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		args    []string
+		stdin   string
+		wantErr bool
+		left    int
+	}{
+		{name: "declined", args: []string{"-l", "team=foo"}, stdin: "n\n", wantErr: true, left: 3},
+		{name: "confirmed", args: []string{"-l", "team=foo"}, stdin: "y\n", left: 1},
+		{name: "yes flag", args: []string{"-l", "team=foo", "--yes"}, left: 1},
+		{name: "by name", args: []string{"a"}, left: 2},
+		{name: "name and selector", args: []string{"a", "-l", "team=foo"}, wantErr: true, left: 3},
+		{name: "neither", wantErr: true, left: 3},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(
+				deleteTestInstance("a", map[string]string{"team": "foo"}),
+				deleteTestInstance("b", map[string]string{"team": "bar"}),
+				deleteTestInstance("c", map[string]string{"team": "foo"}),
+			).Build()
+			oldClient, oldNS := k8sClient, namespace
+			k8sClient, namespace = c, "default"
+			t.Cleanup(func() { k8sClient, namespace = oldClient, oldNS })
+
+			cmd := newDeleteCmd("SympoziumInstance", "instances")
+			cmd.SetArgs(tc.args)
+			cmd.SetIn(strings.NewReader(tc.stdin))
+			var out bytes.Buffer
+			cmd.SetOut(&out)
+			cmd.SetErr(&out)
+			err := cmd.Execute()
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("err = %v, wantErr %v\n%s", err, tc.wantErr, out.String())
+			}
+
+			var left sympoziumv1alpha1.SympoziumInstanceList
+			if err := c.List(context.Background(), &left); err != nil {
+				t.Fatal(err)
+			}
+			if len(left.Items) != tc.left {
+				t.Errorf("%d instances left, want %d\n%s", len(left.Items), tc.left, out.String())
+			}
+		})
+	}
+}
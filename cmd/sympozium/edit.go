@@ -0,0 +1,350 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	sympoziumv1alpha1 "github.com/alexsjones/sympozium/api/v1alpha1"
+)
+
+// ── edit (instances, policies, skills) ──────────────────────────────────────
+
+// editHiddenMetadata lists metadata fields left out of the editor buffer.
+// They are restored from the live object before the update is sent.
+var editHiddenMetadata = []string{
+	"uid", "resourceVersion", "generation", "creationTimestamp", "deletionTimestamp",
+	"deletionGracePeriodSeconds", "managedFields", "selfLink",
+}
+
+// launchEditor opens path in the user's editor and waits for it to exit.
+// It is a variable so tests can substitute a scripted editor.
+var launchEditor = func(path string) error {
+	editor := editorCommand()
+	// Run through the shell so editors with arguments ("code --wait") work.
+	cmd := exec.Command("sh", "-c", editor+` "$1"`, "editor", path)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor %q: %w", editor, err)
+	}
+	return nil
+}
+
+// editorCommand returns KUBE_EDITOR, EDITOR, or vi.
+func editorCommand() string {
+	for _, env := range []string{"KUBE_EDITOR", "EDITOR"} {
+		if v := strings.TrimSpace(os.Getenv(env)); v != "" {
+			return v
+		}
+	}
+	return "vi"
+}
+
+func newEditCmd(kind, plural string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "edit [name]",
+		Short: "Edit a " + kind + " in $EDITOR",
+		Long: `Open a ` + kind + ` in $KUBE_EDITOR or $EDITOR (default vi) with status and
+server-set metadata removed. On save the YAML is validated locally (strict
+decoding; kind, name and namespace cannot change) and sent as an update that
+fails if someone else changed the object meanwhile. Invalid edits re-open the
+editor with the error; on a conflict you are offered to re-open your changes
+on top of the latest version. Exiting without changes aborts the edit.
+
+If the API server rejects the change (for example an admission webhook
+denies it), your edited copy is kept in a temporary file.`,
+		Example: "  sympozium " + plural + " edit my-" + strings.ToLower(kind) + "\n  KUBE_EDITOR='code --wait' sympozium " + plural + " edit my-" + strings.ToLower(kind),
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			gvk := sympoziumv1alpha1.GroupVersion.WithKind(kind)
+			e := &objectEditor{
+				client: k8sClient,
+				scheme: k8sClient.Scheme(),
+				in:     bufio.NewReader(os.Stdin),
+				out:    os.Stdout,
+			}
+			return e.edit(context.Background(), gvk, types.NamespacedName{Name: args[0], Namespace: namespace})
+		},
+	}
+}
+
+// objectEditor runs one interactive edit session.
+type objectEditor struct {
+	client client.Client
+	scheme *runtime.Scheme
+	in     *bufio.Reader
+	out    io.Writer
+}
+
+// edit fetches the object, loops through the editor until the change is
+// applied, abandoned or rejected, and reports the outcome.
+func (e *objectEditor) edit(ctx context.Context, gvk schema.GroupVersionKind, key types.NamespacedName) error {
+	live := &unstructured.Unstructured{}
+	live.SetGroupVersionKind(gvk)
+	if err := e.client.Get(ctx, key, live); err != nil {
+		return err
+	}
+
+	base, err := editableView(live.Object)
+	if err != nil {
+		return err
+	}
+	baseYAML, err := yaml.Marshal(base)
+	if err != nil {
+		return err
+	}
+	buffer, baseline := baseYAML, baseYAML
+	notes := []string{}
+
+	for {
+		edited, err := e.openInEditor(editHeader(notes), buffer)
+		if err != nil {
+			return err
+		}
+		if len(bytes.TrimSpace(edited)) == 0 || bytes.Equal(bytes.TrimSpace(edited), bytes.TrimSpace(baseline)) {
+			if len(notes) > 0 {
+				// The user gave up after an error; keep what they had.
+				return e.keepCopy(buffer, fmt.Errorf("edit cancelled"))
+			}
+			fmt.Fprintln(e.out, "Edit cancelled, no changes made.")
+			return nil
+		}
+
+		desired, err := e.validate(edited, live)
+		if err != nil {
+			notes = []string{"The edited file failed validation:", err.Error()}
+			buffer, baseline = edited, edited
+			continue
+		}
+		patch := createMergePatch(base, desired)
+		if len(patch) == 0 {
+			fmt.Fprintln(e.out, "Edit cancelled, no changes made.")
+			return nil
+		}
+
+		update := &unstructured.Unstructured{Object: applyMergePatch(runtime.DeepCopyJSON(live.Object), patch)}
+		err = e.client.Update(ctx, update)
+		switch {
+		case err == nil:
+			fmt.Fprintf(e.out, "%s.%s/%s edited\n", strings.ToLower(gvk.Kind), gvk.Group, key.Name)
+			return nil
+		case apierrors.IsConflict(err):
+			latest := &unstructured.Unstructured{}
+			latest.SetGroupVersionKind(gvk)
+			if getErr := e.client.Get(ctx, key, latest); getErr != nil {
+				return e.keepCopy(edited, getErr)
+			}
+			fmt.Fprintf(e.out, "%s %q was modified after you started editing.\n", gvk.Kind, key.Name)
+			if !promptYNFrom(e.in, e.out, "Re-open the editor with your changes applied to the latest version?", false) {
+				return e.keepCopy(edited, err)
+			}
+			live = latest
+			if base, err = editableView(live.Object); err != nil {
+				return err
+			}
+			if baseline, err = yaml.Marshal(base); err != nil {
+				return err
+			}
+			if buffer, err = yaml.Marshal(applyMergePatch(runtime.DeepCopyJSON(base), patch)); err != nil {
+				return err
+			}
+			notes = []string{"Your changes were re-applied to the latest version of the object.",
+				"Review them (a field you edited may have been changed by someone else) and save to apply."}
+		default:
+			return e.keepCopy(edited, explainAdmissionError(err))
+		}
+	}
+}
+
+// openInEditor writes header and content to a temp file, opens it and
+// returns the saved content with the leading comment block removed.
+func (e *objectEditor) openInEditor(header string, content []byte) ([]byte, error) {
+	f, err := os.CreateTemp("", "sympozium-edit-*.yaml")
+	if err != nil {
+		return nil, err
+	}
+	path := f.Name()
+	defer os.Remove(path)
+	_, err = f.WriteString(header)
+	if err == nil {
+		_, err = f.Write(content)
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := launchEditor(path); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return stripLeadingComments(data), nil
+}
+
+// validate strictly decodes the edited YAML and checks that it still
+// describes the same object.
+func (e *objectEditor) validate(edited []byte, live *unstructured.Unstructured) (map[string]interface{}, error) {
+	objs, err := decodeSympoziumDocuments("edited object", edited, e.scheme)
+	if err != nil {
+		return nil, err
+	}
+	if len(objs) != 1 {
+		return nil, fmt.Errorf("expected exactly one object, found %d", len(objs))
+	}
+	obj := objs[0].obj
+	for _, c := range []struct{ field, was, now string }{
+		{"apiVersion", live.GetAPIVersion(), obj.GetAPIVersion()},
+		{"kind", live.GetKind(), obj.GetKind()},
+		{"metadata.name", live.GetName(), obj.GetName()},
+		{"metadata.namespace", live.GetNamespace(), obj.GetNamespace()},
+	} {
+		if c.was != c.now {
+			return nil, fmt.Errorf("%s cannot be changed (was %q, now %q)", c.field, c.was, c.now)
+		}
+	}
+	return obj.Object, nil
+}
+
+// keepCopy saves data to a temp file so rejected work is not lost, and
+// returns cause annotated with its location.
+func (e *objectEditor) keepCopy(data []byte, cause error) error {
+	f, err := os.CreateTemp("", "sympozium-edit-rejected-*.yaml")
+	if err != nil {
+		return cause
+	}
+	defer f.Close()
+	if _, err := f.Write(data); err != nil {
+		return cause
+	}
+	return fmt.Errorf("%w\nA copy of your changes has been stored to %q", cause, f.Name())
+}
+
+// editHeader renders the comment block shown above the object.
+func editHeader(notes []string) string {
+	var b strings.Builder
+	b.WriteString("# Please edit the object below. The leading '#' lines are ignored; an\n")
+	b.WriteString("# empty file or one saved without changes aborts the edit.\n")
+	if len(notes) > 0 {
+		b.WriteString("#\n")
+		for _, n := range notes {
+			for _, line := range strings.Split(n, "\n") {
+				b.WriteString("# " + line + "\n")
+			}
+		}
+	}
+	b.WriteString("#\n")
+	return b.String()
+}
+
+// stripLeadingComments removes the comment block at the top of the file.
+// Comments further down are kept because block scalars (such as SkillPack
+// Markdown) legitimately contain lines starting with '#'.
+func stripLeadingComments(data []byte) []byte {
+	lines := strings.SplitAfter(string(data), "\n")
+	i := 0
+	for i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), "#") {
+		i++
+	}
+	return []byte(strings.Join(lines[i:], ""))
+}
+
+// editableView returns a copy of obj without status, hidden metadata and
+// the last-applied annotation. The copy is round-tripped through JSON so its
+// numbers compare equal to those decoded from the edited YAML.
+func editableView(obj map[string]interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	var view map[string]interface{}
+	if err := json.Unmarshal(data, &view); err != nil {
+		return nil, err
+	}
+	delete(view, "status")
+	if meta, ok := view["metadata"].(map[string]interface{}); ok {
+		for _, f := range editHiddenMetadata {
+			delete(meta, f)
+		}
+		if ann, ok := meta["annotations"].(map[string]interface{}); ok {
+			delete(ann, corev1.LastAppliedConfigAnnotation)
+			if len(ann) == 0 {
+				delete(meta, "annotations")
+			}
+		}
+	}
+	return view, nil
+}
+
+// createMergePatch returns the RFC 7386 JSON merge patch turning original
+// into modified: changed keys carry their new value, removed keys are null.
+func createMergePatch(original, modified map[string]interface{}) map[string]interface{} {
+	patch := map[string]interface{}{}
+	for k, ov := range original {
+		mv, ok := modified[k]
+		if !ok {
+			patch[k] = nil
+			continue
+		}
+		om, oIsMap := ov.(map[string]interface{})
+		mm, mIsMap := mv.(map[string]interface{})
+		if oIsMap && mIsMap {
+			if sub := createMergePatch(om, mm); len(sub) > 0 {
+				patch[k] = sub
+			}
+			continue
+		}
+		if !reflect.DeepEqual(ov, mv) {
+			patch[k] = mv
+		}
+	}
+	for k, mv := range modified {
+		if _, ok := original[k]; !ok {
+			patch[k] = mv
+		}
+	}
+	return patch
+}
+
+// applyMergePatch applies an RFC 7386 JSON merge patch to target in place
+// and returns it.
+func applyMergePatch(target, patch map[string]interface{}) map[string]interface{} {
+	for k, pv := range patch {
+		if pv == nil {
+			delete(target, k)
+			continue
+		}
+		pm, pIsMap := pv.(map[string]interface{})
+		tm, tIsMap := target[k].(map[string]interface{})
+		if pIsMap {
+			if !tIsMap {
+				tm = map[string]interface{}{}
+			}
+			target[k] = applyMergePatch(tm, pm)
+			continue
+		}
+		target[k] = pv
+	}
+	return target
+}
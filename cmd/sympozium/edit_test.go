@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sympoziumv1alpha1 "github.com/alexsjones/sympozium/api/v1alpha1"
+)
+
+// scriptEditor replaces launchEditor with steps applied to the buffer in
+// turn, each receiving the file content without its comment header.
+func scriptEditor(t *testing.T, steps ...func(string) string) *int {
+	t.Helper()
+	calls := 0
+	old := launchEditor
+	launchEditor = func(path string) error {
+		if calls >= len(steps) {
+			t.Fatalf("editor opened %d times, only %d scripted", calls+1, len(steps))
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		out := steps[calls](string(stripLeadingComments(data)))
+		calls++
+		return os.WriteFile(path, []byte(out), 0o600)
+	}
+	t.Cleanup(func() { launchEditor = old })
+	return &calls
+}
+
+func testSkillPack() *sympoziumv1alpha1.SkillPack {
+	return &sympoziumv1alpha1.SkillPack{
+		ObjectMeta: metav1.ObjectMeta{Name: "ops", Namespace: "default"},
+		Spec: sympoziumv1alpha1.SkillPackSpec{
+			Version: "0.1.0",
+			Skills:  []sympoziumv1alpha1.Skill{{Name: "overview", Content: "# Heading\n\nbody\n"}},
+		},
+	}
+}
+
+func runTestEdit(t *testing.T, c client.Client, stdin string) (string, error) {
+	t.Helper()
+	var out bytes.Buffer
+	e := &objectEditor{client: c, scheme: testScheme(t), in: bufio.NewReader(strings.NewReader(stdin)), out: &out}
+	err := e.edit(context.Background(), sympoziumv1alpha1.GroupVersion.WithKind("SkillPack"),
+		types.NamespacedName{Name: "ops", Namespace: "default"})
+	return out.String(), err
+}
+
+func getSkillPack(t *testing.T, c client.Client) *sympoziumv1alpha1.SkillPack {
+	t.Helper()
+	var sp sympoziumv1alpha1.SkillPack
+	if err := c.Get(context.Background(), types.NamespacedName{Name: "ops", Namespace: "default"}, &sp); err != nil {
+		t.Fatal(err)
+	}
+	return &sp
+}
+
+func TestObjectEditor_AppliesChange(t *testing.T) {
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(testSkillPack()).Build()
+	scriptEditor(t, func(s string) string {
+		if strings.Contains(s, "resourceVersion") || strings.Contains(s, "creationTimestamp") {
+			t.Errorf("server metadata shown in editor:\n%s", s)
+		}
+		return strings.Replace(s, "version: 0.1.0", "version: 0.2.0", 1)
+	})
+
+	out, err := runTestEdit(t, c, "")
+	if err != nil {
+		t.Fatalf("edit: %v", err)
+	}
+	if !strings.Contains(out, "skillpack.sympozium.ai/ops edited") {
+		t.Errorf("output = %q", out)
+	}
+	sp := getSkillPack(t, c)
+	if sp.Spec.Version != "0.2.0" || sp.Spec.Skills[0].Content != "# Heading\n\nbody\n" {
+		t.Errorf("unexpected spec after edit: %+v", sp.Spec)
+	}
+}
+
+func TestObjectEditor_UnchangedCancels(t *testing.T) {
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(testSkillPack()).Build()
+	scriptEditor(t, func(s string) string { return s })
+
+	out, err := runTestEdit(t, c, "")
+	if err != nil || !strings.Contains(out, "Edit cancelled") {
+		t.Fatalf("out = %q, err = %v", out, err)
+	}
+}
+
+func TestObjectEditor_InvalidEditReopens(t *testing.T) {
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(testSkillPack()).Build()
+	calls := scriptEditor(t,
+		func(s string) string { return strings.Replace(s, "name: ops", "name: renamed", 1) },
+		func(s string) string {
+			s = strings.Replace(s, "name: renamed", "name: ops", 1)
+			return strings.Replace(s, "version: 0.1.0", "version: 0.3.0", 1)
+		},
+	)
+
+	if _, err := runTestEdit(t, c, ""); err != nil {
+		t.Fatalf("edit: %v", err)
+	}
+	if *calls != 2 {
+		t.Errorf("editor opened %d times, want 2", *calls)
+	}
+	if v := getSkillPack(t, c).Spec.Version; v != "0.3.0" {
+		t.Errorf("version = %s, want 0.3.0", v)
+	}
+}
+
+func TestObjectEditor_GiveUpKeepsCopy(t *testing.T) {
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(testSkillPack()).Build()
+	scriptEditor(t,
+		func(s string) string { return s + "bogusField: true\n" },
+		func(s string) string { return s },
+	)
+
+	_, err := runTestEdit(t, c, "")
+	if err == nil || !strings.Contains(err.Error(), "A copy of your changes has been stored to") {
+		t.Fatalf("err = %v, want a kept copy", err)
+	}
+	_, quoted, _ := strings.Cut(err.Error(), "stored to ")
+	path, uerr := strconv.Unquote(quoted)
+	if uerr != nil {
+		t.Fatalf("cannot parse path from %q: %v", err, uerr)
+	}
+	defer os.Remove(path)
+	if kept, _ := os.ReadFile(path); !strings.Contains(string(kept), "bogusField: true") {
+		t.Errorf("kept copy lacks the rejected edit:\n%s", kept)
+	}
+	if v := getSkillPack(t, c).Spec.Version; v != "0.1.0" {
+		t.Errorf("version = %s, want unchanged 0.1.0", v)
+	}
+}
+
+func TestObjectEditor_ConflictReopensOnLatest(t *testing.T) {
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(testSkillPack()).Build()
+	scriptEditor(t,
+		func(s string) string {
+			// Someone else changes the category while the editor is open.
+			sp := getSkillPack(t, c)
+			sp.Spec.Category = "kubernetes"
+			if err := c.Update(context.Background(), sp); err != nil {
+				t.Fatal(err)
+			}
+			return strings.Replace(s, "version: 0.1.0", "version: 0.2.0", 1)
+		},
+		func(s string) string {
+			if !strings.Contains(s, "category: kubernetes") || !strings.Contains(s, "version: 0.2.0") {
+				t.Errorf("merged buffer lacks concurrent or local change:\n%s", s)
+			}
+			return s
+		},
+	)
+
+	out, err := runTestEdit(t, c, "y\n")
+	if err != nil {
+		t.Fatalf("edit: %v", err)
+	}
+	if !strings.Contains(out, "was modified after you started editing") {
+		t.Errorf("output = %q", out)
+	}
+	sp := getSkillPack(t, c)
+	if sp.Spec.Version != "0.2.0" || sp.Spec.Category != "kubernetes" {
+		t.Errorf("spec = %+v, want both changes", sp.Spec)
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	original := map[string]interface{}{
+		"a": "1",
+		"b": map[string]interface{}{"keep": "x", "drop": "y", "change": "z"},
+		"c": []interface{}{"p", "q"},
+	}
+	modified := map[string]interface{}{
+		"a": "1",
+		"b": map[string]interface{}{"keep": "x", "change": "Z", "add": "w"},
+		"c": []interface{}{"p"},
+		"d": true,
+	}
+	patch := createMergePatch(original, modified)
+	want := map[string]interface{}{
+		"b": map[string]interface{}{"drop": nil, "change": "Z", "add": "w"},
+		"c": []interface{}{"p"},
+		"d": true,
+	}
+	if !reflect.DeepEqual(patch, want) {
+		t.Fatalf("patch = %v, want %v", patch, want)
+	}
+	if got := applyMergePatch(original, patch); !reflect.DeepEqual(got, modified) {
+		t.Errorf("applied = %v, want %v", got, modified)
+	}
+}
+
+func TestStripLeadingComments(t *testing.T) {
+	in := "# header\n#\n  # indented\nspec:\n  content: |\n    # Markdown heading\n"
+	want := "spec:\n  content: |\n    # Markdown heading\n"
+	if got := string(stripLeadingComments([]byte(in))); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
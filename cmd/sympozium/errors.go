@@ -0,0 +1,247 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ── Machine-readable errors (--error-format json) ───────────────────────────
+
+// errorFormat is the global --error-format flag: "text" (default) or "json".
+var errorFormat = "text"
+
+// verbose is the global --verbose flag. It adds raw API detail to errors.
+var verbose bool
+
+// errorCode is a stable, machine-readable classification of a CLI failure.
+type errorCode string
+
+const (
+	errNotFound         errorCode = "NotFound"
+	errAlreadyExists    errorCode = "AlreadyExists"
+	errConflict         errorCode = "Conflict"
+	errInvalid          errorCode = "Invalid"
+	errWebhookDenied    errorCode = "WebhookDenied"
+	errForbidden        errorCode = "Forbidden"
+	errUnauthorized     errorCode = "Unauthorized"
+	errTimeout          errorCode = "Timeout"
+	errTooManyRequests  errorCode = "TooManyRequests"
+	errUnavailable      errorCode = "Unavailable"
+	errConnectionFailed errorCode = "ConnectionFailed"
+	errInvalidArgument  errorCode = "InvalidArgument"
+	errInternal         errorCode = "Internal"
+	errUnknown          errorCode = "Unknown"
+)
+
+// errorCodes documents every code. It drives both classification defaults
+// and the `sympozium help error-codes` text, so the two cannot drift.
+var errorCodes = []struct {
+	code        errorCode
+	description string
+	suggestion  string
+}{
+	{errNotFound, "The named resource does not exist.", "Check the name and --namespace."},
+	{errAlreadyExists, "A resource with that name already exists.", "Choose another name or delete the existing resource."},
+	{errConflict, "The resource changed since it was read.", "Retry the command."},
+	{errInvalid, "The API server rejected the resource as invalid.", "Fix the fields named in the message."},
+	{errWebhookDenied, "A Sympozium admission webhook (e.g. a SympoziumPolicy) denied the request.", "Review the policy bound to the instance."},
+	{errForbidden, "RBAC does not allow the request.", "Ask a cluster admin for the required permissions."},
+	{errUnauthorized, "The cluster credentials were rejected.", "Refresh your kubeconfig credentials."},
+	{errTimeout, "The request or wait did not finish in time.", "Retry, or raise the timeout if the command has one."},
+	{errTooManyRequests, "The API server is throttling requests.", "Retry after a short delay."},
+	{errUnavailable, "The API server or a control-plane component is unavailable.", "Check 'sympozium status' and retry."},
+	{errConnectionFailed, "The cluster could not be reached or configured.", "Check --kubeconfig, the current context and network access."},
+	{errInvalidArgument, "A flag or argument was invalid.", "See --help for the command's usage."},
+	{errInternal, "The API server reported an internal error.", "Retry; check the API server logs if it persists."},
+	{errUnknown, "Any other failure.", ""},
+}
+
+// cliError is an error with a stable code, for failures the CLI detects
+// itself rather than ones returned by the API server.
+type cliError struct {
+	Code       errorCode
+	Message    string
+	Resource   string
+	Suggestion string
+}
+
+func (e *cliError) Error() string { return e.Message }
+
+// errorReport is the JSON object written to stderr with --error-format json.
+type errorReport struct {
+	Code       errorCode `json:"code"`
+	Message    string    `json:"message"`
+	Resource   string    `json:"resource,omitempty"`
+	Suggestion string    `json:"suggestion,omitempty"`
+}
+
+// statusReasonCodes maps API status reasons onto error codes.
+var statusReasonCodes = map[metav1.StatusReason]errorCode{
+	metav1.StatusReasonNotFound:           errNotFound,
+	metav1.StatusReasonAlreadyExists:      errAlreadyExists,
+	metav1.StatusReasonConflict:           errConflict,
+	metav1.StatusReasonInvalid:            errInvalid,
+	metav1.StatusReasonBadRequest:         errInvalid,
+	metav1.StatusReasonForbidden:          errForbidden,
+	metav1.StatusReasonUnauthorized:       errUnauthorized,
+	metav1.StatusReasonTimeout:            errTimeout,
+	metav1.StatusReasonServerTimeout:      errTimeout,
+	metav1.StatusReasonTooManyRequests:    errTooManyRequests,
+	metav1.StatusReasonServiceUnavailable: errUnavailable,
+	metav1.StatusReasonInternalError:      errInternal,
+}
+
+// classifyError builds the report for err.
+func classifyError(err error) errorReport {
+	var ce *cliError
+	if errors.As(err, &ce) {
+		return withDefaultSuggestion(errorReport{Code: ce.Code, Message: err.Error(), Resource: ce.Resource, Suggestion: ce.Suggestion})
+	}
+
+	report := errorReport{Code: errUnknown, Message: err.Error()}
+	var status apierrors.APIStatus
+	if errors.As(err, &status) {
+		st := status.Status()
+		if code, ok := statusReasonCodes[st.Reason]; ok {
+			report.Code = code
+		}
+		// A server timeout's Details.Name is the operation, not an object.
+		if d := st.Details; d != nil && d.Name != "" && st.Reason != metav1.StatusReasonServerTimeout {
+			report.Resource = d.Name
+			if d.Kind != "" {
+				report.Resource = d.Kind + "/" + d.Name
+			}
+		}
+		// Validating webhooks surface as Forbidden or Invalid; their message
+		// is the only reliable marker.
+		if strings.Contains(st.Message, "admission webhook") && strings.Contains(st.Message, "denied the request") {
+			report.Code = errWebhookDenied
+		}
+		return withDefaultSuggestion(report)
+	}
+
+	var urlErr *url.Error
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		report.Code = errTimeout
+	case errors.As(err, &urlErr), errors.As(err, &netErr):
+		report.Code = errConnectionFailed
+	}
+	return withDefaultSuggestion(report)
+}
+
+// withDefaultSuggestion fills in the code's documented suggestion when the
+// error did not carry its own.
+func withDefaultSuggestion(r errorReport) errorReport {
+	if r.Suggestion != "" {
+		return r
+	}
+	for _, c := range errorCodes {
+		if c.code == r.Code {
+			r.Suggestion = c.suggestion
+			break
+		}
+	}
+	return r
+}
+
+// reportError writes err to w in the selected --error-format.
+func reportError(w io.Writer, err error) {
+	if errorFormat != "json" {
+		fmt.Fprintln(w, "Error:", err)
+		return
+	}
+	data, mErr := json.Marshal(classifyError(err))
+	if mErr != nil {
+		fmt.Fprintln(w, "Error:", err)
+		return
+	}
+	fmt.Fprintln(w, string(data))
+}
+
+// webhookDenialRe matches the API server's wrapping of an admission webhook
+// denial and captures the webhook name and its message.
+var webhookDenialRe = regexp.MustCompile(`admission webhook "([^"]+)" denied the request:?\s*(.*)`)
+
+// explainAdmissionError turns an admission webhook denial into a short,
+// readable error naming the webhook, the status reason and the webhook's own
+// message; with --verbose the raw API status follows. Other errors are
+// returned unchanged.
+func explainAdmissionError(err error) error {
+	var status apierrors.APIStatus
+	if err == nil || !errors.As(err, &status) {
+		return err
+	}
+	st := status.Status()
+	m := webhookDenialRe.FindStringSubmatch(st.Message)
+	if m == nil {
+		return err
+	}
+
+	var b strings.Builder
+	resource := ""
+	if d := st.Details; d != nil && d.Name != "" {
+		resource = d.Kind + "/" + d.Name
+		fmt.Fprintf(&b, "%s rejected by admission webhook %s\n", resource, m[1])
+	} else {
+		fmt.Fprintf(&b, "request rejected by admission webhook %s\n", m[1])
+	}
+	fmt.Fprintf(&b, "  reason:  %s\n", st.Reason)
+	fmt.Fprintf(&b, "  message: %s", strings.TrimSpace(m[2]))
+	if verbose {
+		if raw, mErr := json.MarshalIndent(st, "  ", "  "); mErr == nil {
+			fmt.Fprintf(&b, "\n  status:\n  %s", raw)
+		}
+	}
+	return &cliError{
+		Code:       errWebhookDenied,
+		Message:    b.String(),
+		Resource:   resource,
+		Suggestion: "Fix the spec or review the SympoziumPolicy bound to the instance.",
+	}
+}
+
+// validateErrorFormat checks the --error-format value.
+func validateErrorFormat() error {
+	switch errorFormat {
+	case "text", "json":
+		return nil
+	}
+	return &cliError{Code: errInvalidArgument, Message: fmt.Sprintf("unsupported error format %q (supported: text, json)", errorFormat)}
+}
+
+// newErrorCodesHelpCmd returns the `help error-codes` topic.
+func newErrorCodesHelpCmd() *cobra.Command {
+	var b strings.Builder
+	b.WriteString(`With --error-format json, a failing command writes exactly one JSON object
+to stderr and exits non-zero; stdout is unchanged:
+
+  {"code":"NotFound","message":"...","resource":"sympoziuminstances/my-agent","suggestion":"..."}
+
+"code" values are stable: existing codes keep their meaning across releases
+and new codes may be added. "message" and "suggestion" are for humans and may
+change. "resource" is set when the failing object is known.
+
+Codes:
+`)
+	for _, c := range errorCodes {
+		fmt.Fprintf(&b, "  %-18s %s\n", c.code, c.description)
+	}
+	return &cobra.Command{
+		Use:   "error-codes",
+		Short: "Error codes reported by --error-format json",
+		Long:  strings.TrimRight(b.String(), "\n"),
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestClassifyError(t *testing.T) {
+	gr := schema.GroupResource{Group: "sympozium.ai", Resource: "sympoziuminstances"}
+	tests := []struct {
+		name     string
+		err      error
+		code     errorCode
+		resource string
+	}{
+		{"not found", apierrors.NewNotFound(gr, "my-agent"), errNotFound, "sympoziuminstances/my-agent"},
+		{"wrapped conflict", fmt.Errorf("update: %w", apierrors.NewConflict(gr, "my-agent", errors.New("stale"))), errConflict, "sympoziuminstances/my-agent"},
+		{"forbidden", apierrors.NewForbidden(gr, "my-agent", errors.New("no rbac")), errForbidden, "sympoziuminstances/my-agent"},
+		{"webhook denial", apierrors.NewForbidden(gr, "my-agent",
+			errors.New(`admission webhook "vsympoziuminstance.sympozium.ai" denied the request: tool not allowed`)), errWebhookDenied, "sympoziuminstances/my-agent"},
+		{"server timeout", apierrors.NewServerTimeout(gr, "list", 1), errTimeout, ""},
+		{"context deadline", fmt.Errorf("wait: %w", context.DeadlineExceeded), errTimeout, ""},
+		{"cli error", &cliError{Code: errInvalidArgument, Message: "bad flag"}, errInvalidArgument, ""},
+		{"plain", errors.New("boom"), errUnknown, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := classifyError(tt.err)
+			if got.Code != tt.code {
+				t.Errorf("code = %s, want %s", got.Code, tt.code)
+			}
+			if got.Resource != tt.resource {
+				t.Errorf("resource = %q, want %q", got.Resource, tt.resource)
+			}
+			if got.Message != tt.err.Error() {
+				t.Errorf("message = %q, want %q", got.Message, tt.err.Error())
+			}
+		})
+	}
+}
+
+func TestErrorCodesDocumented(t *testing.T) {
+	documented := map[errorCode]bool{}
+	for _, c := range errorCodes {
+		documented[c.code] = true
+	}
+	for reason, code := range statusReasonCodes {
+		if !documented[code] {
+			t.Errorf("status reason %s maps to undocumented code %s", reason, code)
+		}
+	}
+	help := newErrorCodesHelpCmd().Long
+	for _, c := range errorCodes {
+		if !strings.Contains(help, string(c.code)) {
+			t.Errorf("help text is missing %s", c.code)
+		}
+	}
+}
+
+func TestReportError_JSON(t *testing.T) {
+	old := errorFormat
+	errorFormat = "json"
+	t.Cleanup(func() { errorFormat = old })
+
+	var buf bytes.Buffer
+	reportError(&buf, apierrors.NewNotFound(schema.GroupResource{Resource: "agentruns"}, "run-1"))
+	if n := strings.Count(buf.String(), "\n"); n != 1 {
+		t.Fatalf("want a single line, got %q", buf.String())
+	}
+	var got map[string]string
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("not JSON: %v", err)
+	}
+	if got["code"] != "NotFound" || got["resource"] != "agentruns/run-1" || got["suggestion"] == "" {
+		t.Errorf("unexpected report %v", got)
+	}
+}
+
+func TestExplainAdmissionError(t *testing.T) {
+	gr := schema.GroupResource{Group: "sympozium.ai", Resource: "sympoziuminstances"}
+	denied := apierrors.NewForbidden(gr, "my-agent",
+		errors.New(`admission webhook "policy.sympozium.ai" denied the request: sandbox is required by policy`))
+
+	err := fmt.Errorf("apply: %w", explainAdmissionError(denied))
+	want := "apply: sympoziuminstances/my-agent rejected by admission webhook policy.sympozium.ai\n" +
+		"  reason:  Forbidden\n" +
+		"  message: sandbox is required by policy"
+	if err.Error() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", err, want)
+	}
+	if r := classifyError(err); r.Code != errWebhookDenied || r.Resource != "sympoziuminstances/my-agent" {
+		t.Errorf("classified as %+v", r)
+	}
+
+	old := verbose
+	verbose = true
+	t.Cleanup(func() { verbose = old })
+	if got := explainAdmissionError(denied).Error(); !strings.Contains(got, `"reason": "Forbidden"`) {
+		t.Errorf("verbose output lacks raw status:\n%s", got)
+	}
+
+	other := apierrors.NewNotFound(gr, "my-agent")
+	if got := explainAdmissionError(other); got != other {
+		t.Errorf("non-webhook error was rewritten: %v", got)
+	}
+}
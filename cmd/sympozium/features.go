@@ -0,0 +1,229 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	sympoziumv1alpha1 "github.com/alexsjones/sympozium/api/v1alpha1"
+)
+
+// ── features list --all-instances ───────────────────────────────────────────
+
+// instanceFeatureGates is the effective feature gate set of one instance.
+type instanceFeatureGates struct {
+	Instance string
+	// Policy is the bound SympoziumPolicy, empty if the instance has none.
+	Policy string
+	// PolicyMissing is set when Policy does not exist.
+	PolicyMissing bool
+	// Gates are the gates set on the policy; nil when no policy applies.
+	Gates map[string]bool
+}
+
+// resolveInstanceFeatureGates returns the effective gates of every instance
+// in ns, sorted by instance name. Each policy is fetched once.
+func resolveInstanceFeatureGates(ctx context.Context, c client.Reader, ns string) ([]instanceFeatureGates, error) {
+	var instances sympoziumv1alpha1.SympoziumInstanceList
+	if err := c.List(ctx, &instances, client.InNamespace(ns)); err != nil {
+		return nil, err
+	}
+	policies := map[string]*sympoziumv1alpha1.SympoziumPolicy{}
+	var rows []instanceFeatureGates
+	for _, inst := range instances.Items {
+		row := instanceFeatureGates{Instance: inst.Name, Policy: inst.Spec.PolicyRef}
+		if row.Policy != "" {
+			pol, fetched := policies[row.Policy]
+			if !fetched {
+				pol = &sympoziumv1alpha1.SympoziumPolicy{}
+				err := c.Get(ctx, types.NamespacedName{Name: row.Policy, Namespace: ns}, pol)
+				switch {
+				case apierrors.IsNotFound(err):
+					pol = nil
+				case err != nil:
+					return nil, err
+				}
+				policies[row.Policy] = pol
+			}
+			if pol == nil {
+				row.PolicyMissing = true
+			} else {
+				row.Gates = pol.Spec.FeatureGates
+			}
+		}
+		rows = append(rows, row)
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Instance < rows[j].Instance })
+	return rows, nil
+}
+
+// printInstanceFeatureGates prints one row per instance and one column per
+// gate (the catalog plus any gate set on a listed policy). Cells are on,
+// off, default (not set, so not restricted), or ? when the policy is
+// missing. A warning is written to warn for each missing policy.
+func printInstanceFeatureGates(out, warn io.Writer, rows []instanceFeatureGates) error {
+	names := map[string]bool{}
+	for _, g := range featureGateCatalog {
+		names[g.text] = true
+	}
+	for _, r := range rows {
+		for name := range r.Gates {
+			names[name] = true
+		}
+	}
+	gates := make([]string, 0, len(names))
+	for name := range names {
+		gates = append(gates, name)
+	}
+	sort.Strings(gates)
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "INSTANCE\tPOLICY\t"+strings.ToUpper(strings.Join(gates, "\t")))
+	for _, r := range rows {
+		policy := r.Policy
+		switch {
+		case policy == "":
+			policy = "<none>"
+		case r.PolicyMissing:
+			policy += " (missing)"
+			fmt.Fprintf(warn, "Warning: instance %s references SympoziumPolicy %q, which does not exist in namespace %s\n", r.Instance, r.Policy, namespace)
+		}
+		cells := []string{r.Instance, policy}
+		for _, name := range gates {
+			cell := "default"
+			if r.PolicyMissing {
+				cell = "?"
+			} else if enabled, ok := r.Gates[name]; ok {
+				cell = onOff(enabled)
+			}
+			cells = append(cells, cell)
+		}
+		fmt.Fprintln(w, strings.Join(cells, "\t"))
+	}
+	return w.Flush()
+}
+
+// ── features apply ──────────────────────────────────────────────────────────
+
+// featureGateChanges is what features apply did to a policy's gates.
+type featureGateChanges struct {
+	// Added and Updated are the gates set from the file, by name.
+	Added, Updated []string
+	// Removed are the gates on the policy that the file does not set. It is
+	// always empty with --merge.
+	Removed []string
+	// Old holds the previous value of each updated or removed gate.
+	Old map[string]bool
+}
+
+func (c featureGateChanges) empty() bool {
+	return len(c.Added)+len(c.Updated)+len(c.Removed) == 0
+}
+
+// readFeatureGatesFile reads a YAML or JSON map of gate name to enabled,
+// from stdin when path is "-".
+func readFeatureGatesFile(path string, stdin io.Reader) (map[string]bool, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	gates := map[string]bool{}
+	if err := yaml.UnmarshalStrict(data, &gates); err != nil {
+		return nil, fmt.Errorf("%s: want a map of feature gate to true or false: %w", path, err)
+	}
+	for name := range gates {
+		if strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("%s: empty feature gate name", path)
+		}
+	}
+	return gates, nil
+}
+
+// reconcileFeatureGates returns the gates of a policy after applying
+// desired to current, and what changed. By default the result is exactly
+// desired; with merge, gates desired does not mention are kept.
+func reconcileFeatureGates(current, desired map[string]bool, merge bool) (map[string]bool, featureGateChanges) {
+	changes := featureGateChanges{Old: map[string]bool{}}
+	out := make(map[string]bool, len(desired))
+	if merge {
+		for name, enabled := range current {
+			out[name] = enabled
+		}
+	}
+	for name, enabled := range desired {
+		old, ok := current[name]
+		switch {
+		case !ok:
+			changes.Added = append(changes.Added, name)
+		case old != enabled:
+			changes.Updated = append(changes.Updated, name)
+			changes.Old[name] = old
+		}
+		out[name] = enabled
+	}
+	if !merge {
+		for name, enabled := range current {
+			if _, ok := desired[name]; !ok {
+				changes.Removed = append(changes.Removed, name)
+				changes.Old[name] = enabled
+			}
+		}
+	}
+	sort.Strings(changes.Added)
+	sort.Strings(changes.Updated)
+	sort.Strings(changes.Removed)
+	return out, changes
+}
+
+// applyFeatureGates sets the gates of a policy from desired and updates the
+// policy if anything changed.
+func applyFeatureGates(ctx context.Context, c client.Client, ns, policyName string, desired map[string]bool, merge bool) (featureGateChanges, error) {
+	var pol sympoziumv1alpha1.SympoziumPolicy
+	if err := c.Get(ctx, types.NamespacedName{Name: policyName, Namespace: ns}, &pol); err != nil {
+		return featureGateChanges{}, err
+	}
+	gates, changes := reconcileFeatureGates(pol.Spec.FeatureGates, desired, merge)
+	if changes.empty() {
+		return changes, nil
+	}
+	pol.Spec.FeatureGates = gates
+	return changes, c.Update(ctx, &pol)
+}
+
+// printFeatureGateChanges prints one line per changed gate and a summary.
+func printFeatureGateChanges(out io.Writer, policyName string, desired map[string]bool, changes featureGateChanges, merge bool) {
+	for _, name := range changes.Added {
+		fmt.Fprintf(out, "  added    %s: %s\n", name, onOff(desired[name]))
+	}
+	for _, name := range changes.Updated {
+		fmt.Fprintf(out, "  updated  %s: %s -> %s\n", name, onOff(changes.Old[name]), onOff(desired[name]))
+	}
+	for _, name := range changes.Removed {
+		fmt.Fprintf(out, "  removed  %s (was %s)\n", name, onOff(changes.Old[name]))
+	}
+	mode := "replace"
+	if merge {
+		mode = "merge"
+	}
+	if changes.empty() {
+		fmt.Fprintf(out, "Policy %s unchanged (%s)\n", policyName, mode)
+		return
+	}
+	fmt.Fprintf(out, "Policy %s: %d added, %d updated, %d removed (%s)\n",
+		policyName, len(changes.Added), len(changes.Updated), len(changes.Removed), mode)
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sympoziumv1alpha1 "github.com/alexsjones/sympozium/api/v1alpha1"
+)
+
+func TestInstanceFeatureGates(t *testing.T) {
+	inst := func(name, policy string) *sympoziumv1alpha1.SympoziumInstance {
+		return &sympoziumv1alpha1.SympoziumInstance{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       sympoziumv1alpha1.SympoziumInstanceSpec{PolicyRef: policy},
+		}
+	}
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(
+		inst("bravo", "strict"),
+		inst("alpha", ""),
+		inst("charlie", "gone"),
+		&sympoziumv1alpha1.SympoziumPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "strict", Namespace: "default"},
+			Spec: sympoziumv1alpha1.SympoziumPolicySpec{
+				FeatureGates: map[string]bool{"code-execution": false, "gpu-access": true},
+			},
+		},
+	).Build()
+
+	rows, err := resolveInstanceFeatureGates(context.Background(), c, "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out, warn bytes.Buffer
+	if err := printInstanceFeatureGates(&out, &warn, rows); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	want := [][]string{
+		{"INSTANCE", "POLICY", "BROWSER-AUTOMATION", "CODE-EXECUTION", "FILE-ACCESS", "GPU-ACCESS", "SUB-AGENTS"},
+		{"alpha", "<none>", "default", "default", "default", "default", "default"},
+		{"bravo", "strict", "default", "off", "default", "on", "default"},
+		{"charlie", "gone", "(missing)", "?", "?", "?", "?", "?"},
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("got %d lines, want %d:\n%s", len(lines), len(want), out.String())
+	}
+	for i, fields := range want {
+		if got := strings.Fields(lines[i]); strings.Join(got, " ") != strings.Join(fields, " ") {
+			t.Errorf("line %d = %q, want %q", i, got, fields)
+		}
+	}
+	if !strings.Contains(warn.String(), `instance charlie references SympoziumPolicy "gone"`) {
+		t.Errorf("warning = %q, want a missing-policy warning for charlie", warn.String())
+	}
+}
+
+func TestApplyFeatureGates(t *testing.T) {
+	policy := func() *sympoziumv1alpha1.SympoziumPolicy {
+		return &sympoziumv1alpha1.SympoziumPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "shared", Namespace: "default"},
+			Spec: sympoziumv1alpha1.SympoziumPolicySpec{
+				FeatureGates: map[string]bool{"code-execution": true, "file-access": true, "sub-agents": false},
+			},
+		}
+	}
+	desired := map[string]bool{"code-execution": false, "file-access": true, "gpu-access": true}
+
+	for _, tt := range []struct {
+		name    string
+		merge   bool
+		want    map[string]bool
+		removed []string
+		summary string
+	}{
+		{"replace", false,
+			map[string]bool{"code-execution": false, "file-access": true, "gpu-access": true},
+			[]string{"sub-agents"}, "1 added, 1 updated, 1 removed (replace)"},
+		{"merge", true,
+			map[string]bool{"code-execution": false, "file-access": true, "gpu-access": true, "sub-agents": false},
+			nil, "1 added, 1 updated, 0 removed (merge)"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(policy()).Build()
+			changes, err := applyFeatureGates(ctx, c, "default", "shared", desired, tt.merge)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Join(changes.Added, ",") != "gpu-access" || strings.Join(changes.Updated, ",") != "code-execution" ||
+				strings.Join(changes.Removed, ",") != strings.Join(tt.removed, ",") {
+				t.Errorf("changes = %+v", changes)
+			}
+			var pol sympoziumv1alpha1.SympoziumPolicy
+			c.Get(ctx, client.ObjectKeyFromObject(policy()), &pol)
+			if !reflect.DeepEqual(pol.Spec.FeatureGates, tt.want) {
+				t.Errorf("gates = %v, want %v", pol.Spec.FeatureGates, tt.want)
+			}
+			var out bytes.Buffer
+			printFeatureGateChanges(&out, "shared", desired, changes, tt.merge)
+			if !strings.Contains(out.String(), "updated  code-execution: on -> off") || !strings.Contains(out.String(), tt.summary) {
+				t.Errorf("output:\n%s", out.String())
+			}
+		})
+	}
+
+	changes, _ := reconcileFeatureGates(map[string]bool{"a": true}, map[string]bool{"a": true}, false)
+	if !changes.empty() {
+		t.Errorf("same gates: changes = %+v", changes)
+	}
+}
+
+func TestReadFeatureGatesFile(t *testing.T) {
+	gates, err := readFeatureGatesFile("-", strings.NewReader("gpu-access: true\nsub-agents: false\n"))
+	if err != nil || len(gates) != 2 || !gates["gpu-access"] {
+		t.Errorf("got %v, %v", gates, err)
+	}
+	if _, err := readFeatureGatesFile("-", strings.NewReader("gpu-access: maybe\n")); err == nil {
+		t.Error("non-boolean value: want error")
+	}
+}
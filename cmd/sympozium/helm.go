@@ -0,0 +1,273 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// ── Helm chart rendering (install --render-helm) ────────────────────────────
+
+var (
+	helmResourcesRe  = regexp.MustCompile(`^(\s*)resources:\s*$`)
+	helmSectionRe    = regexp.MustCompile(`^(\s*)(limits|requests):\s*$`)
+	helmQuantityRe   = regexp.MustCompile(`^(\s*)(cpu|memory):\s*(\S+)\s*$`)
+	helmContainersRe = regexp.MustCompile(`^(\s*)(initContainers|containers):\s*$`)
+	helmContainerRe  = regexp.MustCompile(`^(\s*)- name:\s*(\S+)\s*$`)
+)
+
+// renderHelmChart converts an extracted release bundle into a minimal Helm
+// chart under outDir. The templates are the release manifests with the image
+// registry/tag, control-plane namespace, and workload resource quantities
+// replaced by values, and optional component groups wrapped in feature
+// flags. The image and namespace overrides in opts become the defaults in
+// values.yaml. Only plain text/template constructs are used so the chart
+// renders identically under Helm and under the standard library.
+func renderHelmChart(bundleDir, outDir, ver string, opts installOptions) error {
+	for _, d := range []string{"templates", "crds"} {
+		if err := os.MkdirAll(filepath.Join(outDir, d), 0o755); err != nil {
+			return err
+		}
+	}
+
+	values := map[string]any{
+		"namespace": defaultInstallNamespace,
+		"image": map[string]any{
+			"registry": defaultImageRegistry,
+			"tag":      ver,
+		},
+	}
+	resources := map[string]any{}
+	features := map[string]any{}
+	imageTagSeen := false
+
+	for _, g := range installManifestGroups {
+		entries, err := os.ReadDir(filepath.Join(bundleDir, g.dir))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if g.feature != "" {
+			features[g.feature] = true
+		}
+		for _, e := range entries {
+			if e.IsDir() || !isYAMLFile(e.Name()) {
+				continue
+			}
+			data, err := os.ReadFile(filepath.Join(bundleDir, g.dir, e.Name()))
+			if err != nil {
+				return err
+			}
+
+			// CRDs go into crds/ untouched; Helm never templates them.
+			if g.dir == "config/crd/bases" {
+				if err := os.WriteFile(filepath.Join(outDir, "crds", e.Name()), data, 0o644); err != nil {
+					return err
+				}
+				continue
+			}
+
+			var docs []string
+			for _, doc := range splitYAMLDocuments(string(data)) {
+				doc, err = templateResources(doc, resources)
+				if err != nil {
+					return fmt.Errorf("%s/%s: %w", g.dir, e.Name(), err)
+				}
+				doc = sympoziumImageRe.ReplaceAllStringFunc(doc, func(ref string) string {
+					m := sympoziumImageRe.FindStringSubmatch(ref)
+					if !imageTagSeen {
+						values["image"].(map[string]any)["tag"] = m[2]
+						imageTagSeen = true
+					}
+					return "{{ .Values.image.registry }}/" + m[1] + ":{{ .Values.image.tag }}"
+				})
+				doc = strings.ReplaceAll(doc, defaultInstallNamespace, "{{ .Values.namespace }}")
+				docs = append(docs, doc)
+			}
+
+			out := "---\n" + strings.Join(docs, "\n---\n") + "\n"
+			if g.feature != "" {
+				out = fmt.Sprintf("{{- if .Values.features.%s }}\n%s{{- end }}\n", g.feature, out)
+			}
+			name := filepath.Base(g.dir) + "-" + e.Name()
+			if err := os.WriteFile(filepath.Join(outDir, "templates", name), []byte(out), 0o644); err != nil {
+				return err
+			}
+		}
+	}
+	values["resources"] = resources
+	values["features"] = features
+	if opts.ImageRegistry != "" {
+		values["image"].(map[string]any)["registry"] = strings.TrimRight(opts.ImageRegistry, "/")
+	}
+	if opts.ImageTag != "" {
+		values["image"].(map[string]any)["tag"] = opts.ImageTag
+	}
+	if opts.Namespace != "" {
+		values["namespace"] = opts.Namespace
+	}
+
+	valuesYAML, err := yaml.Marshal(values)
+	if err != nil {
+		return fmt.Errorf("marshal values: %w", err)
+	}
+	header := "# Values for the Sympozium control plane chart rendered by\n" +
+		"# 'sympozium install --render-helm'. cert-manager must already be installed.\n"
+	if err := os.WriteFile(filepath.Join(outDir, "values.yaml"), append([]byte(header), valuesYAML...), 0o644); err != nil {
+		return err
+	}
+
+	chart := fmt.Sprintf(`apiVersion: v2
+name: sympozium
+description: Sympozium control plane rendered from the %s release manifests
+type: application
+version: %s
+appVersion: %q
+`, ver, helmChartVersion(ver), ver)
+	return os.WriteFile(filepath.Join(outDir, "Chart.yaml"), []byte(chart), 0o644)
+}
+
+// templateResources escapes any literal template delimiters in a manifest
+// document and, for workload kinds, replaces container cpu/memory quantities
+// with lookups into .Values.resources keyed by workload and container name;
+// init containers are keyed under "initContainers" so one may share a name
+// with a container. The original quantities are recorded in resources as
+// defaults.
+func templateResources(doc string, resources map[string]any) (string, error) {
+	var meta struct {
+		Kind     string `json:"kind"`
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+	}
+	if err := yaml.Unmarshal([]byte(doc), &meta); err != nil {
+		return "", fmt.Errorf("parse manifest: %w", err)
+	}
+
+	// Escape existing delimiters (e.g. in skill content) before inserting
+	// our own template actions.
+	doc = strings.ReplaceAll(doc, "{{", `{{ "{{" }}`)
+
+	switch meta.Kind {
+	case "Deployment", "StatefulSet", "DaemonSet":
+	default:
+		return doc, nil
+	}
+
+	lines := strings.Split(doc, "\n")
+	container, list, containerList := "", "", ""
+	resIndent, section := -1, ""
+	// listIndent is the indent of the items of the current containers list,
+	// so env and port entries, which are also "- name:" items, are skipped.
+	listKeyIndent, listIndent := -1, -1
+	for i, line := range lines {
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		if m := helmContainersRe.FindStringSubmatch(line); m != nil {
+			listKeyIndent, listIndent, list = len(m[1]), -1, m[2]
+			continue
+		}
+		if listKeyIndent >= 0 && strings.TrimSpace(line) != "" && indent <= listKeyIndent && !strings.HasPrefix(strings.TrimSpace(line), "- ") {
+			listKeyIndent, listIndent = -1, -1
+		}
+		if m := helmContainerRe.FindStringSubmatch(line); m != nil && listKeyIndent >= 0 {
+			if listIndent < 0 {
+				listIndent = len(m[1])
+			}
+			if len(m[1]) == listIndent {
+				container, containerList = strings.Trim(m[2], `"'`), list
+			}
+		}
+		if m := helmResourcesRe.FindStringSubmatch(line); m != nil {
+			resIndent, section = len(m[1]), ""
+			continue
+		}
+		if resIndent < 0 {
+			continue
+		}
+		if strings.TrimSpace(line) != "" && indent <= resIndent {
+			resIndent, section = -1, ""
+			continue
+		}
+		if m := helmSectionRe.FindStringSubmatch(line); m != nil {
+			section = m[2]
+			continue
+		}
+		m := helmQuantityRe.FindStringSubmatch(line)
+		if m == nil || section == "" {
+			continue
+		}
+		raw := m[3]
+		quoted := strings.HasPrefix(raw, `"`) || strings.HasPrefix(raw, `'`)
+		val := strings.Trim(raw, `"'`)
+
+		keys := []string{meta.Metadata.Name, container, section}
+		if containerList == "initContainers" {
+			keys = []string{meta.Metadata.Name, containerList, container, section}
+		}
+		byKey := resources
+		for _, k := range keys {
+			byKey = nestedMap(byKey, k)
+		}
+		byKey[m[2]] = val
+
+		ref := "{{ index .Values.resources"
+		for _, k := range append(keys, m[2]) {
+			ref += fmt.Sprintf(" %q", k)
+		}
+		ref += " }}"
+		if quoted {
+			ref = `"` + ref + `"`
+		}
+		lines[i] = m[1] + m[2] + ": " + ref
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// nestedMap returns m[key] as a map, creating it when absent.
+func nestedMap(m map[string]any, key string) map[string]any {
+	if v, ok := m[key].(map[string]any); ok {
+		return v
+	}
+	v := map[string]any{}
+	m[key] = v
+	return v
+}
+
+// splitYAMLDocuments splits a multi-document YAML stream on "---" separator
+// lines, dropping empty documents.
+func splitYAMLDocuments(data string) []string {
+	var docs []string
+	var cur []string
+	flush := func() {
+		doc := strings.Trim(strings.Join(cur, "\n"), "\n")
+		if strings.TrimSpace(doc) != "" {
+			docs = append(docs, doc)
+		}
+		cur = nil
+	}
+	for _, line := range strings.Split(data, "\n") {
+		if strings.TrimRight(line, " ") == "---" {
+			flush()
+			continue
+		}
+		cur = append(cur, line)
+	}
+	flush()
+	return docs
+}
+
+// helmChartVersion derives a SemVer chart version from a release tag,
+// falling back to a pre-release version for development builds.
+func helmChartVersion(ver string) string {
+	v := strings.TrimPrefix(ver, "v")
+	if regexp.MustCompile(`^\d+\.\d+\.\d+`).MatchString(v) {
+		return v
+	}
+	return "0.0.0-" + regexp.MustCompile(`[^0-9A-Za-z-]`).ReplaceAllString(v, "-")
+}
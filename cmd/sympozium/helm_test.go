@@ -0,0 +1,341 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"text/template"
+
+	"sigs.k8s.io/yaml"
+)
+
+// writeTestBundle lays out a minimal release bundle covering CRDs, a
+// templated workload, an optional component group, and literal template
+// delimiters that must survive rendering.
+func writeTestBundle(t *testing.T, dir string) {
+	t.Helper()
+	files := map[string]string{
+		"config/crd/bases/sympozium.ai_agentruns.yaml": `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: agentruns.sympozium.ai
+spec:
+  group: sympozium.ai
+`,
+		"config/manager/manager.yaml": `---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: sympozium-system
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: sympozium-controller-manager
+  namespace: sympozium-system
+  annotations:
+    example.com/template: "{{ not-a-template }}"
+spec:
+  template:
+    spec:
+      initContainers:
+        - name: "manager"
+          image: ghcr.io/alexsjones/sympozium/controller:v0.1.0
+          resources:
+            limits:
+              cpu: 50m
+      containers:
+        - name: manager
+          image: ghcr.io/alexsjones/sympozium/controller:v0.1.0
+          env:
+            - name: EVENT_BUS_URL
+              value: nats://nats.sympozium-system.svc:4222
+          resources:
+            limits:
+              cpu: 500m
+              memory: "256Mi"
+            requests:
+              cpu: 100m
+              memory: 128Mi
+`,
+		"config/network/policies.yaml": `apiVersion: networking.k8s.io/v1
+kind: NetworkPolicy
+metadata:
+  name: sympozium-controller
+  namespace: sympozium-system
+spec:
+  podSelector: {}
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// objectsByKey parses a multi-document YAML stream into objects keyed by
+// kind/namespace/name so renderings can be compared independent of order
+// and formatting.
+func objectsByKey(t *testing.T, stream string) map[string]map[string]any {
+	t.Helper()
+	out := map[string]map[string]any{}
+	for _, doc := range splitYAMLDocuments(stream) {
+		var obj map[string]any
+		if err := yaml.Unmarshal([]byte(doc), &obj); err != nil {
+			t.Fatalf("parse rendered document: %v\n%s", err, doc)
+		}
+		if obj == nil {
+			continue
+		}
+		meta, _ := obj["metadata"].(map[string]any)
+		key := strings.Join([]string{obj["kind"].(string), stringOf(meta["namespace"]), stringOf(meta["name"])}, "/")
+		out[key] = obj
+	}
+	return out
+}
+
+func stringOf(v any) string {
+	s, _ := v.(string)
+	return s
+}
+
+// renderChartWithStdlib executes the chart templates the way Helm would,
+// using the chart's values.yaml with the given overrides applied.
+func renderChartWithStdlib(t *testing.T, chartDir string, override func(map[string]any)) string {
+	t.Helper()
+	raw, err := os.ReadFile(filepath.Join(chartDir, "values.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var values map[string]any
+	if err := yaml.Unmarshal(raw, &values); err != nil {
+		t.Fatalf("parse values.yaml: %v", err)
+	}
+	override(values)
+
+	var buf bytes.Buffer
+	for _, sub := range []string{"crds", "templates"} {
+		entries, err := os.ReadDir(filepath.Join(chartDir, sub))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, e := range entries {
+			data, err := os.ReadFile(filepath.Join(chartDir, sub, e.Name()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sub == "crds" {
+				buf.WriteString("---\n" + string(data) + "\n")
+				continue
+			}
+			tmpl, err := template.New(e.Name()).Option("missingkey=error").Parse(string(data))
+			if err != nil {
+				t.Fatalf("parse template %s: %v", e.Name(), err)
+			}
+			if err := tmpl.Execute(&buf, map[string]any{"Values": values}); err != nil {
+				t.Fatalf("execute template %s: %v", e.Name(), err)
+			}
+			buf.WriteString("\n")
+		}
+	}
+	return buf.String()
+}
+
+func TestRenderHelmChart_MatchesDryRun(t *testing.T) {
+	chartBundle := t.TempDir()
+	writeTestBundle(t, chartBundle)
+	chartDir := t.TempDir()
+	if err := renderHelmChart(chartBundle, chartDir, "v0.1.0", installOptions{}); err != nil {
+		t.Fatalf("renderHelmChart: %v", err)
+	}
+
+	for _, f := range []string{"Chart.yaml", "values.yaml", "crds/sympozium.ai_agentruns.yaml", "templates/manager-manager.yaml"} {
+		if _, err := os.Stat(filepath.Join(chartDir, f)); err != nil {
+			t.Errorf("expected %s in chart: %v", f, err)
+		}
+	}
+
+	opts := installOptions{ImageTag: "custom", ImageRegistry: "registry.example.com/sym", Namespace: "agents"}
+	dryBundle := t.TempDir()
+	writeTestBundle(t, dryBundle)
+	if err := applyInstallOverrides(dryBundle, opts, io.Discard); err != nil {
+		t.Fatalf("applyInstallOverrides: %v", err)
+	}
+	var dry bytes.Buffer
+	if err := printInstallManifests(&dry, dryBundle); err != nil {
+		t.Fatalf("printInstallManifests: %v", err)
+	}
+
+	rendered := renderChartWithStdlib(t, chartDir, func(v map[string]any) {
+		v["namespace"] = opts.Namespace
+		img := v["image"].(map[string]any)
+		img["registry"] = opts.ImageRegistry
+		img["tag"] = opts.ImageTag
+	})
+
+	want := objectsByKey(t, dry.String())
+	got := objectsByKey(t, rendered)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("chart output differs from dry-run\n got: %v\nwant: %v", got, want)
+	}
+
+	dep := got["Deployment/agents/sympozium-controller-manager"]
+	if dep == nil {
+		t.Fatal("rendered chart is missing the controller Deployment")
+	}
+	if ann := dep["metadata"].(map[string]any)["annotations"].(map[string]any)["example.com/template"]; ann != "{{ not-a-template }}" {
+		t.Errorf("literal template delimiters not preserved: %v", ann)
+	}
+
+	// The same overrides given to --render-helm become the chart defaults.
+	defaultsDir := t.TempDir()
+	if err := renderHelmChart(chartBundle, defaultsDir, "v0.1.0", opts); err != nil {
+		t.Fatalf("renderHelmChart with overrides: %v", err)
+	}
+	if got := objectsByKey(t, renderChartWithStdlib(t, defaultsDir, func(map[string]any) {})); !reflect.DeepEqual(got, want) {
+		t.Errorf("chart defaults differ from dry-run with the same overrides\n got: %v\nwant: %v", got, want)
+	}
+
+	if helm, err := exec.LookPath("helm"); err == nil {
+		out, err := exec.Command(helm, "template", "sympozium", chartDir, "--include-crds",
+			"--set", "namespace="+opts.Namespace,
+			"--set", "image.registry="+opts.ImageRegistry,
+			"--set", "image.tag="+opts.ImageTag).CombinedOutput()
+		if err != nil {
+			t.Fatalf("helm template: %v\n%s", err, out)
+		}
+		if got := objectsByKey(t, string(out)); !reflect.DeepEqual(got, want) {
+			t.Errorf("helm template output differs from dry-run\n got: %v\nwant: %v", got, want)
+		}
+	}
+}
+
+func TestRenderHelmChart_FeatureFlagsAndResources(t *testing.T) {
+	bundle := t.TempDir()
+	writeTestBundle(t, bundle)
+	chartDir := t.TempDir()
+	if err := renderHelmChart(bundle, chartDir, "v0.1.0", installOptions{}); err != nil {
+		t.Fatalf("renderHelmChart: %v", err)
+	}
+
+	rendered := objectsByKey(t, renderChartWithStdlib(t, chartDir, func(v map[string]any) {
+		v["features"].(map[string]any)["networkPolicies"] = false
+		limits := v["resources"].(map[string]any)["sympozium-controller-manager"].(map[string]any)["manager"].(map[string]any)["limits"].(map[string]any)
+		limits["cpu"] = "2"
+	}))
+
+	for key := range rendered {
+		if strings.HasPrefix(key, "NetworkPolicy/") {
+			t.Errorf("network policies rendered with feature disabled: %s", key)
+		}
+	}
+	dep := rendered["Deployment/sympozium-system/sympozium-controller-manager"]
+	podSpec := dep["spec"].(map[string]any)["template"].(map[string]any)["spec"].(map[string]any)
+	containers := podSpec["containers"].([]any)
+	res := containers[0].(map[string]any)["resources"].(map[string]any)
+	if cpu := res["limits"].(map[string]any)["cpu"]; cpu != float64(2) && cpu != "2" {
+		t.Errorf("limits.cpu = %v, want 2", cpu)
+	}
+	if mem := res["limits"].(map[string]any)["memory"]; mem != "256Mi" {
+		t.Errorf("limits.memory = %v, want 256Mi", mem)
+	}
+
+	// The init container shares the container's (quoted) name but has its
+	// own entry, unaffected by the container's override.
+	initRes := podSpec["initContainers"].([]any)[0].(map[string]any)["resources"].(map[string]any)
+	if cpu := initRes["limits"].(map[string]any)["cpu"]; cpu != "50m" {
+		t.Errorf("init container limits.cpu = %v, want 50m", cpu)
+	}
+	raw, err := os.ReadFile(filepath.Join(chartDir, "values.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var values struct {
+		Resources map[string]map[string]any `json:"resources"`
+	}
+	if err := yaml.Unmarshal(raw, &values); err != nil {
+		t.Fatal(err)
+	}
+	byContainer := values.Resources["sympozium-controller-manager"]
+	if _, ok := byContainer[`"manager"`]; ok {
+		t.Errorf("resources keyed by the quoted name: %v", byContainer)
+	}
+	want := map[string]any{"manager": map[string]any{"limits": map[string]any{"cpu": "50m"}}}
+	if got := byContainer["initContainers"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("initContainers resources = %v, want %v", got, want)
+	}
+}
+
+func TestTemplateResources_EnvBeforeResources(t *testing.T) {
+	doc := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+        - name: app
+          env:
+            - name: LOG_LEVEL
+              value: debug
+          ports:
+            - name: http
+              containerPort: 8080
+          resources:
+            limits:
+              cpu: 500m
+              memory: "256Mi"
+        - name: proxy
+          env:
+            - name: UPSTREAM
+              value: localhost
+          resources:
+            requests:
+              cpu: 10m
+`
+	resources := map[string]any{}
+	out, err := templateResources(doc, resources)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{"web": map[string]any{
+		"app":   map[string]any{"limits": map[string]any{"cpu": "500m", "memory": "256Mi"}},
+		"proxy": map[string]any{"requests": map[string]any{"cpu": "10m"}},
+	}}
+	if !reflect.DeepEqual(resources, want) {
+		t.Errorf("resources = %v, want %v", resources, want)
+	}
+	for _, line := range []string{
+		`cpu: {{ index .Values.resources "web" "app" "limits" "cpu" }}`,
+		`memory: "{{ index .Values.resources "web" "app" "limits" "memory" }}"`,
+		`cpu: {{ index .Values.resources "web" "proxy" "requests" "cpu" }}`,
+	} {
+		if !strings.Contains(out, line) {
+			t.Errorf("templated manifest missing %q:\n%s", line, out)
+		}
+	}
+}
+
+func TestHelmChartVersion(t *testing.T) {
+	tests := map[string]string{
+		"v0.0.32": "0.0.32",
+		"1.2.3":   "1.2.3",
+		"dev":     "0.0.0-dev",
+	}
+	for in, want := range tests {
+		if got := helmChartVersion(in); got != want {
+			t.Errorf("helmChartVersion(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
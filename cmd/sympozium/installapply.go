@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// ── install apply ───────────────────────────────────────────────────────────
+
+// serverSideApplyArgs returns the kubectl arguments that server-side apply
+// path as fieldManager, taking ownership of conflicting fields when force
+// is set.
+func serverSideApplyArgs(path, fieldManager string, force bool) []string {
+	args := []string{"apply", "--server-side", "--field-manager=" + fieldManager}
+	if force {
+		args = append(args, "--force-conflicts")
+	}
+	return append(args, "-f", path)
+}
+
+// kubectlServerSideApply server-side applies path with kubectl. kubectl's
+// output is shown as usual; a field manager conflict is turned into an
+// error that says how to resolve it.
+func kubectlServerSideApply(path, fieldManager string, force bool) error {
+	var stderr bytes.Buffer
+	cmd := exec.Command("kubectl", serverSideApplyArgs(path, fieldManager, force)...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = io.MultiWriter(os.Stderr, &stderr)
+	if err := cmd.Run(); err != nil {
+		return applyConflictError(stderr.String(), fieldManager, err)
+	}
+	return nil
+}
+
+// conflictManagerRe matches the managers named in kubectl's server-side
+// apply conflict message, e.g. `conflict with "helm" using apps/v1`.
+var conflictManagerRe = regexp.MustCompile(`conflicts? with "([^"]+)"`)
+
+// applyConflictError explains a server-side apply failure caused by fields
+// owned by another field manager, such as a previous Helm install. Other
+// failures are returned unchanged.
+func applyConflictError(stderr, fieldManager string, err error) error {
+	if !strings.Contains(stderr, "Apply failed with") {
+		return err
+	}
+	var managers []string
+	seen := map[string]bool{}
+	for _, m := range conflictManagerRe.FindAllStringSubmatch(stderr, -1) {
+		if !seen[m[1]] {
+			seen[m[1]] = true
+			managers = append(managers, m[1])
+		}
+	}
+	owner := "another field manager"
+	if len(managers) > 0 {
+		owner = "field manager(s) " + strings.Join(managers, ", ")
+	}
+	return fmt.Errorf("fields are owned by %s, not %s (for example after installing with Helm); "+
+		"re-run with --force-conflicts to take ownership: %w", owner, fieldManager, err)
+}
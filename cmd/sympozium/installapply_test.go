@@ -0,0 +1,42 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestServerSideApplyArgs(t *testing.T) {
+	got := strings.Join(serverSideApplyArgs("config/rbac/", "sympozium-cli", false), " ")
+	if want := "apply --server-side --field-manager=sympozium-cli -f config/rbac/"; got != want {
+		t.Errorf("args = %q, want %q", got, want)
+	}
+	got = strings.Join(serverSideApplyArgs("config/rbac/", "gitops", true), " ")
+	if want := "apply --server-side --field-manager=gitops --force-conflicts -f config/rbac/"; got != want {
+		t.Errorf("forced args = %q, want %q", got, want)
+	}
+}
+
+func TestApplyConflictError(t *testing.T) {
+	exitErr := errors.New("exit status 1")
+	stderr := `error: Apply failed with 2 conflicts: conflicts with "helm" using apps/v1:
+- .spec.replicas
+- .spec.template.spec.containers[name="manager"].image
+Please review the fields above--they currently have other managers.
+Error from server (Conflict): Apply failed with 1 conflict: conflict with "kube-controller-manager" using v1: .data
+error: Apply failed with 1 conflict: conflict with "helm" using v1: .metadata.labels`
+
+	err := applyConflictError(stderr, "sympozium-cli", exitErr)
+	if !errors.Is(err, exitErr) {
+		t.Errorf("conflict error does not wrap the kubectl error: %v", err)
+	}
+	for _, want := range []string{"field manager(s) helm, kube-controller-manager", "not sympozium-cli", "--force-conflicts"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not contain %q", err, want)
+		}
+	}
+
+	if err := applyConflictError("error: the server could not find the requested resource", "sympozium-cli", exitErr); err != exitErr {
+		t.Errorf("other failure = %v, want it unchanged", err)
+	}
+}
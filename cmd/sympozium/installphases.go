@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ── install phases ──────────────────────────────────────────────────────────
+
+// installPhase is one step of `install` that changes the cluster.
+type installPhase struct {
+	name string
+	run  func() error
+}
+
+// installPhaseResult is the outcome of an attempted phase.
+type installPhaseResult struct {
+	name string
+	err  error
+}
+
+// runInstallPhases runs phases in order. By default it stops at the first
+// failure; with continueOnError it attempts every phase. When anything
+// failed, a summary of succeeded, failed and skipped phases with recovery
+// advice is written to w and an error naming the failed phases is returned.
+func runInstallPhases(w io.Writer, phases []installPhase, continueOnError bool) error {
+	var results []installPhaseResult
+	var failed []string
+	for _, p := range phases {
+		err := p.run()
+		results = append(results, installPhaseResult{name: p.name, err: err})
+		if err != nil {
+			failed = append(failed, p.name)
+			if !continueOnError {
+				break
+			}
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+
+	fmt.Fprintln(w, "\n  Install incomplete:")
+	for _, r := range results {
+		if r.err != nil {
+			fmt.Fprintf(w, "    ✗ %s: %v\n", r.name, r.err)
+		} else {
+			fmt.Fprintf(w, "    ✓ %s\n", r.name)
+		}
+	}
+	for _, p := range phases[len(results):] {
+		fmt.Fprintf(w, "    - %s (not attempted)\n", p.name)
+	}
+	fmt.Fprintln(w, "\n  The control plane is only partially installed. Fix the problem above and")
+	fmt.Fprintln(w, "  re-run 'sympozium install' (already applied phases are safe to re-apply),")
+	fmt.Fprintln(w, "  or remove what was installed with 'sympozium uninstall'.")
+	if !continueOnError && len(results) < len(phases) {
+		fmt.Fprintln(w, "  Use --continue-on-error to attempt the remaining phases anyway.")
+	}
+	return fmt.Errorf("install failed in phase(s): %s", strings.Join(failed, ", "))
+}
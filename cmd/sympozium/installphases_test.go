@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRunInstallPhases(t *testing.T) {
+	var ran []string
+	phase := func(name string, err error) installPhase {
+		return installPhase{name, func() error { ran = append(ran, name); return err }}
+	}
+	phases := func() []installPhase {
+		return []installPhase{
+			phase("CRDs", nil),
+			phase("Control plane", nil),
+			phase("Webhook", errors.New("exit status 1")),
+			phase("Network policies", nil),
+			phase("Defaults", errors.New("boom")),
+		}
+	}
+
+	t.Run("stops at first failure", func(t *testing.T) {
+		ran = nil
+		var out bytes.Buffer
+		err := runInstallPhases(&out, phases(), false)
+		if err == nil || err.Error() != "install failed in phase(s): Webhook" {
+			t.Fatalf("err = %v", err)
+		}
+		if strings.Join(ran, ",") != "CRDs,Control plane,Webhook" {
+			t.Errorf("ran %v", ran)
+		}
+		for _, want := range []string{
+			"✓ Control plane", "✗ Webhook: exit status 1", "- Network policies (not attempted)",
+			"sympozium uninstall", "--continue-on-error",
+		} {
+			if !strings.Contains(out.String(), want) {
+				t.Errorf("summary missing %q:\n%s", want, out.String())
+			}
+		}
+	})
+
+	t.Run("continue on error", func(t *testing.T) {
+		ran = nil
+		var out bytes.Buffer
+		err := runInstallPhases(&out, phases(), true)
+		if err == nil || err.Error() != "install failed in phase(s): Webhook, Defaults" {
+			t.Fatalf("err = %v", err)
+		}
+		if len(ran) != 5 {
+			t.Errorf("ran %v, want all phases", ran)
+		}
+		if strings.Contains(out.String(), "not attempted") || strings.Contains(out.String(), "--continue-on-error") {
+			t.Errorf("unexpected summary:\n%s", out.String())
+		}
+	})
+
+	t.Run("success prints nothing", func(t *testing.T) {
+		var out bytes.Buffer
+		if err := runInstallPhases(&out, []installPhase{phase("CRDs", nil)}, false); err != nil || out.Len() != 0 {
+			t.Errorf("err = %v, out = %q", err, out.String())
+		}
+	})
+}
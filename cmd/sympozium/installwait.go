@@ -0,0 +1,215 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ── install waits ───────────────────────────────────────────────────────────
+
+// Defaults for --timeout and --poll-interval.
+const (
+	defaultWaitTimeout  = 2 * time.Minute
+	defaultPollInterval = 2 * time.Second
+)
+
+// waitOptions bound the polling done by install, uninstall and status:
+// each polled condition gets Timeout and is re-checked every PollInterval.
+type waitOptions struct {
+	Timeout      time.Duration
+	PollInterval time.Duration
+}
+
+// addWaitFlags registers --timeout and --poll-interval on cmd.
+func addWaitFlags(cmd *cobra.Command, opts *waitOptions, what string) {
+	cmd.Flags().DurationVar(&opts.Timeout, "timeout", defaultWaitTimeout, "How long to wait for "+what)
+	cmd.Flags().DurationVar(&opts.PollInterval, "poll-interval", defaultPollInterval, "How often to re-check while waiting")
+}
+
+// validate rejects non-positive durations and a poll interval longer than
+// the timeout.
+func (o waitOptions) validate() error {
+	if o.Timeout <= 0 {
+		return fmt.Errorf("--timeout must be positive, got %s", o.Timeout)
+	}
+	if o.PollInterval <= 0 {
+		return fmt.Errorf("--poll-interval must be positive, got %s", o.PollInterval)
+	}
+	if o.PollInterval > o.Timeout {
+		return fmt.Errorf("--poll-interval %s is longer than --timeout %s", o.PollInterval, o.Timeout)
+	}
+	return nil
+}
+
+// pollUntil calls check every PollInterval until it reports done or fails.
+// After Timeout it gives up with an error naming what was waited for and,
+// when check returned one, the last reason it was not done.
+func pollUntil(opts waitOptions, what string, check func() (done bool, reason string, err error)) error {
+	deadline := time.Now().Add(opts.Timeout)
+	for {
+		done, reason, err := check()
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+		if !time.Now().Add(opts.PollInterval).Before(deadline) {
+			msg := fmt.Sprintf("timed out after %s waiting for %s", opts.Timeout, what)
+			if reason != "" {
+				msg += ": " + reason
+			}
+			return fmt.Errorf("%s (raise --timeout on slow clusters)", msg)
+		}
+		time.Sleep(opts.PollInterval)
+	}
+}
+
+// kubectlGetJSON runs `kubectl get <args> -o json` and returns its output.
+func kubectlGetJSON(args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("kubectl", append(append([]string{"get"}, args...), "-o", "json")...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("kubectl get %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// crdList is the part of a CustomResourceDefinitionList that
+// notEstablishedCRDs reads.
+type crdList struct {
+	Items []struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+		Status struct {
+			Conditions []struct {
+				Type   string `json:"type"`
+				Status string `json:"status"`
+			} `json:"conditions"`
+		} `json:"status"`
+	} `json:"items"`
+}
+
+// notEstablishedCRDs returns the names of the Sympozium CRDs in a
+// `kubectl get crd -o json` list that are not yet Established, and how many
+// Sympozium CRDs the list holds.
+func notEstablishedCRDs(data []byte) (pending []string, total int, err error) {
+	var list crdList
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, 0, fmt.Errorf("decode CRD list: %w", err)
+	}
+	for _, crd := range list.Items {
+		if !strings.HasSuffix(crd.Metadata.Name, ".sympozium.ai") {
+			continue
+		}
+		total++
+		established := false
+		for _, c := range crd.Status.Conditions {
+			established = established || (c.Type == "Established" && c.Status == "True")
+		}
+		if !established {
+			pending = append(pending, crd.Metadata.Name)
+		}
+	}
+	sort.Strings(pending)
+	return pending, total, nil
+}
+
+// waitForCRDsEstablished waits until the API server serves every Sympozium
+// CRD, so the resources applied after them are accepted.
+func waitForCRDsEstablished(opts waitOptions) error {
+	fmt.Println("  Waiting for CRDs to be established...")
+	return pollUntil(opts, "CRDs to be established", func() (bool, string, error) {
+		data, err := kubectlGetJSON("crd")
+		if err != nil {
+			return false, "", err
+		}
+		pending, total, err := notEstablishedCRDs(data)
+		switch {
+		case err != nil:
+			return false, "", err
+		case total == 0:
+			return false, "no Sympozium CRDs found", nil
+		case len(pending) > 0:
+			return false, "not established: " + strings.Join(pending, ", "), nil
+		}
+		return true, "", nil
+	})
+}
+
+// unavailableDeployments returns the deployments in a `kubectl get
+// deployments -o json` list that are not Available. With names, only those
+// are checked and a missing one is reported as not found; without, every
+// listed deployment must be Available and an empty list is not ready.
+func unavailableDeployments(data []byte, names ...string) ([]string, error) {
+	var list appsv1.DeploymentList
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("decode deployment list: %w", err)
+	}
+	available := map[string]bool{}
+	for _, d := range list.Items {
+		ok := false
+		for _, c := range d.Status.Conditions {
+			ok = ok || (c.Type == appsv1.DeploymentAvailable && c.Status == corev1.ConditionTrue)
+		}
+		available[d.Name] = ok
+	}
+	var pending []string
+	if len(names) == 0 {
+		if len(list.Items) == 0 {
+			return []string{"(none found)"}, nil
+		}
+		for _, d := range list.Items {
+			if !available[d.Name] {
+				pending = append(pending, d.Name)
+			}
+		}
+		return pending, nil
+	}
+	for _, n := range names {
+		ok, found := available[n]
+		switch {
+		case !found:
+			pending = append(pending, n+" (not found)")
+		case !ok:
+			pending = append(pending, n)
+		}
+	}
+	return pending, nil
+}
+
+// waitForDeploymentsAvailable waits until the deployments in namespace ns
+// are Available: the named ones, or with no names every deployment
+// matching selector.
+func waitForDeploymentsAvailable(opts waitOptions, ns, selector string, names ...string) error {
+	args := []string{"deployments", "-n", ns}
+	if selector != "" {
+		args = append(args, "-l", selector)
+	}
+	return pollUntil(opts, "deployments in "+ns+" to be available", func() (bool, string, error) {
+		data, err := kubectlGetJSON(args...)
+		if err != nil {
+			return false, "", err
+		}
+		pending, err := unavailableDeployments(data, names...)
+		if err != nil {
+			return false, "", err
+		}
+		if len(pending) > 0 {
+			return false, "not available: " + strings.Join(pending, ", "), nil
+		}
+		return true, "", nil
+	})
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWaitOptionsValidate(t *testing.T) {
+	tests := []struct {
+		opts    waitOptions
+		wantErr string
+	}{
+		{waitOptions{Timeout: defaultWaitTimeout, PollInterval: defaultPollInterval}, ""},
+		{waitOptions{Timeout: 0, PollInterval: time.Second}, "--timeout"},
+		{waitOptions{Timeout: time.Minute, PollInterval: -time.Second}, "--poll-interval"},
+		{waitOptions{Timeout: time.Second, PollInterval: time.Minute}, "longer than --timeout"},
+	}
+	for _, tt := range tests {
+		err := tt.opts.validate()
+		if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("validate(%+v) = %v, want %q", tt.opts, err, tt.wantErr)
+		}
+	}
+}
+
+func TestPollUntil(t *testing.T) {
+	opts := waitOptions{Timeout: time.Second, PollInterval: time.Millisecond}
+
+	calls := 0
+	err := pollUntil(opts, "thing", func() (bool, string, error) {
+		calls++
+		return calls == 3, "", nil
+	})
+	if err != nil || calls != 3 {
+		t.Errorf("done on third check: err = %v after %d calls", err, calls)
+	}
+
+	boom := errors.New("boom")
+	if err := pollUntil(opts, "thing", func() (bool, string, error) { return false, "", boom }); !errors.Is(err, boom) {
+		t.Errorf("check error: err = %v", err)
+	}
+
+	opts = waitOptions{Timeout: 20 * time.Millisecond, PollInterval: 5 * time.Millisecond}
+	start := time.Now()
+	err = pollUntil(opts, "the widget", func() (bool, string, error) { return false, "still spinning", nil })
+	if err == nil || !strings.Contains(err.Error(), "timed out after 20ms waiting for the widget: still spinning") {
+		t.Errorf("timeout: err = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("timeout took %s", elapsed)
+	}
+}
+
+func TestNotEstablishedCRDs(t *testing.T) {
+	data := []byte(`{"items":[
+		{"metadata":{"name":"agentruns.sympozium.ai"},"status":{"conditions":[{"type":"NamesAccepted","status":"True"},{"type":"Established","status":"True"}]}},
+		{"metadata":{"name":"skillpacks.sympozium.ai"},"status":{"conditions":[{"type":"Established","status":"False"}]}},
+		{"metadata":{"name":"personapacks.sympozium.ai"}},
+		{"metadata":{"name":"certificates.cert-manager.io"}}
+	]}`)
+	pending, total, err := notEstablishedCRDs(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 3 || !reflect.DeepEqual(pending, []string{"personapacks.sympozium.ai", "skillpacks.sympozium.ai"}) {
+		t.Errorf("pending = %v of %d", pending, total)
+	}
+	if _, _, err := notEstablishedCRDs([]byte("not json")); err == nil {
+		t.Error("want an error for invalid JSON")
+	}
+}
+
+func TestUnavailableDeployments(t *testing.T) {
+	data := []byte(`{"items":[
+		{"metadata":{"name":"cert-manager"},"status":{"conditions":[{"type":"Available","status":"True"}]}},
+		{"metadata":{"name":"cert-manager-webhook"},"status":{"conditions":[{"type":"Available","status":"False"}]}},
+		{"metadata":{"name":"other"}}
+	]}`)
+
+	got, err := unavailableDeployments(data, "cert-manager", "cert-manager-webhook", "cert-manager-cainjector")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"cert-manager-webhook", "cert-manager-cainjector (not found)"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("named = %v, want %v", got, want)
+	}
+
+	if got, _ := unavailableDeployments(data); !reflect.DeepEqual(got, []string{"cert-manager-webhook", "other"}) {
+		t.Errorf("all = %v", got)
+	}
+	if got, _ := unavailableDeployments([]byte(`{"items":[]}`)); len(got) != 1 {
+		t.Errorf("empty list = %v, want it reported as not ready", got)
+	}
+	if got, _ := unavailableDeployments(data, "cert-manager"); len(got) != 0 {
+		t.Errorf("available = %v, want none pending", got)
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	sympoziumv1alpha1 "github.com/alexsjones/sympozium/api/v1alpha1"
+)
+
+// ── instances get --show-pods ───────────────────────────────────────────────
+
+// instancePodLabel is the label the controller puts on every pod it creates
+// for an instance: agent runs, channels and their sidecars alike.
+const instancePodLabel = "sympozium.ai/instance"
+
+var podColumns = []tableColumn[corev1.Pod]{
+	{header: "NAME", value: func(pod *corev1.Pod, _ bool) string { return pod.Name }},
+	{header: "COMPONENT", value: func(pod *corev1.Pod, _ bool) string {
+		return orNone(pod.Labels["sympozium.ai/component"])
+	}},
+	{header: "PHASE", value: func(pod *corev1.Pod, _ bool) string { return string(pod.Status.Phase) }},
+	{header: "NODE", value: func(pod *corev1.Pod, _ bool) string { return orNone(pod.Spec.NodeName) }},
+	{header: "RESTARTS", value: func(pod *corev1.Pod, _ bool) string { return fmt.Sprint(podRestarts(pod)) }},
+	ageColumn(func(pod *corev1.Pod) time.Time { return pod.CreationTimestamp.Time }),
+}
+
+// orNone returns s, or "<none>" when s is empty, as kubectl prints unset
+// fields.
+func orNone(s string) string {
+	if s == "" {
+		return "<none>"
+	}
+	return s
+}
+
+// podRestarts sums the restart counts of the pod's init and app containers.
+func podRestarts(pod *corev1.Pod) int32 {
+	var n int32
+	for _, cs := range pod.Status.InitContainerStatuses {
+		n += cs.RestartCount
+	}
+	for _, cs := range pod.Status.ContainerStatuses {
+		n += cs.RestartCount
+	}
+	return n
+}
+
+// listInstancePods returns the pods in the instance's namespace that carry
+// its instance label, oldest first.
+func listInstancePods(ctx context.Context, c client.Reader, inst *sympoziumv1alpha1.SympoziumInstance) ([]corev1.Pod, error) {
+	var pods corev1.PodList
+	if err := c.List(ctx, &pods, client.InNamespace(inst.Namespace), client.MatchingLabels{instancePodLabel: inst.Name}); err != nil {
+		return nil, fmt.Errorf("listing pods of instance %s: %w", inst.Name, err)
+	}
+	sort.Slice(pods.Items, func(i, j int) bool {
+		a, b := pods.Items[i].CreationTimestamp, pods.Items[j].CreationTimestamp
+		if !a.Equal(&b) {
+			return a.Before(&b)
+		}
+		return pods.Items[i].Name < pods.Items[j].Name
+	})
+	return pods.Items, nil
+}
+
+// instanceWithPodsList wraps the instance and its pods in a v1 List, the
+// shape kubectl uses for a get of several objects.
+func instanceWithPodsList(inst *sympoziumv1alpha1.SympoziumInstance, pods []corev1.Pod) *corev1.List {
+	list := &corev1.List{Items: []runtime.RawExtension{{Object: inst}}}
+	for i := range pods {
+		stripManagedFields(&pods[i])
+		list.Items = append(list.Items, runtime.RawExtension{Object: &pods[i]})
+	}
+	return list
+}
+
+// printInstancePods writes the instance's status line followed by a table of
+// its pods.
+func printInstancePods(w io.Writer, inst *sympoziumv1alpha1.SympoziumInstance, pods []corev1.Pod) error {
+	if err := printTable(w, instanceColumns, []sympoziumv1alpha1.SympoziumInstance{*inst}, false); err != nil {
+		return err
+	}
+	fmt.Fprintln(w)
+	if len(pods) == 0 {
+		_, err := fmt.Fprintf(w, "No pods found for instance %s.\n", inst.Name)
+		return err
+	}
+	return printTable(w, podColumns, pods, false)
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sympoziumv1alpha1 "github.com/alexsjones/sympozium/api/v1alpha1"
+)
+
+func TestListInstancePods(t *testing.T) {
+	now := time.Now()
+	pod := func(name, ns, instance, component string, created time.Time) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name, Namespace: ns, CreationTimestamp: metav1.NewTime(created),
+				Labels: map[string]string{instancePodLabel: instance, "sympozium.ai/component": component},
+			},
+			Spec: corev1.PodSpec{NodeName: "node-1"},
+			Status: corev1.PodStatus{
+				Phase:                 corev1.PodRunning,
+				InitContainerStatuses: []corev1.ContainerStatus{{RestartCount: 1}},
+				ContainerStatuses:     []corev1.ContainerStatus{{RestartCount: 2}, {RestartCount: 3}},
+			},
+		}
+	}
+	pending := pod("run-b", "default", "my-agent", "agent-run", now.Add(-time.Minute))
+	pending.Spec.NodeName, pending.Status = "", corev1.PodStatus{Phase: corev1.PodPending}
+	inst := &sympoziumv1alpha1.SympoziumInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-agent", Namespace: "default"},
+		Status:     sympoziumv1alpha1.SympoziumInstanceStatus{Phase: "Running", ActiveAgentPods: 1},
+	}
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(
+		inst,
+		pending,
+		pod("channel-a", "default", "my-agent", "channel", now.Add(-time.Hour)),
+		pod("other", "default", "other-agent", "agent-run", now.Add(-time.Hour)),
+		pod("elsewhere", "prod", "my-agent", "agent-run", now.Add(-time.Hour)),
+	).Build()
+
+	pods, err := listInstancePods(context.Background(), c, inst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, p := range pods {
+		names = append(names, p.Name)
+	}
+	if got := strings.Join(names, ","); got != "channel-a,run-b" {
+		t.Fatalf("pods = %s, want channel-a,run-b (oldest first, this instance and namespace only)", got)
+	}
+
+	var out bytes.Buffer
+	if err := printInstancePods(&out, inst, pods); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimRight(out.String(), "\n"), "\n")
+	want := [][]string{
+		{"NAME", "PHASE", "CHANNELS", "AGENT", "PODS", "AGE"},
+		{"my-agent", "Running", "1"},
+		{},
+		{"NAME", "COMPONENT", "PHASE", "NODE", "RESTARTS", "AGE"},
+		{"channel-a", "channel", "Running", "node-1", "6"},
+		{"run-b", "agent-run", "Pending", "<none>", "0"},
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("output:\n%s", out.String())
+	}
+	for i, w := range want {
+		if got := strings.Fields(lines[i]); len(w) > 0 && strings.Join(got[:len(w)], " ") != strings.Join(w, " ") {
+			t.Errorf("line %d = %q, want it to start with %q", i, lines[i], w)
+		}
+	}
+
+	out.Reset()
+	if err := printInstancePods(&out, inst, nil); err != nil || !strings.Contains(out.String(), "No pods found for instance my-agent.") {
+		t.Errorf("no pods: %q, %v", out.String(), err)
+	}
+}
+
+func TestInstanceWithPodsList(t *testing.T) {
+	inst := &sympoziumv1alpha1.SympoziumInstance{ObjectMeta: metav1.ObjectMeta{Name: "my-agent"}}
+	pods := []corev1.Pod{{ObjectMeta: metav1.ObjectMeta{
+		Name:          "run-a",
+		ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "kubectl"}},
+	}}}
+	list := instanceWithPodsList(inst, pods)
+	setTypeMeta(list)
+	if list.Kind != "List" || list.APIVersion != "v1" || len(list.Items) != 2 {
+		t.Fatalf("list = %s/%s with %d items, want v1/List with 2", list.APIVersion, list.Kind, len(list.Items))
+	}
+	if list.Items[0].Object != inst {
+		t.Error("the instance should be the first item")
+	}
+	if p := list.Items[1].Object.(*corev1.Pod); p.Name != "run-a" || p.ManagedFields != nil {
+		t.Errorf("pod item = %s with managedFields %v", p.Name, p.ManagedFields)
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	sympoziumv1alpha1 "github.com/alexsjones/sympozium/api/v1alpha1"
+)
+
+// ── @last run shorthand ─────────────────────────────────────────────────────
+
+// lastRunRef is the argument that refers to the most recently created run.
+const lastRunRef = "@last"
+
+// noRecord disables recording created runs for @last (--no-record).
+var noRecord bool
+
+// addNoRecordFlag registers --no-record as a persistent flag of root, so
+// scripts can pass it to any command that creates runs, not only the TUI.
+func addNoRecordFlag(root *cobra.Command) {
+	root.PersistentFlags().BoolVar(&noRecord, "no-record", false, "Do not remember created runs as @last")
+}
+
+// lastRunStatePath returns the file holding the last created run, next to
+// the CLI config file.
+func lastRunStatePath() (string, error) {
+	cfg, err := cliConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(cfg), "last-run"), nil
+}
+
+// recordLastRun remembers ns/name as the run @last refers to, unless
+// --no-record is set.
+func recordLastRun(ns, name string) error {
+	if noRecord {
+		return nil
+	}
+	path, err := lastRunStatePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(ns+"/"+name+"\n"), 0o644)
+}
+
+// readLastRun returns the recorded run.
+func readLastRun() (types.NamespacedName, error) {
+	path, err := lastRunStatePath()
+	if err != nil {
+		return types.NamespacedName{}, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return types.NamespacedName{}, fmt.Errorf("no run has been recorded yet (%s does not exist); pass a run name instead of %s", path, lastRunRef)
+	}
+	if err != nil {
+		return types.NamespacedName{}, err
+	}
+	ns, name, ok := strings.Cut(strings.TrimSpace(string(data)), "/")
+	if !ok || ns == "" || name == "" {
+		return types.NamespacedName{}, fmt.Errorf("%s is corrupt (want namespace/name); pass a run name instead of %s", path, lastRunRef)
+	}
+	return types.NamespacedName{Namespace: ns, Name: name}, nil
+}
+
+// resolveRunArg turns a command's optional run argument into the run's key.
+// A name is looked up in --namespace; no argument or @last means the last
+// run this CLI created, in the namespace it was created in, and must still
+// exist.
+func resolveRunArg(ctx context.Context, c client.Reader, args []string) (types.NamespacedName, error) {
+	if len(args) > 0 && args[0] != lastRunRef {
+		return types.NamespacedName{Name: args[0], Namespace: namespace}, nil
+	}
+	key, err := readLastRun()
+	if err != nil {
+		return key, err
+	}
+	var run sympoziumv1alpha1.AgentRun
+	if err := c.Get(ctx, key, &run); err != nil {
+		if apierrors.IsNotFound(err) {
+			return key, fmt.Errorf("the last recorded run %s no longer exists", key)
+		}
+		return key, err
+	}
+	return key, nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sympoziumv1alpha1 "github.com/alexsjones/sympozium/api/v1alpha1"
+)
+
+func TestResolveRunArg_LastRun(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("SYMPOZIUM_CONFIG", filepath.Join(dir, "config.yaml"))
+	prevNS := namespace
+	namespace = "default"
+	t.Cleanup(func() { namespace = prevNS })
+
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(
+		&sympoziumv1alpha1.AgentRun{ObjectMeta: metav1.ObjectMeta{Name: "r1", Namespace: "team-a"}},
+	).Build()
+	ctx := context.Background()
+
+	if _, err := resolveRunArg(ctx, c, nil); err == nil || !strings.Contains(err.Error(), "no run has been recorded") {
+		t.Errorf("no state: err = %v, want a helpful error", err)
+	}
+
+	if err := recordLastRun("team-a", "r1"); err != nil {
+		t.Fatal(err)
+	}
+	want := types.NamespacedName{Namespace: "team-a", Name: "r1"}
+	for _, args := range [][]string{nil, {"@last"}} {
+		if got, err := resolveRunArg(ctx, c, args); err != nil || got != want {
+			t.Errorf("args %v: got %v, %v; want %v", args, got, err, want)
+		}
+	}
+
+	// An explicit name uses --namespace and is not checked here.
+	if got, _ := resolveRunArg(ctx, c, []string{"other"}); got != (types.NamespacedName{Namespace: "default", Name: "other"}) {
+		t.Errorf("explicit name resolved to %v", got)
+	}
+
+	if err := recordLastRun("team-a", "gone"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := resolveRunArg(ctx, c, nil); err == nil || !strings.Contains(err.Error(), "team-a/gone no longer exists") {
+		t.Errorf("deleted run: err = %v, want a no-longer-exists error", err)
+	}
+}
+
+func TestRecordLastRun_NoRecord(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("SYMPOZIUM_CONFIG", filepath.Join(dir, "config.yaml"))
+	noRecord = true
+	t.Cleanup(func() { noRecord = false })
+
+	if err := recordLastRun("default", "r1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "last-run")); !os.IsNotExist(err) {
+		t.Errorf("state file written despite --no-record (stat err = %v)", err)
+	}
+}
+
+func TestNoRecordFlag_Subcommands(t *testing.T) {
+	t.Cleanup(func() { noRecord = false })
+	root := &cobra.Command{Use: "sympozium"}
+	addNoRecordFlag(root)
+	var ran bool
+	root.AddCommand(&cobra.Command{Use: "create", RunE: func(*cobra.Command, []string) error { ran = true; return nil }})
+	root.SetArgs([]string{"create", "--no-record"})
+	if err := root.Execute(); err != nil || !ran {
+		t.Fatalf("create --no-record: ran = %v, err = %v", ran, err)
+	}
+	if !noRecord {
+		t.Error("--no-record on a subcommand did not disable recording")
+	}
+}
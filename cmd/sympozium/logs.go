@@ -0,0 +1,521 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	sympoziumv1alpha1 "github.com/alexsjones/sympozium/api/v1alpha1"
+)
+
+// ── instances logs ──────────────────────────────────────────────────────────
+
+// instanceLogPollInterval is how often a following `instances logs` looks for
+// new agent pods.
+var instanceLogPollInterval = 2 * time.Second
+
+// instanceLogOptions controls which logs `instances logs` prints.
+type instanceLogOptions struct {
+	Container string
+	Follow    bool
+	TailLines int64
+}
+
+func newInstancesLogsCmd() *cobra.Command {
+	opts := instanceLogOptions{Container: "agent"}
+	cmd := &cobra.Command{
+		Use:   "logs [name]",
+		Short: "Print logs from all agent pods of a SympoziumInstance",
+		Long: `Print the agent container logs of every pod belonging to a SympoziumInstance,
+each line prefixed with [pod-name].
+
+With -f the logs are followed: pods that start later are picked up
+automatically and pods that go away are dropped.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cs, err := newClientset()
+			if err != nil {
+				return err
+			}
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			return streamInstanceLogs(ctx, cs, namespace, args[0], opts, os.Stdout)
+		},
+	}
+	cmd.Flags().BoolVarP(&opts.Follow, "follow", "f", false, "Follow the logs of current and future agent pods")
+	cmd.Flags().Int64Var(&opts.TailLines, "tail", -1, "Lines of recent log to show per pod (-1 for all)")
+	cmd.Flags().StringVarP(&opts.Container, "container", "c", opts.Container, "Container to read logs from")
+	return cmd
+}
+
+// streamInstanceLogs writes the logs of the instance's agent pods to w, one
+// prefixed line at a time. Without Follow each pod's logs are printed in pod
+// creation order; with Follow all pods are streamed concurrently and the pod
+// list is re-checked every instanceLogPollInterval until ctx is done.
+func streamInstanceLogs(ctx context.Context, cs kubernetes.Interface, ns, instance string, opts instanceLogOptions, w io.Writer) error {
+	out := &lineWriter{w: w}
+
+	if !opts.Follow {
+		pods, err := instancePods(ctx, cs, ns, instance)
+		if err != nil {
+			return err
+		}
+		if len(pods) == 0 {
+			return fmt.Errorf("no agent pods found for instance %s", instance)
+		}
+		for _, pod := range pods {
+			if err := copyPodLogs(ctx, cs, &pod, opts, out); err != nil {
+				out.printf("[%s] error: %v\n", pod.Name, err)
+			}
+		}
+		return nil
+	}
+
+	var (
+		mu        sync.Mutex
+		streaming = map[string]bool{} // pods with an open log stream
+		finished  = map[string]bool{} // pods whose agent container has exited and been fully read
+		wg        sync.WaitGroup
+	)
+	ticker := time.NewTicker(instanceLogPollInterval)
+	defer ticker.Stop()
+	for {
+		pods, err := instancePods(ctx, cs, ns, instance)
+		if err != nil && ctx.Err() == nil {
+			out.printf("error listing pods: %v\n", err)
+		}
+		present := map[string]bool{}
+		for i := range pods {
+			pod := pods[i]
+			present[pod.Name] = true
+			mu.Lock()
+			skip := streaming[pod.Name] || finished[pod.Name]
+			if !skip {
+				streaming[pod.Name] = true
+			}
+			mu.Unlock()
+			if skip {
+				continue
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				err := copyPodLogs(ctx, cs, &pod, opts, out)
+				mu.Lock()
+				defer mu.Unlock()
+				delete(streaming, pod.Name)
+				// A stream that opened and ended cleanly means the container
+				// has exited; one that failed to open (e.g. the container is
+				// still starting) is retried on the next poll.
+				if err == nil {
+					finished[pod.Name] = true
+				}
+			}()
+		}
+		// Forget pods that have been deleted so a recreated pod with the same
+		// name is streamed again.
+		mu.Lock()
+		for name := range finished {
+			if !present[name] {
+				delete(finished, name)
+			}
+		}
+		mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// instancePods lists the agent pods of an instance, oldest first.
+func instancePods(ctx context.Context, cs kubernetes.Interface, ns, instance string) ([]corev1.Pod, error) {
+	list, err := cs.CoreV1().Pods(ns).List(ctx, metav1.ListOptions{
+		LabelSelector: "sympozium.ai/instance=" + instance + ",sympozium.ai/component=agent-run",
+	})
+	if err != nil {
+		return nil, err
+	}
+	pods := list.Items
+	sort.Slice(pods, func(i, j int) bool {
+		return pods[i].CreationTimestamp.Before(&pods[j].CreationTimestamp)
+	})
+	return pods, nil
+}
+
+// copyPodLogs streams one pod's container logs to out with a [pod] prefix.
+func copyPodLogs(ctx context.Context, cs kubernetes.Interface, pod *corev1.Pod, opts instanceLogOptions, out *lineWriter) error {
+	logOpts := &corev1.PodLogOptions{Container: opts.Container, Follow: opts.Follow}
+	if opts.TailLines >= 0 {
+		tail := opts.TailLines
+		logOpts.TailLines = &tail
+	}
+	return copyLogs(ctx, cs, pod.Namespace, pod.Name, logOpts, "["+pod.Name+"] ", out)
+}
+
+// copyLogs streams the logs selected by logOpts to out, one line at a time,
+// each starting with prefix.
+func copyLogs(ctx context.Context, cs kubernetes.Interface, ns, pod string, logOpts *corev1.PodLogOptions, prefix string, out *lineWriter) error {
+	stream, err := cs.CoreV1().Pods(ns).GetLogs(pod, logOpts).Stream(ctx)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	scanner := bufio.NewScanner(stream)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		out.printf("%s%s\n", prefix, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		return err
+	}
+	return nil
+}
+
+// ── runs logs ───────────────────────────────────────────────────────────────
+
+// runLogOptions controls which logs `runs logs` prints.
+type runLogOptions struct {
+	Container     string
+	AllContainers bool
+	Previous      bool
+	Follow        bool
+	TailLines     int64
+}
+
+func newRunsLogsCmd() *cobra.Command {
+	opts := runLogOptions{Container: "agent"}
+	cmd := &cobra.Command{
+		Use:   "logs [name|@last]",
+		Short: "Print logs from an AgentRun pod",
+		Long: `Print the logs of an AgentRun's pod. By default the agent container is
+shown; -c selects a sidecar and --all-containers prints every container with
+a [container] prefix. --previous shows the logs of the previous, crashed
+instance of the container and cannot be combined with -f.
+
+Without a name, or with @last, the run most recently created by this CLI is
+used.`,
+		Example: `  sympozium runs logs my-run -f
+  sympozium runs logs my-run --previous
+  sympozium runs logs my-run --all-containers
+  sympozium runs logs @last -f`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if opts.Previous && opts.Follow {
+				return fmt.Errorf("only one of --follow (-f) or --previous (-p) is allowed")
+			}
+			if opts.AllContainers && cmd.Flags().Changed("container") {
+				return fmt.Errorf("--container cannot be combined with --all-containers")
+			}
+			key, err := resolveRunArg(context.Background(), k8sClient, args)
+			if err != nil {
+				return err
+			}
+			var run sympoziumv1alpha1.AgentRun
+			if err := k8sClient.Get(context.Background(), key, &run); err != nil {
+				return err
+			}
+			if run.Status.PodName == "" {
+				return fmt.Errorf("agentrun %s has no pod yet (phase: %s)", key.Name, run.Status.Phase)
+			}
+			cs, err := newClientset()
+			if err != nil {
+				return err
+			}
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			return streamRunLogs(ctx, cs, key.Namespace, run.Status.PodName, opts, os.Stdout)
+		},
+	}
+	cmd.Flags().BoolVarP(&opts.Follow, "follow", "f", false, "Follow the log output")
+	cmd.Flags().BoolVarP(&opts.Previous, "previous", "p", false, "Print the logs of the previous instance of the container")
+	cmd.Flags().StringVarP(&opts.Container, "container", "c", opts.Container, "Container to read logs from")
+	cmd.Flags().BoolVar(&opts.AllContainers, "all-containers", false, "Print the logs of every container, prefixed with [container]")
+	cmd.Flags().Int64Var(&opts.TailLines, "tail", -1, "Lines of recent log to show per container (-1 for all)")
+	return cmd
+}
+
+// streamRunLogs writes the logs of the selected containers of pod to w.
+// A single container is printed as is; with AllContainers each line is
+// prefixed with [container], and followed streams are interleaved as lines
+// arrive.
+func streamRunLogs(ctx context.Context, cs kubernetes.Interface, ns, podName string, opts runLogOptions, w io.Writer) error {
+	pod, err := cs.CoreV1().Pods(ns).Get(ctx, podName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	var names []string
+	for _, c := range pod.Spec.InitContainers {
+		names = append(names, c.Name)
+	}
+	for _, c := range pod.Spec.Containers {
+		names = append(names, c.Name)
+	}
+
+	containers := names
+	if !opts.AllContainers {
+		found := false
+		for _, n := range names {
+			found = found || n == opts.Container
+		}
+		if !found {
+			return fmt.Errorf("container %q not found in pod %s; available containers: %s",
+				opts.Container, podName, strings.Join(names, ", "))
+		}
+		containers = []string{opts.Container}
+	}
+
+	out := &lineWriter{w: w}
+	logOpts := func(container string) *corev1.PodLogOptions {
+		lo := &corev1.PodLogOptions{Container: container, Follow: opts.Follow, Previous: opts.Previous}
+		if opts.TailLines >= 0 {
+			tail := opts.TailLines
+			lo.TailLines = &tail
+		}
+		return lo
+	}
+	prefix := func(container string) string {
+		if opts.AllContainers {
+			return "[" + container + "] "
+		}
+		return ""
+	}
+
+	if !opts.Follow || len(containers) == 1 {
+		for _, c := range containers {
+			if err := copyLogs(ctx, cs, ns, podName, logOpts(c), prefix(c), out); err != nil {
+				if len(containers) == 1 {
+					return err
+				}
+				out.printf("%serror: %v\n", prefix(c), err)
+			}
+		}
+		return nil
+	}
+
+	var wg sync.WaitGroup
+	for _, c := range containers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := copyLogs(ctx, cs, ns, podName, logOpts(c), prefix(c), out); err != nil {
+				out.printf("%serror: %v\n", prefix(c), err)
+			}
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// ── logs (control plane) ────────────────────────────────────────────────────
+
+// controlPlaneDeployments maps each `logs` subcommand to the Deployment it
+// reads. Both the kustomize manifests and the Helm chart label them with
+// app.kubernetes.io/component set to the subcommand name.
+var controlPlaneDeployments = map[string]string{
+	"controller": "sympozium-controller-manager",
+	"apiserver":  "sympozium-apiserver",
+	"webhook":    "sympozium-webhook",
+}
+
+// controlPlaneLogOptions controls which logs `logs <component>` prints.
+type controlPlaneLogOptions struct {
+	InstallNamespace string
+	Follow           bool
+	TailLines        int64
+	Since            string
+}
+
+func newLogsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "logs",
+		Short: "Print logs of the Sympozium control plane",
+	}
+	for _, component := range []string{"controller", "apiserver", "webhook"} {
+		cmd.AddCommand(newControlPlaneLogsCmd(component))
+	}
+	return cmd
+}
+
+func newControlPlaneLogsCmd(component string) *cobra.Command {
+	var opts controlPlaneLogOptions
+	cmd := &cobra.Command{
+		Use:   component,
+		Short: fmt.Sprintf("Print logs from the %s pods", controlPlaneDeployments[component]),
+		Long: fmt.Sprintf(`Print the logs of the %s Deployment's pods. When it runs more
+than one replica, each line is prefixed with [pod-name].
+
+The namespace is found from the Deployment's labels, so it works wherever
+Sympozium was installed; --install-namespace skips the lookup.`, controlPlaneDeployments[component]),
+		Example: fmt.Sprintf(`  sympozium logs %[1]s -f
+  sympozium logs %[1]s --since 10m --tail 200`, component),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cs, err := newClientset()
+			if err != nil {
+				return err
+			}
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			return streamControlPlaneLogs(ctx, cs, component, opts, time.Now(), os.Stdout)
+		},
+	}
+	cmd.Flags().StringVar(&opts.InstallNamespace, "install-namespace", "", "Namespace of the Sympozium control plane (default: found from the Deployment labels)")
+	cmd.Flags().BoolVarP(&opts.Follow, "follow", "f", false, "Follow the log output")
+	cmd.Flags().Int64Var(&opts.TailLines, "tail", -1, "Lines of recent log to show per pod (-1 for all)")
+	cmd.Flags().StringVar(&opts.Since, "since", "", "Only show logs newer than a relative duration (30m, 6h, 2d) or an RFC3339 timestamp")
+	return cmd
+}
+
+// streamControlPlaneLogs writes the logs of the component's pods to w.
+func streamControlPlaneLogs(ctx context.Context, cs kubernetes.Interface, component string, opts controlPlaneLogOptions, now time.Time, w io.Writer) error {
+	logOpts := &corev1.PodLogOptions{Follow: opts.Follow}
+	if opts.TailLines >= 0 {
+		tail := opts.TailLines
+		logOpts.TailLines = &tail
+	}
+	if opts.Since != "" {
+		cutoff, err := parseSince(opts.Since, now)
+		if err != nil {
+			return err
+		}
+		logOpts.SinceTime = &metav1.Time{Time: cutoff}
+	}
+
+	deploy, err := findControlPlaneDeployment(ctx, cs, component, opts.InstallNamespace)
+	if err != nil {
+		return err
+	}
+	selector, err := metav1.LabelSelectorAsSelector(deploy.Spec.Selector)
+	if err != nil {
+		return fmt.Errorf("deployment %s/%s: %w", deploy.Namespace, deploy.Name, err)
+	}
+	list, err := cs.CoreV1().Pods(deploy.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return err
+	}
+	pods := list.Items
+	if len(pods) == 0 {
+		return fmt.Errorf("deployment %s/%s has no pods", deploy.Namespace, deploy.Name)
+	}
+	sort.Slice(pods, func(i, j int) bool {
+		return pods[i].CreationTimestamp.Before(&pods[j].CreationTimestamp)
+	})
+
+	out := &lineWriter{w: w}
+	prefix := func(pod *corev1.Pod) string {
+		if len(pods) == 1 {
+			return ""
+		}
+		return "[" + pod.Name + "] "
+	}
+	podLogOpts := func(pod *corev1.Pod) *corev1.PodLogOptions {
+		lo := *logOpts
+		lo.Container = defaultContainer(pod)
+		return &lo
+	}
+	if !opts.Follow || len(pods) == 1 {
+		for i := range pods {
+			if err := copyLogs(ctx, cs, pods[i].Namespace, pods[i].Name, podLogOpts(&pods[i]), prefix(&pods[i]), out); err != nil {
+				if len(pods) == 1 {
+					return err
+				}
+				out.printf("%serror: %v\n", prefix(&pods[i]), err)
+			}
+		}
+		return nil
+	}
+	var wg sync.WaitGroup
+	for i := range pods {
+		pod := &pods[i]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := copyLogs(ctx, cs, pod.Namespace, pod.Name, podLogOpts(pod), prefix(pod), out); err != nil {
+				out.printf("%serror: %v\n", prefix(pod), err)
+			}
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// findControlPlaneDeployment returns the component's Deployment from ns, or
+// when ns is empty, from whichever namespace has it.
+func findControlPlaneDeployment(ctx context.Context, cs kubernetes.Interface, component, ns string) (*appsv1.Deployment, error) {
+	name := controlPlaneDeployments[component]
+	if ns != "" {
+		deploy, err := cs.AppsV1().Deployments(ns).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("get deployment %s/%s: %w", ns, name, err)
+		}
+		return deploy, nil
+	}
+	list, err := cs.AppsV1().Deployments(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		LabelSelector: "app.kubernetes.io/component=" + component,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var found []*appsv1.Deployment
+	for i := range list.Items {
+		if list.Items[i].Name == name {
+			found = append(found, &list.Items[i])
+		}
+	}
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("no %s deployment found in any namespace; is Sympozium installed? (see 'sympozium status')", name)
+	case 1:
+		return found[0], nil
+	}
+	var namespaces []string
+	for _, d := range found {
+		namespaces = append(namespaces, d.Namespace)
+	}
+	return nil, fmt.Errorf("%s is deployed in several namespaces (%s); choose one with --install-namespace",
+		name, strings.Join(namespaces, ", "))
+}
+
+// defaultContainer returns the container kubectl would pick for pod: the
+// one named by the kubectl.kubernetes.io/default-container annotation, or
+// else the first.
+func defaultContainer(pod *corev1.Pod) string {
+	if c := pod.Annotations["kubectl.kubernetes.io/default-container"]; c != "" {
+		return c
+	}
+	if len(pod.Spec.Containers) > 0 {
+		return pod.Spec.Containers[0].Name
+	}
+	return ""
+}
+
+// lineWriter serialises whole lines from concurrent log streams.
+type lineWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (l *lineWriter) printf(format string, args ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	fmt.Fprintf(l.w, format, args...)
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func agentPod(name, instance string, created time.Time) *corev1.Pod {
+	return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:              name,
+		Namespace:         "default",
+		CreationTimestamp: metav1.NewTime(created),
+		Labels: map[string]string{
+			"sympozium.ai/instance":  instance,
+			"sympozium.ai/component": "agent-run",
+		},
+	}}
+}
+
+// syncBuffer is a strings.Builder safe for the concurrent follow streams.
+type syncBuffer struct {
+	mu sync.Mutex
+	b  strings.Builder
+}
+
+func (s *syncBuffer) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.b.Write(p)
+}
+
+func (s *syncBuffer) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.b.String()
+}
+
+func TestStreamInstanceLogs_PrefixesPodsInOrder(t *testing.T) {
+	now := time.Now()
+	cs := fake.NewSimpleClientset(
+		agentPod("alpha-run-2", "alpha", now),
+		agentPod("alpha-run-1", "alpha", now.Add(-time.Minute)),
+		agentPod("beta-run-1", "beta", now),
+	)
+
+	var out strings.Builder
+	opts := instanceLogOptions{Container: "agent", TailLines: -1}
+	if err := streamInstanceLogs(context.Background(), cs, "default", "alpha", opts, &out); err != nil {
+		t.Fatalf("streamInstanceLogs: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2:\n%s", len(lines), out.String())
+	}
+	if !strings.HasPrefix(lines[0], "[alpha-run-1] ") || !strings.HasPrefix(lines[1], "[alpha-run-2] ") {
+		t.Errorf("lines not prefixed in creation order: %q", lines)
+	}
+
+	if err := streamInstanceLogs(context.Background(), cs, "default", "gamma", opts, &out); err == nil {
+		t.Error("expected an error for an instance without pods")
+	}
+}
+
+func TestStreamInstanceLogs_FollowPicksUpNewPods(t *testing.T) {
+	old := instanceLogPollInterval
+	instanceLogPollInterval = 20 * time.Millisecond
+	t.Cleanup(func() { instanceLogPollInterval = old })
+
+	cs := fake.NewSimpleClientset(agentPod("alpha-run-1", "alpha", time.Now()))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var out syncBuffer
+	done := make(chan error, 1)
+	go func() {
+		done <- streamInstanceLogs(ctx, cs, "default", "alpha", instanceLogOptions{Container: "agent", Follow: true, TailLines: -1}, &out)
+	}()
+
+	waitFor := func(substr string) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for !strings.Contains(out.String(), substr) {
+			if time.Now().After(deadline) {
+				t.Fatalf("timed out waiting for %q in:\n%s", substr, out.String())
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	waitFor("[alpha-run-1] ")
+
+	if _, err := cs.CoreV1().Pods("default").Create(ctx, agentPod("alpha-run-2", "alpha", time.Now()), metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor("[alpha-run-2] ")
+
+	// Finished pods are not streamed twice.
+	time.Sleep(5 * instanceLogPollInterval)
+	if n := strings.Count(out.String(), "[alpha-run-1] "); n != 1 {
+		t.Errorf("alpha-run-1 streamed %d times, want 1", n)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("follow returned %v", err)
+	}
+}
+
+func TestStreamRunLogs_ContainerSelection(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "run-1-pod", Namespace: "default"},
+		Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{{Name: "setup"}},
+			Containers:     []corev1.Container{{Name: "agent"}, {Name: "ipc-bridge"}},
+		},
+	}
+	cs := fake.NewSimpleClientset(pod)
+	ctx := context.Background()
+
+	var out strings.Builder
+	if err := streamRunLogs(ctx, cs, "default", "run-1-pod", runLogOptions{Container: "agent", TailLines: -1}, &out); err != nil {
+		t.Fatalf("single container: %v", err)
+	}
+	if got := strings.TrimSpace(out.String()); got != "fake logs" {
+		t.Errorf("single container output = %q, want unprefixed logs", got)
+	}
+
+	err := streamRunLogs(ctx, cs, "default", "run-1-pod", runLogOptions{Container: "agnet", TailLines: -1}, &out)
+	if err == nil || !strings.Contains(err.Error(), "available containers: setup, agent, ipc-bridge") {
+		t.Errorf("unknown container error = %v", err)
+	}
+
+	out.Reset()
+	if err := streamRunLogs(ctx, cs, "default", "run-1-pod", runLogOptions{AllContainers: true, Previous: true, TailLines: -1}, &out); err != nil {
+		t.Fatalf("all containers: %v", err)
+	}
+	want := "[setup] fake logs\n[agent] fake logs\n[ipc-bridge] fake logs\n"
+	if out.String() != want {
+		t.Errorf("all containers output = %q, want %q", out.String(), want)
+	}
+}
+
+func controlPlaneDeployment(ns string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "sympozium-controller-manager",
+			Namespace: ns,
+			Labels:    map[string]string{"app.kubernetes.io/component": "controller"},
+		},
+		Spec: appsv1.DeploymentSpec{Selector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{"control-plane": "controller-manager"},
+		}},
+	}
+}
+
+func controllerPod(name, ns string, created time.Time) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         ns,
+			CreationTimestamp: metav1.NewTime(created),
+			Labels:            map[string]string{"control-plane": "controller-manager"},
+			Annotations:       map[string]string{"kubectl.kubernetes.io/default-container": "manager"},
+		},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "kube-rbac-proxy"}, {Name: "manager"}}},
+	}
+}
+
+func TestStreamControlPlaneLogs(t *testing.T) {
+	now := time.Now()
+	cs := fake.NewSimpleClientset(
+		controlPlaneDeployment("sympozium-system"),
+		controllerPod("manager-b", "sympozium-system", now),
+		controllerPod("manager-a", "sympozium-system", now.Add(-time.Minute)),
+		controllerPod("unrelated", "default", now),
+	)
+	opts := controlPlaneLogOptions{TailLines: 100, Since: "10m"}
+
+	var out strings.Builder
+	if err := streamControlPlaneLogs(context.Background(), cs, "controller", opts, now, &out); err != nil {
+		t.Fatalf("streamControlPlaneLogs: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "[manager-a] ") || !strings.HasPrefix(lines[1], "[manager-b] ") {
+		t.Errorf("lines = %q, want both replicas prefixed in creation order", lines)
+	}
+
+	opts.InstallNamespace = "other"
+	if err := streamControlPlaneLogs(context.Background(), cs, "controller", opts, now, &out); err == nil {
+		t.Error("expected an error for a namespace without the deployment")
+	}
+	opts.InstallNamespace = ""
+	if err := streamControlPlaneLogs(context.Background(), cs, "webhook", opts, now, &out); err == nil || !strings.Contains(err.Error(), "sympozium-webhook") {
+		t.Errorf("missing webhook: err = %v", err)
+	}
+	opts.Since = "10"
+	if err := streamControlPlaneLogs(context.Background(), cs, "controller", opts, now, &out); err == nil {
+		t.Error("expected an error for --since without a unit")
+	}
+}
+
+func TestFindControlPlaneDeployment(t *testing.T) {
+	cs := fake.NewSimpleClientset(controlPlaneDeployment("team-a"), controlPlaneDeployment("team-b"))
+	if _, err := findControlPlaneDeployment(context.Background(), cs, "controller", ""); err == nil || !strings.Contains(err.Error(), "--install-namespace") {
+		t.Errorf("two installs: err = %v, want a hint to use --install-namespace", err)
+	}
+	d, err := findControlPlaneDeployment(context.Background(), cs, "controller", "team-b")
+	if err != nil || d.Namespace != "team-b" {
+		t.Errorf("--install-namespace team-b = %v, %v", d, err)
+	}
+}
+
+func TestDefaultContainer(t *testing.T) {
+	pod := controllerPod("p", "ns", time.Now())
+	if got := defaultContainer(pod); got != "manager" {
+		t.Errorf("annotated pod = %q, want manager", got)
+	}
+	pod.Annotations = nil
+	if got := defaultContainer(pod); got != "kube-rbac-proxy" {
+		t.Errorf("unannotated pod = %q, want the first container", got)
+	}
+}
@@ -8,11 +8,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/fs"
 	"net/http"
 	"os"
 	"os/exec"
 	"os/signal"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -26,10 +29,13 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	"github.com/spf13/cobra"
 
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -42,9 +48,28 @@ var (
 
 	kubeconfig string
 	namespace  string
+	inCluster  bool
 	k8sClient  client.Client
+	restConfig *rest.Config
+
+	// impersonateUser and impersonateGroups are --as and --as-group.
+	impersonateUser   string
+	impersonateGroups []string
+
+	skipVersionCheck bool
+
+	// pageLimit is --page-limit on the list commands.
+	pageLimit int64
+
+	// namespaceFlagSet records whether -n was given explicitly, so an
+	// in-cluster client can default to its service account's namespace.
+	namespaceFlagSet bool
 )
 
+// serviceAccountNamespaceFile holds the namespace of the pod's service
+// account when the CLI runs inside a cluster.
+var serviceAccountNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
 func main() {
 	rootCmd := &cobra.Command{
 		Use:   "sympozium",
@@ -53,13 +78,37 @@ func main() {
 SkillPacks, and feature gates in your Kubernetes cluster.
 
 Running without a subcommand launches the interactive TUI.`,
+		// Errors are printed by reportError so --error-format applies.
+		SilenceErrors: true,
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-			// Skip K8s client init for commands that don't need it.
-			switch cmd.Name() {
-			case "version", "install", "uninstall", "onboard", "tui", "sympozium", "serve":
+			if err := validateErrorFormat(); err != nil {
+				return err
+			}
+			if err := validateImpersonation(); err != nil {
+				return err
+			}
+			if errorFormat == "json" {
+				// Keep stderr to the single JSON error object.
+				cmd.SilenceUsage = true
+			}
+			namespaceFlagSet = cmd.Flags().Changed("namespace")
+			// Skip K8s client init for commands that don't need it. Match on
+			// the full path so e.g. "skills install" still gets a client.
+			switch strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ") {
+			case "version", "install", "uninstall", "status", "onboard", "tui", cmd.Root().Name(), "serve", "skills search", "skills validate":
 				return nil
 			}
-			return initClient()
+			if err := initClient(); err != nil {
+				return &cliError{Code: errConnectionFailed, Message: err.Error()}
+			}
+			if !skipVersionCheck {
+				warnOnVersionSkew()
+			}
+			if alphaWarningsEnabled() {
+				printAlphaNotice()
+				warnOnNewerAPIVersion(restConfig)
+			}
+			return nil
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := initClient(); err != nil {
@@ -74,44 +123,69 @@ Running without a subcommand launches the interactive TUI.`,
 	}
 
 	rootCmd.PersistentFlags().StringVar(&kubeconfig, "kubeconfig", "", "Path to kubeconfig")
+	rootCmd.PersistentFlags().BoolVar(&inCluster, "in-cluster", false, "Use the pod's service account instead of a kubeconfig")
+	rootCmd.PersistentFlags().BoolVar(&skipVersionCheck, "skip-version-check", false, "Do not warn when the CLI and control-plane versions differ")
+	rootCmd.PersistentFlags().BoolVar(&noAlphaWarning, "no-alpha-warning", false, "Do not print notices about the alpha (v1alpha1) API")
+	rootCmd.PersistentFlags().StringVar(&impersonateUser, "as", "", "Username to impersonate for the operation, as with kubectl --as")
+	rootCmd.PersistentFlags().StringArrayVar(&impersonateGroups, "as-group", nil, "Group to impersonate for the operation; repeat for several groups (requires --as)")
 	rootCmd.PersistentFlags().StringVarP(&namespace, "namespace", "n", "default", "Kubernetes namespace")
+	rootCmd.PersistentFlags().StringVar(&outputFile, "output-file", "", "Write -o json|yaml output to this file instead of stdout")
+	rootCmd.PersistentFlags().BoolVar(&verbose, "verbose", false, "Show raw API status details in errors")
+	rootCmd.PersistentFlags().StringVar(&errorFormat, "error-format", errorFormat, "Error output on stderr: text or json (see 'sympozium help error-codes')")
+	addNoRecordFlag(rootCmd)
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		if errorFormat == "json" {
+			cmd.SilenceUsage = true
+		}
+		return &cliError{Code: errInvalidArgument, Message: err.Error()}
+	})
 
 	rootCmd.AddCommand(
 		newInstallCmd(),
 		newUninstallCmd(),
+		newStatusCmd(),
+		newOverviewCmd(),
 		newOnboardCmd(),
+		newApplyCmd(),
 		newInstancesCmd(),
 		newRunsCmd(),
 		newPoliciesCmd(),
 		newSkillsCmd(),
 		newFeaturesCmd(),
+		newLogsCmd(),
 		newVersionCmd(),
 		newTUICmd(),
 		newServeCmd(),
+		newErrorCodesHelpCmd(),
 	)
 
-	if err := rootCmd.Execute(); err != nil {
-		os.Exit(1)
+	var updateHintCh <-chan string
+	if len(os.Args) < 2 || os.Args[1] != "version" {
+		updateHintCh = startBackgroundUpdateCheck()
+	}
+	err := rootCmd.Execute()
+	printUpdateHint(updateHintCh)
+	if err != nil {
+		reportError(os.Stderr, err)
+		os.Exit(exitCode(err))
 	}
 }
 
 func initClient() error {
-	scheme := runtime.NewScheme()
-	_ = corev1.AddToScheme(scheme)
-	if err := sympoziumv1alpha1.AddToScheme(scheme); err != nil {
-		return fmt.Errorf("failed to register scheme: %w", err)
-	}
-
-	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
-	if kubeconfig != "" {
-		loadingRules.ExplicitPath = kubeconfig
+	scheme, err := newCLIScheme()
+	if err != nil {
+		return err
 	}
 
-	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
-		loadingRules, &clientcmd.ConfigOverrides{},
-	).ClientConfig()
+	config, fromCluster, err := loadRESTConfig()
 	if err != nil {
-		return fmt.Errorf("failed to load kubeconfig: %w", err)
+		return err
+	}
+	applyImpersonation(config)
+	if fromCluster && !namespaceFlagSet {
+		if ns := serviceAccountNamespace(); ns != "" {
+			namespace = ns
+		}
 	}
 
 	c, err := client.New(config, client.Options{Scheme: scheme})
@@ -120,9 +194,100 @@ func initClient() error {
 	}
 
 	k8sClient = c
+	restConfig = config
 	return nil
 }
 
+// newClientset returns a typed clientset for APIs the controller-runtime
+// client does not cover, such as streaming pod logs.
+func newClientset() (kubernetes.Interface, error) {
+	if restConfig == nil {
+		return nil, fmt.Errorf("kubernetes client not initialised")
+	}
+	return kubernetes.NewForConfig(restConfig)
+}
+
+// loadRESTConfig returns the REST config for the cluster and whether it came
+// from the pod's service account. The in-cluster config is used when
+// --in-cluster is set, or as a fallback when no kubeconfig exists and the
+// process is running in a pod (e.g. as a CronJob).
+func loadRESTConfig() (*rest.Config, bool, error) {
+	if !inCluster {
+		cc := kubeClientConfig()
+		raw, rawErr := cc.RawConfig()
+		noKubeconfig := rawErr == nil && kubeconfig == "" && len(raw.Clusters) == 0
+		if !noKubeconfig || os.Getenv("KUBERNETES_SERVICE_HOST") == "" {
+			config, err := cc.ClientConfig()
+			if err != nil {
+				return nil, false, fmt.Errorf("failed to load kubeconfig: %w", err)
+			}
+			return config, false, nil
+		}
+	}
+
+	config, err := rest.InClusterConfig()
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to load in-cluster config: %w", err)
+	}
+	return config, true, nil
+}
+
+// validateImpersonation rejects --as-group without --as; the API server
+// needs a user to attach the groups to.
+func validateImpersonation() error {
+	if impersonateUser == "" && len(impersonateGroups) > 0 {
+		return &cliError{Code: errInvalidArgument, Message: "--as-group requires --as"}
+	}
+	return nil
+}
+
+// applyImpersonation makes every request of config act as --as and
+// --as-group. It applies after the kubeconfig (or in-cluster config) is
+// resolved, so the identity in use authenticates the impersonation; an
+// act-as set in the kubeconfig is replaced rather than merged.
+func applyImpersonation(config *rest.Config) {
+	if impersonateUser == "" {
+		return
+	}
+	config.Impersonate = rest.ImpersonationConfig{UserName: impersonateUser, Groups: impersonateGroups}
+}
+
+// serviceAccountNamespace returns the namespace of the pod's service
+// account, or "" outside a cluster.
+func serviceAccountNamespace() string {
+	data, err := os.ReadFile(serviceAccountNamespaceFile)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// newCLIScheme returns the scheme shared by the CLI client and by local
+// decoding of Sympozium manifests.
+func newCLIScheme() (*runtime.Scheme, error) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = appsv1.AddToScheme(scheme)
+	if err := sympoziumv1alpha1.AddToScheme(scheme); err != nil {
+		return nil, fmt.Errorf("failed to register scheme: %w", err)
+	}
+	return scheme, nil
+}
+
+// kubeClientConfig returns the kubeconfig loader used by initClient. An
+// explicit --kubeconfig wins; otherwise the standard loading rules apply, so
+// KUBECONFIG may list several files (separated like PATH) whose clusters,
+// users and contexts are merged exactly as kubectl merges them.
+func kubeClientConfig() clientcmd.ClientConfig {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if kubeconfig != "" {
+		loadingRules.ExplicitPath = kubeconfig
+	}
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		loadingRules, &clientcmd.ConfigOverrides{},
+	)
+}
+
 func newInstancesCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     "instances",
@@ -130,63 +295,153 @@ func newInstancesCmd() *cobra.Command {
 		Short:   "Manage SympoziumInstances",
 	}
 
-	cmd.AddCommand(
-		&cobra.Command{
-			Use:   "list",
-			Short: "List SympoziumInstances",
-			RunE: func(cmd *cobra.Command, args []string) error {
-				ctx := context.Background()
-				var list sympoziumv1alpha1.SympoziumInstanceList
-				if err := k8sClient.List(ctx, &list, client.InNamespace(namespace)); err != nil {
-					return err
+	var listOutput, getOutput, provider string
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List SympoziumInstances",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateOutput(listOutput, "json", "yaml"); err != nil {
+				return err
+			}
+			ctx := context.Background()
+			var all sympoziumv1alpha1.SympoziumInstanceList
+			table := newTableStream(os.Stdout, instanceColumns, false)
+			err := listPages(ctx, k8sClient, pageLimit, func(page *sympoziumv1alpha1.SympoziumInstanceList) error {
+				items := page.Items
+				if provider != "" {
+					items = filterInstancesByProvider(items, provider)
 				}
-				w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
-				fmt.Fprintln(w, "NAME\tPHASE\tCHANNELS\tAGENT PODS\tAGE")
-				for _, inst := range list.Items {
-					age := time.Since(inst.CreationTimestamp.Time).Round(time.Second)
-					channels := make([]string, 0)
-					for _, ch := range inst.Status.Channels {
-						channels = append(channels, ch.Type)
-					}
-					fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\n",
-						inst.Name, inst.Status.Phase,
-						strings.Join(channels, ","),
-						inst.Status.ActiveAgentPods, age)
+				if isStructuredOutput(listOutput) {
+					all.Items = append(all.Items, items...)
+					return nil
 				}
-				return w.Flush()
-			},
+				return table.write(items)
+			}, client.InNamespace(namespace))
+			if err != nil {
+				return err
+			}
+			if isStructuredOutput(listOutput) {
+				return printStructured(listOutput, &all)
+			}
+			return nil
 		},
-		&cobra.Command{
-			Use:   "get [name]",
-			Short: "Get a SympoziumInstance",
-			Args:  cobra.ExactArgs(1),
-			RunE: func(cmd *cobra.Command, args []string) error {
-				ctx := context.Background()
-				var inst sympoziumv1alpha1.SympoziumInstance
-				if err := k8sClient.Get(ctx, types.NamespacedName{Name: args[0], Namespace: namespace}, &inst); err != nil {
-					return err
+	}
+	addOutputFlag(listCmd, &listOutput, "", "json", "yaml")
+	addPageLimitFlag(listCmd, &pageLimit)
+	listCmd.Flags().StringVar(&provider, "provider", "", "Only list instances with credentials for this AI provider")
+	_ = listCmd.RegisterFlagCompletionFunc("provider", completeProviders)
+
+	var (
+		watch, showPods bool
+		waitFor         string
+		watchTimeout    time.Duration
+	)
+	getCmd := &cobra.Command{
+		Use:   "get [name]",
+		Short: "Get a SympoziumInstance",
+		Long: `Get a SympoziumInstance as JSON or YAML.
+
+With -w the instance's status (phase, channels, agent pod count) is printed
+as a line each time it changes, until Ctrl-C, --timeout or the instance is
+deleted.
+
+With --for the command waits, like kubectl wait, until the instance meets
+condition=<type>[=<status>] (status defaults to True) or phase=<phase>,
+printing the phase it reached. The controller reports readiness through the
+phase, so condition=Ready is also met by phase Running. Add -w to see the
+status lines while waiting. Exit codes:
+  0  the condition was met
+  1  CLI or API error (e.g. the instance does not exist)
+  2  the instance was deleted while waiting
+  3  --timeout elapsed first
+
+With --show-pods the pods behind the instance (agent runs and channels,
+selected by the sympozium.ai/instance label) are listed with their phase,
+node, restarts and age below the instance's status line. With -o json or
+-o yaml the instance and its pods are printed together as a v1 List.`,
+		Example: `  sympozium instances get my-agent --show-pods
+  sympozium instances get my-agent --show-pods -o yaml
+  sympozium instances get my-agent --for=condition=Ready --timeout=5m`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if watch || waitFor != "" {
+				if outputFile != "" || cmd.Flags().Changed("output") || showPods {
+					return fmt.Errorf("-w and --for cannot be combined with -o, --output-file or --show-pods")
 				}
-				data, _ := json.MarshalIndent(inst, "", "  ")
-				fmt.Println(string(data))
-				return nil
-			},
-		},
-		&cobra.Command{
-			Use:   "delete [name]",
-			Short: "Delete a SympoziumInstance",
-			Args:  cobra.ExactArgs(1),
-			RunE: func(cmd *cobra.Command, args []string) error {
-				ctx := context.Background()
-				inst := &sympoziumv1alpha1.SympoziumInstance{
-					ObjectMeta: metav1.ObjectMeta{Name: args[0], Namespace: namespace},
+				var until *instanceWaitCondition
+				if waitFor != "" {
+					var err error
+					if until, err = parseInstanceWaitCondition(waitFor); err != nil {
+						return err
+					}
+				}
+				cmd.SilenceUsage = true
+				ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+				defer stop()
+				if watchTimeout > 0 {
+					var cancel context.CancelFunc
+					ctx, cancel = context.WithTimeout(ctx, watchTimeout)
+					defer cancel()
 				}
-				if err := k8sClient.Delete(ctx, inst); err != nil {
+				var lines io.Writer = io.Discard
+				if watch {
+					lines = os.Stdout
+				}
+				key := types.NamespacedName{Name: args[0], Namespace: namespace}
+				out, err := watchInstance(ctx, k8sClient, key, lines, until)
+				if err != nil || until == nil {
 					return err
 				}
-				fmt.Printf("sympoziuminstance/%s deleted\n", args[0])
-				return nil
-			},
+				if out.Code == exitRunSucceeded {
+					fmt.Println(out.Phase)
+					return nil
+				}
+				code := errUnknown
+				if out.Code == exitRunTimeout {
+					code = errTimeout
+				}
+				msg := fmt.Sprintf("sympoziuminstance %s: %s", key.Name, out.Message)
+				return &exitCodeError{code: out.Code, err: &cliError{Code: code, Message: msg, Resource: "sympoziuminstances/" + key.Name}}
+			}
+			if watchTimeout > 0 {
+				return fmt.Errorf("--timeout requires -w or --for")
+			}
+			if err := validateOutput(getOutput, "json", "yaml"); err != nil {
+				return err
+			}
+			ctx := context.Background()
+			var inst sympoziumv1alpha1.SympoziumInstance
+			if err := k8sClient.Get(ctx, types.NamespacedName{Name: args[0], Namespace: namespace}, &inst); err != nil {
+				return err
+			}
+			stripManagedFields(&inst)
+			if !showPods {
+				return printStructured(getOutput, &inst)
+			}
+			pods, err := listInstancePods(ctx, k8sClient, &inst)
+			if err != nil {
+				return err
+			}
+			if cmd.Flags().Changed("output") || outputFile != "" {
+				return printStructured(getOutput, instanceWithPodsList(&inst, pods))
+			}
+			return printInstancePods(cmd.OutOrStdout(), &inst, pods)
 		},
+	}
+	addOutputFlag(getCmd, &getOutput, "json", "json", "yaml")
+	addShowManagedFieldsFlag(getCmd)
+	getCmd.Flags().BoolVarP(&watch, "watch", "w", false, "Print a status line each time the instance's status changes")
+	getCmd.Flags().BoolVar(&showPods, "show-pods", false, "List the pods behind the instance with their phase, node, restarts and age")
+	getCmd.Flags().StringVar(&waitFor, "for", "", "Wait until the instance meets condition=<type>[=<status>] or phase=<phase>")
+	getCmd.Flags().DurationVar(&watchTimeout, "timeout", 0, "Stop -w or --for after this long; --for then exits 3 (0 waits indefinitely)")
+
+	cmd.AddCommand(
+		listCmd,
+		getCmd,
+		newInstancesTemplateCmd(),
+		newInstancesLogsCmd(),
+		newEditCmd("SympoziumInstance", "instances"),
+		newDeleteCmd("SympoziumInstance", "instances"),
 	)
 	return cmd
 }
@@ -198,67 +453,173 @@ func newRunsCmd() *cobra.Command {
 		Short:   "Manage AgentRuns",
 	}
 
-	cmd.AddCommand(
-		&cobra.Command{
-			Use:   "list",
-			Short: "List AgentRuns",
-			RunE: func(cmd *cobra.Command, args []string) error {
-				ctx := context.Background()
-				var list sympoziumv1alpha1.AgentRunList
-				if err := k8sClient.List(ctx, &list, client.InNamespace(namespace)); err != nil {
+	var (
+		listOutput     string
+		since          string
+		sinceCompleted bool
+		phase          string
+	)
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List AgentRuns",
+		Example: `  sympozium runs list --since 30m
+  sympozium runs list --since 2d --since-completed
+  sympozium runs list --since 2026-03-09T08:00:00Z
+  sympozium runs list --phase Failed`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateOutput(listOutput, "wide", "json", "yaml"); err != nil {
+				return err
+			}
+			if sinceCompleted && since == "" {
+				return fmt.Errorf("--since-completed requires --since")
+			}
+			var cutoff time.Time
+			if since != "" {
+				var err error
+				if cutoff, err = parseSince(since, time.Now()); err != nil {
 					return err
 				}
-				w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
-				fmt.Fprintln(w, "NAME\tINSTANCE\tPHASE\tPOD\tTOKENS\tAGE")
-				for _, run := range list.Items {
-					age := time.Since(run.CreationTimestamp.Time).Round(time.Second)
-					tokens := "-"
-					if run.Status.TokenUsage != nil {
-						tokens = fmt.Sprintf("%d/%d", run.Status.TokenUsage.InputTokens, run.Status.TokenUsage.OutputTokens)
-					}
-					fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
-						run.Name, run.Spec.InstanceRef,
-						run.Status.Phase, run.Status.PodName, tokens, age)
-				}
-				return w.Flush()
-			},
-		},
-		&cobra.Command{
-			Use:   "get [name]",
-			Short: "Get an AgentRun",
-			Args:  cobra.ExactArgs(1),
-			RunE: func(cmd *cobra.Command, args []string) error {
-				ctx := context.Background()
-				var run sympoziumv1alpha1.AgentRun
-				if err := k8sClient.Get(ctx, types.NamespacedName{Name: args[0], Namespace: namespace}, &run); err != nil {
+			}
+			if phase != "" {
+				if _, err := filterRunsByPhase(nil, phase); err != nil {
 					return err
 				}
-				data, _ := json.MarshalIndent(run, "", "  ")
-				fmt.Println(string(data))
-				return nil
-			},
-		},
-		&cobra.Command{
-			Use:   "logs [name]",
-			Short: "Stream logs from an AgentRun pod",
-			Args:  cobra.ExactArgs(1),
-			RunE: func(cmd *cobra.Command, args []string) error {
-				ctx := context.Background()
-				var run sympoziumv1alpha1.AgentRun
-				if err := k8sClient.Get(ctx, types.NamespacedName{Name: args[0], Namespace: namespace}, &run); err != nil {
-					return err
+			}
+			ctx := context.Background()
+			var all sympoziumv1alpha1.AgentRunList
+			table := newTableStream(os.Stdout, agentRunColumns, listOutput == "wide")
+			err := listPages(ctx, k8sClient, pageLimit, func(page *sympoziumv1alpha1.AgentRunList) error {
+				items := page.Items
+				if since != "" {
+					items = filterRunsSince(items, cutoff, sinceCompleted)
 				}
-				if run.Status.PodName == "" {
-					return fmt.Errorf("agentrun %s has no pod yet (phase: %s)", args[0], run.Status.Phase)
+				if phase != "" {
+					items, _ = filterRunsByPhase(items, phase)
 				}
-				fmt.Printf("Use: kubectl logs %s -c agent -f\n", run.Status.PodName)
-				return nil
-			},
+				if isStructuredOutput(listOutput) {
+					all.Items = append(all.Items, items...)
+					return nil
+				}
+				return table.write(items)
+			}, client.InNamespace(namespace))
+			if err != nil {
+				return err
+			}
+			if isStructuredOutput(listOutput) {
+				return printStructured(listOutput, &all)
+			}
+			return nil
+		},
+	}
+	addOutputFlag(listCmd, &listOutput, "", "wide", "json", "yaml")
+	addPageLimitFlag(listCmd, &pageLimit)
+	listCmd.Flags().StringVar(&since, "since", "", sinceFlagUsage)
+	listCmd.Flags().BoolVar(&sinceCompleted, "since-completed", false, "Apply --since to the completion time instead of the creation time (excludes unfinished runs)")
+	listCmd.Flags().StringVar(&phase, "phase", "", "Only list runs in this phase (Pending, Running, Succeeded, Failed)")
+	_ = listCmd.RegisterFlagCompletionFunc("phase", completeRunPhases)
+
+	var getOutput string
+	getCmd := &cobra.Command{
+		Use:   "get [name|@last]",
+		Short: "Get an AgentRun",
+		Long: `Get an AgentRun as JSON or YAML. Without a name, or with @last, the run
+most recently created by this CLI is shown.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateOutput(getOutput, "json", "yaml"); err != nil {
+				return err
+			}
+			ctx := context.Background()
+			key, err := resolveRunArg(ctx, k8sClient, args)
+			if err != nil {
+				return err
+			}
+			var run sympoziumv1alpha1.AgentRun
+			if err := k8sClient.Get(ctx, key, &run); err != nil {
+				return err
+			}
+			stripManagedFields(&run)
+			return printStructured(getOutput, &run)
 		},
+	}
+	addOutputFlag(getCmd, &getOutput, "json", "json", "yaml")
+	addShowManagedFieldsFlag(getCmd)
+
+	cmd.AddCommand(
+		listCmd,
+		getCmd,
+		newRunsCreateCmd(),
+		newDeleteCmd("AgentRun", "runs"),
+		newRunsWaitCmd(),
+		newRunsWatchCmd(),
+		newRunsLogsCmd(),
 	)
 	return cmd
 }
 
+// runMessage returns the most useful explanation of a run's state: the
+// recorded error, or else the message of the most recent condition. Newlines
+// are flattened so the value fits in a table cell.
+func runMessage(run *sympoziumv1alpha1.AgentRun) string {
+	msg := run.Status.Error
+	if msg == "" {
+		if latest := latestCondition(run.Status.Conditions); latest != nil {
+			msg = latest.Message
+		}
+	}
+	return strings.Join(strings.Fields(msg), " ")
+}
+
+// filterRunsByPhase keeps the runs in phase, matched case-insensitively.
+// An unknown phase is an error rather than an empty list.
+func filterRunsByPhase(runs []sympoziumv1alpha1.AgentRun, phase string) ([]sympoziumv1alpha1.AgentRun, error) {
+	var known []string
+	matched := false
+	for _, p := range agentRunPhases {
+		known = append(known, p.text)
+		if strings.EqualFold(p.text, phase) {
+			phase, matched = p.text, true
+		}
+	}
+	if !matched {
+		return nil, fmt.Errorf("unknown phase %q (known: %s)", phase, strings.Join(known, ", "))
+	}
+	var out []sympoziumv1alpha1.AgentRun
+	for _, r := range runs {
+		if string(r.Status.Phase) == phase {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+// filterInstancesByProvider keeps the instances with an auth reference for
+// provider.
+func filterInstancesByProvider(instances []sympoziumv1alpha1.SympoziumInstance, provider string) []sympoziumv1alpha1.SympoziumInstance {
+	var out []sympoziumv1alpha1.SympoziumInstance
+	for _, inst := range instances {
+		for _, ref := range inst.Spec.AuthRefs {
+			if strings.EqualFold(ref.Provider, provider) {
+				out = append(out, inst)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// latestCondition returns the most recently transitioned condition, or nil.
+func latestCondition(conds []metav1.Condition) *metav1.Condition {
+	var latest *metav1.Condition
+	for i := range conds {
+		c := &conds[i]
+		if latest == nil || c.LastTransitionTime.After(latest.LastTransitionTime.Time) {
+			latest = c
+		}
+	}
+	return latest
+}
+
 func newPoliciesCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     "policies",
@@ -266,41 +627,57 @@ func newPoliciesCmd() *cobra.Command {
 		Short:   "Manage SympoziumPolicies",
 	}
 
-	cmd.AddCommand(
-		&cobra.Command{
-			Use:   "list",
-			Short: "List SympoziumPolicies",
-			RunE: func(cmd *cobra.Command, args []string) error {
-				ctx := context.Background()
-				var list sympoziumv1alpha1.SympoziumPolicyList
-				if err := k8sClient.List(ctx, &list, client.InNamespace(namespace)); err != nil {
-					return err
-				}
-				w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
-				fmt.Fprintln(w, "NAME\tBOUND INSTANCES\tAGE")
-				for _, pol := range list.Items {
-					age := time.Since(pol.CreationTimestamp.Time).Round(time.Second)
-					fmt.Fprintf(w, "%s\t%d\t%s\n", pol.Name, pol.Status.BoundInstances, age)
+	var listOutput, getOutput string
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List SympoziumPolicies",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateOutput(listOutput, "json", "yaml"); err != nil {
+				return err
+			}
+			ctx := context.Background()
+			var all sympoziumv1alpha1.SympoziumPolicyList
+			table := newTableStream(os.Stdout, policyColumns, false)
+			err := listPages(ctx, k8sClient, pageLimit, func(page *sympoziumv1alpha1.SympoziumPolicyList) error {
+				if isStructuredOutput(listOutput) {
+					all.Items = append(all.Items, page.Items...)
+					return nil
 				}
-				return w.Flush()
-			},
+				return table.write(page.Items)
+			}, client.InNamespace(namespace))
+			if err != nil {
+				return err
+			}
+			if isStructuredOutput(listOutput) {
+				return printStructured(listOutput, &all)
+			}
+			return nil
 		},
-		&cobra.Command{
-			Use:   "get [name]",
-			Short: "Get a SympoziumPolicy",
-			Args:  cobra.ExactArgs(1),
-			RunE: func(cmd *cobra.Command, args []string) error {
-				ctx := context.Background()
-				var pol sympoziumv1alpha1.SympoziumPolicy
-				if err := k8sClient.Get(ctx, types.NamespacedName{Name: args[0], Namespace: namespace}, &pol); err != nil {
-					return err
-				}
-				data, _ := json.MarshalIndent(pol, "", "  ")
-				fmt.Println(string(data))
-				return nil
-			},
+	}
+	addOutputFlag(listCmd, &listOutput, "", "json", "yaml")
+	addPageLimitFlag(listCmd, &pageLimit)
+
+	getCmd := &cobra.Command{
+		Use:   "get [name]",
+		Short: "Get a SympoziumPolicy",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateOutput(getOutput, "json", "yaml"); err != nil {
+				return err
+			}
+			ctx := context.Background()
+			var pol sympoziumv1alpha1.SympoziumPolicy
+			if err := k8sClient.Get(ctx, types.NamespacedName{Name: args[0], Namespace: namespace}, &pol); err != nil {
+				return err
+			}
+			stripManagedFields(&pol)
+			return printStructured(getOutput, &pol)
 		},
-	)
+	}
+	addOutputFlag(getCmd, &getOutput, "json", "json", "yaml")
+	addShowManagedFieldsFlag(getCmd)
+
+	cmd.AddCommand(listCmd, getCmd, newPoliciesDescribeCmd(), newEditCmd("SympoziumPolicy", "policies"), newDeleteCmd("SympoziumPolicy", "policies"))
 	return cmd
 }
 
@@ -311,27 +688,56 @@ func newSkillsCmd() *cobra.Command {
 		Short:   "Manage SkillPacks",
 	}
 
-	cmd.AddCommand(
-		&cobra.Command{
-			Use:   "list",
-			Short: "List SkillPacks",
-			RunE: func(cmd *cobra.Command, args []string) error {
-				ctx := context.Background()
-				var list sympoziumv1alpha1.SkillPackList
-				if err := k8sClient.List(ctx, &list, client.InNamespace(namespace)); err != nil {
+	var (
+		listOutput string
+		withUsage  bool
+	)
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List SkillPacks",
+		Long: `List SkillPacks.
+
+With --with-usage a USED BY column shows how many SympoziumInstances in the
+namespace reference each pack in spec.skills, by name or through the pack's
+ConfigMap; -o wide also lists their names. Check it is 0 before deleting a
+pack.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateOutput(listOutput, "wide", "json", "yaml"); err != nil {
+				return err
+			}
+			ctx := context.Background()
+			var all sympoziumv1alpha1.SkillPackList
+			columns := skillPackColumns
+			if withUsage && !isStructuredOutput(listOutput) {
+				usage, err := skillPackUsage(ctx, k8sClient, namespace, pageLimit)
+				if err != nil {
 					return err
 				}
-				w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
-				fmt.Fprintln(w, "NAME\tSKILLS\tCONFIGMAP\tAGE")
-				for _, sk := range list.Items {
-					age := time.Since(sk.CreationTimestamp.Time).Round(time.Second)
-					fmt.Fprintf(w, "%s\t%d\t%s\t%s\n",
-						sk.Name, len(sk.Spec.Skills), sk.Status.ConfigMapName, age)
+				columns = skillPackUsageColumns(usage)
+			}
+			table := newTableStream(os.Stdout, columns, listOutput == "wide")
+			err := listPages(ctx, k8sClient, pageLimit, func(page *sympoziumv1alpha1.SkillPackList) error {
+				if isStructuredOutput(listOutput) {
+					all.Items = append(all.Items, page.Items...)
+					return nil
 				}
-				return w.Flush()
-			},
+				return table.write(page.Items)
+			}, client.InNamespace(namespace))
+			if err != nil {
+				return err
+			}
+			if isStructuredOutput(listOutput) {
+				return printStructured(listOutput, &all)
+			}
+			return nil
 		},
-	)
+	}
+	addOutputFlag(listCmd, &listOutput, "", "wide", "json", "yaml")
+	addPageLimitFlag(listCmd, &pageLimit)
+	listCmd.Flags().BoolVar(&withUsage, "with-usage", false, "Show the SympoziumInstances using each pack")
+
+	cmd.AddCommand(listCmd, newEditCmd("SkillPack", "skills"), newSkillsSearchCmd(), newSkillsInstallCmd(), newSkillsValidateCmd(),
+		newDeleteCmd("SkillPack", "skills"))
 	return cmd
 }
 
@@ -343,9 +749,10 @@ func newFeaturesCmd() *cobra.Command {
 	}
 
 	enableCmd := &cobra.Command{
-		Use:   "enable [feature]",
-		Short: "Enable a feature gate",
-		Args:  cobra.ExactArgs(1),
+		Use:               "enable [feature]",
+		Short:             "Enable a feature gate",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeFeatureGates,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return toggleFeature(args[0], true, cmd)
 		},
@@ -353,24 +760,49 @@ func newFeaturesCmd() *cobra.Command {
 	enableCmd.Flags().String("policy", "", "Target SympoziumPolicy")
 
 	disableCmd := &cobra.Command{
-		Use:   "disable [feature]",
-		Short: "Disable a feature gate",
-		Args:  cobra.ExactArgs(1),
+		Use:               "disable [feature]",
+		Short:             "Disable a feature gate",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeFeatureGates,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return toggleFeature(args[0], false, cmd)
 		},
 	}
 	disableCmd.Flags().String("policy", "", "Target SympoziumPolicy")
 
+	var allInstances bool
 	listCmd := &cobra.Command{
 		Use:   "list",
 		Short: "List feature gates on a policy",
+		Long: `List the feature gates set on a SympoziumPolicy.
+
+With --all-instances, print the effective gates of every SympoziumInstance
+in the namespace instead, resolved through the policy each instance is
+bound to. "default" means the gate is not set on the policy and is therefore
+not restricted; "?" means the instance references a policy that does not
+exist.`,
+		Example: `  sympozium features list --policy default-policy
+  sympozium features list --all-instances`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			policyName, _ := cmd.Flags().GetString("policy")
+			ctx := context.Background()
+			if allInstances {
+				if policyName != "" {
+					return fmt.Errorf("--policy and --all-instances cannot be combined")
+				}
+				rows, err := resolveInstanceFeatureGates(ctx, k8sClient, namespace)
+				if err != nil {
+					return err
+				}
+				if len(rows) == 0 {
+					fmt.Printf("No SympoziumInstances in namespace %s\n", namespace)
+					return nil
+				}
+				return printInstanceFeatureGates(os.Stdout, os.Stderr, rows)
+			}
 			if policyName == "" {
 				return fmt.Errorf("--policy is required")
 			}
-			ctx := context.Background()
 			var pol sympoziumv1alpha1.SympoziumPolicy
 			if err := k8sClient.Get(ctx, types.NamespacedName{Name: policyName, Namespace: namespace}, &pol); err != nil {
 				return err
@@ -386,8 +818,50 @@ func newFeaturesCmd() *cobra.Command {
 		},
 	}
 	listCmd.Flags().String("policy", "", "Target SympoziumPolicy")
+	listCmd.Flags().BoolVar(&allInstances, "all-instances", false, "Show the effective gates of every instance in the namespace")
 
-	cmd.AddCommand(enableCmd, disableCmd, listCmd)
+	var (
+		gatesFile string
+		merge     bool
+	)
+	applyCmd := &cobra.Command{
+		Use:   "apply -f FILE --policy POLICY",
+		Short: "Set the feature gates of a policy from a file",
+		Long: `Set the feature gates of a SympoziumPolicy from a YAML or JSON map of gate
+name to true or false (-f - reads stdin).
+
+By default the file is the complete set: gates on the policy that the file
+does not mention are removed. With --merge, only the gates in the file are
+added or updated and every other gate is left as it is, so teams owning
+different gates of a shared policy do not undo each other's changes.`,
+		Example: `  sympozium features apply -f gates.yaml --policy default-policy
+  echo 'gpu-access: true' | sympozium features apply -f - --policy shared --merge`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			policyName, _ := cmd.Flags().GetString("policy")
+			if policyName == "" {
+				return fmt.Errorf("--policy is required")
+			}
+			if gatesFile == "" {
+				return fmt.Errorf("-f/--filename is required")
+			}
+			desired, err := readFeatureGatesFile(gatesFile, os.Stdin)
+			if err != nil {
+				return err
+			}
+			changes, err := applyFeatureGates(context.Background(), k8sClient, namespace, policyName, desired, merge)
+			if err != nil {
+				return err
+			}
+			printFeatureGateChanges(os.Stdout, policyName, desired, changes, merge)
+			return nil
+		},
+	}
+	applyCmd.Flags().String("policy", "", "Target SympoziumPolicy")
+	applyCmd.Flags().StringVarP(&gatesFile, "filename", "f", "", "YAML or JSON map of feature gate to true/false (- for stdin)")
+	applyCmd.Flags().BoolVar(&merge, "merge", false, "Only add or update the gates in the file; keep the others (default: replace all gates)")
+
+	cmd.AddCommand(enableCmd, disableCmd, listCmd, applyCmd)
 	return cmd
 }
 
@@ -421,13 +895,31 @@ func toggleFeature(feature string, enabled bool, cmd *cobra.Command) error {
 }
 
 func newVersionCmd() *cobra.Command {
-	return &cobra.Command{
+	var check, failIfOutdated bool
+	cmd := &cobra.Command{
 		Use:   "version",
 		Short: "Print the version",
-		Run: func(cmd *cobra.Command, args []string) {
+		Long: `Print the CLI version.
+
+With --check, also look up the latest GitHub release and report whether an
+update is available. --fail-if-outdated implies --check and exits non-zero
+when the CLI is behind, for use as a CI gate.
+
+Set SYMPOZIUM_UPDATE_CHECK=true to get a one-line hint after other commands
+when a newer release exists (checked at most once a day). Setting
+SYMPOZIUM_NO_UPDATE_CHECK disables that background check entirely.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
 			fmt.Printf("sympozium %s\n", version)
+			if !check && !failIfOutdated {
+				return nil
+			}
+			cmd.SilenceUsage = true
+			return runVersionCheck(failIfOutdated)
 		},
 	}
+	cmd.Flags().BoolVar(&check, "check", false, "Check GitHub for a newer release")
+	cmd.Flags().BoolVar(&failIfOutdated, "fail-if-outdated", false, "Exit non-zero when a newer release exists (implies --check)")
+	return cmd
 }
 
 const (
@@ -854,11 +1346,16 @@ func promptSecret(reader *bufio.Reader, label string) string {
 
 // promptYN asks a yes/no question.
 func promptYN(reader *bufio.Reader, label string, defaultYes bool) bool {
+	return promptYNFrom(reader, os.Stdout, label, defaultYes)
+}
+
+// promptYNFrom is promptYN writing the prompt to out.
+func promptYNFrom(reader *bufio.Reader, out io.Writer, label string, defaultYes bool) bool {
 	hint := "Y/n"
 	if !defaultYes {
 		hint = "y/N"
 	}
-	fmt.Printf("%s [%s]: ", label, hint)
+	fmt.Fprintf(out, "%s [%s]: ", label, hint)
 	line, _ := reader.ReadString('\n')
 	line = strings.TrimSpace(strings.ToLower(line))
 	if line == "" {
@@ -961,8 +1458,7 @@ func kubectlApplyStdin(yaml string) error {
 }
 
 func newInstallCmd() *cobra.Command {
-	var manifestVersion string
-	var imageTag string
+	var opts installOptions
 	cmd := &cobra.Command{
 		Use:   "install",
 		Short: "Install Sympozium into the current Kubernetes cluster",
@@ -973,158 +1469,386 @@ Installs CRDs, the controller manager, API server, admission webhook,
 RBAC rules, and network policies.
 
 Use --image-tag to override the container image tag in the manifests,
-for example when you have sideloaded images into Kind with a custom tag.`,
+for example when you have sideloaded images into Kind with a custom tag.
+
+Use --install-namespace to place the control plane somewhere other than
+sympozium-system. Namespaced objects, webhook service references and RBAC
+subjects are rewritten before applying, and the chosen namespace is recorded
+in a marker ConfigMap so uninstall and status find it later.
+
+Use --dry-run to print the manifests that would be applied, with all
+overrides, instead of applying them.
+
+Manifests are server-side applied with the field manager set by
+--field-manager. If another manager owns some of the fields, for example
+after installing with Helm, install stops and names it; use
+--force-conflicts to take ownership of those fields.
+
+If a phase fails, install stops and prints which phases succeeded, which
+failed and which were not attempted. Re-running install is safe; use
+--continue-on-error to attempt every phase and report all failures at the
+end.
+
+Waits (CRDs established, cert-manager available, the webhook certificate
+accepted) each give up after --timeout and re-check every --poll-interval;
+raise --timeout on slow clusters or lower it in CI.
+
+Use --render-helm --out <dir> to convert the release bundle into a minimal
+Helm chart for GitOps workflows. The chart exposes the image registry and
+tag, namespace, resource limits, and optional components as values;
+--image-registry, --image-tag and --install-namespace set their defaults.
+'helm template' with matching values produces the same objects as
+--dry-run. cert-manager must already be present in the target cluster.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runInstall(manifestVersion, imageTag)
+			if err := opts.Wait.validate(); err != nil {
+				return err
+			}
+			return runInstall(opts)
 		},
 	}
-	cmd.Flags().StringVar(&manifestVersion, "version", "", "Release version to install (default: latest)")
-	cmd.Flags().StringVar(&imageTag, "image-tag", "", "Override image tag in manifests (e.g. 'latest')")
+	cmd.Flags().StringVar(&opts.Version, "version", "", "Release version to install (default: latest)")
+	cmd.Flags().StringVar(&opts.ImageTag, "image-tag", "", "Override image tag in manifests (e.g. 'latest')")
+	cmd.Flags().StringVar(&opts.ImageRegistry, "image-registry", "", "Override image registry in manifests (default: "+defaultImageRegistry+")")
+	cmd.Flags().StringVar(&opts.Namespace, "install-namespace", defaultInstallNamespace, "Namespace for the Sympozium control plane")
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "Print the manifests that would be applied and exit")
+	cmd.Flags().BoolVar(&opts.RenderHelm, "render-helm", false, "Render the release manifests as a Helm chart instead of installing")
+	cmd.Flags().StringVar(&opts.OutDir, "out", "", "Output directory for --render-helm")
+	cmd.Flags().BoolVar(&opts.ContinueOnError, "continue-on-error", false, "Attempt every install phase even after one fails, then report all failures")
+	cmd.Flags().StringVar(&opts.FieldManager, "field-manager", cliFieldManager, "Field manager recorded for server-side applied manifests")
+	cmd.Flags().BoolVar(&opts.ForceConflicts, "force-conflicts", false, "Take ownership of fields owned by another field manager (e.g. a prior Helm install)")
+	addWaitFlags(cmd, &opts.Wait, "each install step that polls the cluster")
 	return cmd
 }
 
 func newUninstallCmd() *cobra.Command {
-	return &cobra.Command{
+	var opts uninstallOptions
+	cmd := &cobra.Command{
 		Use:   "uninstall",
 		Short: "Remove Sympozium from the current Kubernetes cluster",
+		Long: `Removes the Sympozium control plane and CRDs.
+
+Deleting the CRDs deletes every SympoziumInstance, AgentRun and other
+Sympozium resource in the cluster, so uninstall first counts them and asks
+for confirmation (skip with --yes). Use --keep-crds to remove only the
+control plane and leave CRDs and resources in place.
+
+Resources are deleted while the controller is still running so it can
+process their finalizers. Anything still stuck on finalizers afterwards is
+reported; pass --force-finalizers to clear them and continue. --timeout
+bounds that wait and --poll-interval sets how often it re-checks.
+
+The control-plane namespace is read from the install marker ConfigMap
+written by 'sympozium install'. Use --install-namespace to override it,
+or to choose one when markers are found in several namespaces.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runUninstall()
+			if err := opts.Wait.validate(); err != nil {
+				return err
+			}
+			if opts.Namespace == "" {
+				ns, err := resolveInstallNamespace("--install-namespace")
+				if err != nil {
+					return err
+				}
+				opts.Namespace = ns
+			}
+			return runUninstall(opts)
 		},
 	}
+	cmd.Flags().StringVar(&opts.Namespace, "install-namespace", "", "Namespace of the Sympozium control plane (default: recorded at install time)")
+	cmd.Flags().BoolVar(&opts.KeepCRDs, "keep-crds", false, "Remove only the control plane; keep CRDs and all Sympozium resources")
+	cmd.Flags().BoolVarP(&opts.Yes, "yes", "y", false, "Do not ask for confirmation before deleting Sympozium resources")
+	cmd.Flags().BoolVar(&opts.ForceFinalizers, "force-finalizers", false, "Clear finalizers on resources stuck in deletion")
+	addWaitFlags(cmd, &opts.Wait, "deleted resources to have their finalizers processed")
+	return cmd
 }
 
-func runInstall(ver, imageTag string) error {
-	if ver == "" || ver == "latest" {
-		if version != "dev" && ver == "" {
-			ver = version
-		} else {
-			v, err := resolveLatestTag()
-			if err != nil {
+func newStatusCmd() *cobra.Command {
+	var (
+		installNamespace string
+		wait             bool
+		waitOpts         waitOptions
+	)
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show where Sympozium is installed and the state of its control plane",
+		Long: `Show where Sympozium is installed and the state of its control plane.
+
+With --wait, status first waits until every control-plane Deployment is
+Available, up to --timeout, and fails if they are not.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := waitOpts.validate(); err != nil {
 				return err
 			}
-			ver = v
-		}
+			ns := installNamespace
+			if ns == "" {
+				var err error
+				if ns, err = resolveInstallNamespace("--install-namespace"); err != nil {
+					return err
+				}
+			}
+			if wait {
+				if err := waitForDeploymentsAvailable(waitOpts, ns, "app.kubernetes.io/name=sympozium"); err != nil {
+					return err
+				}
+			}
+			fmt.Printf("  Namespace: %s\n", ns)
+			if ver := installMarkerValue(ns, "version"); ver != "" {
+				fmt.Printf("  Version:   %s\n", ver)
+			}
+			fmt.Println()
+			return kubectl("get", "deployments", "-n", ns, "-l", "app.kubernetes.io/name=sympozium")
+		},
 	}
+	cmd.Flags().StringVar(&installNamespace, "install-namespace", "", "Namespace of the Sympozium control plane (default: recorded at install time)")
+	cmd.Flags().BoolVar(&wait, "wait", false, "Wait for the control-plane Deployments to be Available")
+	addWaitFlags(cmd, &waitOpts, "the control plane with --wait")
+	return cmd
+}
 
-	fmt.Printf("  Installing Sympozium %s...\n", ver)
+// installOptions carries the flags accepted by the install command.
+type installOptions struct {
+	Version       string
+	ImageTag      string
+	ImageRegistry string
+	Namespace     string
+	DryRun        bool
+	RenderHelm    bool
+	OutDir        string
+	// ContinueOnError attempts every phase instead of stopping at the
+	// first failure.
+	ContinueOnError bool
+	// FieldManager owns the applied fields; ForceConflicts takes over
+	// fields owned by other managers.
+	FieldManager   string
+	ForceConflicts bool
+	Wait           waitOptions
+}
+
+// resolveInstallVersion maps an empty or "latest" version to a concrete
+// release tag: the CLI's own version for release builds, otherwise the
+// latest GitHub release.
+func resolveInstallVersion(ver string) (string, error) {
+	if ver == "" || ver == "latest" {
+		if version != "dev" && ver == "" {
+			return version, nil
+		}
+		return resolveLatestTag()
+	}
+	return ver, nil
+}
 
-	// Download manifest bundle.
+// fetchManifestBundle downloads and extracts the release manifest bundle for
+// ver into a new temporary directory. The caller must remove the directory.
+func fetchManifestBundle(ver string, progress io.Writer) (string, error) {
 	url := fmt.Sprintf("https://github.com/%s/releases/download/%s/%s", ghRepo, ver, manifestAsset)
 	tmpDir, err := os.MkdirTemp("", "sympozium-install-*")
 	if err != nil {
-		return fmt.Errorf("create temp dir: %w", err)
+		return "", fmt.Errorf("create temp dir: %w", err)
 	}
-	defer os.RemoveAll(tmpDir)
 
 	bundlePath := filepath.Join(tmpDir, manifestAsset)
-	fmt.Println("  Downloading manifests...")
+	fmt.Fprintln(progress, "  Downloading manifests...")
 	if err := downloadFile(url, bundlePath); err != nil {
-		return fmt.Errorf("download manifests: %w", err)
+		os.RemoveAll(tmpDir)
+		return "", fmt.Errorf("download manifests: %w", err)
 	}
 
-	// Extract.
-	fmt.Println("  Extracting...")
+	fmt.Fprintln(progress, "  Extracting...")
 	tar := exec.Command("tar", "-xzf", bundlePath, "-C", tmpDir)
 	tar.Stderr = os.Stderr
 	if err := tar.Run(); err != nil {
-		return fmt.Errorf("extract manifests: %w", err)
-	}
-
-	// Rewrite image tags if --image-tag was provided.
-	if imageTag != "" {
-		fmt.Printf("  Rewriting image tags to :%s...\n", imageTag)
-		sed := exec.Command("find", filepath.Join(tmpDir, "config"), "-name", "*.yaml", "-exec",
-			"sed", "-i",
-			fmt.Sprintf(`s|ghcr.io/alexsjones/sympozium/\([^:]*\):[^ ]*|ghcr.io/alexsjones/sympozium/\1:%s|g`, imageTag),
-			"{}", "+")
-		sed.Stderr = os.Stderr
-		if err := sed.Run(); err != nil {
+		os.RemoveAll(tmpDir)
+		return "", fmt.Errorf("extract manifests: %w", err)
+	}
+	return tmpDir, nil
+}
+
+// applyInstallOverrides rewrites the extracted manifests in place according
+// to the image and namespace overrides in opts.
+func applyInstallOverrides(bundleDir string, opts installOptions, progress io.Writer) error {
+	configDir := filepath.Join(bundleDir, "config")
+	if opts.ImageTag != "" || opts.ImageRegistry != "" {
+		fmt.Fprintf(progress, "  Rewriting images (registry=%q tag=%q)...\n", opts.ImageRegistry, opts.ImageTag)
+		if err := rewriteImages(configDir, opts.ImageRegistry, opts.ImageTag); err != nil {
 			return fmt.Errorf("rewrite image tags: %w", err)
 		}
 	}
+	if opts.Namespace != defaultInstallNamespace {
+		fmt.Fprintf(progress, "  Rewriting control-plane namespace to %s...\n", opts.Namespace)
+		if err := rewriteInstallNamespace(configDir, opts.Namespace); err != nil {
+			return fmt.Errorf("rewrite namespace: %w", err)
+		}
+	}
+	return nil
+}
 
-	// Apply CRDs first (server-side apply to handle schema updates cleanly).
-	fmt.Println("  Applying CRDs...")
-	if err := kubectl("apply", "--server-side", "--force-conflicts", "-f", filepath.Join(tmpDir, "config/crd/bases/")); err != nil {
+func runInstall(opts installOptions) error {
+	if opts.Namespace == "" {
+		opts.Namespace = defaultInstallNamespace
+	}
+	ver, err := resolveInstallVersion(opts.Version)
+	if err != nil {
 		return err
 	}
+	installNS := opts.Namespace
+
+	// Dry-run and chart rendering write YAML to stdout/disk, so progress
+	// goes to stderr to keep the data stream clean.
+	var progress io.Writer = os.Stdout
+	if opts.DryRun || opts.RenderHelm {
+		progress = os.Stderr
+	}
 
-	// Create namespace before RBAC (ServiceAccounts reference it).
-	// Ignore AlreadyExists error on re-installs.
-	fmt.Println("  Creating namespace...")
-	_ = kubectl("create", "namespace", "sympozium-system")
+	if opts.RenderHelm {
+		fmt.Fprintf(progress, "  Rendering Helm chart for Sympozium %s...\n", ver)
+	} else {
+		fmt.Fprintf(progress, "  Installing Sympozium %s...\n", ver)
+	}
 
-	// Deploy NATS event bus.
-	fmt.Println("  Deploying NATS event bus...")
-	if err := kubectl("apply", "-f", resolveConfigPath(tmpDir, "config/nats/")); err != nil {
+	tmpDir, err := fetchManifestBundle(ver, progress)
+	if err != nil {
 		return err
 	}
+	defer os.RemoveAll(tmpDir)
 
-	// Install cert-manager if not present, then apply webhook certificate.
-	fmt.Println("  Checking cert-manager...")
-	if err := kubectlQuiet("get", "namespace", "cert-manager"); err != nil {
-		fmt.Println("  Installing cert-manager...")
-		if err := kubectl("apply", "-f",
-			"https://github.com/cert-manager/cert-manager/releases/download/v1.17.1/cert-manager.yaml"); err != nil {
-			return fmt.Errorf("install cert-manager: %w", err)
-		}
-		fmt.Println("  Waiting for cert-manager to be ready...")
-		_ = kubectl("wait", "--for=condition=Available", "deployment/cert-manager",
-			"-n", "cert-manager", "--timeout=120s")
-		_ = kubectl("wait", "--for=condition=Available", "deployment/cert-manager-webhook",
-			"-n", "cert-manager", "--timeout=120s")
-		_ = kubectl("wait", "--for=condition=Available", "deployment/cert-manager-cainjector",
-			"-n", "cert-manager", "--timeout=120s")
-		// The webhook needs a few extra seconds after the Deployment is Available
-		// to finish TLS bootstrapping. Retry the certificate creation.
-		fmt.Println("  Waiting for cert-manager webhook TLS to bootstrap...")
-		time.Sleep(10 * time.Second)
-	}
-
-	fmt.Println("  Creating webhook certificate...")
-	// Retry with backoff — cert-manager's webhook may still be bootstrapping TLS.
-	var certErr error
-	for attempt := 0; attempt < 5; attempt++ {
-		if certErr = kubectl("apply", "-f", resolveConfigPath(tmpDir, "config/cert/")); certErr == nil {
-			break
+	if opts.RenderHelm {
+		if opts.OutDir == "" {
+			return fmt.Errorf("--out is required with --render-helm")
 		}
-		wait := time.Duration(5*(attempt+1)) * time.Second
-		fmt.Printf("  Cert-manager webhook not ready, retrying in %s...\n", wait)
-		time.Sleep(wait)
-	}
-	if certErr != nil {
-		return fmt.Errorf("creating webhook certificate (cert-manager webhook may not be ready): %w", certErr)
+		if err := renderHelmChart(tmpDir, opts.OutDir, ver, opts); err != nil {
+			return fmt.Errorf("render helm chart: %w", err)
+		}
+		fmt.Fprintf(progress, "  Helm chart written to %s\n", opts.OutDir)
+		return nil
 	}
 
-	// Apply RBAC.
-	fmt.Println("  Applying RBAC...")
-	if err := kubectl("apply", "-f", filepath.Join(tmpDir, "config/rbac/")); err != nil {
+	if err := applyInstallOverrides(tmpDir, opts, progress); err != nil {
 		return err
 	}
 
-	// Apply manager (controller + apiserver).
-	fmt.Println("  Deploying control plane...")
-	if err := kubectl("apply", "-f", filepath.Join(tmpDir, "config/manager/")); err != nil {
-		return err
+	if opts.DryRun {
+		return printInstallManifests(os.Stdout, tmpDir)
 	}
 
-	// Apply webhook (use --server-side --force-conflicts to overwrite stale configs).
-	fmt.Println("  Deploying webhook...")
-	if err := kubectl("apply", "--server-side", "--force-conflicts", "-f", filepath.Join(tmpDir, "config/webhook/")); err != nil {
+	if opts.FieldManager == "" {
+		opts.FieldManager = cliFieldManager
+	}
+	apply := func(path string) error {
+		return kubectlServerSideApply(path, opts.FieldManager, opts.ForceConflicts)
+	}
+	phases := []installPhase{
+		{"CRDs", func() error {
+			// Always forced: the CRDs must match this release's schema.
+			fmt.Println("  Applying CRDs...")
+			if err := kubectlServerSideApply(filepath.Join(tmpDir, "config/crd/bases/"), opts.FieldManager, true); err != nil {
+				return err
+			}
+			return waitForCRDsEstablished(opts.Wait)
+		}},
+		{"Namespace", func() error {
+			// Create namespace before RBAC (ServiceAccounts reference it).
+			// Ignore AlreadyExists error on re-installs.
+			fmt.Println("  Creating namespace...")
+			_ = kubectlQuiet("create", "namespace", installNS)
+			if err := recordInstallNamespace(installNS, ver); err != nil {
+				fmt.Printf("  Warning: failed to record install namespace: %v\n", err)
+			}
+			return nil
+		}},
+		{"NATS event bus", func() error {
+			fmt.Println("  Deploying NATS event bus...")
+			return apply(resolveConfigPath(tmpDir, "config/nats/"))
+		}},
+		{"cert-manager", func() error { return installCertManager(opts.Wait) }},
+		{"Webhook certificate", func() error {
+			fmt.Println("  Creating webhook certificate...")
+			// Retry until it is accepted — cert-manager's webhook may still
+			// be bootstrapping TLS.
+			var certErr error
+			err := pollUntil(opts.Wait, "the webhook certificate to be accepted", func() (bool, string, error) {
+				if certErr = apply(resolveConfigPath(tmpDir, "config/cert/")); certErr != nil {
+					fmt.Printf("  Cert-manager webhook not ready, retrying in %s...\n", opts.Wait.PollInterval)
+					return false, "", nil
+				}
+				return true, "", nil
+			})
+			if err != nil {
+				return fmt.Errorf("creating webhook certificate (cert-manager webhook may not be ready): %w", certErr)
+			}
+			return nil
+		}},
+		{"RBAC", func() error {
+			fmt.Println("  Applying RBAC...")
+			return apply(filepath.Join(tmpDir, "config/rbac/"))
+		}},
+		{"Control plane", func() error {
+			// Controller manager and API server.
+			fmt.Println("  Deploying control plane...")
+			return apply(filepath.Join(tmpDir, "config/manager/"))
+		}},
+		{"Webhook", func() error {
+			// Always forced, which overwrites stale configs.
+			fmt.Println("  Deploying webhook...")
+			return kubectlServerSideApply(filepath.Join(tmpDir, "config/webhook/"), opts.FieldManager, true)
+		}},
+		{"Network policies", func() error {
+			fmt.Println("  Applying network policies...")
+			return apply(filepath.Join(tmpDir, "config/network/"))
+		}},
+		{"Defaults", func() error {
+			// SkillPacks, SympoziumPolicies and PersonaPacks are optional.
+			installOptionalDefaults(tmpDir, opts.FieldManager)
+			return nil
+		}},
+		{"Web UI token", func() error {
+			ensureUITokenSecret(installNS)
+			return nil
+		}},
+	}
+	if err := runInstallPhases(os.Stdout, phases, opts.ContinueOnError); err != nil {
 		return err
 	}
 
-	// Apply network policies.
-	fmt.Println("  Applying network policies...")
-	if err := kubectl("apply", "-f", filepath.Join(tmpDir, "config/network/")); err != nil {
+	fmt.Println("\n  Sympozium installed successfully!")
+	fmt.Println("  Run: sympozium")
+	fmt.Println("\n  To access the web dashboard:")
+	fmt.Println("    sympozium serve")
+	return nil
+}
+
+// installCertManager installs cert-manager unless its namespace already
+// exists, and waits for it to become ready.
+func installCertManager(wait waitOptions) error {
+	fmt.Println("  Checking cert-manager...")
+	if err := kubectlQuiet("get", "namespace", "cert-manager"); err == nil {
+		return nil
+	}
+	fmt.Println("  Installing cert-manager...")
+	if err := kubectl("apply", "-f",
+		"https://github.com/cert-manager/cert-manager/releases/download/v1.17.1/cert-manager.yaml"); err != nil {
+		return fmt.Errorf("install cert-manager: %w", err)
+	}
+	fmt.Println("  Waiting for cert-manager to be ready...")
+	if err := waitForDeploymentsAvailable(wait, "cert-manager", "",
+		"cert-manager", "cert-manager-webhook", "cert-manager-cainjector"); err != nil {
 		return err
 	}
+	// The webhook needs a few extra seconds after the Deployment is Available
+	// to finish TLS bootstrapping. Retry the certificate creation.
+	fmt.Println("  Waiting for cert-manager webhook TLS to bootstrap...")
+	time.Sleep(10 * time.Second)
+	return nil
+}
 
-	// Install default SkillPacks into sympozium-system.
+// installOptionalDefaults installs the default SkillPacks, SympoziumPolicies
+// and PersonaPacks shipped in the bundle, taking ownership of their fields.
+// Failures are warnings only.
+func installOptionalDefaults(tmpDir, fieldManager string) {
+	// Install default SkillPacks into the control-plane namespace.
 	skillsDir := filepath.Join(tmpDir, "config/skills/")
 	if _, err := os.Stat(skillsDir); err == nil {
 		fmt.Println("  Installing default SkillPacks...")
-		if err := kubectl("apply", "--server-side", "--force-conflicts", "-f", skillsDir); err != nil {
+		if err := kubectlServerSideApply(skillsDir, fieldManager, true); err != nil {
 			// Non-fatal — skills are optional.
 			fmt.Printf("  Warning: failed to install default skills: %v\n", err)
 		}
@@ -1134,7 +1858,7 @@ func runInstall(ver, imageTag string) error {
 	policiesDir := filepath.Join(tmpDir, "config/policies/")
 	if _, err := os.Stat(policiesDir); err == nil {
 		fmt.Println("  Installing default SympoziumPolicies...")
-		if err := kubectl("apply", "--server-side", "--force-conflicts", "-f", policiesDir); err != nil {
+		if err := kubectlServerSideApply(policiesDir, fieldManager, true); err != nil {
 			// Non-fatal — policies are optional.
 			fmt.Printf("  Warning: failed to install default policies: %v\n", err)
 		}
@@ -1144,18 +1868,21 @@ func runInstall(ver, imageTag string) error {
 	personasDir := filepath.Join(tmpDir, "config/personas/")
 	if _, err := os.Stat(personasDir); err == nil {
 		fmt.Println("  Installing default PersonaPacks...")
-		if err := kubectl("apply", "--server-side", "--force-conflicts", "-f", personasDir); err != nil {
+		if err := kubectlServerSideApply(personasDir, fieldManager, true); err != nil {
 			// Non-fatal — persona packs are optional.
 			fmt.Printf("  Warning: failed to install default persona packs: %v\n", err)
 		}
 	}
+}
 
+// ensureUITokenSecret creates the web dashboard token secret if missing.
+func ensureUITokenSecret(installNS string) {
 	// Generate a random UI token for the web dashboard (if not already present).
 	fmt.Println("  Creating web UI token secret...")
-	if err := kubectlQuiet("get", "secret", "sympozium-ui-token", "-n", "sympozium-system"); err != nil {
+	if err := kubectlQuiet("get", "secret", "sympozium-ui-token", "-n", installNS); err != nil {
 		// Secret doesn't exist — create one with a random token.
 		createSecret := exec.Command("kubectl", "create", "secret", "generic", "sympozium-ui-token",
-			"-n", "sympozium-system",
+			"-n", installNS,
 			"--from-literal=token="+generateToken(32))
 		createSecret.Stderr = os.Stderr
 		if secErr := createSecret.Run(); secErr != nil {
@@ -1164,21 +1891,82 @@ func runInstall(ver, imageTag string) error {
 	} else {
 		fmt.Println("  UI token secret already exists, skipping.")
 	}
+}
 
-	fmt.Println("\n  Sympozium installed successfully!")
-	fmt.Println("  Run: sympozium")
-	fmt.Println("\n  To access the web dashboard:")
-	fmt.Println("    sympozium serve")
-	return nil
+// uninstallOptions holds the flags for 'sympozium uninstall'.
+type uninstallOptions struct {
+	Namespace       string
+	KeepCRDs        bool
+	Yes             bool
+	ForceFinalizers bool
+	Wait            waitOptions
 }
 
-func runUninstall() error {
-	fmt.Println("  Removing Sympozium...")
+// sympoziumResources lists the Sympozium custom resources in the order they
+// are deleted on uninstall: dependents before the objects they reference.
+var sympoziumResources = []struct {
+	resource string
+	kind     string
+}{
+	{"agentruns", "AgentRun"},
+	{"sympoziumschedules", "SympoziumSchedule"},
+	{"sympoziuminstances", "SympoziumInstance"},
+	{"personapacks", "PersonaPack"},
+	{"sympoziumpolicies", "SympoziumPolicy"},
+	{"skillpacks", "SkillPack"},
+}
 
-	// Delete default SkillPacks from sympozium-system first (before CRDs go away).
-	fmt.Println("  Removing default SkillPacks...")
-	_ = kubectl("delete", "skillpacks.sympozium.ai", "--ignore-not-found",
-		"-n", "sympozium-system", "-l", "sympozium.ai/builtin=true")
+func runUninstall(opts uninstallOptions) error {
+	installNS := opts.Namespace
+
+	if !opts.KeepCRDs {
+		counts := map[string]int{}
+		for _, r := range sympoziumResources {
+			objs, err := listSympoziumObjects(r.resource)
+			if err != nil {
+				return fmt.Errorf("count %s: %w", r.resource, err)
+			}
+			counts[r.kind] = len(objs)
+		}
+		if summary := describeResourceCounts(counts); summary != "" {
+			fmt.Printf("  This will delete %s across all namespaces.\n", summary)
+			if !opts.Yes {
+				if !promptYN(bufio.NewReader(os.Stdin), "  Continue?", false) {
+					return fmt.Errorf("uninstall aborted; use --keep-crds to keep Sympozium resources")
+				}
+			}
+		}
+
+		// Delete resources while the controller is still running so their
+		// finalizers are processed normally.
+		fmt.Println("  Deleting Sympozium resources...")
+		for _, r := range sympoziumResources {
+			_ = kubectlQuiet("delete", r.resource+".sympozium.ai", "--all", "--all-namespaces",
+				"--ignore-not-found", "--wait=false")
+		}
+		stuck, err := waitForSympoziumResourcesGone(opts.Wait)
+		if err != nil {
+			return err
+		}
+		if len(stuck) > 0 {
+			fmt.Printf("  %d resource(s) are stuck on finalizers:\n", len(stuck))
+			for _, o := range stuck {
+				fmt.Printf("    %s %s/%s (finalizers: %s)\n", o.kind, o.Namespace, o.Name, strings.Join(o.Finalizers, ", "))
+			}
+			if !opts.ForceFinalizers {
+				return fmt.Errorf("%d resource(s) stuck on finalizers; the control plane was left in place, rerun with --force-finalizers to clear them", len(stuck))
+			}
+			fmt.Println("  Clearing finalizers...")
+			for _, o := range stuck {
+				if err := kubectlQuiet("patch", o.resource+".sympozium.ai", o.Name, "-n", o.Namespace,
+					"--type=merge", "-p", `{"metadata":{"finalizers":null}}`); err != nil {
+					fmt.Fprintf(os.Stderr, "  Warning: could not clear finalizers on %s %s/%s: %v\n", o.kind, o.Namespace, o.Name, err)
+				}
+			}
+		}
+	}
+
+	fmt.Printf("  Removing Sympozium from namespace %s...\n", installNS)
 
 	// Delete in reverse order.
 	manifests := []string{
@@ -1187,15 +1975,36 @@ func runUninstall() error {
 		"https://raw.githubusercontent.com/" + ghRepo + "/main/config/manager/manager.yaml",
 		"https://raw.githubusercontent.com/" + ghRepo + "/main/config/rbac/role.yaml",
 	}
+	if installNS != defaultInstallNamespace {
+		// The published manifests name the default namespace; fetch and
+		// rewrite them so the delete targets the recorded namespace.
+		tmpDir, err := os.MkdirTemp("", "sympozium-uninstall-*")
+		if err != nil {
+			return fmt.Errorf("create temp dir: %w", err)
+		}
+		defer os.RemoveAll(tmpDir)
+		for i, m := range manifests {
+			dest := filepath.Join(tmpDir, fmt.Sprintf("%d-%s", i, filepath.Base(m)))
+			if err := downloadFile(m, dest); err != nil {
+				return fmt.Errorf("download %s: %w", m, err)
+			}
+			manifests[i] = dest
+		}
+		if err := rewriteInstallNamespace(tmpDir, installNS); err != nil {
+			return fmt.Errorf("rewrite namespace: %w", err)
+		}
+	}
 	for _, m := range manifests {
 		_ = kubectl("delete", "--ignore-not-found", "-f", m)
 	}
+	_ = kubectl("delete", "configmap", installMarkerName, "--ignore-not-found", "-n", installNS)
+	if installNS != defaultInstallNamespace && !opts.KeepCRDs {
+		_ = kubectl("delete", "namespace", installNS, "--ignore-not-found")
+	}
 
-	// Strip finalizers from all Sympozium CRD instances so CRD deletion doesn't
-	// hang waiting for the (now-deleted) controller to reconcile them.
-	fmt.Println("  Removing finalizers from Sympozium resources...")
-	for _, res := range []string{"agentruns", "sympoziuminstances", "sympoziumpolicies", "skillpacks", "sympoziumschedules", "personapacks"} {
-		stripFinalizers(res)
+	if opts.KeepCRDs {
+		fmt.Println("  Sympozium control plane removed; CRDs and resources were kept.")
+		return nil
 	}
 
 	// CRDs last.
@@ -1216,34 +2025,302 @@ func runUninstall() error {
 	return nil
 }
 
-// stripFinalizers patches all instances of a Sympozium CRD to remove finalizers.
-func stripFinalizers(resource string) {
-	// List all resource names across all namespaces.
-	out, err := exec.Command("kubectl", "get", resource+".sympozium.ai",
-		"--all-namespaces", "-o", "jsonpath={range .items[*]}{.metadata.namespace}/{.metadata.name}{\"\\n\"}{end}").
-		Output()
+// sympoziumObject identifies a Sympozium custom resource found in the cluster.
+type sympoziumObject struct {
+	resource string
+	kind     string
+	metav1.ObjectMeta
+}
+
+// listSympoziumObjects lists every object of a Sympozium resource across all
+// namespaces. A missing CRD yields no objects.
+func listSympoziumObjects(resource string) ([]metav1.PartialObjectMetadata, error) {
+	var stderr strings.Builder
+	cmd := exec.Command("kubectl", "get", resource+".sympozium.ai", "--all-namespaces", "-o", "json")
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
 	if err != nil {
-		return // CRD may not exist
+		if strings.Contains(stderr.String(), "the server doesn't have a resource type") {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	var list metav1.PartialObjectMetadataList
+	if err := json.Unmarshal(out, &list); err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// waitForSympoziumResourcesGone polls until no Sympozium resources remain or
+// the timeout elapses, returning whatever is still present.
+func waitForSympoziumResourcesGone(wait waitOptions) ([]sympoziumObject, error) {
+	var remaining []sympoziumObject
+	var listErr error
+	_ = pollUntil(wait, "resources to be deleted", func() (bool, string, error) {
+		remaining = nil
+		for _, r := range sympoziumResources {
+			objs, err := listSympoziumObjects(r.resource)
+			if err != nil {
+				listErr = fmt.Errorf("list %s: %w", r.resource, err)
+				return false, "", listErr
+			}
+			for _, o := range objs {
+				remaining = append(remaining, sympoziumObject{resource: r.resource, kind: r.kind, ObjectMeta: o.ObjectMeta})
+			}
+		}
+		return len(remaining) == 0, "", nil
+	})
+	if listErr != nil {
+		return nil, listErr
 	}
-	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
-		if line == "" {
+	// Whatever is left after the timeout is reported as stuck.
+	return remaining, nil
+}
+
+// describeResourceCounts renders non-zero counts per kind in deletion order,
+// e.g. "14 SympoziumInstances and 2,310 AgentRuns". It returns "" when there
+// is nothing to delete.
+func describeResourceCounts(counts map[string]int) string {
+	var parts []string
+	for _, r := range sympoziumResources {
+		n := counts[r.kind]
+		if n == 0 {
 			continue
 		}
-		parts := strings.SplitN(line, "/", 2)
-		if len(parts) != 2 {
+		kind := r.kind
+		if n != 1 {
+			kind = pluralKind(kind)
+		}
+		parts = append(parts, formatThousands(n)+" "+kind)
+	}
+	switch len(parts) {
+	case 0:
+		return ""
+	case 1:
+		return parts[0]
+	}
+	return strings.Join(parts[:len(parts)-1], ", ") + " and " + parts[len(parts)-1]
+}
+
+// pluralKind returns the English plural of a Sympozium kind name.
+func pluralKind(kind string) string {
+	if strings.HasSuffix(kind, "y") {
+		return strings.TrimSuffix(kind, "y") + "ies"
+	}
+	return kind + "s"
+}
+
+// formatThousands formats n with comma thousands separators.
+func formatThousands(n int) string {
+	if n < 0 {
+		return "-" + formatThousands(-n)
+	}
+	s := strconv.Itoa(n)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
+
+const (
+	// defaultInstallNamespace is the control-plane namespace baked into the
+	// release manifests.
+	defaultInstallNamespace = "sympozium-system"
+
+	// installMarkerName is the ConfigMap that records where the control plane
+	// was installed. It carries installMarkerLabel so it can be found without
+	// knowing the namespace up front.
+	installMarkerName  = "sympozium-install"
+	installMarkerLabel = "sympozium.ai/install-marker"
+)
+
+// defaultImageRegistry is the registry prefix used by the release manifests.
+const defaultImageRegistry = "ghcr.io/alexsjones/sympozium"
+
+// sympoziumImageRe matches Sympozium component image references, capturing
+// the component name and tag.
+var sympoziumImageRe = regexp.MustCompile(regexp.QuoteMeta(defaultImageRegistry) + `/([^:\s"]+):([^\s"]+)`)
+
+// installManifestGroups lists the bundle directories in the order install
+// applies them. Groups with a feature name are optional components that can
+// be toggled off in a rendered Helm chart.
+var installManifestGroups = []struct {
+	dir     string
+	feature string
+}{
+	{dir: "config/crd/bases"},
+	{dir: "config/nats"},
+	{dir: "config/cert"},
+	{dir: "config/rbac"},
+	{dir: "config/manager"},
+	{dir: "config/webhook"},
+	{dir: "config/network", feature: "networkPolicies"},
+	{dir: "config/skills", feature: "defaultSkills"},
+	{dir: "config/policies", feature: "defaultPolicies"},
+	{dir: "config/personas", feature: "defaultPersonas"},
+}
+
+// rewriteImages replaces the registry and/or tag of every Sympozium image
+// reference in the YAML files under dir. Empty values leave that part as is.
+func rewriteImages(dir, registry, tag string) error {
+	return rewriteYAMLFiles(dir, func(data string) string {
+		return sympoziumImageRe.ReplaceAllStringFunc(data, func(ref string) string {
+			m := sympoziumImageRe.FindStringSubmatch(ref)
+			reg, t := defaultImageRegistry, m[2]
+			if registry != "" {
+				reg = strings.TrimRight(registry, "/")
+			}
+			if tag != "" {
+				t = tag
+			}
+			return reg + "/" + m[1] + ":" + t
+		})
+	})
+}
+
+// rewriteYAMLFiles applies fn to the contents of every YAML file under dir,
+// writing back only files that changed.
+func rewriteYAMLFiles(dir string, fn func(string) string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !isYAMLFile(path) {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		out := fn(string(data))
+		if out == string(data) {
+			return nil
+		}
+		return os.WriteFile(path, []byte(out), 0o644)
+	})
+}
+
+func isYAMLFile(path string) bool {
+	ext := filepath.Ext(path)
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// installManifestFiles returns the YAML files of a bundle in apply order.
+func installManifestFiles(bundleDir string) ([]string, error) {
+	var files []string
+	for _, g := range installManifestGroups {
+		entries, err := os.ReadDir(filepath.Join(bundleDir, g.dir))
+		if os.IsNotExist(err) {
 			continue
 		}
-		ns, name := parts[0], parts[1]
-		_ = exec.Command("kubectl", "patch", resource+".sympozium.ai", name,
-			"-n", ns, "--type=merge",
-			"-p", `{"metadata":{"finalizers":[]}}`).Run()
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			if !e.IsDir() && isYAMLFile(e.Name()) {
+				files = append(files, filepath.Join(bundleDir, g.dir, e.Name()))
+			}
+		}
+	}
+	return files, nil
+}
+
+// printInstallManifests writes every manifest install would apply to w as a
+// single multi-document YAML stream.
+func printInstallManifests(w io.Writer, bundleDir string) error {
+	files, err := installManifestFiles(bundleDir)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(bundleDir, f)
+		fmt.Fprintf(w, "---\n# Source: %s\n%s\n", rel, strings.TrimPrefix(strings.TrimSpace(string(data)), "---\n"))
+	}
+	return nil
+}
+
+// rewriteInstallNamespace replaces every reference to the default
+// control-plane namespace in the YAML files under dir. A textual rewrite
+// covers object namespaces, RBAC subjects, webhook clientConfig services,
+// cert-manager annotations and in-cluster service DNS names alike.
+func rewriteInstallNamespace(dir, ns string) error {
+	return rewriteYAMLFiles(dir, func(data string) string {
+		return strings.ReplaceAll(data, defaultInstallNamespace, ns)
+	})
+}
+
+// recordInstallNamespace writes the install marker ConfigMap into ns.
+func recordInstallNamespace(ns, ver string) error {
+	return kubectlApplyStdin(installMarkerManifest(ns, ver))
+}
+
+// installMarkerManifest returns the install marker ConfigMap for a control
+// plane of version ver installed into ns.
+func installMarkerManifest(ns, ver string) string {
+	return fmt.Sprintf(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: %s
+  namespace: %s
+  labels:
+    %s: "true"
+data:
+  namespace: %s
+  version: %q
+`, installMarkerName, ns, installMarkerLabel, ns, ver)
+}
+
+// kubectlOutput runs kubectl and returns its stdout. Tests replace it.
+var kubectlOutput = func(args ...string) ([]byte, error) {
+	return exec.Command("kubectl", args...).Output()
+}
+
+// resolveInstallNamespace looks up the namespace recorded by the install
+// marker, falling back to the default when no marker is found. Markers in
+// several namespaces are an error, since any pick could target the wrong
+// control plane; flag names the option that selects one instead.
+func resolveInstallNamespace(flag string) (string, error) {
+	out, err := kubectlOutput("get", "configmaps", "--all-namespaces",
+		"-l", installMarkerLabel+"=true",
+		"-o", `jsonpath={range .items[*]}{.data.namespace}{"\n"}{end}`)
+	if err != nil {
+		return defaultInstallNamespace, nil
+	}
+	namespaces := strings.Fields(string(out))
+	sort.Strings(namespaces)
+	namespaces = slices.Compact(namespaces)
+	switch len(namespaces) {
+	case 0:
+		return defaultInstallNamespace, nil
+	case 1:
+		return namespaces[0], nil
+	}
+	return "", fmt.Errorf("found Sympozium install markers in several namespaces (%s); pass %s to choose one",
+		strings.Join(namespaces, ", "), flag)
+}
+
+// installMarkerValue reads a single key from the install marker in ns.
+func installMarkerValue(ns, key string) string {
+	out, err := kubectlOutput("get", "configmap", installMarkerName, "-n", ns,
+		"-o", "jsonpath={.data."+key+"}")
+	if err != nil {
+		return ""
 	}
+	return strings.TrimSpace(string(out))
 }
 
 func resolveLatestTag() (string, error) {
-	client := &http.Client{CheckRedirect: func(req *http.Request, via []*http.Request) error {
-		return http.ErrUseLastResponse
-	}}
+	client := &http.Client{
+		Timeout: 15 * time.Second,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
 	resp, err := client.Get(fmt.Sprintf("https://github.com/%s/releases/latest", ghRepo))
 	if err != nil {
 		return "", fmt.Errorf("resolve latest release: %w", err)
@@ -6355,51 +7432,12 @@ func (m tuiModel) renderEditModal(base string) string {
 // ── TUI command implementations ──────────────────────────────────────────────
 
 func tuiCreateRun(ns, instance, task string) (string, error) {
-	ctx := context.Background()
-	var inst sympoziumv1alpha1.SympoziumInstance
-	if err := k8sClient.Get(ctx, types.NamespacedName{Name: instance, Namespace: ns}, &inst); err != nil {
-		return "", fmt.Errorf("instance %q not found: %w", instance, err)
-	}
-
-	// Resolve auth secret and provider from instance — first AuthRef wins.
-	authSecret := ""
-	provider := "openai"
-	if len(inst.Spec.AuthRefs) > 0 {
-		authSecret = inst.Spec.AuthRefs[0].Secret
-		if inst.Spec.AuthRefs[0].Provider != "" {
-			provider = inst.Spec.AuthRefs[0].Provider
-		}
-	}
-	if authSecret == "" {
-		return "", fmt.Errorf("instance %q has no API key configured (authRefs is empty) — "+
-			"activate the persona pack through the TUI onboarding wizard or add an authRef manually", instance)
-	}
-
-	runName := fmt.Sprintf("%s-run-%d", instance, time.Now().Unix())
-	run := &sympoziumv1alpha1.AgentRun{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      runName,
-			Namespace: ns,
-			Labels: map[string]string{
-				"sympozium.ai/instance": instance,
-			},
-		},
-		Spec: sympoziumv1alpha1.AgentRunSpec{
-			InstanceRef: instance,
-			Task:        task,
-			Model: sympoziumv1alpha1.ModelSpec{
-				Provider:      provider,
-				Model:         inst.Spec.Agents.Default.Model,
-				BaseURL:       inst.Spec.Agents.Default.BaseURL,
-				AuthSecretRef: authSecret,
-			},
-			Skills:  inst.Spec.Skills,
-			Timeout: &metav1.Duration{Duration: 10 * time.Minute},
-		},
-	}
-	if err := k8sClient.Create(ctx, run); err != nil {
-		return "", fmt.Errorf("create run: %w", err)
+	runName, err := createRunForInstance(context.Background(), k8sClient, ns, instance, task, false)
+	if err != nil {
+		return "", err
 	}
+	// Best effort: failing to remember the run must not fail its creation.
+	_ = recordLastRun(ns, runName)
 	return tuiSuccessStyle.Render(fmt.Sprintf("✓ Created AgentRun: %s", runName)), nil
 }
 
@@ -8345,11 +9383,11 @@ func tuiOnboardApply(ns string, w *wizardState) (string, error) {
 			if getErr := k8sClient.Get(ctx, types.NamespacedName{Name: policyName, Namespace: ns}, &existingPol); getErr == nil {
 				existingPol.Spec = pol.Spec
 				if err2 := k8sClient.Update(ctx, &existingPol); err2 != nil {
-					return "", fmt.Errorf("update policy: %w", err2)
+					return "", fmt.Errorf("update policy: %w", explainAdmissionError(err2))
 				}
 				msgs = append(msgs, tuiSuccessStyle.Render(fmt.Sprintf("✓ Updated policy: %s", policyName)))
 			} else {
-				return "", fmt.Errorf("apply policy: %w", err)
+				return "", fmt.Errorf("apply policy: %w", explainAdmissionError(err))
 			}
 		} else {
 			msgs = append(msgs, tuiSuccessStyle.Render(fmt.Sprintf("✓ Created policy: %s", policyName)))
@@ -8414,11 +9452,11 @@ func tuiOnboardApply(ns string, w *wizardState) (string, error) {
 		if getErr := k8sClient.Get(ctx, types.NamespacedName{Name: w.instanceName, Namespace: ns}, &existing); getErr == nil {
 			existing.Spec = inst.Spec
 			if err2 := k8sClient.Update(ctx, &existing); err2 != nil {
-				return "", fmt.Errorf("update instance: %w", err2)
+				return "", fmt.Errorf("update instance: %w", explainAdmissionError(err2))
 			}
 			msgs = append(msgs, tuiSuccessStyle.Render(fmt.Sprintf("✓ Updated SympoziumInstance: %s", w.instanceName)))
 		} else {
-			return "", fmt.Errorf("create instance: %w", err)
+			return "", fmt.Errorf("create instance: %w", explainAdmissionError(err))
 		}
 	} else {
 		msgs = append(msgs, tuiSuccessStyle.Render(fmt.Sprintf("✓ Created SympoziumInstance: %s", w.instanceName)))
@@ -8683,7 +9721,10 @@ the login URL.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ns := svcNamespace
 			if ns == "" {
-				ns = "sympozium-system"
+				var err error
+				if ns, err = resolveInstallNamespace("--service-namespace"); err != nil {
+					return err
+				}
 			}
 
 			// Retrieve the UI token from the cluster secret.
@@ -8777,7 +9818,7 @@ the login URL.`,
 
 	cmd.Flags().StringVar(&localPort, "port", "8080", "Local port to forward to")
 	cmd.Flags().BoolVar(&openBrowser, "open", false, "Open a browser automatically")
-	cmd.Flags().StringVar(&svcNamespace, "service-namespace", "sympozium-system", "Namespace of the sympozium-apiserver service")
+	cmd.Flags().StringVar(&svcNamespace, "service-namespace", "", "Namespace of the sympozium-apiserver service (default: recorded at install time)")
 
 	return cmd
 }
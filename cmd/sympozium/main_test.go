@@ -0,0 +1,451 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/yaml"
+)
+
+// writeKubeconfig writes a single-context kubeconfig pointing at server.
+func writeKubeconfig(t *testing.T, dir, name, server string, current bool) string {
+	t.Helper()
+	currentContext := ""
+	if current {
+		currentContext = "current-context: " + name
+	}
+	content := fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: %[1]s
+  cluster:
+    server: %[2]s
+users:
+- name: %[1]s
+  user:
+    token: %[1]s-token
+contexts:
+- name: %[1]s
+  context:
+    cluster: %[1]s
+    user: %[1]s
+%[3]s
+`, name, server, currentContext)
+	path := filepath.Join(dir, name+".yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestKubeClientConfig_MergesKUBECONFIGPaths(t *testing.T) {
+	dir := t.TempDir()
+	first := writeKubeconfig(t, dir, "alpha", "https://alpha.example.com", true)
+	second := writeKubeconfig(t, dir, "beta", "https://beta.example.com", false)
+
+	t.Setenv("KUBECONFIG", first+string(os.PathListSeparator)+second)
+	old := kubeconfig
+	kubeconfig = ""
+	t.Cleanup(func() { kubeconfig = old })
+
+	raw, err := kubeClientConfig().RawConfig()
+	if err != nil {
+		t.Fatalf("RawConfig: %v", err)
+	}
+	for _, name := range []string{"alpha", "beta"} {
+		if _, ok := raw.Contexts[name]; !ok {
+			t.Errorf("context %q missing from merged config (have %v)", name, raw.Contexts)
+		}
+	}
+	if raw.CurrentContext != "alpha" {
+		t.Errorf("current-context = %q, want alpha", raw.CurrentContext)
+	}
+
+	cfg, err := kubeClientConfig().ClientConfig()
+	if err != nil {
+		t.Fatalf("ClientConfig: %v", err)
+	}
+	if cfg.Host != "https://alpha.example.com" {
+		t.Errorf("host = %q, want https://alpha.example.com", cfg.Host)
+	}
+}
+
+func TestKubeClientConfig_ExplicitPathOverridesEnv(t *testing.T) {
+	dir := t.TempDir()
+	fromEnv := writeKubeconfig(t, dir, "alpha", "https://alpha.example.com", true)
+	explicit := writeKubeconfig(t, dir, "beta", "https://beta.example.com", true)
+
+	t.Setenv("KUBECONFIG", fromEnv)
+	old := kubeconfig
+	kubeconfig = explicit
+	t.Cleanup(func() { kubeconfig = old })
+
+	raw, err := kubeClientConfig().RawConfig()
+	if err != nil {
+		t.Fatalf("RawConfig: %v", err)
+	}
+	if _, ok := raw.Contexts["alpha"]; ok {
+		t.Error("KUBECONFIG contexts should be ignored when --kubeconfig is set")
+	}
+	if raw.CurrentContext != "beta" {
+		t.Errorf("current-context = %q, want beta", raw.CurrentContext)
+	}
+}
+
+func TestDescribeResourceCounts(t *testing.T) {
+	tests := []struct {
+		counts map[string]int
+		want   string
+	}{
+		{map[string]int{}, ""},
+		{map[string]int{"AgentRun": 1}, "1 AgentRun"},
+		{
+			map[string]int{"SympoziumInstance": 14, "AgentRun": 2310},
+			"2,310 AgentRuns and 14 SympoziumInstances",
+		},
+		{
+			map[string]int{"SympoziumPolicy": 2, "SkillPack": 1, "AgentRun": 0, "SympoziumInstance": 3},
+			"3 SympoziumInstances, 2 SympoziumPolicies and 1 SkillPack",
+		},
+	}
+	for _, tt := range tests {
+		if got := describeResourceCounts(tt.counts); got != tt.want {
+			t.Errorf("describeResourceCounts(%v) = %q, want %q", tt.counts, got, tt.want)
+		}
+	}
+}
+
+func TestFormatThousands(t *testing.T) {
+	tests := map[int]string{0: "0", 999: "999", 1000: "1,000", 2310: "2,310", 1234567: "1,234,567", -4500: "-4,500"}
+	for n, want := range tests {
+		if got := formatThousands(n); got != want {
+			t.Errorf("formatThousands(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestServiceAccountNamespace(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "namespace")
+	old := serviceAccountNamespaceFile
+	serviceAccountNamespaceFile = path
+	t.Cleanup(func() { serviceAccountNamespaceFile = old })
+
+	if ns := serviceAccountNamespace(); ns != "" {
+		t.Errorf("missing file: namespace = %q, want empty", ns)
+	}
+	if err := os.WriteFile(path, []byte("ops-jobs\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if ns := serviceAccountNamespace(); ns != "ops-jobs" {
+		t.Errorf("namespace = %q, want ops-jobs", ns)
+	}
+}
+
+func TestLoadRESTConfig(t *testing.T) {
+	if _, err := os.Stat("/var/run/secrets/kubernetes.io/serviceaccount/token"); err == nil {
+		t.Skip("running inside a pod; in-cluster config would succeed")
+	}
+	dir := t.TempDir()
+	oldKubeconfig, oldInCluster := kubeconfig, inCluster
+	t.Cleanup(func() { kubeconfig, inCluster = oldKubeconfig, oldInCluster })
+	kubeconfig, inCluster = "", false
+
+	// A kubeconfig wins even inside a pod.
+	t.Setenv("KUBERNETES_SERVICE_HOST", "10.0.0.1")
+	t.Setenv("KUBERNETES_SERVICE_PORT", "443")
+	t.Setenv("KUBECONFIG", writeKubeconfig(t, dir, "alpha", "https://alpha.example.com", true))
+	cfg, fromCluster, err := loadRESTConfig()
+	if err != nil {
+		t.Fatalf("loadRESTConfig: %v", err)
+	}
+	if fromCluster || cfg.Host != "https://alpha.example.com" {
+		t.Errorf("got host %q fromCluster=%v, want kubeconfig host", cfg.Host, fromCluster)
+	}
+
+	// Without a kubeconfig the pod's service account is tried. The token
+	// is absent in tests, so the in-cluster error is surfaced.
+	t.Setenv("KUBECONFIG", filepath.Join(dir, "missing"))
+	t.Setenv("HOME", dir)
+	if _, _, err := loadRESTConfig(); err == nil || !strings.Contains(err.Error(), "in-cluster") {
+		t.Errorf("err = %v, want in-cluster config error", err)
+	}
+
+	// Outside a pod the kubeconfig error is reported instead.
+	t.Setenv("KUBERNETES_SERVICE_HOST", "")
+	if _, _, err := loadRESTConfig(); err == nil || !strings.Contains(err.Error(), "kubeconfig") {
+		t.Errorf("err = %v, want kubeconfig error", err)
+	}
+
+	// --in-cluster skips the kubeconfig entirely.
+	t.Setenv("KUBECONFIG", writeKubeconfig(t, dir, "beta", "https://beta.example.com", true))
+	inCluster = true
+	if _, _, err := loadRESTConfig(); err == nil || !strings.Contains(err.Error(), "in-cluster") {
+		t.Errorf("--in-cluster: err = %v, want in-cluster config error", err)
+	}
+}
+
+func TestImpersonation(t *testing.T) {
+	headers := make(chan http.Header, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers <- r.Header
+	}))
+	defer srv.Close()
+
+	oldKubeconfig, oldUser, oldGroups := kubeconfig, impersonateUser, impersonateGroups
+	t.Cleanup(func() { kubeconfig, impersonateUser, impersonateGroups = oldKubeconfig, oldUser, oldGroups })
+	kubeconfig = writeKubeconfig(t, t.TempDir(), "alpha", srv.URL, true)
+
+	impersonateUser, impersonateGroups = "", []string{"team-a"}
+	if err := validateImpersonation(); err == nil || classifyError(err).Code != errInvalidArgument {
+		t.Errorf("--as-group without --as: err = %v, want InvalidArgument", err)
+	}
+
+	impersonateUser, impersonateGroups = "jane", []string{"team-a", "team-b"}
+	if err := validateImpersonation(); err != nil {
+		t.Fatal(err)
+	}
+	cfg, _, err := loadRESTConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	applyImpersonation(cfg)
+	hc, err := rest.HTTPClientFor(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := hc.Get(srv.URL + "/api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	h := <-headers
+	if got, groups := h.Get("Impersonate-User"), h.Values("Impersonate-Group"); got != "jane" || strings.Join(groups, ",") != "team-a,team-b" {
+		t.Errorf("Impersonate-User = %q, Impersonate-Group = %v", got, groups)
+	}
+
+	impersonateUser, impersonateGroups = "", nil
+	cfg.Impersonate = rest.ImpersonationConfig{UserName: "from-kubeconfig"}
+	if applyImpersonation(cfg); cfg.Impersonate.UserName != "from-kubeconfig" {
+		t.Errorf("without --as the kubeconfig's impersonation should be kept, got %+v", cfg.Impersonate)
+	}
+}
+
+func TestRewriteInstallNamespace(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "namespaced object",
+			in: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: sympozium-controller-manager
+  namespace: sympozium-system
+`,
+			want: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: sympozium-controller-manager
+  namespace: sym-prod
+`,
+		},
+		{
+			name: "webhook client config",
+			in: `kind: ValidatingWebhookConfiguration
+metadata:
+  annotations:
+    cert-manager.io/inject-ca-from: sympozium-system/sympozium-webhook-cert
+webhooks:
+  - clientConfig:
+      service:
+        name: sympozium-webhook-service
+        namespace: sympozium-system
+`,
+			want: `kind: ValidatingWebhookConfiguration
+metadata:
+  annotations:
+    cert-manager.io/inject-ca-from: sym-prod/sympozium-webhook-cert
+webhooks:
+  - clientConfig:
+      service:
+        name: sympozium-webhook-service
+        namespace: sym-prod
+`,
+		},
+		{
+			name: "RBAC subjects",
+			in: `kind: ClusterRoleBinding
+subjects:
+  - kind: ServiceAccount
+    name: sympozium-controller-manager
+    namespace: sympozium-system
+  - kind: ServiceAccount
+    name: sympozium-apiserver
+    namespace: sympozium-system
+`,
+			want: `kind: ClusterRoleBinding
+subjects:
+  - kind: ServiceAccount
+    name: sympozium-controller-manager
+    namespace: sym-prod
+  - kind: ServiceAccount
+    name: sympozium-apiserver
+    namespace: sym-prod
+`,
+		},
+		{
+			name: "certificate DNS names",
+			in: `kind: Certificate
+spec:
+  dnsNames:
+    - sympozium-webhook-service.sympozium-system
+    - sympozium-webhook-service.sympozium-system.svc
+`,
+			want: `kind: Certificate
+spec:
+  dnsNames:
+    - sympozium-webhook-service.sym-prod
+    - sympozium-webhook-service.sym-prod.svc
+`,
+		},
+		{
+			name: "cluster-scoped object",
+			in: `kind: ClusterRole
+metadata:
+  name: sympozium-manager-role
+`,
+			want: `kind: ClusterRole
+metadata:
+  name: sympozium-manager-role
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "manifest.yaml")
+			if err := os.WriteFile(path, []byte(tt.in), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			other := filepath.Join(dir, "README.md")
+			if err := os.WriteFile(other, []byte("namespace: sympozium-system\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if err := rewriteInstallNamespace(dir, "sym-prod"); err != nil {
+				t.Fatal(err)
+			}
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("rewritten manifest:\n%s\nwant:\n%s", got, tt.want)
+			}
+			if data, _ := os.ReadFile(other); string(data) != "namespace: sympozium-system\n" {
+				t.Errorf("non-YAML file rewritten: %q", data)
+			}
+		})
+	}
+}
+
+func TestInstallMarkerManifest(t *testing.T) {
+	var cm corev1.ConfigMap
+	if err := yaml.UnmarshalStrict([]byte(installMarkerManifest("sym-prod", "v0.1.0")), &cm); err != nil {
+		t.Fatal(err)
+	}
+	if cm.Kind != "ConfigMap" || cm.Name != installMarkerName || cm.Namespace != "sym-prod" {
+		t.Errorf("marker = %s %s/%s", cm.Kind, cm.Namespace, cm.Name)
+	}
+	if cm.Labels[installMarkerLabel] != "true" {
+		t.Errorf("labels = %v, want %s=true", cm.Labels, installMarkerLabel)
+	}
+	if cm.Data["namespace"] != "sym-prod" || cm.Data["version"] != "v0.1.0" {
+		t.Errorf("data = %v", cm.Data)
+	}
+}
+
+// stubKubectlOutput replaces kubectlOutput with one answering the install
+// marker lookups from markers, a map of namespace to recorded version.
+func stubKubectlOutput(t *testing.T, markers map[string]string) *[]string {
+	t.Helper()
+	var calls []string
+	old := kubectlOutput
+	kubectlOutput = func(args ...string) ([]byte, error) {
+		calls = append(calls, strings.Join(args, " "))
+		switch {
+		case args[0] == "get" && args[1] == "configmaps":
+			var out strings.Builder
+			for ns := range markers {
+				fmt.Fprintln(&out, ns)
+			}
+			return []byte(out.String()), nil
+		case args[0] == "get" && args[1] == "configmap" && args[2] == installMarkerName:
+			if ver, ok := markers[args[4]]; ok {
+				return []byte(ver), nil
+			}
+		}
+		return nil, fmt.Errorf("unexpected kubectl %s", strings.Join(args, " "))
+	}
+	t.Cleanup(func() { kubectlOutput = old })
+	return &calls
+}
+
+func TestResolveInstallNamespace(t *testing.T) {
+	stubKubectlOutput(t, nil)
+	if ns, err := resolveInstallNamespace("--install-namespace"); err != nil || ns != defaultInstallNamespace {
+		t.Errorf("no marker: %q, %v; want %s", ns, err, defaultInstallNamespace)
+	}
+
+	stubKubectlOutput(t, map[string]string{"sym-prod": "v0.1.0"})
+	if ns, err := resolveInstallNamespace("--install-namespace"); err != nil || ns != "sym-prod" {
+		t.Errorf("one marker: %q, %v; want sym-prod", ns, err)
+	}
+	if ver := installMarkerValue("sym-prod", "version"); ver != "v0.1.0" {
+		t.Errorf("recorded version = %q, want v0.1.0", ver)
+	}
+
+	stubKubectlOutput(t, map[string]string{"sym-b": "v0.1.0", "sym-a": "v0.2.0"})
+	_, err := resolveInstallNamespace("--install-namespace")
+	if err == nil || !strings.Contains(err.Error(), "(sym-a, sym-b)") || !strings.Contains(err.Error(), "--install-namespace") {
+		t.Errorf("several markers: err = %v", err)
+	}
+
+	old := kubectlOutput
+	kubectlOutput = func(...string) ([]byte, error) { return nil, fmt.Errorf("no cluster") }
+	t.Cleanup(func() { kubectlOutput = old })
+	if ns, err := resolveInstallNamespace("--install-namespace"); err != nil || ns != defaultInstallNamespace {
+		t.Errorf("kubectl failure: %q, %v; want %s", ns, err, defaultInstallNamespace)
+	}
+}
+
+// Uninstall and status must resolve the recorded namespace before touching
+// the cluster, and stop when the markers are ambiguous.
+func TestInstallNamespaceCommands(t *testing.T) {
+	for _, newCmd := range []func() *cobra.Command{newUninstallCmd, newStatusCmd} {
+		cmd := newCmd()
+		t.Run(cmd.Name(), func(t *testing.T) {
+			calls := stubKubectlOutput(t, map[string]string{"sym-a": "v0.1.0", "sym-b": "v0.1.0"})
+			cmd.SetArgs([]string{})
+			cmd.SetOut(io.Discard)
+			cmd.SetErr(io.Discard)
+			err := cmd.Execute()
+			if err == nil || !strings.Contains(err.Error(), "sym-a, sym-b") {
+				t.Fatalf("err = %v, want the ambiguous namespaces", err)
+			}
+			if len(*calls) != 1 || !strings.Contains((*calls)[0], installMarkerLabel) {
+				t.Errorf("kubectl calls = %q, want only the marker lookup", *calls)
+			}
+		})
+	}
+}
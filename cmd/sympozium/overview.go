@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	sympoziumv1alpha1 "github.com/alexsjones/sympozium/api/v1alpha1"
+)
+
+// ── overview ────────────────────────────────────────────────────────────────
+
+func newOverviewCmd() *cobra.Command {
+	var allNamespaces bool
+	cmd := &cobra.Command{
+		Use:   "overview",
+		Short: "Summarize instances, runs, policies and skills",
+		Long: `Print a one-shot summary of the Sympozium resources in --namespace, or in
+every namespace with -A: instances and runs by phase, the number of
+policies and SkillPacks, and the agent pods currently running.`,
+		Example: `  sympozium overview
+  sympozium overview -A`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ns, scope := namespace, "Namespace: "+namespace
+			if allNamespaces {
+				ns, scope = "", "All namespaces"
+			}
+			o, err := collectOverview(context.Background(), k8sClient, ns)
+			if err != nil {
+				return err
+			}
+			return printOverview(cmd.OutOrStdout(), scope, o)
+		},
+	}
+	cmd.Flags().BoolVarP(&allNamespaces, "all-namespaces", "A", false, "Summarize every namespace")
+	return cmd
+}
+
+// clusterOverview holds the counts shown by the overview command.
+type clusterOverview struct {
+	InstancePhases  map[string]int
+	RunPhases       map[string]int
+	Instances       int
+	Runs            int
+	Policies        int
+	SkillPacks      int
+	ActiveAgentPods int
+}
+
+// collectOverview lists the Sympozium resources in ns, or in every
+// namespace when ns is empty, and counts them. Objects without a phase
+// yet are counted as Unknown.
+func collectOverview(ctx context.Context, c client.Reader, ns string) (clusterOverview, error) {
+	o := clusterOverview{InstancePhases: map[string]int{}, RunPhases: map[string]int{}}
+	var opts []client.ListOption
+	if ns != "" {
+		opts = append(opts, client.InNamespace(ns))
+	}
+
+	var instances sympoziumv1alpha1.SympoziumInstanceList
+	if err := c.List(ctx, &instances, opts...); err != nil {
+		return o, fmt.Errorf("listing instances: %w", err)
+	}
+	for _, inst := range instances.Items {
+		o.InstancePhases[phaseOrUnknown(inst.Status.Phase)]++
+		o.ActiveAgentPods += inst.Status.ActiveAgentPods
+	}
+	o.Instances = len(instances.Items)
+
+	var runs sympoziumv1alpha1.AgentRunList
+	if err := c.List(ctx, &runs, opts...); err != nil {
+		return o, fmt.Errorf("listing runs: %w", err)
+	}
+	for _, run := range runs.Items {
+		o.RunPhases[phaseOrUnknown(string(run.Status.Phase))]++
+	}
+	o.Runs = len(runs.Items)
+
+	var policies sympoziumv1alpha1.SympoziumPolicyList
+	if err := c.List(ctx, &policies, opts...); err != nil {
+		return o, fmt.Errorf("listing policies: %w", err)
+	}
+	o.Policies = len(policies.Items)
+
+	var skills sympoziumv1alpha1.SkillPackList
+	if err := c.List(ctx, &skills, opts...); err != nil {
+		return o, fmt.Errorf("listing skills: %w", err)
+	}
+	o.SkillPacks = len(skills.Items)
+	return o, nil
+}
+
+func phaseOrUnknown(phase string) string {
+	if phase == "" {
+		return "Unknown"
+	}
+	return phase
+}
+
+// printOverview writes the summary as an aligned table under a scope line.
+func printOverview(out io.Writer, scope string, o clusterOverview) error {
+	fmt.Fprintln(out, scope)
+	fmt.Fprintln(out)
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Instances\t%d\t%s\n", o.Instances, formatPhaseCounts(o.InstancePhases))
+	fmt.Fprintf(w, "Runs\t%d\t%s\n", o.Runs, formatPhaseCounts(o.RunPhases))
+	fmt.Fprintf(w, "Policies\t%d\t\n", o.Policies)
+	fmt.Fprintf(w, "SkillPacks\t%d\t\n", o.SkillPacks)
+	fmt.Fprintf(w, "Active agent pods\t%d\t\n", o.ActiveAgentPods)
+	return w.Flush()
+}
+
+// formatPhaseCounts renders counts as "Running 2, Failed 1", most common
+// phase first.
+func formatPhaseCounts(counts map[string]int) string {
+	phases := make([]string, 0, len(counts))
+	for p := range counts {
+		phases = append(phases, p)
+	}
+	sort.Slice(phases, func(i, j int) bool {
+		if counts[phases[i]] != counts[phases[j]] {
+			return counts[phases[i]] > counts[phases[j]]
+		}
+		return phases[i] < phases[j]
+	})
+	parts := make([]string, len(phases))
+	for i, p := range phases {
+		parts[i] = fmt.Sprintf("%s %d", p, counts[p])
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sympoziumv1alpha1 "github.com/alexsjones/sympozium/api/v1alpha1"
+)
+
+func overviewTestObjects() []client.Object {
+	inst := func(ns, name, phase string, pods int) *sympoziumv1alpha1.SympoziumInstance {
+		return &sympoziumv1alpha1.SympoziumInstance{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns},
+			Status:     sympoziumv1alpha1.SympoziumInstanceStatus{Phase: phase, ActiveAgentPods: pods},
+		}
+	}
+	run := func(ns, name string, phase sympoziumv1alpha1.AgentRunPhase) *sympoziumv1alpha1.AgentRun {
+		return &sympoziumv1alpha1.AgentRun{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns},
+			Status:     sympoziumv1alpha1.AgentRunStatus{Phase: phase},
+		}
+	}
+	return []client.Object{
+		inst("default", "a", "Running", 2),
+		inst("default", "b", "", 0),
+		inst("other", "c", "Running", 1),
+		run("default", "r1", sympoziumv1alpha1.AgentRunPhaseSucceeded),
+		run("default", "r2", sympoziumv1alpha1.AgentRunPhaseSucceeded),
+		run("default", "r3", sympoziumv1alpha1.AgentRunPhaseFailed),
+		run("other", "r4", sympoziumv1alpha1.AgentRunPhaseRunning),
+		&sympoziumv1alpha1.SympoziumPolicy{ObjectMeta: metav1.ObjectMeta{Name: "p", Namespace: "default"}},
+		&sympoziumv1alpha1.SkillPack{ObjectMeta: metav1.ObjectMeta{Name: "s1", Namespace: "default"}},
+		&sympoziumv1alpha1.SkillPack{ObjectMeta: metav1.ObjectMeta{Name: "s2", Namespace: "other"}},
+	}
+}
+
+func TestCollectOverview(t *testing.T) {
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(overviewTestObjects()...).
+		WithStatusSubresource(&sympoziumv1alpha1.SympoziumInstance{}, &sympoziumv1alpha1.AgentRun{}).Build()
+
+	o, err := collectOverview(context.Background(), c, "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.Instances != 2 || o.Runs != 3 || o.Policies != 1 || o.SkillPacks != 1 || o.ActiveAgentPods != 2 {
+		t.Errorf("default overview = %+v", o)
+	}
+	if got := formatPhaseCounts(o.InstancePhases); got != "Running 1, Unknown 1" {
+		t.Errorf("instance phases = %q", got)
+	}
+	if got := formatPhaseCounts(o.RunPhases); got != "Succeeded 2, Failed 1" {
+		t.Errorf("run phases = %q", got)
+	}
+
+	all, err := collectOverview(context.Background(), c, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if all.Instances != 3 || all.Runs != 4 || all.SkillPacks != 2 || all.ActiveAgentPods != 3 {
+		t.Errorf("all-namespaces overview = %+v", all)
+	}
+}
+
+func TestPrintOverview(t *testing.T) {
+	var out bytes.Buffer
+	err := printOverview(&out, "All namespaces", clusterOverview{
+		Instances: 3, InstancePhases: map[string]int{"Running": 3},
+		Runs: 4, RunPhases: map[string]int{"Succeeded": 3, "Failed": 1},
+		Policies: 1, SkillPacks: 2, ActiveAgentPods: 3,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"All namespaces\n\n",
+		"Instances          3  Running 3\n",
+		"Runs               4  Succeeded 3, Failed 1\n",
+		"Active agent pods  3",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// defaultPageLimit is the default --page-limit, matching kubectl's
+// --chunk-size.
+const defaultPageLimit = 500
+
+// addPageLimitFlag registers --page-limit on a list command.
+func addPageLimitFlag(cmd *cobra.Command, limit *int64) {
+	cmd.Flags().Int64Var(limit, "page-limit", defaultPageLimit,
+		"Fetch at most this many objects per API request; 0 fetches everything at once")
+}
+
+// listPages lists objects one page of at most limit at a time, following
+// continue tokens, and calls page with each page as it arrives. Every page
+// is decoded into a fresh list. A limit of 0 lists everything in a single
+// request.
+func listPages[L any, PL interface {
+	*L
+	client.ObjectList
+}](ctx context.Context, c client.Reader, limit int64, page func(PL) error, opts ...client.ListOption) error {
+	if limit < 0 {
+		return fmt.Errorf("--page-limit must not be negative")
+	}
+	var cont string
+	for {
+		list := PL(new(L))
+		pageOpts := append(opts[:len(opts):len(opts)], client.Continue(cont))
+		if limit > 0 {
+			pageOpts = append(pageOpts, client.Limit(limit))
+		}
+		if err := c.List(ctx, list, pageOpts...); err != nil {
+			return err
+		}
+		if err := page(list); err != nil {
+			return err
+		}
+		if cont = list.GetContinue(); cont == "" {
+			return nil
+		}
+	}
+}
+
+// tableStream prints a table in batches as pages arrive: the header once,
+// then each batch's rows, flushed so they are shown straight away. Columns
+// are aligned within a batch.
+type tableStream[T any] struct {
+	tw      *tabwriter.Writer
+	columns []tableColumn[T]
+	wide    bool
+	started bool
+}
+
+func newTableStream[T any](w io.Writer, columns []tableColumn[T], wide bool) *tableStream[T] {
+	return &tableStream[T]{tw: tabwriter.NewWriter(w, 0, 4, 2, ' ', 0), columns: columns, wide: wide}
+}
+
+// write prints items, preceded by the header on the first call.
+func (s *tableStream[T]) write(items []T) error {
+	if !s.started {
+		var headers []string
+		for _, c := range s.columns {
+			if c.wide && !s.wide {
+				continue
+			}
+			headers = append(headers, c.header)
+		}
+		fmt.Fprintln(s.tw, strings.Join(headers, "\t"))
+		s.started = true
+	}
+	for i := range items {
+		var cells []string
+		for _, c := range s.columns {
+			if c.wide && !s.wide {
+				continue
+			}
+			cells = append(cells, c.value(&items[i], s.wide))
+		}
+		fmt.Fprintln(s.tw, strings.Join(cells, "\t"))
+	}
+	return s.tw.Flush()
+}
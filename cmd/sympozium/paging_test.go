@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	sympoziumv1alpha1 "github.com/alexsjones/sympozium/api/v1alpha1"
+)
+
+// pagingClient builds a fake client holding n runs whose List honours Limit
+// and Continue like the API server; the continue token is the offset. The
+// limits of every request are recorded in limits.
+func pagingClient(t *testing.T, n int, limits *[]int64) client.Client {
+	var objs []client.Object
+	for i := 0; i < n; i++ {
+		objs = append(objs, &sympoziumv1alpha1.AgentRun{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("run-%d", i), Namespace: "default"}})
+	}
+	return fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(objs...).WithInterceptorFuncs(interceptor.Funcs{
+		List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+			o := (&client.ListOptions{}).ApplyOptions(opts)
+			*limits = append(*limits, o.Limit)
+			if err := c.List(ctx, list, client.InNamespace(o.Namespace)); err != nil {
+				return err
+			}
+			runs := list.(*sympoziumv1alpha1.AgentRunList)
+			start, _ := strconv.Atoi(o.Continue)
+			end := len(runs.Items)
+			if o.Limit > 0 && start+int(o.Limit) < end {
+				end = start + int(o.Limit)
+				runs.Continue = strconv.Itoa(end)
+			}
+			runs.Items = runs.Items[start:end]
+			return nil
+		},
+	}).Build()
+}
+
+func TestListPages(t *testing.T) {
+	var limits []int64
+	c := pagingClient(t, 5, &limits)
+
+	var sizes []int
+	var names []string
+	err := listPages(context.Background(), c, 2, func(page *sympoziumv1alpha1.AgentRunList) error {
+		sizes = append(sizes, len(page.Items))
+		for _, r := range page.Items {
+			names = append(names, r.Name)
+		}
+		return nil
+	}, client.InNamespace("default"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(sizes) != "[2 2 1]" || fmt.Sprint(limits) != "[2 2 2]" {
+		t.Errorf("page sizes = %v, limits = %v", sizes, limits)
+	}
+	if len(names) != 5 || names[0] != "run-0" || names[4] != "run-4" {
+		t.Errorf("names = %v", names)
+	}
+
+	limits = nil
+	sizes = nil
+	err = listPages(context.Background(), c, 0, func(page *sympoziumv1alpha1.AgentRunList) error {
+		sizes = append(sizes, len(page.Items))
+		return nil
+	}, client.InNamespace("default"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(sizes) != "[5]" || fmt.Sprint(limits) != "[0]" {
+		t.Errorf("unlimited: page sizes = %v, limits = %v", sizes, limits)
+	}
+
+	if err := listPages(context.Background(), c, -1, func(*sympoziumv1alpha1.AgentRunList) error { return nil }); err == nil {
+		t.Error("negative limit accepted")
+	}
+}
+
+func TestTableStream(t *testing.T) {
+	columns := []tableColumn[string]{
+		{header: "NAME", value: func(s *string, _ bool) string { return *s }},
+		{header: "DETAIL", wide: true, value: func(s *string, _ bool) string { return "x" }},
+	}
+	var out bytes.Buffer
+	table := newTableStream(&out, columns, false)
+	for _, page := range [][]string{{"a", "b"}, {"c"}, nil} {
+		if err := table.write(page); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if want := "NAME\na\nb\nc\n"; out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+
+	out.Reset()
+	if err := newTableStream(&out, columns, true).write(nil); err != nil {
+		t.Fatal(err)
+	}
+	if want := "NAME  DETAIL\n"; out.String() != want {
+		t.Errorf("empty wide table = %q, want %q", out.String(), want)
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	sympoziumv1alpha1 "github.com/alexsjones/sympozium/api/v1alpha1"
+)
+
+// ── policies describe ───────────────────────────────────────────────────────
+
+func newPoliciesDescribeCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "describe <name>",
+		Short: "Show a policy's feature gates and the instances bound to it",
+		Long: `Show a SympoziumPolicy's feature gates and the SympoziumInstances that
+reference it through spec.policyRef, with their current phase: everything a
+change to the policy affects.`,
+		Example: `  sympozium policies describe default-policy`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			d, err := describePolicy(context.Background(), k8sClient, namespace, args[0])
+			if err != nil {
+				return err
+			}
+			return printPolicyDescription(cmd.OutOrStdout(), d)
+		},
+	}
+}
+
+// policyDescription is what policies describe prints.
+type policyDescription struct {
+	Policy    sympoziumv1alpha1.SympoziumPolicy
+	Instances []sympoziumv1alpha1.SympoziumInstance
+}
+
+// describePolicy fetches the policy name in ns and the instances in ns whose
+// policyRef names it, sorted by name.
+func describePolicy(ctx context.Context, c client.Reader, ns, name string) (policyDescription, error) {
+	var d policyDescription
+	if err := c.Get(ctx, types.NamespacedName{Name: name, Namespace: ns}, &d.Policy); err != nil {
+		return d, err
+	}
+	var instances sympoziumv1alpha1.SympoziumInstanceList
+	if err := c.List(ctx, &instances, client.InNamespace(ns)); err != nil {
+		return d, fmt.Errorf("listing instances: %w", err)
+	}
+	for _, inst := range instances.Items {
+		if inst.Spec.PolicyRef == name {
+			d.Instances = append(d.Instances, inst)
+		}
+	}
+	sort.Slice(d.Instances, func(i, j int) bool { return d.Instances[i].Name < d.Instances[j].Name })
+	return d, nil
+}
+
+func printPolicyDescription(out io.Writer, d policyDescription) error {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "Name:\t%s\n", d.Policy.Name)
+	fmt.Fprintf(w, "Namespace:\t%s\n", d.Policy.Namespace)
+	fmt.Fprintf(w, "Bound instances:\t%d\n", d.Policy.Status.BoundInstances)
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(out, "\nFeature gates:")
+	gates := make([]string, 0, len(d.Policy.Spec.FeatureGates))
+	for g := range d.Policy.Spec.FeatureGates {
+		gates = append(gates, g)
+	}
+	sort.Strings(gates)
+	if len(gates) == 0 {
+		fmt.Fprintln(out, "  <none>")
+	}
+	for _, g := range gates {
+		state := "off"
+		if d.Policy.Spec.FeatureGates[g] {
+			state = "on"
+		}
+		fmt.Fprintf(w, "  %s\t%s\n", g, state)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(out, "\nInstances:")
+	if len(d.Instances) == 0 {
+		fmt.Fprintln(out, "  <none>")
+		return nil
+	}
+	fmt.Fprintln(w, "  NAME\tPHASE")
+	for _, inst := range d.Instances {
+		fmt.Fprintf(w, "  %s\t%s\n", inst.Name, phaseOrUnknown(inst.Status.Phase))
+	}
+	return w.Flush()
+}
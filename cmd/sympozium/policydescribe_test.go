@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sympoziumv1alpha1 "github.com/alexsjones/sympozium/api/v1alpha1"
+)
+
+func TestDescribePolicy(t *testing.T) {
+	inst := func(name, policy, phase string) *sympoziumv1alpha1.SympoziumInstance {
+		return &sympoziumv1alpha1.SympoziumInstance{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       sympoziumv1alpha1.SympoziumInstanceSpec{PolicyRef: policy},
+			Status:     sympoziumv1alpha1.SympoziumInstanceStatus{Phase: phase},
+		}
+	}
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(
+		inst("bravo", "strict", "Running"),
+		inst("alpha", "strict", ""),
+		inst("charlie", "other", "Running"),
+		&sympoziumv1alpha1.SympoziumPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "strict", Namespace: "default"},
+			Spec: sympoziumv1alpha1.SympoziumPolicySpec{
+				FeatureGates: map[string]bool{"gpu-access": true, "code-execution": false},
+			},
+			Status: sympoziumv1alpha1.SympoziumPolicyStatus{BoundInstances: 2},
+		},
+		&sympoziumv1alpha1.SympoziumPolicy{ObjectMeta: metav1.ObjectMeta{Name: "empty", Namespace: "default"}},
+	).WithStatusSubresource(&sympoziumv1alpha1.SympoziumInstance{}, &sympoziumv1alpha1.SympoziumPolicy{}).Build()
+
+	d, err := describePolicy(context.Background(), c, "default", "strict")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := printPolicyDescription(&out, d); err != nil {
+		t.Fatal(err)
+	}
+	want := `Name:             strict
+Namespace:        default
+Bound instances:  2
+
+Feature gates:
+  code-execution  off
+  gpu-access      on
+
+Instances:
+  NAME   PHASE
+  alpha  Unknown
+  bravo  Running
+`
+	if out.String() != want {
+		t.Errorf("output:\n%s\nwant:\n%s", out.String(), want)
+	}
+
+	d, err = describePolicy(context.Background(), c, "default", "empty")
+	if err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	if err := printPolicyDescription(&out, d); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(out.Bytes(), []byte("Feature gates:\n  <none>\n\nInstances:\n  <none>\n")) {
+		t.Errorf("empty policy output:\n%s", out.String())
+	}
+
+	if _, err := describePolicy(context.Background(), c, "default", "missing"); err == nil {
+		t.Error("missing policy did not fail")
+	}
+}
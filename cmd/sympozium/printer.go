@@ -0,0 +1,242 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/yaml"
+
+	sympoziumv1alpha1 "github.com/alexsjones/sympozium/api/v1alpha1"
+)
+
+// ── Shared output printing for get/list commands ────────────────────────────
+
+// outputFile is the global --output-file flag. When set, structured (-o json
+// or -o yaml) output is written there instead of stdout.
+var outputFile string
+
+// addOutputFlag registers -o/--output on cmd. formats lists the accepted
+// values; the empty string always selects the command's default.
+func addOutputFlag(cmd *cobra.Command, target *string, defaultFormat string, formats ...string) {
+	cmd.Flags().StringVarP(target, "output", "o", defaultFormat,
+		fmt.Sprintf("Output format (%s)", strings.Join(formats, "|")))
+}
+
+// validateOutput checks format against the accepted formats and that
+// --output-file is only combined with a structured format.
+func validateOutput(format string, formats ...string) error {
+	if format != "" {
+		ok := false
+		for _, f := range formats {
+			if f == format {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return fmt.Errorf("unsupported output format %q (supported: %s)", format, strings.Join(formats, ", "))
+		}
+	}
+	if outputFile != "" && !isStructuredOutput(format) {
+		return fmt.Errorf("--output-file requires -o json or -o yaml")
+	}
+	return nil
+}
+
+// isStructuredOutput reports whether format serializes whole objects rather
+// than printing a table.
+func isStructuredOutput(format string) bool {
+	return format == "json" || format == "yaml"
+}
+
+// printStructured serializes obj (a single object or a list) as JSON or YAML
+// to stdout, or to --output-file when set. apiVersion and kind are filled in
+// since typed clients leave them empty.
+func printStructured(format string, obj runtime.Object) error {
+	setTypeMeta(obj)
+	if meta.IsListType(obj) {
+		if err := meta.EachListItem(obj, func(item runtime.Object) error {
+			setTypeMeta(item)
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+
+	var (
+		data []byte
+		err  error
+	)
+	switch format {
+	case "yaml":
+		data, err = yaml.Marshal(obj)
+	default:
+		data, err = json.MarshalIndent(obj, "", "  ")
+		data = append(data, '\n')
+	}
+	if err != nil {
+		return fmt.Errorf("encode %s: %w", format, err)
+	}
+
+	if outputFile == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(outputFile, data, 0o644); err != nil {
+		return fmt.Errorf("write %s: %w", outputFile, err)
+	}
+	fmt.Fprintf(os.Stderr, "Wrote %s output to %s\n", format, outputFile)
+	return nil
+}
+
+// showManagedFields is the --show-managed-fields flag of the get commands.
+var showManagedFields bool
+
+// addShowManagedFieldsFlag registers --show-managed-fields on a get command.
+func addShowManagedFieldsFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&showManagedFields, "show-managed-fields", false,
+		"Include metadata.managedFields, e.g. to debug server-side apply ownership")
+}
+
+// stripManagedFields removes metadata.managedFields, which is rarely useful
+// and usually longer than the rest of the object, unless
+// --show-managed-fields is set.
+func stripManagedFields(obj metav1.Object) {
+	if !showManagedFields {
+		obj.SetManagedFields(nil)
+	}
+}
+
+// printScheme is the CLI scheme setTypeMeta looks types up in, built once
+// rather than for every printed object.
+var printScheme = sync.OnceValues(newCLIScheme)
+
+// setTypeMeta fills in apiVersion/kind from the CLI scheme. Objects whose
+// type is unknown are left as they are.
+func setTypeMeta(obj runtime.Object) {
+	scheme, err := printScheme()
+	if err != nil {
+		return
+	}
+	gvk, err := apiutil.GVKForObject(obj, scheme)
+	if err != nil {
+		return
+	}
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+}
+
+// ── Table columns ───────────────────────────────────────────────────────────
+
+// unknownValue is printed for wide columns whose data the object's status
+// does not (yet) carry.
+const unknownValue = "<unknown>"
+
+// tableColumn defines one column of a resource table. Wide columns are only
+// shown with -o wide; value receives the wide flag so a column can show more
+// detail there.
+type tableColumn[T any] struct {
+	header string
+	wide   bool
+	value  func(item *T, wide bool) string
+}
+
+// printTable writes items as an aligned table using the given columns.
+func printTable[T any](w io.Writer, columns []tableColumn[T], items []T, wide bool) error {
+	return newTableStream(w, columns, wide).write(items)
+}
+
+// ageColumn renders the time since an object's creation.
+func ageColumn[T any](created func(*T) time.Time) tableColumn[T] {
+	return tableColumn[T]{header: "AGE", value: func(item *T, _ bool) string {
+		return time.Since(created(item)).Round(time.Second).String()
+	}}
+}
+
+// orUnknown returns s, or unknownValue when s is empty.
+func orUnknown(s string) string {
+	if s == "" {
+		return unknownValue
+	}
+	return s
+}
+
+var instanceColumns = []tableColumn[sympoziumv1alpha1.SympoziumInstance]{
+	{header: "NAME", value: func(inst *sympoziumv1alpha1.SympoziumInstance, _ bool) string { return inst.Name }},
+	{header: "PHASE", value: func(inst *sympoziumv1alpha1.SympoziumInstance, _ bool) string { return inst.Status.Phase }},
+	{header: "CHANNELS", value: func(inst *sympoziumv1alpha1.SympoziumInstance, _ bool) string {
+		channels := make([]string, 0, len(inst.Status.Channels))
+		for _, ch := range inst.Status.Channels {
+			channels = append(channels, ch.Type)
+		}
+		return strings.Join(channels, ",")
+	}},
+	{header: "AGENT PODS", value: func(inst *sympoziumv1alpha1.SympoziumInstance, _ bool) string {
+		return fmt.Sprint(inst.Status.ActiveAgentPods)
+	}},
+	ageColumn(func(inst *sympoziumv1alpha1.SympoziumInstance) time.Time { return inst.CreationTimestamp.Time }),
+}
+
+// runMessageMaxLen caps the MESSAGE column in the default runs table.
+const runMessageMaxLen = 60
+
+var agentRunColumns = []tableColumn[sympoziumv1alpha1.AgentRun]{
+	{header: "NAME", value: func(run *sympoziumv1alpha1.AgentRun, _ bool) string { return run.Name }},
+	{header: "INSTANCE", value: func(run *sympoziumv1alpha1.AgentRun, _ bool) string { return run.Spec.InstanceRef }},
+	{header: "PHASE", value: func(run *sympoziumv1alpha1.AgentRun, _ bool) string { return string(run.Status.Phase) }},
+	{header: "POD", value: func(run *sympoziumv1alpha1.AgentRun, _ bool) string { return run.Status.PodName }},
+	{header: "MODEL", wide: true, value: func(run *sympoziumv1alpha1.AgentRun, _ bool) string { return orUnknown(run.Spec.Model.Model) }},
+	{header: "PROVIDER", wide: true, value: func(run *sympoziumv1alpha1.AgentRun, _ bool) string { return orUnknown(run.Spec.Model.Provider) }},
+	{header: "TOKENS", value: func(run *sympoziumv1alpha1.AgentRun, _ bool) string {
+		if run.Status.TokenUsage == nil {
+			return "-"
+		}
+		return fmt.Sprintf("%d/%d", run.Status.TokenUsage.InputTokens, run.Status.TokenUsage.OutputTokens)
+	}},
+	ageColumn(func(run *sympoziumv1alpha1.AgentRun) time.Time { return run.CreationTimestamp.Time }),
+	{header: "EXIT-REASON", wide: true, value: func(run *sympoziumv1alpha1.AgentRun, _ bool) string { return runExitReason(run) }},
+	{header: "MESSAGE", value: func(run *sympoziumv1alpha1.AgentRun, wide bool) string {
+		msg := runMessage(run)
+		if !wide {
+			msg = truncate(msg, runMessageMaxLen)
+		}
+		if msg == "" {
+			return "-"
+		}
+		return msg
+	}},
+}
+
+// runExitReason returns why a failed run ended: the errorCode the runner
+// classified in result.json, which the controller copies to status. Runs
+// that did not fail show "-".
+func runExitReason(run *sympoziumv1alpha1.AgentRun) string {
+	if run.Status.Phase != sympoziumv1alpha1.AgentRunPhaseFailed {
+		return "-"
+	}
+	return orUnknown(run.Status.ErrorCode)
+}
+
+var policyColumns = []tableColumn[sympoziumv1alpha1.SympoziumPolicy]{
+	{header: "NAME", value: func(pol *sympoziumv1alpha1.SympoziumPolicy, _ bool) string { return pol.Name }},
+	{header: "BOUND INSTANCES", value: func(pol *sympoziumv1alpha1.SympoziumPolicy, _ bool) string {
+		return fmt.Sprint(pol.Status.BoundInstances)
+	}},
+	ageColumn(func(pol *sympoziumv1alpha1.SympoziumPolicy) time.Time { return pol.CreationTimestamp.Time }),
+}
+
+var skillPackColumns = []tableColumn[sympoziumv1alpha1.SkillPack]{
+	{header: "NAME", value: func(sk *sympoziumv1alpha1.SkillPack, _ bool) string { return sk.Name }},
+	{header: "SKILLS", value: func(sk *sympoziumv1alpha1.SkillPack, _ bool) string { return fmt.Sprint(len(sk.Spec.Skills)) }},
+	{header: "CONFIGMAP", value: func(sk *sympoziumv1alpha1.SkillPack, _ bool) string { return sk.Status.ConfigMapName }},
+	ageColumn(func(sk *sympoziumv1alpha1.SkillPack) time.Time { return sk.CreationTimestamp.Time }),
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	sympoziumv1alpha1 "github.com/alexsjones/sympozium/api/v1alpha1"
+)
+
+func setOutputFile(t *testing.T, path string) {
+	t.Helper()
+	old := outputFile
+	outputFile = path
+	t.Cleanup(func() { outputFile = old })
+}
+
+func TestValidateOutput(t *testing.T) {
+	setOutputFile(t, "")
+	if err := validateOutput("", "wide", "json"); err != nil {
+		t.Errorf("default format rejected: %v", err)
+	}
+	if err := validateOutput("wide", "wide", "json"); err != nil {
+		t.Errorf("wide rejected: %v", err)
+	}
+	if err := validateOutput("table", "wide", "json"); err == nil {
+		t.Error("unknown format accepted")
+	}
+
+	setOutputFile(t, filepath.Join(t.TempDir(), "out.json"))
+	if err := validateOutput("", "json", "yaml"); err == nil || !strings.Contains(err.Error(), "--output-file") {
+		t.Errorf("--output-file with table output: err = %v", err)
+	}
+	if err := validateOutput("wide", "wide", "json"); err == nil {
+		t.Error("--output-file with wide output accepted")
+	}
+	if err := validateOutput("yaml", "json", "yaml"); err != nil {
+		t.Errorf("--output-file with yaml rejected: %v", err)
+	}
+}
+
+func TestPrintStructured_WritesOutputFile(t *testing.T) {
+	list := &sympoziumv1alpha1.AgentRunList{
+		Items: []sympoziumv1alpha1.AgentRun{
+			{ObjectMeta: metav1.ObjectMeta{Name: "run-1", Namespace: "default"}},
+		},
+	}
+
+	for _, format := range []string{"json", "yaml"} {
+		t.Run(format, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "runs."+format)
+			setOutputFile(t, path)
+			if err := printStructured(format, list); err != nil {
+				t.Fatalf("printStructured: %v", err)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("output file not written: %v", err)
+			}
+
+			var decoded struct {
+				APIVersion string `json:"apiVersion"`
+				Kind       string `json:"kind"`
+				Items      []struct {
+					Kind     string `json:"kind"`
+					Metadata struct {
+						Name string `json:"name"`
+					} `json:"metadata"`
+				} `json:"items"`
+			}
+			if format == "yaml" {
+				err = yaml.Unmarshal(data, &decoded)
+			} else {
+				err = json.Unmarshal(data, &decoded)
+			}
+			if err != nil {
+				t.Fatalf("decode %s: %v\n%s", format, err, data)
+			}
+			if decoded.Kind != "AgentRunList" || decoded.APIVersion != "sympozium.ai/v1alpha1" {
+				t.Errorf("type meta = %s %s, want sympozium.ai/v1alpha1 AgentRunList", decoded.APIVersion, decoded.Kind)
+			}
+			if len(decoded.Items) != 1 || decoded.Items[0].Kind != "AgentRun" || decoded.Items[0].Metadata.Name != "run-1" {
+				t.Errorf("items = %+v", decoded.Items)
+			}
+		})
+	}
+}
+
+func TestPrintTable_AgentRunColumns(t *testing.T) {
+	runs := []sympoziumv1alpha1.AgentRun{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "done", CreationTimestamp: metav1.Now()},
+			Spec: sympoziumv1alpha1.AgentRunSpec{
+				InstanceRef: "alpha",
+				Model:       sympoziumv1alpha1.ModelSpec{Provider: "anthropic", Model: "claude-sonnet-4"},
+			},
+			Status: sympoziumv1alpha1.AgentRunStatus{
+				Phase:      sympoziumv1alpha1.AgentRunPhaseFailed,
+				Error:      strings.Repeat("x", runMessageMaxLen+20),
+				TokenUsage: &sympoziumv1alpha1.TokenUsage{InputTokens: 12, OutputTokens: 34},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "pending", CreationTimestamp: metav1.Now()},
+			Status:     sympoziumv1alpha1.AgentRunStatus{Phase: sympoziumv1alpha1.AgentRunPhasePending},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "limited", CreationTimestamp: metav1.Now()},
+			Spec: sympoziumv1alpha1.AgentRunSpec{
+				Model: sympoziumv1alpha1.ModelSpec{Provider: "openai", Model: "gpt-4o"},
+			},
+			Status: sympoziumv1alpha1.AgentRunStatus{
+				Phase:      sympoziumv1alpha1.AgentRunPhaseFailed,
+				Error:      "Job failed",
+				ErrorCode:  "rate_limit_error",
+				Conditions: []metav1.Condition{{Type: "Failed", Reason: "JobFailed", LastTransitionTime: metav1.Now()}},
+			},
+		},
+	}
+
+	var narrow strings.Builder
+	if err := printTable(&narrow, agentRunColumns, runs, false); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(narrow.String()), "\n")
+	if got := strings.Fields(lines[0]); strings.Join(got, " ") != "NAME INSTANCE PHASE POD TOKENS AGE MESSAGE" {
+		t.Errorf("default header = %v", got)
+	}
+	if strings.Contains(narrow.String(), strings.Repeat("x", runMessageMaxLen+20)) {
+		t.Error("default table did not truncate the message")
+	}
+
+	var wide strings.Builder
+	if err := printTable(&wide, agentRunColumns, runs, true); err != nil {
+		t.Fatal(err)
+	}
+	lines = strings.Split(strings.TrimSpace(wide.String()), "\n")
+	if got := strings.Fields(lines[0]); strings.Join(got, " ") != "NAME INSTANCE PHASE POD MODEL PROVIDER TOKENS AGE EXIT-REASON MESSAGE" {
+		t.Errorf("wide header = %v", got)
+	}
+	for _, want := range []string{"claude-sonnet-4", "anthropic", "12/34", unknownValue, strings.Repeat("x", runMessageMaxLen+20)} {
+		if !strings.Contains(lines[1], want) {
+			t.Errorf("wide row %q missing %q", lines[1], want)
+		}
+	}
+	if n := strings.Count(lines[2], unknownValue); n != 2 {
+		t.Errorf("pending run without model: %q has %d %s cells, want 2 (model, provider)", lines[2], n, unknownValue)
+	}
+	// EXIT-REASON is the runner's errorCode, not the condition reason.
+	if fields := strings.Fields(lines[3]); fields[len(fields)-3] != "rate_limit_error" || strings.Contains(lines[3], "JobFailed") || strings.Contains(lines[3], unknownValue) {
+		t.Errorf("failed run with an error code: %q", lines[3])
+	}
+}
+
+func TestStripManagedFields(t *testing.T) {
+	newRun := func() *sympoziumv1alpha1.AgentRun {
+		return &sympoziumv1alpha1.AgentRun{ObjectMeta: metav1.ObjectMeta{
+			Name:          "run-1",
+			ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "sympozium-cli", Operation: metav1.ManagedFieldsOperationApply}},
+		}}
+	}
+	old := showManagedFields
+	t.Cleanup(func() { showManagedFields = old })
+
+	showManagedFields = false
+	run := newRun()
+	stripManagedFields(run)
+	if run.ManagedFields != nil {
+		t.Errorf("managedFields kept by default: %+v", run.ManagedFields)
+	}
+
+	showManagedFields = true
+	run = newRun()
+	stripManagedFields(run)
+	if len(run.ManagedFields) != 1 {
+		t.Errorf("--show-managed-fields dropped managedFields")
+	}
+}
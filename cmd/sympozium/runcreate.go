@@ -0,0 +1,258 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	sympoziumv1alpha1 "github.com/alexsjones/sympozium/api/v1alpha1"
+)
+
+const (
+	defaultCreateConcurrency = 4
+	defaultCreateDelay       = 200 * time.Millisecond
+)
+
+func newRunsCreateCmd() *cobra.Command {
+	var (
+		instance    string
+		fromFile    string
+		concurrency int
+		delay       time.Duration
+	)
+	cmd := &cobra.Command{
+		Use:   "create --instance NAME [task | --from-file FILE]",
+		Short: "Create AgentRuns for an instance",
+		Long: `Create an AgentRun for an instance from a task given as an argument, or one
+run per line of --from-file (- reads stdin; blank lines and lines starting
+with # are skipped).
+
+Bulk creation uses a pool of --concurrency workers and waits --delay between
+creates, so fanning out a batch does not flood the API server or the model
+provider. Every line is attempted; failures are reported with their line
+number and make the command exit non-zero.
+
+A single created run is remembered as @last for the runs commands that take
+a run name; pass --no-record to leave @last unchanged.`,
+		Example: `  sympozium runs create --instance my-agent "summarise failing pods"
+  sympozium runs create --instance my-agent --no-record "check node pressure"
+  sympozium runs create --instance my-agent --from-file tasks.txt --concurrency 8
+  generate-tasks | sympozium runs create --instance my-agent --from-file -`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if instance == "" {
+				return fmt.Errorf("--instance is required")
+			}
+			if (fromFile == "") == (len(args) == 0) {
+				return fmt.Errorf("give either a task argument or --from-file")
+			}
+			if concurrency < 1 {
+				return fmt.Errorf("--concurrency must be at least 1")
+			}
+			if delay < 0 {
+				return fmt.Errorf("--delay must not be negative")
+			}
+			ctx := context.Background()
+			out := cmd.OutOrStdout()
+
+			if fromFile == "" {
+				run, err := createRunForInstance(ctx, k8sClient, namespace, instance, args[0], false)
+				if err != nil {
+					return err
+				}
+				// Best effort: failing to remember the run must not fail its creation.
+				_ = recordLastRun(namespace, run)
+				fmt.Fprintf(out, "agentrun.sympozium.ai/%s created\n", run)
+				return nil
+			}
+
+			var r io.Reader = cmd.InOrStdin()
+			if fromFile != "-" {
+				f, err := os.Open(fromFile)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				r = f
+			}
+			tasks, err := readTaskLines(r)
+			if err != nil {
+				return fmt.Errorf("reading %s: %w", fromFile, err)
+			}
+			if len(tasks) == 0 {
+				return fmt.Errorf("no tasks in %s", fromFile)
+			}
+			results := createRunsConcurrently(tasks, concurrency, delay, func(task string) (string, error) {
+				return createRunForInstance(ctx, k8sClient, namespace, instance, task, true)
+			})
+			return reportRunCreates(out, results)
+		},
+	}
+	cmd.Flags().StringVarP(&instance, "instance", "i", "", "SympoziumInstance to run the tasks on")
+	cmd.Flags().StringVarP(&fromFile, "from-file", "f", "", "Create one run per line of this file (- for stdin)")
+	cmd.Flags().IntVar(&concurrency, "concurrency", defaultCreateConcurrency, "Maximum number of runs created at the same time with --from-file")
+	cmd.Flags().DurationVar(&delay, "delay", defaultCreateDelay, "Minimum time between two creates with --from-file")
+	return cmd
+}
+
+// taskLine is a task read from --from-file with its 1-based line number.
+type taskLine struct {
+	line int
+	task string
+}
+
+// readTaskLines returns the non-blank, non-comment lines of r.
+func readTaskLines(r io.Reader) ([]taskLine, error) {
+	var tasks []taskLine
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for n := 1; sc.Scan(); n++ {
+		task := strings.TrimSpace(sc.Text())
+		if task == "" || strings.HasPrefix(task, "#") {
+			continue
+		}
+		tasks = append(tasks, taskLine{line: n, task: task})
+	}
+	return tasks, sc.Err()
+}
+
+// runCreateResult is the outcome of creating the run for one task line.
+type runCreateResult struct {
+	taskLine
+	run string
+	err error
+}
+
+// createRunsConcurrently calls create for every task using at most
+// concurrency workers, starting creates no closer together than delay. The
+// results are returned in task order.
+func createRunsConcurrently(tasks []taskLine, concurrency int, delay time.Duration, create func(task string) (string, error)) []runCreateResult {
+	results := make([]runCreateResult, len(tasks))
+	jobs := make(chan int)
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		next time.Time
+	)
+	// pace blocks until this worker may start a create.
+	pace := func() {
+		mu.Lock()
+		now := time.Now()
+		wait := next.Sub(now)
+		if wait < 0 {
+			wait = 0
+		}
+		next = now.Add(wait + delay)
+		mu.Unlock()
+		time.Sleep(wait)
+	}
+	for w := 0; w < min(concurrency, len(tasks)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				pace()
+				run, err := create(tasks[i].task)
+				results[i] = runCreateResult{taskLine: tasks[i], run: run, err: err}
+			}
+		}()
+	}
+	for i := range tasks {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
+// reportRunCreates prints one line per task and returns an error when any
+// create failed.
+func reportRunCreates(w io.Writer, results []runCreateResult) error {
+	failed := 0
+	for _, r := range results {
+		if r.err != nil {
+			failed++
+			fmt.Fprintf(w, "line %d: failed: %v\n", r.line, r.err)
+			continue
+		}
+		fmt.Fprintf(w, "line %d: agentrun.sympozium.ai/%s created\n", r.line, r.run)
+	}
+	fmt.Fprintf(w, "\nCreated %d of %d run(s).\n", len(results)-failed, len(results))
+	if failed > 0 {
+		return fmt.Errorf("%d of %d run(s) could not be created", failed, len(results))
+	}
+	return nil
+}
+
+// createRunForInstance creates an AgentRun for task on the named instance
+// and returns the run's name. With generateName the API server picks a
+// unique suffix, which bulk creation needs since several runs are created
+// within the same second.
+func createRunForInstance(ctx context.Context, c client.Client, ns, instance, task string, generateName bool) (string, error) {
+	var inst sympoziumv1alpha1.SympoziumInstance
+	if err := c.Get(ctx, types.NamespacedName{Name: instance, Namespace: ns}, &inst); err != nil {
+		return "", fmt.Errorf("instance %q not found: %w", instance, err)
+	}
+	run, err := newAgentRun(&inst, task)
+	if err != nil {
+		return "", err
+	}
+	if generateName {
+		run.GenerateName = instance + "-run-"
+	} else {
+		run.Name = fmt.Sprintf("%s-run-%d", instance, time.Now().Unix())
+	}
+	if err := c.Create(ctx, run); err != nil {
+		return "", fmt.Errorf("create run: %w", explainAdmissionError(err))
+	}
+	return run.Name, nil
+}
+
+// newAgentRun builds an unnamed AgentRun for task that uses the instance's
+// default model and skills. The first AuthRef provides the provider and API
+// key secret.
+func newAgentRun(inst *sympoziumv1alpha1.SympoziumInstance, task string) (*sympoziumv1alpha1.AgentRun, error) {
+	authSecret := ""
+	provider := "openai"
+	if len(inst.Spec.AuthRefs) > 0 {
+		authSecret = inst.Spec.AuthRefs[0].Secret
+		if inst.Spec.AuthRefs[0].Provider != "" {
+			provider = inst.Spec.AuthRefs[0].Provider
+		}
+	}
+	if authSecret == "" {
+		return nil, fmt.Errorf("instance %q has no API key configured (authRefs is empty) — "+
+			"activate the persona pack through the TUI onboarding wizard or add an authRef manually", inst.Name)
+	}
+
+	return &sympoziumv1alpha1.AgentRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: inst.Namespace,
+			Labels: map[string]string{
+				"sympozium.ai/instance": inst.Name,
+			},
+		},
+		Spec: sympoziumv1alpha1.AgentRunSpec{
+			InstanceRef: inst.Name,
+			Task:        task,
+			Model: sympoziumv1alpha1.ModelSpec{
+				Provider:      provider,
+				Model:         inst.Spec.Agents.Default.Model,
+				BaseURL:       inst.Spec.Agents.Default.BaseURL,
+				AuthSecretRef: authSecret,
+			},
+			Skills:  inst.Spec.Skills,
+			Timeout: &metav1.Duration{Duration: 10 * time.Minute},
+		},
+	}, nil
+}
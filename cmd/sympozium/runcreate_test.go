@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sympoziumv1alpha1 "github.com/alexsjones/sympozium/api/v1alpha1"
+)
+
+func TestReadTaskLines(t *testing.T) {
+	tasks, err := readTaskLines(strings.NewReader("first\n\n# comment\n  second  \n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 2 || tasks[0] != (taskLine{1, "first"}) || tasks[1] != (taskLine{4, "second"}) {
+		t.Errorf("tasks = %+v", tasks)
+	}
+}
+
+func TestCreateRunsConcurrently(t *testing.T) {
+	var tasks []taskLine
+	for i := 1; i <= 10; i++ {
+		tasks = append(tasks, taskLine{line: i, task: strings.Repeat("t", i)})
+	}
+	var inFlight, peak atomic.Int32
+	results := createRunsConcurrently(tasks, 3, 0, func(task string) (string, error) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		if len(task) == 4 {
+			return "", errors.New("boom")
+		}
+		return "run-" + task, nil
+	})
+	if p := peak.Load(); p > 3 {
+		t.Errorf("peak concurrency = %d, want at most 3", p)
+	}
+	for i, r := range results {
+		if r.line != i+1 {
+			t.Errorf("result %d is for line %d; results must keep task order", i, r.line)
+		}
+	}
+
+	var out bytes.Buffer
+	err := reportRunCreates(&out, results)
+	if err == nil || err.Error() != "1 of 10 run(s) could not be created" {
+		t.Errorf("err = %v", err)
+	}
+	for _, want := range []string{"line 1: agentrun.sympozium.ai/run-t created", "line 4: failed: boom", "Created 9 of 10 run(s)."} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestCreateRunsConcurrently_Delay(t *testing.T) {
+	tasks := []taskLine{{1, "a"}, {2, "b"}, {3, "c"}}
+	start := time.Now()
+	createRunsConcurrently(tasks, 3, 20*time.Millisecond, func(string) (string, error) { return "r", nil })
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("3 creates 20ms apart took %s, want at least 40ms", elapsed)
+	}
+}
+
+func TestCreateRunForInstance(t *testing.T) {
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(
+		&sympoziumv1alpha1.SympoziumInstance{
+			ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "default"},
+			Spec: sympoziumv1alpha1.SympoziumInstanceSpec{
+				AuthRefs: []sympoziumv1alpha1.SecretRef{{Provider: "anthropic", Secret: "anthropic-key"}},
+				Skills:   []sympoziumv1alpha1.SkillRef{{SkillPackRef: "k8s-ops"}},
+			},
+		},
+		&sympoziumv1alpha1.SympoziumInstance{ObjectMeta: metav1.ObjectMeta{Name: "nokey", Namespace: "default"}},
+	).Build()
+	ctx := context.Background()
+
+	names := map[string]bool{}
+	for i := 0; i < 3; i++ {
+		name, err := createRunForInstance(ctx, c, "default", "agent", "check pods", true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(name, "agent-run-") || names[name] {
+			t.Errorf("generated name %q should be unique with the agent-run- prefix", name)
+		}
+		names[name] = true
+	}
+
+	var list sympoziumv1alpha1.AgentRunList
+	if err := c.List(ctx, &list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Items) != 3 {
+		t.Fatalf("runs = %d, want 3", len(list.Items))
+	}
+	run := list.Items[0]
+	if run.Spec.Model.Provider != "anthropic" || run.Spec.Model.AuthSecretRef != "anthropic-key" ||
+		run.Labels["sympozium.ai/instance"] != "agent" || len(run.Spec.Skills) != 1 {
+		t.Errorf("run = %+v", run)
+	}
+
+	if _, err := createRunForInstance(ctx, c, "default", "nokey", "x", true); err == nil || !strings.Contains(err.Error(), "no API key") {
+		t.Errorf("instance without authRefs: err = %v", err)
+	}
+	if _, err := createRunForInstance(ctx, c, "default", "missing", "x", true); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("missing instance: err = %v", err)
+	}
+}
+
+func TestRunsCreate_RecordsLastRun(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("SYMPOZIUM_CONFIG", filepath.Join(dir, "config.yaml"))
+	prevClient, prevNS := k8sClient, namespace
+	k8sClient = fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(
+		&sympoziumv1alpha1.SympoziumInstance{
+			ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "default"},
+			Spec: sympoziumv1alpha1.SympoziumInstanceSpec{
+				AuthRefs: []sympoziumv1alpha1.SecretRef{{Provider: "anthropic", Secret: "anthropic-key"}},
+			},
+		},
+	).Build()
+	t.Cleanup(func() { k8sClient, namespace, noRecord = prevClient, prevNS, false })
+
+	create := func(args ...string) {
+		t.Helper()
+		root := &cobra.Command{Use: "sympozium"}
+		root.PersistentFlags().StringVarP(&namespace, "namespace", "n", "default", "Kubernetes namespace")
+		addNoRecordFlag(root)
+		root.AddCommand(newRunsCmd())
+		root.SetOut(io.Discard)
+		root.SetArgs(append([]string{"runs", "create", "--instance", "agent"}, args...))
+		if err := root.Execute(); err != nil {
+			t.Fatalf("runs create %v: %v", args, err)
+		}
+	}
+	state := filepath.Join(dir, "last-run")
+
+	create("--no-record", "check pods")
+	if _, err := os.Stat(state); !os.IsNotExist(err) {
+		t.Errorf("state file written despite --no-record (stat err = %v)", err)
+	}
+	create("check pods")
+	if data, err := os.ReadFile(state); err != nil || !strings.HasPrefix(string(data), "default/agent-run-") {
+		t.Errorf("state = %q, %v; want the created run", data, err)
+	}
+}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	sympoziumv1alpha1 "github.com/alexsjones/sympozium/api/v1alpha1"
+)
+
+// ── runs watch ──────────────────────────────────────────────────────────────
+
+// runsWatchRetryDelay is how long `runs watch` waits before re-listing after
+// a failed watch.
+var runsWatchRetryDelay = 2 * time.Second
+
+// runsWatchOptions filters the transitions `runs watch` prints.
+type runsWatchOptions struct {
+	Instance   string
+	FailedOnly bool
+}
+
+func newRunsWatchCmd() *cobra.Command {
+	var opts runsWatchOptions
+	cmd := &cobra.Command{
+		Use:   "watch",
+		Short: "Print AgentRun phase transitions as they happen",
+		Long: `Watch the AgentRuns in --namespace and print one line per phase transition:
+
+  12:04:31 run/foo Pending→Running pod=foo-abc
+  12:05:10 run/foo Running→Succeeded 39s 1.2k tokens
+
+Status updates that do not change the phase are not printed. Runs that
+already exist when the command starts are only reported once they change.`,
+		Example: `  sympozium runs watch
+  sympozium runs watch --instance my-agent --failed-only`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := client.NewWithWatch(restConfig, client.Options{Scheme: k8sClient.Scheme()})
+			if err != nil {
+				return err
+			}
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			return watchRuns(ctx, &clientRunListWatcher{client: c, namespace: namespace}, opts, os.Stdout)
+		},
+	}
+	cmd.Flags().StringVar(&opts.Instance, "instance", "", "Only show runs of this SympoziumInstance")
+	cmd.Flags().BoolVar(&opts.FailedOnly, "failed-only", false, "Only show transitions into Failed")
+	return cmd
+}
+
+// runListWatcher lists AgentRuns and watches them from a resourceVersion.
+type runListWatcher interface {
+	List(ctx context.Context) (*sympoziumv1alpha1.AgentRunList, error)
+	Watch(ctx context.Context, resourceVersion string) (watch.Interface, error)
+}
+
+// clientRunListWatcher is a runListWatcher backed by a controller-runtime
+// client.
+type clientRunListWatcher struct {
+	client    client.WithWatch
+	namespace string
+}
+
+func (l *clientRunListWatcher) List(ctx context.Context) (*sympoziumv1alpha1.AgentRunList, error) {
+	var list sympoziumv1alpha1.AgentRunList
+	if err := l.client.List(ctx, &list, client.InNamespace(l.namespace)); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+func (l *clientRunListWatcher) Watch(ctx context.Context, resourceVersion string) (watch.Interface, error) {
+	var list sympoziumv1alpha1.AgentRunList
+	return l.client.Watch(ctx, &list, client.InNamespace(l.namespace),
+		&client.ListOptions{Raw: &metav1.ListOptions{ResourceVersion: resourceVersion}})
+}
+
+// watchRuns prints transitions until ctx is done. Whenever the watch ends
+// (timeouts, expired resourceVersions, dropped connections) the runs are
+// listed again and compared with the last phase seen per UID, so
+// transitions that happened during the gap are printed once and none twice.
+func watchRuns(ctx context.Context, lw runListWatcher, opts runsWatchOptions, w io.Writer) error {
+	seen := map[types.UID]sympoziumv1alpha1.AgentRunPhase{}
+	report := func(run *sympoziumv1alpha1.AgentRun) {
+		if opts.Instance != "" && run.Spec.InstanceRef != opts.Instance {
+			return
+		}
+		prev, known := seen[run.UID]
+		seen[run.UID] = run.Status.Phase
+		if known && prev == run.Status.Phase {
+			return
+		}
+		if opts.FailedOnly && run.Status.Phase != sympoziumv1alpha1.AgentRunPhaseFailed {
+			return
+		}
+		fmt.Fprintln(w, runTransitionLine(time.Now(), run, prev))
+	}
+
+	first := true
+	for {
+		list, err := lw.List(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if first {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "Warning: listing AgentRuns failed, retrying: %v\n", err)
+			if !sleepCtx(ctx, runsWatchRetryDelay) {
+				return nil
+			}
+			continue
+		}
+		present := map[types.UID]bool{}
+		for i := range list.Items {
+			run := &list.Items[i]
+			present[run.UID] = true
+			if first {
+				// Existing runs are the baseline, not transitions.
+				seen[run.UID] = run.Status.Phase
+				continue
+			}
+			report(run)
+		}
+		for uid := range seen {
+			if !present[uid] {
+				delete(seen, uid)
+			}
+		}
+		first = false
+
+		wi, err := lw.Watch(ctx, list.ResourceVersion)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if !sleepCtx(ctx, runsWatchRetryDelay) {
+				return nil
+			}
+			continue
+		}
+		consumeRunEvents(ctx, wi, report, func(uid types.UID) { delete(seen, uid) })
+		wi.Stop()
+		if ctx.Err() != nil {
+			return nil
+		}
+	}
+}
+
+// consumeRunEvents feeds watch events to report until the watch ends, an
+// error event arrives, or ctx is done.
+func consumeRunEvents(ctx context.Context, wi watch.Interface, report func(*sympoziumv1alpha1.AgentRun), forget func(types.UID)) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-wi.ResultChan():
+			if !ok {
+				return
+			}
+			run, isRun := ev.Object.(*sympoziumv1alpha1.AgentRun)
+			switch ev.Type {
+			case watch.Added, watch.Modified:
+				if isRun {
+					report(run)
+				}
+			case watch.Deleted:
+				if isRun {
+					forget(run.UID)
+				}
+			case watch.Error:
+				// Typically 410 Gone: re-list and watch again.
+				return
+			}
+		}
+	}
+}
+
+// runTransitionLine formats one transition. Terminal phases include the run
+// duration and token usage; other phases show the pod.
+func runTransitionLine(now time.Time, run *sympoziumv1alpha1.AgentRun, prev sympoziumv1alpha1.AgentRunPhase) string {
+	from := string(prev)
+	if from == "" {
+		from = "New"
+	}
+	to := string(run.Status.Phase)
+	if to == "" {
+		to = "New"
+	}
+	line := fmt.Sprintf("%s run/%s %s→%s", now.Format("15:04:05"), run.Name, from, to)
+
+	switch run.Status.Phase {
+	case sympoziumv1alpha1.AgentRunPhaseSucceeded, sympoziumv1alpha1.AgentRunPhaseFailed:
+		if d, ok := runDuration(run); ok {
+			line += " " + d.Round(time.Second).String()
+		}
+		if u := run.Status.TokenUsage; u != nil {
+			line += " " + formatTokenCount(u.InputTokens+u.OutputTokens) + " tokens"
+		}
+		if run.Status.Phase == sympoziumv1alpha1.AgentRunPhaseFailed {
+			if msg := runMessage(run); msg != "" {
+				line += " " + truncate(msg, runMessageMaxLen)
+			}
+		}
+	default:
+		if run.Status.PodName != "" {
+			line += " pod=" + run.Status.PodName
+		}
+	}
+	return line
+}
+
+// runDuration returns how long a finished run took.
+func runDuration(run *sympoziumv1alpha1.AgentRun) (time.Duration, bool) {
+	if run.Status.CompletedAt == nil {
+		return 0, false
+	}
+	start := run.CreationTimestamp.Time
+	if run.Status.StartedAt != nil {
+		start = run.Status.StartedAt.Time
+	}
+	return run.Status.CompletedAt.Sub(start), true
+}
+
+// sleepCtx waits for d and reports false if ctx ended first.
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
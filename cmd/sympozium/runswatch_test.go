@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+
+	sympoziumv1alpha1 "github.com/alexsjones/sympozium/api/v1alpha1"
+)
+
+// fakeRunListWatcher serves scripted lists and hands out fake watches.
+type fakeRunListWatcher struct {
+	mu      sync.Mutex
+	lists   [][]sympoziumv1alpha1.AgentRun
+	watches chan *watch.FakeWatcher
+}
+
+func (f *fakeRunListWatcher) List(ctx context.Context) (*sympoziumv1alpha1.AgentRunList, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	items := f.lists[0]
+	if len(f.lists) > 1 {
+		f.lists = f.lists[1:]
+	}
+	return &sympoziumv1alpha1.AgentRunList{Items: items}, nil
+}
+
+func (f *fakeRunListWatcher) Watch(ctx context.Context, _ string) (watch.Interface, error) {
+	fw := watch.NewFakeWithChanSize(10, false)
+	f.watches <- fw
+	return fw, nil
+}
+
+func testRun(uid, name, instance string, phase sympoziumv1alpha1.AgentRunPhase) *sympoziumv1alpha1.AgentRun {
+	r := &sympoziumv1alpha1.AgentRun{ObjectMeta: metav1.ObjectMeta{Name: name, UID: types.UID(uid)}}
+	r.Spec.InstanceRef = instance
+	r.Status.Phase = phase
+	return r
+}
+
+func TestWatchRuns_DedupesAcrossResets(t *testing.T) {
+	running := testRun("u1", "foo", "alpha", sympoziumv1alpha1.AgentRunPhaseRunning)
+	done := testRun("u1", "foo", "alpha", sympoziumv1alpha1.AgentRunPhaseSucceeded)
+	lw := &fakeRunListWatcher{
+		lists: [][]sympoziumv1alpha1.AgentRun{
+			{*testRun("u1", "foo", "alpha", sympoziumv1alpha1.AgentRunPhasePending)},
+			// Relist after the reset: the run finished while no watch was open.
+			{*done},
+		},
+		watches: make(chan *watch.FakeWatcher, 4),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var out syncBuffer
+	errc := make(chan error, 1)
+	go func() { errc <- watchRuns(ctx, lw, runsWatchOptions{}, &out) }()
+
+	w1 := <-lw.watches
+	w1.Modify(running)
+	w1.Modify(running) // status update without a phase change
+	w1.Stop()          // watch channel reset
+
+	w2 := <-lw.watches
+	w2.Modify(done) // already reported by the relist
+	w2.Add(testRun("u2", "bar", "alpha", sympoziumv1alpha1.AgentRunPhasePending))
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	if err := <-errc; err != nil {
+		t.Fatalf("watchRuns: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	want := []string{"run/foo Pending→Running", "run/foo Running→Succeeded", "run/bar New→Pending"}
+	if len(lines) != len(want) {
+		t.Fatalf("got %d lines, want %d:\n%s", len(lines), len(want), out.String())
+	}
+	for i, w := range want {
+		if !strings.Contains(lines[i], w) {
+			t.Errorf("line %d = %q, want it to contain %q", i, lines[i], w)
+		}
+	}
+}
+
+func TestWatchRuns_Filters(t *testing.T) {
+	lw := &fakeRunListWatcher{lists: [][]sympoziumv1alpha1.AgentRun{{}}, watches: make(chan *watch.FakeWatcher, 1)}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var out syncBuffer
+	errc := make(chan error, 1)
+	go func() { errc <- watchRuns(ctx, lw, runsWatchOptions{Instance: "alpha", FailedOnly: true}, &out) }()
+
+	w := <-lw.watches
+	w.Add(testRun("u1", "a-ok", "alpha", sympoziumv1alpha1.AgentRunPhaseRunning))
+	w.Modify(testRun("u1", "a-ok", "alpha", sympoziumv1alpha1.AgentRunPhaseSucceeded))
+	w.Add(testRun("u2", "a-bad", "alpha", sympoziumv1alpha1.AgentRunPhaseRunning))
+	w.Modify(testRun("u2", "a-bad", "alpha", sympoziumv1alpha1.AgentRunPhaseFailed))
+	w.Add(testRun("u3", "b-bad", "beta", sympoziumv1alpha1.AgentRunPhaseFailed))
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	<-errc
+
+	if got := strings.TrimSpace(out.String()); !strings.HasSuffix(got, "run/a-bad Running→Failed") || strings.Count(got, "\n") != 0 {
+		t.Errorf("output = %q, want only the alpha failure", got)
+	}
+}
+
+func TestRunTransitionLine(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 5, 10, 0, time.UTC)
+	run := testRun("u1", "foo", "alpha", sympoziumv1alpha1.AgentRunPhaseRunning)
+	run.Status.PodName = "foo-abc"
+	if got := runTransitionLine(now, run, sympoziumv1alpha1.AgentRunPhasePending); got != "12:05:10 run/foo Pending→Running pod=foo-abc" {
+		t.Errorf("running line = %q", got)
+	}
+
+	run.Status.Phase = sympoziumv1alpha1.AgentRunPhaseSucceeded
+	started := metav1.NewTime(now.Add(-39 * time.Second))
+	completed := metav1.NewTime(now)
+	run.Status.StartedAt, run.Status.CompletedAt = &started, &completed
+	run.Status.TokenUsage = &sympoziumv1alpha1.TokenUsage{InputTokens: 1000, OutputTokens: 200}
+	if got := runTransitionLine(now, run, sympoziumv1alpha1.AgentRunPhaseRunning); got != "12:05:10 run/foo Running→Succeeded 39s 1.2k tokens" {
+		t.Errorf("succeeded line = %q", got)
+	}
+}
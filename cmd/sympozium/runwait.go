@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	sympoziumv1alpha1 "github.com/alexsjones/sympozium/api/v1alpha1"
+)
+
+// ── runs wait ───────────────────────────────────────────────────────────────
+
+// Exit codes of `runs wait`. Any other CLI or API failure exits 1.
+const (
+	exitRunSucceeded = 0
+	exitRunFailed    = 2
+	exitRunTimeout   = 3
+	exitRunCancelled = 4
+)
+
+// runWaitPollInterval is how often `runs wait` re-reads the AgentRun.
+var runWaitPollInterval = 2 * time.Second
+
+// exitCodeError makes the CLI exit with code after reporting err.
+type exitCodeError struct {
+	code int
+	err  error
+}
+
+func (e *exitCodeError) Error() string { return e.err.Error() }
+func (e *exitCodeError) Unwrap() error { return e.err }
+
+// exitCode returns the process exit code for an error returned by a command.
+func exitCode(err error) int {
+	var ec *exitCodeError
+	if errors.As(err, &ec) {
+		return ec.code
+	}
+	return 1
+}
+
+// runOutcome is how a waited-for AgentRun ended.
+type runOutcome struct {
+	Phase   string
+	Message string
+	Code    int
+}
+
+func newRunsWaitCmd() *cobra.Command {
+	var timeout time.Duration
+	cmd := &cobra.Command{
+		Use:   "wait [name|@last]",
+		Short: "Wait for an AgentRun to finish and exit with its outcome",
+		Long: `Wait until an AgentRun reaches a terminal phase. The terminal phase is
+printed to stdout and any diagnostic detail to stderr.
+
+Exit codes:
+  0  the run succeeded
+  1  CLI or API error (e.g. the run does not exist)
+  2  the run failed (agent error)
+  3  --timeout elapsed before the run finished
+  4  the run was cancelled (deleted before it finished)
+
+Without a name, or with @last, the run most recently created by this CLI is
+waited for.`,
+		Example: `  sympozium runs wait my-run --timeout 10m || echo "exit $?"
+  sympozium runs wait @last`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			if timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, timeout)
+				defer cancel()
+			}
+			key, err := resolveRunArg(ctx, k8sClient, args)
+			if err != nil {
+				return err
+			}
+			out, err := waitForRun(ctx, k8sClient, key)
+			if err != nil {
+				return err
+			}
+			fmt.Println(out.Phase)
+			if out.Code == exitRunSucceeded {
+				return nil
+			}
+			msg := fmt.Sprintf("agentrun %s: %s", key.Name, out.Phase)
+			if out.Message != "" {
+				msg += ": " + out.Message
+			}
+			code := errUnknown
+			if out.Code == exitRunTimeout {
+				code = errTimeout
+			}
+			return &exitCodeError{code: out.Code, err: &cliError{Code: code, Message: msg, Resource: "agentruns/" + key.Name}}
+		},
+	}
+	cmd.Flags().DurationVar(&timeout, "timeout", 0, "Give up after this long and exit 3 (0 waits indefinitely)")
+	return cmd
+}
+
+// waitForRun polls the AgentRun until it is terminal, it is deleted, or ctx
+// is done. A run that cannot be found on the first read is an error; one
+// that disappears while being waited on is reported as cancelled. Timeouts
+// are outcomes too, so only API failures are returned as errors.
+func waitForRun(ctx context.Context, c client.Reader, key types.NamespacedName) (runOutcome, error) {
+	seen := false
+	ticker := time.NewTicker(runWaitPollInterval)
+	defer ticker.Stop()
+	for {
+		var run sympoziumv1alpha1.AgentRun
+		err := c.Get(ctx, key, &run)
+		switch {
+		case apierrors.IsNotFound(err) && seen:
+			return runOutcome{Phase: "Cancelled", Message: "the AgentRun was deleted", Code: exitRunCancelled}, nil
+		case err != nil && ctx.Err() != nil:
+			return timeoutOutcome(ctx, ""), nil
+		case err != nil:
+			return runOutcome{}, err
+		}
+		seen = true
+
+		switch run.Status.Phase {
+		case sympoziumv1alpha1.AgentRunPhaseSucceeded:
+			return runOutcome{Phase: string(run.Status.Phase), Code: exitRunSucceeded}, nil
+		case sympoziumv1alpha1.AgentRunPhaseFailed:
+			out := runOutcome{Phase: string(run.Status.Phase), Message: runMessage(&run), Code: exitRunFailed}
+			if c := latestCondition(run.Status.Conditions); c != nil && c.Reason == "Cancelled" {
+				out.Phase, out.Code = "Cancelled", exitRunCancelled
+			}
+			return out, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return timeoutOutcome(ctx, string(run.Status.Phase)), nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// timeoutOutcome reports a wait that ended because ctx was done. An
+// interrupted wait (Ctrl-C) is a CLI error rather than a timeout.
+func timeoutOutcome(ctx context.Context, phase string) runOutcome {
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return runOutcome{Phase: "Interrupted", Message: "wait interrupted", Code: 1}
+	}
+	msg := "timed out waiting for the run to finish"
+	if phase != "" {
+		msg += " (last phase: " + phase + ")"
+	}
+	return runOutcome{Phase: "Timeout", Message: msg, Code: exitRunTimeout}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sympoziumv1alpha1 "github.com/alexsjones/sympozium/api/v1alpha1"
+)
+
+func TestWaitForRun_Outcomes(t *testing.T) {
+	old := runWaitPollInterval
+	runWaitPollInterval = 10 * time.Millisecond
+	t.Cleanup(func() { runWaitPollInterval = old })
+
+	run := func(phase sympoziumv1alpha1.AgentRunPhase, reason string) *sympoziumv1alpha1.AgentRun {
+		r := &sympoziumv1alpha1.AgentRun{ObjectMeta: metav1.ObjectMeta{Name: "r", Namespace: "default"}}
+		r.Status.Phase = phase
+		if reason != "" {
+			r.Status.Conditions = []metav1.Condition{{Type: "Complete", Status: metav1.ConditionFalse, Reason: reason, Message: "agent exited 1"}}
+		}
+		return r
+	}
+	key := types.NamespacedName{Name: "r", Namespace: "default"}
+
+	tests := []struct {
+		name string
+		run  *sympoziumv1alpha1.AgentRun
+		code int
+	}{
+		{"succeeded", run(sympoziumv1alpha1.AgentRunPhaseSucceeded, ""), exitRunSucceeded},
+		{"failed", run(sympoziumv1alpha1.AgentRunPhaseFailed, "PodFailed"), exitRunFailed},
+		{"cancelled", run(sympoziumv1alpha1.AgentRunPhaseFailed, "Cancelled"), exitRunCancelled},
+		{"timeout", run(sympoziumv1alpha1.AgentRunPhaseRunning, ""), exitRunTimeout},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(tt.run).Build()
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			out, err := waitForRun(ctx, c, key)
+			if err != nil {
+				t.Fatalf("waitForRun: %v", err)
+			}
+			if out.Code != tt.code {
+				t.Errorf("code = %d (%s), want %d", out.Code, out.Phase, tt.code)
+			}
+		})
+	}
+}
+
+func TestWaitForRun_DeletedIsCancelled(t *testing.T) {
+	old := runWaitPollInterval
+	runWaitPollInterval = 10 * time.Millisecond
+	t.Cleanup(func() { runWaitPollInterval = old })
+
+	r := &sympoziumv1alpha1.AgentRun{ObjectMeta: metav1.ObjectMeta{Name: "r", Namespace: "default"}}
+	r.Status.Phase = sympoziumv1alpha1.AgentRunPhaseRunning
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(r).Build()
+
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		_ = c.Delete(context.Background(), r)
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	out, err := waitForRun(ctx, c, types.NamespacedName{Name: "r", Namespace: "default"})
+	if err != nil {
+		t.Fatalf("waitForRun: %v", err)
+	}
+	if out.Code != exitRunCancelled {
+		t.Errorf("code = %d (%s), want %d", out.Code, out.Phase, exitRunCancelled)
+	}
+}
+
+func TestWaitForRun_MissingIsError(t *testing.T) {
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).Build()
+	if _, err := waitForRun(context.Background(), c, types.NamespacedName{Name: "nope", Namespace: "default"}); err == nil {
+		t.Fatal("expected an error for a run that does not exist")
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	if got := exitCode(errors.New("boom")); got != 1 {
+		t.Errorf("plain error = %d, want 1", got)
+	}
+	wrapped := fmt.Errorf("ctx: %w", &exitCodeError{code: exitRunTimeout, err: errors.New("late")})
+	if got := exitCode(wrapped); got != exitRunTimeout {
+		t.Errorf("wrapped = %d, want %d", got, exitRunTimeout)
+	}
+}
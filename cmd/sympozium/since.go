@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	sympoziumv1alpha1 "github.com/alexsjones/sympozium/api/v1alpha1"
+)
+
+// sinceDaysRe matches a leading whole-day component such as "2d" in "2d12h".
+var sinceDaysRe = regexp.MustCompile(`^(\d+)d`)
+
+// sinceFlagUsage is the shared help text for --since flags.
+const sinceFlagUsage = "Only show items newer than a relative duration (30m, 6h, 2d) or an RFC3339 timestamp"
+
+// parseSince converts a --since value into the cutoff time relative to now.
+// It accepts an RFC3339 timestamp or a positive duration using Go units plus
+// a "d" (24h) day unit, e.g. "45s", "30m", "6h", "2d", "1d12h". Bare numbers
+// are rejected because their unit would be ambiguous.
+func parseSince(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, fmt.Errorf("--since must not be empty")
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		if t.After(now) {
+			return time.Time{}, fmt.Errorf("--since %q is in the future", value)
+		}
+		return t, nil
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return time.Time{}, fmt.Errorf("--since %q has no unit; use e.g. %sm, %sh or %sd", value, value, value, value)
+	}
+
+	var d time.Duration
+	rest := value
+	if m := sinceDaysRe.FindStringSubmatch(rest); m != nil {
+		days, err := strconv.Atoi(m[1])
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid --since %q: %w", value, err)
+		}
+		d = time.Duration(days) * 24 * time.Hour
+		rest = rest[len(m[0]):]
+	}
+	if rest != "" {
+		parsed, err := time.ParseDuration(rest)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid --since %q: expected a duration like 30m, 6h or 2d, or an RFC3339 timestamp", value)
+		}
+		d += parsed
+	}
+	if d <= 0 {
+		return time.Time{}, fmt.Errorf("--since %q must be a positive duration", value)
+	}
+	return now.Add(-d), nil
+}
+
+// filterRunsSince keeps the runs created at or after cutoff, or with
+// completed set, the runs that completed at or after cutoff. Runs that have
+// not completed are dropped in that mode.
+func filterRunsSince(runs []sympoziumv1alpha1.AgentRun, cutoff time.Time, completed bool) []sympoziumv1alpha1.AgentRun {
+	out := runs[:0]
+	for _, run := range runs {
+		ts := run.CreationTimestamp.Time
+		if completed {
+			if run.Status.CompletedAt == nil {
+				continue
+			}
+			ts = run.Status.CompletedAt.Time
+		}
+		if !ts.Before(cutoff) {
+			out = append(out, run)
+		}
+	}
+	return out
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	sympoziumv1alpha1 "github.com/alexsjones/sympozium/api/v1alpha1"
+)
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"30m", now.Add(-30 * time.Minute)},
+		{"6h", now.Add(-6 * time.Hour)},
+		{"2d", now.Add(-48 * time.Hour)},
+		{"1d12h", now.Add(-36 * time.Hour)},
+		{"90s", now.Add(-90 * time.Second)},
+		{" 1h30m ", now.Add(-90 * time.Minute)},
+		{"2026-03-09T08:00:00Z", time.Date(2026, 3, 9, 8, 0, 0, 0, time.UTC)},
+		{"2026-03-10T13:00:00+02:00", time.Date(2026, 3, 10, 11, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := parseSince(tt.in, now)
+		if err != nil {
+			t.Errorf("parseSince(%q) error: %v", tt.in, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("parseSince(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseSince_Rejects(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	for _, in := range []string{
+		"",
+		"30",     // bare number: unit is ambiguous
+		"0",      // bare zero
+		"1.5",    // bare fraction
+		"-2h",    // negative
+		"0m",     // zero duration
+		"2 days", // prose
+		"d",      // unit without number
+		"3w",     // unsupported unit
+		"2026-03-10",
+		"2026-03-11T00:00:00Z", // future
+	} {
+		if got, err := parseSince(in, now); err == nil {
+			t.Errorf("parseSince(%q) = %v, want error", in, got)
+		}
+	}
+}
+
+func TestFilterRunsSince(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	run := func(name string, createdAgo time.Duration, completedAgo *time.Duration) sympoziumv1alpha1.AgentRun {
+		r := sympoziumv1alpha1.AgentRun{ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			CreationTimestamp: metav1.NewTime(now.Add(-createdAgo)),
+		}}
+		if completedAgo != nil {
+			done := metav1.NewTime(now.Add(-*completedAgo))
+			r.Status.CompletedAt = &done
+		}
+		return r
+	}
+	tenMin := 10 * time.Minute
+	twoHours := 2 * time.Hour
+	runs := func() []sympoziumv1alpha1.AgentRun {
+		return []sympoziumv1alpha1.AgentRun{
+			run("old-recently-done", 3*time.Hour, &tenMin),
+			run("old-long-done", 3*time.Hour, &twoHours),
+			run("new-running", 5*time.Minute, nil),
+		}
+	}
+	names := func(rs []sympoziumv1alpha1.AgentRun) []string {
+		var out []string
+		for _, r := range rs {
+			out = append(out, r.Name)
+		}
+		return out
+	}
+	cutoff := now.Add(-time.Hour)
+
+	if got := names(filterRunsSince(runs(), cutoff, false)); len(got) != 1 || got[0] != "new-running" {
+		t.Errorf("by creation = %v, want [new-running]", got)
+	}
+	if got := names(filterRunsSince(runs(), cutoff, true)); len(got) != 1 || got[0] != "old-recently-done" {
+		t.Errorf("by completion = %v, want [old-recently-done]", got)
+	}
+}
@@ -0,0 +1,405 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// ── SkillPack registry index (skills search / skills install) ───────────────
+
+// skillIndexAPIVersion is the only index format this CLI understands.
+const skillIndexAPIVersion = "sympozium.ai/skill-index/v1"
+
+// skillIndexMaxBytes bounds how much of an index or pack manifest is read.
+const skillIndexMaxBytes = 16 << 20
+
+// skillIndex is a SkillPack registry index, served as JSON over HTTP in the
+// spirit of a Helm repository index.
+type skillIndex struct {
+	APIVersion string           `json:"apiVersion"`
+	Generated  time.Time        `json:"generated,omitempty"`
+	Packs      []skillIndexPack `json:"packs"`
+}
+
+// skillIndexPack is one SkillPack and all of its published versions.
+type skillIndexPack struct {
+	Name        string              `json:"name"`
+	Description string              `json:"description,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	Source      string              `json:"source,omitempty"`
+	Versions    []skillIndexVersion `json:"versions"`
+}
+
+// skillIndexVersion points at the SkillPack manifest of one version. URL may
+// be relative to the index URL; Digest, when set, is "sha256:<hex>" of the
+// manifest.
+type skillIndexVersion struct {
+	Version string `json:"version"`
+	URL     string `json:"url"`
+	Digest  string `json:"digest,omitempty"`
+}
+
+func newSkillsSearchCmd() *cobra.Command {
+	var (
+		indexURL     string
+		showVersions bool
+	)
+	cmd := &cobra.Command{
+		Use:   "search [query]",
+		Short: "Search the SkillPack registry index",
+		Long: `Search the SkillPack registry index by name, description and tags. With
+--show-versions, list every published version of the named pack.
+
+The index URL is taken from --index, SYMPOZIUM_SKILL_INDEX, or skillIndex in
+the CLI config file (~/.config/sympozium/config.yaml).`,
+		Example: `  sympozium skills search kubernetes
+  sympozium skills search --show-versions k8s-ops`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			query := ""
+			if len(args) == 1 {
+				query = args[0]
+			}
+			if showVersions && query == "" {
+				return fmt.Errorf("--show-versions requires a pack name")
+			}
+			idx, base, err := fetchSkillIndex(indexURL)
+			if err != nil {
+				return err
+			}
+			if showVersions {
+				pack := idx.pack(query)
+				if pack == nil {
+					return fmt.Errorf("SkillPack %q not found in %s", query, base)
+				}
+				return printSkillPackVersions(os.Stdout, pack)
+			}
+			return printSkillSearch(os.Stdout, idx.search(query), base)
+		},
+	}
+	cmd.Flags().StringVar(&indexURL, "index", "", "SkillPack registry index URL (overrides the CLI config)")
+	cmd.Flags().BoolVar(&showVersions, "show-versions", false, "List all published versions of the named pack")
+	return cmd
+}
+
+func newSkillsInstallCmd() *cobra.Command {
+	var indexURL string
+	cmd := &cobra.Command{
+		Use:   "install NAME[@VERSION]",
+		Short: "Install a SkillPack from the registry index",
+		Long: `Install a SkillPack from the registry index into --namespace. Without
+@VERSION the newest published version is installed. The manifest's digest is
+verified when the index records one.`,
+		Example: `  sympozium skills install k8s-ops
+  sympozium skills install k8s-ops@0.2.0 -n team-a`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name, ver, _ := strings.Cut(args[0], "@")
+			idx, base, err := fetchSkillIndex(indexURL)
+			if err != nil {
+				return err
+			}
+			pack := idx.pack(name)
+			if pack == nil {
+				return fmt.Errorf("SkillPack %q not found in %s", name, base)
+			}
+			v, err := pack.resolve(ver)
+			if err != nil {
+				return err
+			}
+			manifestURL, err := resolveIndexURL(base, v.URL)
+			if err != nil {
+				return err
+			}
+			objs, err := fetchSkillPackManifest(manifestURL, v.Digest, k8sClient.Scheme())
+			if err != nil {
+				return err
+			}
+
+			ctx := context.Background()
+			for _, o := range objs {
+				if o.obj.GetNamespace() == "" {
+					o.obj.SetNamespace(namespace)
+				}
+				result, err := applyUnstructured(ctx, o.obj, true)
+				if err != nil {
+					return fmt.Errorf("install %s@%s: %w", pack.Name, v.Version, explainAdmissionError(err))
+				}
+				fmt.Printf("skillpack.sympozium.ai/%s %s (%s)\n", o.obj.GetName(), result, v.Version)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&indexURL, "index", "", "SkillPack registry index URL (overrides the CLI config)")
+	return cmd
+}
+
+// skillIndexURL returns the index URL from the flag, the environment or the
+// CLI config, in that order.
+func skillIndexURL(flag string) (string, error) {
+	if flag != "" {
+		return flag, nil
+	}
+	if env := os.Getenv("SYMPOZIUM_SKILL_INDEX"); env != "" {
+		return env, nil
+	}
+	cfg, err := loadCLIConfig()
+	if err != nil {
+		return "", err
+	}
+	if cfg.SkillIndex == "" {
+		path, _ := cliConfigPath()
+		return "", fmt.Errorf("no SkillPack index configured: pass --index, set SYMPOZIUM_SKILL_INDEX, or add skillIndex to %s", path)
+	}
+	return cfg.SkillIndex, nil
+}
+
+// fetchSkillIndex downloads, parses and validates the configured index. It
+// also returns the index URL so relative version URLs can be resolved.
+func fetchSkillIndex(flag string) (*skillIndex, string, error) {
+	base, err := skillIndexURL(flag)
+	if err != nil {
+		return nil, "", err
+	}
+	data, err := readIndexURL(base)
+	if err != nil {
+		return nil, "", fmt.Errorf("fetch SkillPack index: %w", err)
+	}
+	idx, err := parseSkillIndex(data)
+	if err != nil {
+		return nil, "", fmt.Errorf("SkillPack index %s: %w", base, err)
+	}
+	return idx, base, nil
+}
+
+// readIndexURL reads an http(s) URL, a file:// URL or a local path.
+func readIndexURL(raw string) ([]byte, error) {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		path := raw
+		if err == nil && u.Scheme == "file" {
+			path = u.Path
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return io.ReadAll(io.LimitReader(f, skillIndexMaxBytes))
+	}
+
+	client := &http.Client{Timeout: 15 * time.Second}
+	resp, err := client.Get(raw)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", raw, resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, skillIndexMaxBytes))
+}
+
+// resolveIndexURL resolves ref against the index URL.
+func resolveIndexURL(base, ref string) (string, error) {
+	r, err := url.Parse(ref)
+	if err != nil {
+		return "", fmt.Errorf("invalid url %q: %w", ref, err)
+	}
+	b, err := url.Parse(base)
+	if err != nil || r.IsAbs() {
+		return ref, nil
+	}
+	if b.Scheme == "" {
+		// Local index file: resolve relative to its directory.
+		abs, err := filepath.Abs(base)
+		if err != nil {
+			return "", err
+		}
+		b = &url.URL{Scheme: "file", Path: abs}
+	}
+	return b.ResolveReference(r).String(), nil
+}
+
+// parseSkillIndex decodes an index strictly and validates it, reporting the
+// location of the first problem (e.g. "packs[2].versions[0]: missing url").
+func parseSkillIndex(data []byte) (*skillIndex, error) {
+	dec := json.NewDecoder(strings.NewReader(string(data)))
+	dec.DisallowUnknownFields()
+	var idx skillIndex
+	if err := dec.Decode(&idx); err != nil {
+		return nil, fmt.Errorf("malformed index JSON: %w", err)
+	}
+	switch idx.APIVersion {
+	case skillIndexAPIVersion:
+	case "":
+		return nil, fmt.Errorf("missing apiVersion (expected %s)", skillIndexAPIVersion)
+	default:
+		return nil, fmt.Errorf("unsupported apiVersion %q (this CLI understands %s; try upgrading sympozium)", idx.APIVersion, skillIndexAPIVersion)
+	}
+
+	names := map[string]bool{}
+	for i, p := range idx.Packs {
+		where := fmt.Sprintf("packs[%d]", i)
+		if p.Name == "" {
+			return nil, fmt.Errorf("%s: missing name", where)
+		}
+		where = fmt.Sprintf("packs[%d] (%s)", i, p.Name)
+		if names[p.Name] {
+			return nil, fmt.Errorf("%s: duplicate pack name", where)
+		}
+		names[p.Name] = true
+		if len(p.Versions) == 0 {
+			return nil, fmt.Errorf("%s: no versions", where)
+		}
+		versions := map[string]bool{}
+		for j, v := range p.Versions {
+			vwhere := fmt.Sprintf("%s.versions[%d]", where, j)
+			if _, _, ok := parseVersion(v.Version); !ok {
+				return nil, fmt.Errorf("%s: version %q is not MAJOR.MINOR.PATCH", vwhere, v.Version)
+			}
+			if versions[v.Version] {
+				return nil, fmt.Errorf("%s: duplicate version %s", vwhere, v.Version)
+			}
+			versions[v.Version] = true
+			if v.URL == "" {
+				return nil, fmt.Errorf("%s: missing url", vwhere)
+			}
+			if v.Digest != "" {
+				hexDigest, ok := strings.CutPrefix(v.Digest, "sha256:")
+				if _, err := hex.DecodeString(hexDigest); !ok || err != nil || len(hexDigest) != sha256.Size*2 {
+					return nil, fmt.Errorf("%s: digest %q must be sha256:<64 hex characters>", vwhere, v.Digest)
+				}
+			}
+		}
+		// Newest version first for display and resolution.
+		sort.SliceStable(idx.Packs[i].Versions, func(a, b int) bool {
+			cmp, _ := compareVersions(idx.Packs[i].Versions[a].Version, idx.Packs[i].Versions[b].Version)
+			return cmp > 0
+		})
+	}
+	return &idx, nil
+}
+
+// pack returns the pack with the given name, or nil.
+func (idx *skillIndex) pack(name string) *skillIndexPack {
+	for i := range idx.Packs {
+		if idx.Packs[i].Name == name {
+			return &idx.Packs[i]
+		}
+	}
+	return nil
+}
+
+// search returns the packs whose name, description or tags contain query
+// (case-insensitive), sorted by name. An empty query matches everything.
+func (idx *skillIndex) search(query string) []skillIndexPack {
+	q := strings.ToLower(query)
+	var out []skillIndexPack
+	for _, p := range idx.Packs {
+		haystack := strings.ToLower(p.Name + "\n" + p.Description + "\n" + strings.Join(p.Tags, "\n"))
+		if strings.Contains(haystack, q) {
+			out = append(out, p)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// resolve returns the requested version, or the newest when version is "".
+func (p *skillIndexPack) resolve(version string) (*skillIndexVersion, error) {
+	if version == "" {
+		return &p.Versions[0], nil
+	}
+	want := strings.TrimPrefix(version, "v")
+	for i := range p.Versions {
+		if strings.TrimPrefix(p.Versions[i].Version, "v") == want {
+			return &p.Versions[i], nil
+		}
+	}
+	available := make([]string, 0, len(p.Versions))
+	for _, v := range p.Versions {
+		available = append(available, v.Version)
+	}
+	return nil, fmt.Errorf("SkillPack %s has no version %s (available: %s)", p.Name, version, strings.Join(available, ", "))
+}
+
+// fetchSkillPackManifest downloads a pack manifest, verifies its digest and
+// decodes it, accepting only SkillPack documents.
+func fetchSkillPackManifest(manifestURL, digest string, scheme *runtime.Scheme) ([]applyObject, error) {
+	data, err := readIndexURL(manifestURL)
+	if err != nil {
+		return nil, fmt.Errorf("fetch SkillPack manifest: %w", err)
+	}
+	if digest != "" {
+		sum := sha256.Sum256(data)
+		if got := "sha256:" + hex.EncodeToString(sum[:]); got != digest {
+			return nil, fmt.Errorf("%s: digest mismatch (index says %s, got %s)", manifestURL, digest, got)
+		}
+	}
+	objs, err := decodeSympoziumDocuments(manifestURL, data, scheme)
+	if err != nil {
+		return nil, err
+	}
+	if len(objs) == 0 {
+		return nil, fmt.Errorf("%s: no SkillPack found", manifestURL)
+	}
+	for _, o := range objs {
+		if o.obj.GetKind() != "SkillPack" {
+			return nil, fmt.Errorf("%s: expected only SkillPack documents, found %s %q", o.source, o.obj.GetKind(), o.obj.GetName())
+		}
+	}
+	return objs, nil
+}
+
+// printSkillSearch prints search results as a table. Packs without a source
+// show the index host.
+func printSkillSearch(w io.Writer, packs []skillIndexPack, indexURL string) error {
+	if len(packs) == 0 {
+		fmt.Fprintln(w, "No SkillPacks found.")
+		return nil
+	}
+	fallbackSource := indexURL
+	if u, err := url.Parse(indexURL); err == nil && u.Host != "" {
+		fallbackSource = u.Host
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tVERSION\tDESCRIPTION\tSOURCE")
+	for _, p := range packs {
+		source := p.Source
+		if source == "" {
+			source = fallbackSource
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", p.Name, p.Versions[0].Version, truncate(p.Description, runMessageMaxLen), source)
+	}
+	return tw.Flush()
+}
+
+// printSkillPackVersions lists every version of a pack, newest first.
+func printSkillPackVersions(w io.Writer, p *skillIndexPack) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tVERSION\tDIGEST")
+	for _, v := range p.Versions {
+		digest := v.Digest
+		if digest == "" {
+			digest = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", p.Name, v.Version, digest)
+	}
+	return tw.Flush()
+}
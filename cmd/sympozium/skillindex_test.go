@@ -0,0 +1,149 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const testSkillIndex = `{
+  "apiVersion": "sympozium.ai/skill-index/v1",
+  "packs": [
+    {"name": "k8s-ops", "description": "Kubernetes operations", "tags": ["kubernetes"],
+     "versions": [
+       {"version": "0.1.0", "url": "packs/k8s-ops-0.1.0.yaml"},
+       {"version": "0.10.0", "url": "packs/k8s-ops-0.10.0.yaml"},
+       {"version": "0.2.0", "url": "packs/k8s-ops-0.2.0.yaml"}
+     ]},
+    {"name": "code-review", "description": "Review pull requests", "tags": ["git", "github"], "source": "github.com/acme/skills",
+     "versions": [{"version": "1.0.0", "url": "https://example.com/code-review.yaml"}]}
+  ]
+}`
+
+func TestParseSkillIndex(t *testing.T) {
+	idx, err := parseSkillIndex([]byte(testSkillIndex))
+	if err != nil {
+		t.Fatalf("parseSkillIndex: %v", err)
+	}
+	pack := idx.pack("k8s-ops")
+	if pack == nil {
+		t.Fatal("k8s-ops not found")
+	}
+	if got := pack.Versions[0].Version; got != "0.10.0" {
+		t.Errorf("newest version = %s, want 0.10.0 (semver, not lexical)", got)
+	}
+	if v, err := pack.resolve("v0.2.0"); err != nil || v.URL != "packs/k8s-ops-0.2.0.yaml" {
+		t.Errorf("resolve(v0.2.0) = %v, %v", v, err)
+	}
+	if _, err := pack.resolve("9.9.9"); err == nil || !strings.Contains(err.Error(), "available: 0.10.0, 0.2.0, 0.1.0") {
+		t.Errorf("resolve(9.9.9) error = %v", err)
+	}
+
+	for query, want := range map[string]string{
+		"":           "code-review,k8s-ops",
+		"KUBERNETES": "k8s-ops",
+		"github":     "code-review",
+		"pull":       "code-review",
+		"nothing":    "",
+	} {
+		var names []string
+		for _, p := range idx.search(query) {
+			names = append(names, p.Name)
+		}
+		if got := strings.Join(names, ","); got != want {
+			t.Errorf("search(%q) = %q, want %q", query, got, want)
+		}
+	}
+}
+
+func TestParseSkillIndex_Rejects(t *testing.T) {
+	tests := map[string]struct{ index, want string }{
+		"not json":        {`packs: []`, "malformed index JSON"},
+		"unknown field":   {`{"apiVersion":"sympozium.ai/skill-index/v1","pakcs":[]}`, "malformed index JSON"},
+		"no apiVersion":   {`{"packs":[]}`, "missing apiVersion"},
+		"future version":  {`{"apiVersion":"sympozium.ai/skill-index/v2","packs":[]}`, "unsupported apiVersion"},
+		"nameless pack":   {`{"apiVersion":"sympozium.ai/skill-index/v1","packs":[{"versions":[]}]}`, "packs[0]: missing name"},
+		"no versions":     {`{"apiVersion":"sympozium.ai/skill-index/v1","packs":[{"name":"a","versions":[]}]}`, "packs[0] (a): no versions"},
+		"duplicate pack":  {`{"apiVersion":"sympozium.ai/skill-index/v1","packs":[{"name":"a","versions":[{"version":"1.0.0","url":"x"}]},{"name":"a","versions":[{"version":"1.0.0","url":"x"}]}]}`, "packs[1] (a): duplicate pack name"},
+		"bad version":     {`{"apiVersion":"sympozium.ai/skill-index/v1","packs":[{"name":"a","versions":[{"version":"latest","url":"x"}]}]}`, "packs[0] (a).versions[0]: version \"latest\""},
+		"missing url":     {`{"apiVersion":"sympozium.ai/skill-index/v1","packs":[{"name":"a","versions":[{"version":"1.0.0"}]}]}`, "packs[0] (a).versions[0]: missing url"},
+		"bad digest":      {`{"apiVersion":"sympozium.ai/skill-index/v1","packs":[{"name":"a","versions":[{"version":"1.0.0","url":"x","digest":"md5:abc"}]}]}`, "must be sha256:"},
+		"duplicate vers.": {`{"apiVersion":"sympozium.ai/skill-index/v1","packs":[{"name":"a","versions":[{"version":"1.0.0","url":"x"},{"version":"1.0.0","url":"y"}]}]}`, "duplicate version 1.0.0"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := parseSkillIndex([]byte(tt.index))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveIndexURL(t *testing.T) {
+	tests := []struct{ base, ref, want string }{
+		{"https://skills.example.com/index.json", "packs/a.yaml", "https://skills.example.com/packs/a.yaml"},
+		{"https://skills.example.com/repo/index.json", "/a.yaml", "https://skills.example.com/a.yaml"},
+		{"https://skills.example.com/index.json", "https://cdn.example.com/a.yaml", "https://cdn.example.com/a.yaml"},
+		{"/srv/skills/index.json", "packs/a.yaml", "file:///srv/skills/packs/a.yaml"},
+	}
+	for _, tt := range tests {
+		got, err := resolveIndexURL(tt.base, tt.ref)
+		if err != nil || got != tt.want {
+			t.Errorf("resolveIndexURL(%q, %q) = %q, %v; want %q", tt.base, tt.ref, got, err, tt.want)
+		}
+	}
+}
+
+func TestFetchSkillPackManifest(t *testing.T) {
+	const pack = `apiVersion: sympozium.ai/v1alpha1
+kind: SkillPack
+metadata:
+  name: k8s-ops
+spec:
+  skills:
+    - name: overview
+      content: hi
+`
+	const policy = `apiVersion: sympozium.ai/v1alpha1
+kind: SympoziumPolicy
+metadata:
+  name: sneaky
+`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/pack.yaml":
+			_, _ = w.Write([]byte(pack))
+		case "/policy.yaml":
+			_, _ = w.Write([]byte(policy))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	scheme := testScheme(t)
+	sum := sha256.Sum256([]byte(pack))
+	digest := "sha256:" + hex.EncodeToString(sum[:])
+
+	objs, err := fetchSkillPackManifest(srv.URL+"/pack.yaml", digest, scheme)
+	if err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	if len(objs) != 1 || objs[0].obj.GetName() != "k8s-ops" {
+		t.Fatalf("unexpected objects %v", objs)
+	}
+
+	bad := "sha256:" + strings.Repeat("0", 64)
+	if _, err := fetchSkillPackManifest(srv.URL+"/pack.yaml", bad, scheme); err == nil || !strings.Contains(err.Error(), "digest mismatch") {
+		t.Errorf("digest mismatch error = %v", err)
+	}
+	if _, err := fetchSkillPackManifest(srv.URL+"/policy.yaml", "", scheme); err == nil || !strings.Contains(err.Error(), "expected only SkillPack") {
+		t.Errorf("non-SkillPack error = %v", err)
+	}
+	if _, err := fetchSkillPackManifest(srv.URL+"/missing.yaml", "", scheme); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("missing manifest error = %v", err)
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	sympoziumv1alpha1 "github.com/alexsjones/sympozium/api/v1alpha1"
+)
+
+// skillPackUsage maps each SkillPack name to the SympoziumInstances in
+// namespace whose spec.skills reference it, sorted. ConfigMap references
+// are recorded under their ConfigMap name, so a pack is also found through
+// the ConfigMap the controller generates for it.
+func skillPackUsage(ctx context.Context, c client.Reader, namespace string, limit int64) (map[string][]string, error) {
+	usage := map[string][]string{}
+	err := listPages(ctx, c, limit, func(page *sympoziumv1alpha1.SympoziumInstanceList) error {
+		for _, inst := range page.Items {
+			seen := map[string]bool{}
+			for _, ref := range inst.Spec.Skills {
+				for _, name := range []string{ref.SkillPackRef, ref.ConfigMapRef} {
+					if name != "" && !seen[name] {
+						seen[name] = true
+						usage[name] = append(usage[name], inst.Name)
+					}
+				}
+			}
+		}
+		return nil
+	}, client.InNamespace(namespace))
+	if err != nil {
+		return nil, fmt.Errorf("listing SympoziumInstances: %w", err)
+	}
+	for _, names := range usage {
+		slices.Sort(names)
+	}
+	return usage, nil
+}
+
+// skillPackUsers returns the instances using sk, by name or through its
+// ConfigMap.
+func skillPackUsers(usage map[string][]string, sk *sympoziumv1alpha1.SkillPack) []string {
+	users := usage[sk.Name]
+	if cm := sk.Status.ConfigMapName; cm != "" && cm != sk.Name {
+		users = slices.Concat(users, usage[cm])
+		slices.Sort(users)
+		users = slices.Compact(users)
+	}
+	return users
+}
+
+// skillPackUsageColumns returns skillPackColumns with a USED BY column: the
+// number of instances using each pack, followed by their names with -o
+// wide.
+func skillPackUsageColumns(usage map[string][]string) []tableColumn[sympoziumv1alpha1.SkillPack] {
+	columns := append([]tableColumn[sympoziumv1alpha1.SkillPack]{}, skillPackColumns...)
+	used := tableColumn[sympoziumv1alpha1.SkillPack]{header: "USED BY", value: func(sk *sympoziumv1alpha1.SkillPack, wide bool) string {
+		users := skillPackUsers(usage, sk)
+		if wide && len(users) > 0 {
+			return fmt.Sprintf("%d (%s)", len(users), strings.Join(users, ","))
+		}
+		return fmt.Sprint(len(users))
+	}}
+	// Before AGE, the last column.
+	return append(columns[:len(columns)-1], used, columns[len(columns)-1])
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sympoziumv1alpha1 "github.com/alexsjones/sympozium/api/v1alpha1"
+)
+
+func usageTestInstance(name, namespace string, skills ...sympoziumv1alpha1.SkillRef) *sympoziumv1alpha1.SympoziumInstance {
+	return &sympoziumv1alpha1.SympoziumInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec:       sympoziumv1alpha1.SympoziumInstanceSpec{Skills: skills},
+	}
+}
+
+func TestSkillPackUsage(t *testing.T) {
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(
+		usageTestInstance("b", "default", sympoziumv1alpha1.SkillRef{SkillPackRef: "ops"}),
+		usageTestInstance("a", "default", sympoziumv1alpha1.SkillRef{SkillPackRef: "ops"}, sympoziumv1alpha1.SkillRef{SkillPackRef: "ops"}),
+		usageTestInstance("c", "default", sympoziumv1alpha1.SkillRef{ConfigMapRef: "skillpack-ops"}),
+		usageTestInstance("other", "elsewhere", sympoziumv1alpha1.SkillRef{SkillPackRef: "ops"}),
+	).Build()
+	usage, err := skillPackUsage(context.Background(), c, "default", defaultPageLimit)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ops := testSkillPack()
+	ops.Status.ConfigMapName = "skillpack-ops"
+	unused := testSkillPack()
+	unused.Name = "unused"
+	if got := strings.Join(skillPackUsers(usage, ops), ","); got != "a,b,c" {
+		t.Errorf("ops users = %q, want a,b,c", got)
+	}
+
+	packs := []sympoziumv1alpha1.SkillPack{*ops, *unused}
+	var narrow, wide bytes.Buffer
+	if err := printTable(&narrow, skillPackUsageColumns(usage), packs, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := printTable(&wide, skillPackUsageColumns(usage), packs, true); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(narrow.String(), "\n")
+	if fields := strings.Fields(lines[0]); strings.Join(fields, " ") != "NAME SKILLS CONFIGMAP USED BY AGE" {
+		t.Errorf("header = %q", lines[0])
+	}
+	if fields := strings.Fields(lines[1]); len(fields) != 5 || fields[3] != "3" {
+		t.Errorf("ops row = %q", lines[1])
+	}
+	if !strings.Contains(wide.String(), "3 (a,b,c)") || !strings.Contains(strings.Split(wide.String(), "\n")[2], " 0 ") {
+		t.Errorf("wide table = %q", wide.String())
+	}
+	if len(skillPackColumns) != 4 {
+		t.Errorf("skillPackUsageColumns changed skillPackColumns")
+	}
+}
@@ -0,0 +1,345 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	sympoziumv1alpha1 "github.com/alexsjones/sympozium/api/v1alpha1"
+)
+
+// maxConfigMapBytes is the size limit Kubernetes enforces on a ConfigMap's
+// data; the controller renders every skill of a pack into one ConfigMap.
+const maxConfigMapBytes = 1 << 20
+
+// agentToolNames are the tools the agent runner provides (see
+// cmd/agent-runner/tools.go), which skills may list in requires.tools.
+var agentToolNames = map[string]bool{
+	"execute_command":      true,
+	"read_file":            true,
+	"write_file":           true,
+	"list_directory":       true,
+	"send_channel_message": true,
+	"fetch_url":            true,
+	"schedule_task":        true,
+}
+
+// skillFields are the fields a skill entry may set.
+var skillFields = map[string]bool{"name": true, "description": true, "requires": true, "content": true}
+
+// skillIssue is one problem found in a SkillPack manifest.
+type skillIssue struct {
+	warning bool
+	line    int
+	field   string
+	message string
+}
+
+func (i skillIssue) format(source string) string {
+	severity := "error"
+	if i.warning {
+		severity = "warning"
+	}
+	loc := source
+	if i.line > 0 {
+		loc = fmt.Sprintf("%s:%d", source, i.line)
+	}
+	if i.field != "" {
+		return fmt.Sprintf("%s: %s: %s: %s", loc, severity, i.field, i.message)
+	}
+	return fmt.Sprintf("%s: %s: %s", loc, severity, i.message)
+}
+
+func newSkillsValidateCmd() *cobra.Command {
+	var files []string
+	cmd := &cobra.Command{
+		Use:   "validate -f FILE",
+		Short: "Check SkillPack manifests for problems before applying them",
+		Long: `Check SkillPack manifests without contacting the cluster: duplicate or
+missing skill names, empty skill content, unknown fields, packs whose
+generated ConfigMap would exceed the 1 MiB limit, and invalid references
+(unknown agent tools, malformed quantities, incomplete sidecars and RBAC
+rules).
+
+Each problem is reported as file:line: severity: field: message. The command
+exits non-zero when any error is found; warnings alone do not fail it.`,
+		Example: `  sympozium skills validate -f skillpack.yaml
+  cat skillpack.yaml | sympozium skills validate -f -`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(files) == 0 {
+				return fmt.Errorf("at least one -f/--filename is required")
+			}
+			out := cmd.OutOrStdout()
+			var failed []string
+			for _, f := range files {
+				var data []byte
+				var err error
+				source := f
+				if f == "-" {
+					source = "stdin"
+					data, err = io.ReadAll(cmd.InOrStdin())
+				} else {
+					data, err = os.ReadFile(f)
+				}
+				if err != nil {
+					return fmt.Errorf("reading %s: %w", f, err)
+				}
+				if !reportSkillPackIssues(out, source, data) {
+					failed = append(failed, source)
+				}
+			}
+			if len(failed) > 0 {
+				return fmt.Errorf("validation failed: %s", strings.Join(failed, ", "))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringSliceVarP(&files, "filename", "f", nil, "SkillPack manifest to validate, or - for stdin")
+	return cmd
+}
+
+// reportSkillPackIssues validates data, writes its issues and a summary to
+// w, and reports whether it is free of errors.
+func reportSkillPackIssues(w io.Writer, source string, data []byte) bool {
+	issues, packs, skills := validateSkillPacks(data)
+	errs, warnings := 0, 0
+	for _, i := range issues {
+		fmt.Fprintln(w, i.format(source))
+		if i.warning {
+			warnings++
+		} else {
+			errs++
+		}
+	}
+	switch {
+	case errs > 0:
+		fmt.Fprintf(w, "%s: %d error(s), %d warning(s)\n", source, errs, warnings)
+	case packs == 0:
+		fmt.Fprintf(w, "%s: no SkillPacks found\n", source)
+		return false
+	default:
+		fmt.Fprintf(w, "%s: %d SkillPack(s) with %d skill(s) valid, %d warning(s)\n", source, packs, skills, warnings)
+	}
+	return errs == 0
+}
+
+// validateSkillPacks checks every SkillPack document in data and returns the
+// issues found along with the number of packs and skills checked.
+func validateSkillPacks(data []byte) (issues []skillIssue, packs, skills int) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc yaml.Node
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			// The decoder cannot continue past a syntax error.
+			return append(issues, skillIssue{message: err.Error()}), packs, skills
+		}
+		if len(doc.Content) == 0 {
+			continue
+		}
+		root := doc.Content[0]
+		if root.Kind != yaml.MappingNode {
+			issues = append(issues, skillIssue{line: root.Line, message: "document is not a mapping"})
+			continue
+		}
+		if kind := scalarValue(root, "kind"); kind != "SkillPack" {
+			_, v := mappingValue(root, "kind")
+			issues = append(issues, skillIssue{warning: true, line: nodeLine(v, root), field: "kind",
+				message: fmt.Sprintf("%q is not a SkillPack; skipped", kind)})
+			continue
+		}
+		packs++
+		n, packIssues := validateSkillPack(root)
+		skills += n
+		issues = append(issues, packIssues...)
+	}
+	return issues, packs, skills
+}
+
+// validateSkillPack checks one SkillPack document and returns its number of
+// skills and the issues found.
+func validateSkillPack(root *yaml.Node) (int, []skillIssue) {
+	var issues []skillIssue
+	add := func(warning bool, n *yaml.Node, field, format string, args ...any) {
+		issues = append(issues, skillIssue{warning: warning, line: nodeLine(n, root), field: field, message: fmt.Sprintf(format, args...)})
+	}
+
+	if _, v := mappingValue(root, "apiVersion"); v == nil {
+		add(false, root, "apiVersion", "is required")
+	} else if v.Value != sympoziumv1alpha1.GroupVersion.String() {
+		add(false, v, "apiVersion", "is %q, want %q", v.Value, sympoziumv1alpha1.GroupVersion.String())
+	}
+
+	_, meta := mappingValue(root, "metadata")
+	_, name := mappingValue(meta, "name")
+	switch {
+	case name == nil || name.Value == "":
+		add(false, meta, "metadata.name", "is required")
+	default:
+		// The generated ConfigMap takes the pack's name.
+		for _, msg := range validation.IsDNS1123Subdomain(name.Value) {
+			add(false, name, "metadata.name", "%s", msg)
+		}
+	}
+
+	_, spec := mappingValue(root, "spec")
+	_, skillsNode := mappingValue(spec, "skills")
+	if skillsNode == nil || skillsNode.Kind != yaml.SequenceNode || len(skillsNode.Content) == 0 {
+		add(false, skillsNode, "spec.skills", "at least one skill is required")
+		return 0, issues
+	}
+
+	firstSeen := map[string]int{}
+	dataBytes := 0
+	hasSidecar := false
+	if _, sc := mappingValue(spec, "sidecar"); sc != nil {
+		hasSidecar = true
+	}
+	for i, item := range skillsNode.Content {
+		field := fmt.Sprintf("spec.skills[%d]", i)
+		if item.Kind != yaml.MappingNode {
+			add(false, item, field, "must be a mapping")
+			continue
+		}
+		for j := 0; j+1 < len(item.Content); j += 2 {
+			if k := item.Content[j]; !skillFields[k.Value] {
+				add(false, k, field+"."+k.Value, "unknown field")
+			}
+		}
+
+		_, n := mappingValue(item, "name")
+		skillName := ""
+		switch {
+		case n == nil || n.Value == "":
+			add(false, item, field+".name", "is required")
+		default:
+			skillName = n.Value
+			for _, msg := range validation.IsConfigMapKey(skillName + ".md") {
+				add(false, n, field+".name", "%q cannot be used as a ConfigMap key: %s", skillName, msg)
+			}
+			if line, dup := firstSeen[skillName]; dup {
+				add(false, n, field+".name", "duplicate skill name %q (first defined on line %d)", skillName, line)
+			} else {
+				firstSeen[skillName] = n.Line
+			}
+		}
+
+		_, content := mappingValue(item, "content")
+		if content == nil || strings.TrimSpace(content.Value) == "" {
+			add(false, item, field+".content", "is required")
+		}
+		description := scalarValue(item, "description")
+		rendered := ""
+		if content != nil {
+			rendered = content.Value
+		}
+		if description != "" {
+			// Mirrors the header the SkillPack controller prepends.
+			rendered = fmt.Sprintf("# %s\n\n> %s\n\n%s", skillName, description, rendered)
+		}
+		dataBytes += len(skillName) + len(".md") + len(rendered)
+
+		_, requires := mappingValue(item, "requires")
+		_, tools := mappingValue(requires, "tools")
+		for _, t := range sequenceValues(tools) {
+			if !agentToolNames[t.Value] {
+				add(true, t, field+".requires.tools", "unknown agent tool %q", t.Value)
+			}
+		}
+		if _, bins := mappingValue(requires, "bins"); len(sequenceValues(bins)) > 0 && !hasSidecar {
+			add(true, bins, field+".requires.bins", "the pack has no sidecar to provide these binaries")
+		}
+	}
+	if dataBytes > maxConfigMapBytes {
+		add(false, skillsNode, "spec.skills",
+			"generated ConfigMap would hold %d bytes of skill content, over the %d byte (1 MiB) limit", dataBytes, maxConfigMapBytes)
+	}
+
+	_, rr := mappingValue(spec, "runtimeRequirements")
+	for _, f := range []string{"minMemory", "minCPU"} {
+		validateQuantity(rr, f, "spec.runtimeRequirements."+f, add)
+	}
+
+	if _, sc := mappingValue(spec, "sidecar"); sc != nil {
+		if scalarValue(sc, "image") == "" {
+			add(false, sc, "spec.sidecar.image", "is required when a sidecar is set")
+		}
+		_, res := mappingValue(sc, "resources")
+		for _, f := range []string{"cpu", "memory"} {
+			validateQuantity(res, f, "spec.sidecar.resources."+f, add)
+		}
+		for _, key := range []string{"rbac", "clusterRBAC"} {
+			_, rules := mappingValue(sc, key)
+			for i, rule := range sequenceValues(rules) {
+				for _, f := range []string{"resources", "verbs"} {
+					if _, v := mappingValue(rule, f); len(sequenceValues(v)) == 0 {
+						add(false, rule, fmt.Sprintf("spec.sidecar.%s[%d].%s", key, i, f), "must list at least one entry")
+					}
+				}
+			}
+		}
+	}
+
+	return len(skillsNode.Content), issues
+}
+
+// validateQuantity reports field of n when it is set but is not a valid
+// resource quantity such as 256Mi or 100m.
+func validateQuantity(n *yaml.Node, field, path string, add func(bool, *yaml.Node, string, string, ...any)) {
+	_, v := mappingValue(n, field)
+	if v == nil || v.Value == "" {
+		return
+	}
+	if _, err := resource.ParseQuantity(v.Value); err != nil {
+		add(false, v, path, "%q is not a valid quantity", v.Value)
+	}
+}
+
+// mappingValue returns the key and value nodes for key in mapping n, or nils
+// when n is not a mapping or has no such key.
+func mappingValue(n *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i], n.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+// scalarValue returns the scalar value of key in mapping n, or "".
+func scalarValue(n *yaml.Node, key string) string {
+	if _, v := mappingValue(n, key); v != nil && v.Kind == yaml.ScalarNode {
+		return v.Value
+	}
+	return ""
+}
+
+// sequenceValues returns the items of sequence n, or nil.
+func sequenceValues(n *yaml.Node) []*yaml.Node {
+	if n == nil || n.Kind != yaml.SequenceNode {
+		return nil
+	}
+	return n.Content
+}
+
+// nodeLine returns n's line, falling back to the document root's.
+func nodeLine(n, root *yaml.Node) int {
+	if n != nil {
+		return n.Line
+	}
+	return root.Line
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const validSkillPack = `apiVersion: sympozium.ai/v1alpha1
+kind: SkillPack
+metadata:
+  name: k8s-ops
+spec:
+  skills:
+    - name: triage
+      description: Triage failing pods
+      requires:
+        tools: [execute_command]
+        bins: [kubectl]
+      content: |
+        Run kubectl get pods.
+  sidecar:
+    image: ghcr.io/example/kubectl:latest
+    resources:
+      memory: 128Mi
+    rbac:
+      - apiGroups: [""]
+        resources: [pods]
+        verbs: [get, list]
+`
+
+func TestValidateSkillPacks_Valid(t *testing.T) {
+	issues, packs, skills := validateSkillPacks([]byte(validSkillPack))
+	if len(issues) != 0 || packs != 1 || skills != 1 {
+		t.Fatalf("issues=%v packs=%d skills=%d", issues, packs, skills)
+	}
+}
+
+func TestValidateSkillPacks_Issues(t *testing.T) {
+	manifest := `apiVersion: sympozium.ai/v1alpha1
+kind: SkillPack
+metadata:
+  name: Bad_Name
+spec:
+  skills:
+    - name: triage
+      content: one
+    - name: triage
+      content: two
+    - description: no name
+      content: three
+    - name: empty
+      content: "  "
+      extra: true
+    - name: tools
+      requires:
+        tools: [kubectl_apply]
+        bins: [helm]
+      content: four
+  runtimeRequirements:
+    minMemory: lots
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: other
+`
+	issues, packs, _ := validateSkillPacks([]byte(manifest))
+	if packs != 1 {
+		t.Errorf("packs = %d, want 1", packs)
+	}
+	var got []string
+	for _, i := range issues {
+		got = append(got, i.format("pack.yaml"))
+	}
+	out := strings.Join(got, "\n")
+	for _, want := range []string{
+		"pack.yaml:4: error: metadata.name:",
+		`pack.yaml:9: error: spec.skills[1].name: duplicate skill name "triage" (first defined on line 7)`,
+		"pack.yaml:11: error: spec.skills[2].name: is required",
+		"pack.yaml:15: error: spec.skills[3].extra: unknown field",
+		"error: spec.skills[3].content: is required",
+		`pack.yaml:18: warning: spec.skills[4].requires.tools: unknown agent tool "kubectl_apply"`,
+		"pack.yaml:19: warning: spec.skills[4].requires.bins: the pack has no sidecar",
+		`pack.yaml:22: error: spec.runtimeRequirements.minMemory: "lots" is not a valid quantity`,
+		`pack.yaml:25: warning: kind: "ConfigMap" is not a SkillPack; skipped`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestValidateSkillPacks_ConfigMapSize(t *testing.T) {
+	big := strings.Repeat("x", 600*1024)
+	manifest := `apiVersion: sympozium.ai/v1alpha1
+kind: SkillPack
+metadata:
+  name: huge
+spec:
+  skills:
+    - name: a
+      content: ` + big + `
+    - name: b
+      content: ` + big + `
+`
+	issues, _, _ := validateSkillPacks([]byte(manifest))
+	if len(issues) != 1 || issues[0].field != "spec.skills" || !strings.Contains(issues[0].message, "1 MiB") {
+		t.Errorf("issues = %+v, want one size error", issues)
+	}
+}
+
+func TestReportSkillPackIssues(t *testing.T) {
+	var buf bytes.Buffer
+	if !reportSkillPackIssues(&buf, "ok.yaml", []byte(validSkillPack)) {
+		t.Errorf("valid pack reported as failing:\n%s", buf.String())
+	}
+	if !strings.Contains(buf.String(), "1 SkillPack(s) with 1 skill(s) valid") {
+		t.Errorf("summary = %q", buf.String())
+	}
+
+	buf.Reset()
+	if reportSkillPackIssues(&buf, "bad.yaml", []byte("kind: SkillPack\nspec: [")) {
+		t.Error("syntax error should fail validation")
+	}
+	buf.Reset()
+	if reportSkillPackIssues(&buf, "empty.yaml", []byte("")) {
+		t.Error("a file without SkillPacks should fail validation")
+	}
+}
+
+func TestSkillsValidateCmd(t *testing.T) {
+	cmd := newSkillsValidateCmd()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetIn(strings.NewReader(strings.Replace(validSkillPack, "execute_command", "nope", 1)))
+	cmd.SetArgs([]string{"-f", "-"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("warnings alone should not fail: %v\n%s", err, out.String())
+	}
+	if !strings.Contains(out.String(), "stdin:10: warning:") {
+		t.Errorf("output = %q", out.String())
+	}
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	sympoziumv1alpha1 "github.com/alexsjones/sympozium/api/v1alpha1"
+)
+
+// ── instances template ──────────────────────────────────────────────────────
+
+// redactedValue replaces every Secret value in templated output.
+const redactedValue = "<REDACTED>"
+
+// serverSetMetadata lists metadata fields owned by the API server that must
+// not be carried into a template.
+var serverSetMetadata = []string{
+	"uid", "resourceVersion", "generation", "creationTimestamp", "deletionTimestamp",
+	"deletionGracePeriodSeconds", "managedFields", "ownerReferences", "finalizers", "selfLink",
+}
+
+func newInstancesTemplateCmd() *cobra.Command {
+	var (
+		newName      string
+		newNamespace string
+		withDeps     bool
+	)
+	cmd := &cobra.Command{
+		Use:   "template [name]",
+		Short: "Print a SympoziumInstance as clean YAML for re-use",
+		Long: `Print a SympoziumInstance with its status and server-set metadata removed,
+ready for 'sympozium apply' in another namespace or cluster. The source is
+read from --namespace; --target-namespace and --name rewrite the output.
+
+With --with-dependencies the referenced SympoziumPolicy and SkillPacks are
+included, along with a commented-out stub of each provider Secret. Secret
+values are never printed; every key is shown as <REDACTED>.`,
+		Example: `  sympozium instances template my-agent --target-namespace staging > my-agent.yaml
+  sympozium instances template my-agent --name my-agent-2 --with-dependencies`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+			var inst sympoziumv1alpha1.SympoziumInstance
+			if err := k8sClient.Get(ctx, types.NamespacedName{Name: args[0], Namespace: namespace}, &inst); err != nil {
+				return err
+			}
+			targetNS := inst.Namespace
+			if newNamespace != "" {
+				targetNS = newNamespace
+			}
+			objs := []runtime.Object{}
+			var secrets []*corev1.Secret
+			if withDeps {
+				deps, secs, err := instanceDependencies(ctx, &inst)
+				if err != nil {
+					return err
+				}
+				objs = append(objs, deps...)
+				secrets = secs
+			}
+			if newName != "" {
+				inst.Name = newName
+			}
+			objs = append(objs, &inst)
+			return writeInstanceTemplate(os.Stdout, objs, secrets, targetNS)
+		},
+	}
+	cmd.Flags().StringVar(&newName, "name", "", "Name for the templated instance")
+	cmd.Flags().StringVar(&newNamespace, "target-namespace", "", "Namespace for the templated resources (default: the source namespace)")
+	cmd.Flags().BoolVar(&withDeps, "with-dependencies", false, "Include the referenced policy, SkillPacks and a redacted provider Secret stub")
+	return cmd
+}
+
+// instanceDependencies fetches the SympoziumPolicy, SkillPacks and provider
+// Secrets an instance references. References that cannot be found in the
+// instance's namespace (e.g. built-in SkillPacks) are skipped with a note on
+// stderr.
+func instanceDependencies(ctx context.Context, inst *sympoziumv1alpha1.SympoziumInstance) ([]runtime.Object, []*corev1.Secret, error) {
+	var objs []runtime.Object
+	get := func(kind, name string, obj client.Object) (bool, error) {
+		err := k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: inst.Namespace}, obj)
+		if apierrors.IsNotFound(err) {
+			fmt.Fprintf(os.Stderr, "Note: %s %q not found in namespace %s; not included\n", kind, name, inst.Namespace)
+			return false, nil
+		}
+		return err == nil, err
+	}
+
+	if inst.Spec.PolicyRef != "" {
+		pol := &sympoziumv1alpha1.SympoziumPolicy{}
+		found, err := get("SympoziumPolicy", inst.Spec.PolicyRef, pol)
+		if err != nil {
+			return nil, nil, err
+		}
+		if found {
+			objs = append(objs, pol)
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, ref := range inst.Spec.Skills {
+		// Skill references may name the SkillPack's ConfigMap
+		// ("skillpack-<name>") rather than the SkillPack itself.
+		name := strings.TrimPrefix(ref.SkillPackRef, "skillpack-")
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		sp := &sympoziumv1alpha1.SkillPack{}
+		found, err := get("SkillPack", name, sp)
+		if err != nil {
+			return nil, nil, err
+		}
+		if found {
+			objs = append(objs, sp)
+		}
+	}
+
+	var secrets []*corev1.Secret
+	for _, ref := range inst.Spec.AuthRefs {
+		if ref.Secret == "" {
+			continue
+		}
+		sec := &corev1.Secret{}
+		found, err := get("Secret", ref.Secret, sec)
+		if err != nil {
+			return nil, nil, err
+		}
+		if found {
+			secrets = append(secrets, sec)
+		}
+	}
+	return objs, secrets, nil
+}
+
+// writeInstanceTemplate prints objs as a multi-document YAML stream moved to
+// namespace, preceded by commented-out, redacted stubs of secrets. Secret
+// stubs are commented so that applying the template never overwrites a real
+// Secret with placeholders.
+func writeInstanceTemplate(w io.Writer, objs []runtime.Object, secrets []*corev1.Secret, namespace string) error {
+	for _, sec := range secrets {
+		data, err := yaml.Marshal(redactedSecretStub(sec, namespace))
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "# Provider Secret %q: fill in the values and create it with kubectl.\n", sec.Name)
+		for _, line := range strings.Split(strings.TrimRight(string(data), "\n"), "\n") {
+			fmt.Fprintf(w, "# %s\n", line)
+		}
+	}
+
+	for _, obj := range objs {
+		u, err := templateObject(obj, namespace)
+		if err != nil {
+			return err
+		}
+		data, err := yaml.Marshal(u)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "---\n%s", data)
+	}
+	return nil
+}
+
+// templateObject converts obj to a map with apiVersion/kind set, status and
+// server-set metadata removed, and the namespace replaced.
+func templateObject(obj runtime.Object, namespace string) (map[string]interface{}, error) {
+	setTypeMeta(obj)
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	delete(u, "status")
+	meta, _ := u["metadata"].(map[string]interface{})
+	if meta == nil {
+		meta = map[string]interface{}{}
+		u["metadata"] = meta
+	}
+	for _, f := range serverSetMetadata {
+		delete(meta, f)
+	}
+	if ann, ok := meta["annotations"].(map[string]interface{}); ok {
+		delete(ann, corev1.LastAppliedConfigAnnotation)
+		if len(ann) == 0 {
+			delete(meta, "annotations")
+		}
+	}
+	meta["namespace"] = namespace
+	return u, nil
+}
+
+// redactedSecretStub returns a Secret manifest with sec's name, type and
+// keys but every value replaced by redactedValue.
+func redactedSecretStub(sec *corev1.Secret, namespace string) map[string]interface{} {
+	keys := make([]string, 0, len(sec.Data)+len(sec.StringData))
+	for k := range sec.Data {
+		keys = append(keys, k)
+	}
+	for k := range sec.StringData {
+		if _, dup := sec.Data[k]; !dup {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	stringData := map[string]interface{}{}
+	for _, k := range keys {
+		stringData[k] = redactedValue
+	}
+	stub := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata": map[string]interface{}{
+			"name":      sec.Name,
+			"namespace": namespace,
+		},
+		"stringData": stringData,
+	}
+	if sec.Type != "" {
+		stub["type"] = string(sec.Type)
+	}
+	return stub
+}
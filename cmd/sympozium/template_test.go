@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	sympoziumv1alpha1 "github.com/alexsjones/sympozium/api/v1alpha1"
+)
+
+func TestWriteInstanceTemplate(t *testing.T) {
+	inst := &sympoziumv1alpha1.SympoziumInstance{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "alpha",
+			Namespace:         "default",
+			UID:               "1234",
+			ResourceVersion:   "42",
+			Generation:        3,
+			CreationTimestamp: metav1.Now(),
+			Finalizers:        []string{"sympozium.ai/finalizer"},
+			ManagedFields:     []metav1.ManagedFieldsEntry{{Manager: "kubectl"}},
+			Labels:            map[string]string{"team": "platform"},
+			Annotations:       map[string]string{corev1.LastAppliedConfigAnnotation: "{}"},
+		},
+		Spec: sympoziumv1alpha1.SympoziumInstanceSpec{
+			PolicyRef: "strict",
+			AuthRefs:  []sympoziumv1alpha1.SecretRef{{Provider: "openai", Secret: "alpha-openai-key"}},
+		},
+		Status: sympoziumv1alpha1.SympoziumInstanceStatus{Phase: "Running", ActiveAgentPods: 2},
+	}
+	pol := &sympoziumv1alpha1.SympoziumPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "strict", Namespace: "default", UID: "5678"},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "alpha-openai-key", Namespace: "default"},
+		Type:       corev1.SecretTypeOpaque,
+		Data:       map[string][]byte{"OPENAI_API_KEY": []byte("sk-supersecret")},
+	}
+
+	inst.Name = "beta"
+	var out strings.Builder
+	if err := writeInstanceTemplate(&out, []runtime.Object{pol, inst}, []*corev1.Secret{secret}, "staging"); err != nil {
+		t.Fatalf("writeInstanceTemplate: %v", err)
+	}
+	got := out.String()
+
+	if strings.Contains(got, "sk-supersecret") || strings.Contains(got, base64.StdEncoding.EncodeToString([]byte("sk-supersecret"))) {
+		t.Fatalf("secret value leaked into template:\n%s", got)
+	}
+	if !strings.Contains(got, "#   OPENAI_API_KEY: "+redactedValue) {
+		t.Errorf("redacted secret key missing:\n%s", got)
+	}
+	for _, line := range strings.Split(got, "\n") {
+		if strings.Contains(line, "kind: Secret") && !strings.HasPrefix(line, "#") {
+			t.Errorf("secret stub must be commented out: %q", line)
+		}
+	}
+	for _, unwanted := range []string{"status:", "uid:", "resourceVersion:", "generation:", "creationTimestamp:", "managedFields:", "finalizers:", "last-applied-configuration", "namespace: default"} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("template contains %q:\n%s", unwanted, got)
+		}
+	}
+
+	objs, err := decodeSympoziumDocuments("template", []byte(got), testScheme(t))
+	if err != nil {
+		t.Fatalf("template is not accepted by apply: %v\n%s", err, got)
+	}
+	if len(objs) != 2 {
+		t.Fatalf("decoded %d objects, want 2", len(objs))
+	}
+	if k := objs[0].obj.GetKind(); k != "SympoziumPolicy" {
+		t.Errorf("first object = %s, want the policy before the instance", k)
+	}
+	o := objs[1].obj
+	if o.GetName() != "beta" || o.GetNamespace() != "staging" || o.GetLabels()["team"] != "platform" {
+		t.Errorf("instance = %s/%s labels=%v, want staging/beta with labels kept", o.GetNamespace(), o.GetName(), o.GetLabels())
+	}
+}
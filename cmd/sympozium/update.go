@@ -0,0 +1,249 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ── Update checks (version --check and the background hint) ─────────────────
+
+const (
+	// updateCheckInterval rate-limits the background update check.
+	updateCheckInterval = 24 * time.Hour
+
+	// updateHintWait bounds how long a finished command waits for the
+	// background check before exiting without a hint.
+	updateHintWait = 1500 * time.Millisecond
+)
+
+// updateCheckCache is persisted in the user config dir so the background
+// check contacts GitHub at most once per updateCheckInterval.
+type updateCheckCache struct {
+	CheckedAt time.Time `json:"checkedAt"`
+	Latest    string    `json:"latest,omitempty"`
+}
+
+// releaseURL returns the GitHub release page for tag.
+func releaseURL(tag string) string {
+	return fmt.Sprintf("https://github.com/%s/releases/tag/%s", ghRepo, tag)
+}
+
+// runVersionCheck prints whether a newer release than the running build
+// exists. With failIfOutdated it returns an error when one does.
+func runVersionCheck(failIfOutdated bool) error {
+	latest, err := resolveLatestTag()
+	if err != nil {
+		return err
+	}
+	cmp, ok := compareVersions(version, latest)
+	switch {
+	case !ok:
+		fmt.Printf("Latest release is %s (this is a %s build; cannot compare).\n", latest, version)
+		fmt.Printf("  %s\n", releaseURL(latest))
+	case cmp < 0:
+		fmt.Printf("A newer version is available: %s (you have %s)\n", latest, version)
+		fmt.Printf("  %s\n", releaseURL(latest))
+		if failIfOutdated {
+			return fmt.Errorf("sympozium %s is older than the latest release %s", version, latest)
+		}
+	default:
+		fmt.Println("You are running the latest version.")
+	}
+	return nil
+}
+
+// startBackgroundUpdateCheck starts the opt-in background update check and
+// returns a channel that yields a one-line hint (or "") when it completes.
+// It returns nil when the check is disabled: SYMPOZIUM_UPDATE_CHECK is not
+// "true", SYMPOZIUM_NO_UPDATE_CHECK is set, stderr is not a terminal, or the
+// CLI is a development build.
+func startBackgroundUpdateCheck() <-chan string {
+	if os.Getenv("SYMPOZIUM_NO_UPDATE_CHECK") != "" || os.Getenv("SYMPOZIUM_UPDATE_CHECK") != "true" {
+		return nil
+	}
+	if _, ok := compareVersions(version, version); !ok {
+		return nil
+	}
+	if fi, err := os.Stderr.Stat(); err != nil || fi.Mode()&os.ModeCharDevice == 0 {
+		return nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return nil
+	}
+	cachePath := filepath.Join(dir, "sympozium", "update-check.json")
+
+	ch := make(chan string, 1)
+	go func() {
+		ch <- updateHint(cachePath, time.Now(), version, resolveLatestTag)
+	}()
+	return ch
+}
+
+// printUpdateHint waits briefly for the background check and prints its hint
+// to stderr. A nil channel is a no-op.
+func printUpdateHint(ch <-chan string) {
+	if ch == nil {
+		return
+	}
+	select {
+	case hint := <-ch:
+		if hint != "" {
+			fmt.Fprintln(os.Stderr, hint)
+		}
+	case <-time.After(updateHintWait):
+	}
+}
+
+// updateHint returns a hint line when a release newer than current exists.
+// The latest tag is read from the cache at cachePath while it is fresh;
+// otherwise fetch is called and the result cached. Failed fetches are also
+// recorded so an unreachable GitHub is not retried on every command.
+func updateHint(cachePath string, now time.Time, current string, fetch func() (string, error)) string {
+	var cache updateCheckCache
+	if data, err := os.ReadFile(cachePath); err == nil {
+		_ = json.Unmarshal(data, &cache)
+	}
+
+	if cache.CheckedAt.IsZero() || now.Sub(cache.CheckedAt) >= updateCheckInterval {
+		cache.CheckedAt = now
+		if latest, err := fetch(); err == nil {
+			cache.Latest = latest
+		}
+		if data, err := json.Marshal(cache); err == nil {
+			if err := os.MkdirAll(filepath.Dir(cachePath), 0o755); err == nil {
+				_ = os.WriteFile(cachePath, data, 0o644)
+			}
+		}
+	}
+
+	if cache.Latest == "" {
+		return ""
+	}
+	if cmp, ok := compareVersions(current, cache.Latest); !ok || cmp >= 0 {
+		return ""
+	}
+	return fmt.Sprintf("A new sympozium release is available: %s → %s (%s)", current, cache.Latest, releaseURL(cache.Latest))
+}
+
+// compareVersions compares two "vMAJOR.MINOR.PATCH[-pre]" versions, returning
+// -1, 0 or 1. A pre-release sorts before its release. ok is false when either
+// version cannot be parsed (e.g. "dev").
+func compareVersions(a, b string) (cmp int, ok bool) {
+	pa, preA, okA := parseVersion(a)
+	pb, preB, okB := parseVersion(b)
+	if !okA || !okB {
+		return 0, false
+	}
+	for i := range pa {
+		if pa[i] != pb[i] {
+			if pa[i] < pb[i] {
+				return -1, true
+			}
+			return 1, true
+		}
+	}
+	switch {
+	case preA == preB:
+		return 0, true
+	case preA == "":
+		return 1, true
+	case preB == "":
+		return -1, true
+	case preA < preB:
+		return -1, true
+	default:
+		return 1, true
+	}
+}
+
+// parseVersion splits a version into its numeric parts and pre-release
+// suffix. Build metadata is ignored.
+func parseVersion(v string) (nums [3]int, pre string, ok bool) {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	v, _, _ = strings.Cut(v, "+")
+	v, pre, _ = strings.Cut(v, "-")
+	parts := strings.Split(v, ".")
+	if len(parts) != 3 {
+		return nums, "", false
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return nums, "", false
+		}
+		nums[i] = n
+	}
+	return nums, pre, true
+}
+
+// ── Control-plane version skew ──────────────────────────────────────────────
+
+// warnOnVersionSkew prints a warning to stderr when the installed controller
+// differs from the CLI by a major or minor version. Any failure to determine
+// the controller version (no permission, not installed, dev builds) is
+// silently ignored.
+func warnOnVersionSkew() {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var deps appsv1.DeploymentList
+	if err := k8sClient.List(ctx, &deps, client.MatchingLabels{
+		"app.kubernetes.io/name":      "sympozium",
+		"app.kubernetes.io/component": "controller",
+	}); err != nil || len(deps.Items) == 0 {
+		return
+	}
+	if msg := versionSkewWarning(version, controllerVersion(&deps.Items[0])); msg != "" {
+		fmt.Fprintln(os.Stderr, msg)
+	}
+}
+
+// controllerVersion returns the version of a controller Deployment from its
+// app.kubernetes.io/version label, falling back to the manager image tag.
+func controllerVersion(dep *appsv1.Deployment) string {
+	if v := dep.Labels["app.kubernetes.io/version"]; v != "" {
+		return v
+	}
+	containers := dep.Spec.Template.Spec.Containers
+	for _, c := range containers {
+		if c.Name == "manager" {
+			return imageTag(c.Image)
+		}
+	}
+	if len(containers) > 0 {
+		return imageTag(containers[0].Image)
+	}
+	return ""
+}
+
+// imageTag returns the tag of an image reference, ignoring any digest.
+func imageTag(image string) string {
+	image, _, _ = strings.Cut(image, "@")
+	i := strings.LastIndex(image, ":")
+	if i < 0 || strings.Contains(image[i:], "/") {
+		return ""
+	}
+	return image[i+1:]
+}
+
+// versionSkewWarning returns a warning when cli and server differ in major
+// or minor version, or "" when they match or either cannot be parsed.
+func versionSkewWarning(cli, server string) string {
+	c, _, okC := parseVersion(cli)
+	s, _, okS := parseVersion(server)
+	if !okC || !okS || (c[0] == s[0] && c[1] == s[1]) {
+		return ""
+	}
+	return fmt.Sprintf("Warning: sympozium CLI %s does not match the installed control plane %s; "+
+		"some commands may not behave as expected (use --skip-version-check to silence)", cli, server)
+}
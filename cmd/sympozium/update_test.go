@@ -0,0 +1,135 @@
+package main
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b   string
+		want   int
+		wantOK bool
+	}{
+		{"v0.0.30", "v0.0.32", -1, true},
+		{"v0.1.0", "v0.0.99", 1, true},
+		{"1.2.3", "v1.2.3", 0, true},
+		{"v1.10.0", "v1.9.0", 1, true},
+		{"v1.0.0-rc.1", "v1.0.0", -1, true},
+		{"v1.0.0", "v1.0.0-rc.1", 1, true},
+		{"v1.0.0+build.5", "v1.0.0", 0, true},
+		{"dev", "v1.0.0", 0, false},
+		{"v1.0", "v1.0.0", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := compareVersions(tt.a, tt.b)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("compareVersions(%q, %q) = (%d, %v), want (%d, %v)", tt.a, tt.b, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestUpdateHint_CachesForInterval(t *testing.T) {
+	cachePath := filepath.Join(t.TempDir(), "sympozium", "update-check.json")
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	calls := 0
+	latest := "v0.0.40"
+	fetch := func() (string, error) {
+		calls++
+		return latest, nil
+	}
+
+	hint := updateHint(cachePath, now, "v0.0.32", fetch)
+	if !strings.Contains(hint, "v0.0.40") || !strings.Contains(hint, releaseURL("v0.0.40")) {
+		t.Errorf("hint = %q, want mention of v0.0.40 and its release URL", hint)
+	}
+
+	// Within the interval the cached tag is used without fetching.
+	latest = "v0.0.41"
+	if hint := updateHint(cachePath, now.Add(23*time.Hour), "v0.0.32", fetch); !strings.Contains(hint, "v0.0.40") {
+		t.Errorf("hint within interval = %q, want cached v0.0.40", hint)
+	}
+	if calls != 1 {
+		t.Errorf("fetch called %d times within interval, want 1", calls)
+	}
+
+	// After the interval the tag is refreshed.
+	if hint := updateHint(cachePath, now.Add(25*time.Hour), "v0.0.32", fetch); !strings.Contains(hint, "v0.0.41") {
+		t.Errorf("hint after interval = %q, want v0.0.41", hint)
+	}
+	if calls != 2 {
+		t.Errorf("fetch called %d times, want 2", calls)
+	}
+}
+
+func TestUpdateHint_NoHint(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+
+	if hint := updateHint(filepath.Join(dir, "a.json"), now, "v0.0.40", func() (string, error) { return "v0.0.40", nil }); hint != "" {
+		t.Errorf("up to date: hint = %q, want empty", hint)
+	}
+
+	failPath := filepath.Join(dir, "b.json")
+	calls := 0
+	failing := func() (string, error) {
+		calls++
+		return "", errors.New("offline")
+	}
+	if hint := updateHint(failPath, now, "v0.0.1", failing); hint != "" {
+		t.Errorf("fetch failure: hint = %q, want empty", hint)
+	}
+	updateHint(failPath, now.Add(time.Hour), "v0.0.1", failing)
+	if calls != 1 {
+		t.Errorf("failed fetch retried within interval: %d calls", calls)
+	}
+}
+
+func TestVersionSkewWarning(t *testing.T) {
+	tests := []struct {
+		cli, server string
+		warn        bool
+	}{
+		{"v0.1.0", "v0.1.7", false},
+		{"v0.2.0", "v0.1.7", true},
+		{"v1.0.0", "v0.0.32", true},
+		{"dev", "v0.1.0", false},
+		{"v0.1.0", "latest", false},
+	}
+	for _, tt := range tests {
+		got := versionSkewWarning(tt.cli, tt.server)
+		if (got != "") != tt.warn {
+			t.Errorf("versionSkewWarning(%q, %q) = %q, want warning=%v", tt.cli, tt.server, got, tt.warn)
+		}
+	}
+}
+
+func TestControllerVersion(t *testing.T) {
+	dep := &appsv1.Deployment{}
+	dep.Spec.Template.Spec.Containers = []corev1.Container{
+		{Name: "sidecar", Image: "busybox:1.36"},
+		{Name: "manager", Image: "registry.local:5000/sympozium/controller:v0.1.3"},
+	}
+	if got := controllerVersion(dep); got != "v0.1.3" {
+		t.Errorf("from image: got %q, want v0.1.3", got)
+	}
+
+	dep.Labels = map[string]string{"app.kubernetes.io/version": "v0.2.0"}
+	if got := controllerVersion(dep); got != "v0.2.0" {
+		t.Errorf("from label: got %q, want v0.2.0", got)
+	}
+
+	if got := imageTag("registry.local:5000/controller"); got != "" {
+		t.Errorf("untagged image with registry port: got %q, want empty", got)
+	}
+	if got := imageTag("ghcr.io/x/controller:v1.0.0@sha256:abc"); got != "v1.0.0" {
+		t.Errorf("digest image: got %q, want v1.0.0", got)
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	sympoziumv1alpha1 "github.com/alexsjones/sympozium/api/v1alpha1"
+)
+
+// ── instances get -w ────────────────────────────────────────────────────────
+
+// instanceWatchPollInterval is how often `instances get -w` re-reads the
+// instance.
+var instanceWatchPollInterval = 2 * time.Second
+
+// instanceWaitCondition is the --for of `instances get`: a phase, or a
+// status condition with the status it must have.
+type instanceWaitCondition struct {
+	Phase     string
+	Condition string
+	Status    metav1.ConditionStatus
+}
+
+// parseInstanceWaitCondition parses phase=<phase> or
+// condition=<type>[=<status>]; the status defaults to True.
+func parseInstanceWaitCondition(s string) (*instanceWaitCondition, error) {
+	kind, rest, _ := strings.Cut(s, "=")
+	switch {
+	case kind == "phase" && rest != "":
+		return &instanceWaitCondition{Phase: rest}, nil
+	case kind == "condition" && rest != "":
+		typ, status, ok := strings.Cut(rest, "=")
+		if !ok {
+			status = string(metav1.ConditionTrue)
+		}
+		if typ == "" || status == "" {
+			break
+		}
+		return &instanceWaitCondition{Condition: typ, Status: metav1.ConditionStatus(status)}, nil
+	}
+	return nil, fmt.Errorf("invalid --for %q: want condition=<type>[=<status>] or phase=<phase>", s)
+}
+
+func (w *instanceWaitCondition) String() string {
+	if w.Phase != "" {
+		return "phase=" + w.Phase
+	}
+	return fmt.Sprintf("condition=%s=%s", w.Condition, w.Status)
+}
+
+// met reports whether inst satisfies the condition. Phases and condition
+// types compare case-insensitively, like kubectl wait. The controller
+// reports an instance's readiness through its phase, so condition=Ready
+// is also met by phase Running when the instance has no Ready condition.
+func (w *instanceWaitCondition) met(inst *sympoziumv1alpha1.SympoziumInstance) bool {
+	if w.Phase != "" {
+		return strings.EqualFold(inst.Status.Phase, w.Phase)
+	}
+	for _, c := range inst.Status.Conditions {
+		if strings.EqualFold(c.Type, w.Condition) {
+			return strings.EqualFold(string(c.Status), string(w.Status))
+		}
+	}
+	return strings.EqualFold(w.Condition, "Ready") && w.Status == metav1.ConditionTrue &&
+		inst.Status.Phase == "Running"
+}
+
+// watchInstance prints a status line for the instance now and again each
+// time its status changes to w, until ctx is done or the instance is
+// deleted. With until, it stops as soon as the condition is met; the
+// outcome's Code is then exitRunSucceeded, exitRunFailed if the instance
+// was deleted first, or exitRunTimeout if ctx ran out. Without until, the
+// watch always ends with exitRunSucceeded. Only a failure to read the
+// instance the first time is returned as an error.
+func watchInstance(ctx context.Context, c client.Reader, key types.NamespacedName, w io.Writer, until *instanceWaitCondition) (runOutcome, error) {
+	last, phase := "", ""
+	ticker := time.NewTicker(instanceWatchPollInterval)
+	defer ticker.Stop()
+	for {
+		var inst sympoziumv1alpha1.SympoziumInstance
+		err := c.Get(ctx, key, &inst)
+		switch {
+		case apierrors.IsNotFound(err) && last != "":
+			fmt.Fprintf(w, "%s  %s  deleted\n", time.Now().Format(time.RFC3339), key.Name)
+			if until != nil {
+				return runOutcome{Phase: "Deleted", Message: "the SympoziumInstance was deleted", Code: exitRunFailed}, nil
+			}
+			return runOutcome{Phase: "Deleted"}, nil
+		case err != nil && ctx.Err() != nil:
+			return instanceWatchEnded(ctx, until, phase), nil
+		case err != nil && last == "":
+			return runOutcome{}, err
+		case err == nil:
+			phase = inst.Status.Phase
+			if line := instanceStatusLine(&inst); line != last {
+				fmt.Fprintf(w, "%s  %s  %s\n", time.Now().Format(time.RFC3339), key.Name, line)
+				last = line
+			}
+			if until != nil && until.met(&inst) {
+				return runOutcome{Phase: orUnknown(phase), Code: exitRunSucceeded}, nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return instanceWatchEnded(ctx, until, phase), nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// instanceWatchEnded is the outcome of a watch whose ctx is done: a
+// timeout or interruption when waiting for a condition, and a normal end
+// otherwise.
+func instanceWatchEnded(ctx context.Context, until *instanceWaitCondition, phase string) runOutcome {
+	if until == nil {
+		return runOutcome{Phase: orUnknown(phase)}
+	}
+	out := timeoutOutcome(ctx, "")
+	if out.Code == exitRunTimeout {
+		out.Message = fmt.Sprintf("timed out waiting for %s (last phase: %s)", until, orUnknown(phase))
+	}
+	return out
+}
+
+// instanceStatusLine summarises the parts of an instance's status that
+// `instances get -w` reports.
+func instanceStatusLine(inst *sympoziumv1alpha1.SympoziumInstance) string {
+	channels := make([]string, 0, len(inst.Status.Channels))
+	for _, ch := range inst.Status.Channels {
+		channels = append(channels, ch.Type+"="+orUnknown(ch.Status))
+	}
+	chs := "-"
+	if len(channels) > 0 {
+		chs = strings.Join(channels, ",")
+	}
+	return fmt.Sprintf("phase=%s channels=%s pods=%d", orUnknown(inst.Status.Phase), chs, inst.Status.ActiveAgentPods)
+}
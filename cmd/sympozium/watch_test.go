@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sympoziumv1alpha1 "github.com/alexsjones/sympozium/api/v1alpha1"
+)
+
+func TestWatchInstance_PrintsChangesAndDeletion(t *testing.T) {
+	old := instanceWatchPollInterval
+	instanceWatchPollInterval = 10 * time.Millisecond
+	t.Cleanup(func() { instanceWatchPollInterval = old })
+
+	inst := &sympoziumv1alpha1.SympoziumInstance{ObjectMeta: metav1.ObjectMeta{Name: "alpha", Namespace: "default"}}
+	inst.Status.Phase = "Pending"
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(inst).WithStatusSubresource(inst).Build()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		var cur sympoziumv1alpha1.SympoziumInstance
+		_ = c.Get(ctx, types.NamespacedName{Name: "alpha", Namespace: "default"}, &cur)
+		cur.Status.Phase = "Running"
+		cur.Status.ActiveAgentPods = 2
+		cur.Status.Channels = []sympoziumv1alpha1.ChannelStatus{{Type: "telegram", Status: "Connected"}}
+		_ = c.Status().Update(ctx, &cur)
+		time.Sleep(50 * time.Millisecond)
+		_ = c.Delete(ctx, &cur)
+	}()
+
+	var out syncBuffer
+	if _, err := watchInstance(ctx, c, types.NamespacedName{Name: "alpha", Namespace: "default"}, &out, nil); err != nil {
+		t.Fatalf("watchInstance: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("want 3 lines (initial, change, deleted), got %d:\n%s", len(lines), out.String())
+	}
+	for i, want := range []string{
+		"alpha  phase=Pending channels=- pods=0",
+		"alpha  phase=Running channels=telegram=Connected pods=2",
+		"alpha  deleted",
+	} {
+		if !strings.HasSuffix(lines[i], want) {
+			t.Errorf("line %d = %q, want suffix %q", i, lines[i], want)
+		}
+	}
+}
+
+func TestWatchInstance_For(t *testing.T) {
+	old := instanceWatchPollInterval
+	instanceWatchPollInterval = 10 * time.Millisecond
+	t.Cleanup(func() { instanceWatchPollInterval = old })
+	key := types.NamespacedName{Name: "alpha", Namespace: "default"}
+
+	newClient := func(phase string, conds ...metav1.Condition) (*sympoziumv1alpha1.SympoziumInstance, client.WithWatch) {
+		inst := &sympoziumv1alpha1.SympoziumInstance{ObjectMeta: metav1.ObjectMeta{Name: "alpha", Namespace: "default"}}
+		inst.Status.Phase = phase
+		inst.Status.Conditions = conds
+		return inst, fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(inst).WithStatusSubresource(inst).Build()
+	}
+	ready, _ := parseInstanceWaitCondition("condition=Ready")
+
+	t.Run("met after a change", func(t *testing.T) {
+		_, c := newClient("Pending")
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			var cur sympoziumv1alpha1.SympoziumInstance
+			_ = c.Get(ctx, key, &cur)
+			cur.Status.Phase = "Running"
+			_ = c.Status().Update(ctx, &cur)
+		}()
+		out, err := watchInstance(ctx, c, key, io.Discard, ready)
+		if err != nil || out.Code != exitRunSucceeded || out.Phase != "Running" {
+			t.Errorf("got %+v, %v", out, err)
+		}
+	})
+
+	t.Run("explicit condition wins over the phase", func(t *testing.T) {
+		_, c := newClient("Running", metav1.Condition{Type: "Ready", Status: metav1.ConditionFalse})
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		out, err := watchInstance(ctx, c, key, io.Discard, ready)
+		if err != nil || out.Code != exitRunTimeout || !strings.Contains(out.Message, "condition=Ready=True") {
+			t.Errorf("got %+v, %v", out, err)
+		}
+	})
+
+	t.Run("deleted", func(t *testing.T) {
+		inst, c := newClient("Pending")
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			_ = c.Delete(ctx, inst)
+		}()
+		until, _ := parseInstanceWaitCondition("phase=running")
+		out, err := watchInstance(ctx, c, key, io.Discard, until)
+		if err != nil || out.Code != exitRunFailed {
+			t.Errorf("got %+v, %v", out, err)
+		}
+	})
+
+	t.Run("missing instance", func(t *testing.T) {
+		c := fake.NewClientBuilder().WithScheme(testScheme(t)).Build()
+		if _, err := watchInstance(context.Background(), c, key, io.Discard, ready); err == nil {
+			t.Error("want an error for a missing instance")
+		}
+	})
+}
+
+func TestParseInstanceWaitCondition(t *testing.T) {
+	for in, want := range map[string]string{
+		"condition=Ready":          "condition=Ready=True",
+		"condition=Degraded=False": "condition=Degraded=False",
+		"phase=Running":            "phase=Running",
+	} {
+		if got, err := parseInstanceWaitCondition(in); err != nil || got.String() != want {
+			t.Errorf("%s = %v, %v; want %s", in, got, err, want)
+		}
+	}
+	for _, bad := range []string{"", "Ready", "condition=", "phase=", "delete", "condition==True"} {
+		if _, err := parseInstanceWaitCondition(bad); err == nil {
+			t.Errorf("%q: want error", bad)
+		}
+	}
+}
@@ -2,7 +2,7 @@ package controller
 
 import (
 	"context"
-	"encoding/json"
+	stderrors "errors"
 	"fmt"
 	"io"
 	"sort"
@@ -23,6 +23,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	sympoziumv1alpha1 "github.com/alexsjones/sympozium/api/v1alpha1"
+	"github.com/alexsjones/sympozium/internal/ipcfile"
 	"github.com/alexsjones/sympozium/internal/orchestrator"
 )
 
@@ -229,12 +230,14 @@ func (r *AgentRunReconciler) reconcileRunning(ctx context.Context, log logr.Logg
 	// Check Job completion
 	if job.Status.Succeeded > 0 {
 		// Extract the LLM response from pod logs before the pod is gone.
-		result, usage := r.extractResultFromPod(ctx, log, agentRun)
+		result, usage, _ := r.extractResultFromPod(ctx, log, agentRun)
 		// Extract and persist memory updates if applicable.
 		r.extractAndPersistMemory(ctx, log, agentRun)
 		return r.succeedRun(ctx, agentRun, result, usage)
 	}
 	if job.Status.Failed > 0 {
+		// The failed pod is kept, so its result marker says why.
+		_, _, agentRun.Status.ErrorCode = r.extractResultFromPod(ctx, log, agentRun)
 		return ctrl.Result{}, r.failRun(ctx, agentRun, "Job failed")
 	}
 
@@ -249,7 +252,7 @@ func (r *AgentRunReconciler) reconcileRunning(ctx context.Context, log logr.Logg
 		if done, exitCode, reason, hasSidecars := r.checkAgentContainer(ctx, log, agentRun); done && hasSidecars {
 			if exitCode == 0 {
 				log.Info("Agent container terminated successfully; cleaning up lingering sidecars")
-				result, usage := r.extractResultFromPod(ctx, log, agentRun)
+				result, usage, _ := r.extractResultFromPod(ctx, log, agentRun)
 				r.extractAndPersistMemory(ctx, log, agentRun)
 				// Delete the Job so Kubernetes kills remaining sidecar containers.
 				_ = r.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground))
@@ -261,9 +264,11 @@ func (r *AgentRunReconciler) reconcileRunning(ctx context.Context, log logr.Logg
 			}
 			log.Info("Agent container terminated with error; cleaning up", "exitCode", exitCode, "reason", reason)
 			// Try to extract the error from pod logs before cleaning up.
-			if logErr, _ := r.extractResultFromPod(ctx, log, agentRun); logErr != "" {
+			logErr, _, errorCode := r.extractResultFromPod(ctx, log, agentRun)
+			if logErr != "" {
 				errMsg = logErr
 			}
+			agentRun.Status.ErrorCode = errorCode
 			_ = r.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground))
 			return ctrl.Result{}, r.failRun(ctx, agentRun, errMsg)
 		}
@@ -595,6 +600,10 @@ func (r *AgentRunReconciler) buildContainers(agentRun *sympoziumv1alpha1.AgentRu
 				{Name: "MODEL_BASE_URL", Value: agentRun.Spec.Model.BaseURL},
 				{Name: "THINKING_MODE", Value: agentRun.Spec.Model.Thinking},
 			},
+			// agent-runner serves /healthz, /readyz and /metrics here.
+			Ports: []corev1.ContainerPort{
+				{Name: "metrics", ContainerPort: 8090, Protocol: corev1.ProtocolTCP},
+			},
 			VolumeMounts: []corev1.VolumeMount{
 				{Name: "workspace", MountPath: "/workspace"},
 				{Name: "skills", MountPath: "/skills", ReadOnly: true},
@@ -919,10 +928,11 @@ const (
 )
 
 // extractResultFromPod reads the agent container logs and looks for the
-// structured result marker written by agent-runner.
-func (r *AgentRunReconciler) extractResultFromPod(ctx context.Context, log logr.Logger, agentRun *sympoziumv1alpha1.AgentRun) (string, *sympoziumv1alpha1.TokenUsage) {
+// structured result marker written by agent-runner. It returns the response
+// and usage of a successful run, and the errorCode of a failed one.
+func (r *AgentRunReconciler) extractResultFromPod(ctx context.Context, log logr.Logger, agentRun *sympoziumv1alpha1.AgentRun) (string, *sympoziumv1alpha1.TokenUsage, string) {
 	if r.Clientset == nil || agentRun.Status.PodName == "" {
-		return "", nil
+		return "", nil, ""
 	}
 
 	tailLines := int64(20)
@@ -934,45 +944,52 @@ func (r *AgentRunReconciler) extractResultFromPod(ctx context.Context, log logr.
 	stream, err := req.Stream(ctx)
 	if err != nil {
 		log.V(1).Info("could not read pod logs for result", "err", err)
-		return "", nil
+		return "", nil, ""
 	}
 	defer stream.Close()
 
 	raw, err := io.ReadAll(stream)
 	if err != nil {
 		log.V(1).Info("error reading pod logs", "err", err)
-		return "", nil
+		return "", nil, ""
 	}
 
 	logs := string(raw)
 	startIdx := strings.LastIndex(logs, resultMarkerStart)
 	if startIdx < 0 {
-		return "", nil
+		return "", nil, ""
 	}
 	payload := logs[startIdx+len(resultMarkerStart):]
 	endIdx := strings.Index(payload, resultMarkerEnd)
 	if endIdx < 0 {
-		return "", nil
+		return "", nil, ""
 	}
 	jsonStr := strings.TrimSpace(payload[:endIdx])
 
 	// Parse the full agent result including metrics.
 	var parsed struct {
-		Status   string `json:"status"`
-		Response string `json:"response"`
-		Metrics  struct {
+		Status    string `json:"status"`
+		Response  string `json:"response"`
+		ErrorCode string `json:"errorCode"`
+		Metrics   struct {
 			DurationMs   int64 `json:"durationMs"`
 			InputTokens  int   `json:"inputTokens"`
 			OutputTokens int   `json:"outputTokens"`
 			ToolCalls    int   `json:"toolCalls"`
 		} `json:"metrics"`
 	}
-	if err := json.Unmarshal([]byte(jsonStr), &parsed); err != nil {
-		log.V(1).Info("could not parse result JSON", "err", err)
-		return jsonStr, nil // Return raw JSON as fallback.
+	if err := ipcfile.Decode("", []byte(jsonStr), &parsed); err != nil {
+		if stderrors.Is(err, ipcfile.ErrCorrupt) {
+			log.Info("result marker holds corrupt JSON; using it as the raw result", "err", err)
+		} else {
+			log.V(1).Info("could not parse result JSON", "err", err)
+		}
+		return jsonStr, nil, "" // Return raw JSON as fallback.
 	}
-	if parsed.Status == "error" {
-		return "", nil
+	// A cancelled run's response is partial; like an error it is not a
+	// result.
+	if parsed.Status == "error" || parsed.Status == "cancelled" {
+		return "", nil, parsed.ErrorCode
 	}
 
 	var usage *sympoziumv1alpha1.TokenUsage
@@ -992,7 +1009,7 @@ func (r *AgentRunReconciler) extractResultFromPod(ctx context.Context, log logr.
 			"durationMs", usage.DurationMs)
 	}
 
-	return parsed.Response, usage
+	return parsed.Response, usage, ""
 }
 
 const (
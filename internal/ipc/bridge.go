@@ -9,15 +9,18 @@ package ipc
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
 
 	"github.com/alexsjones/sympozium/internal/eventbus"
+	"github.com/alexsjones/sympozium/internal/ipcfile"
 )
 
 // IPCDir layout constants matching the design doc protocol.
@@ -129,50 +132,64 @@ func (b *Bridge) watchOutput(ctx context.Context) {
 
 // handleOutputFile processes a file created in /ipc/output/.
 func (b *Bridge) handleOutputFile(ctx context.Context, fe FileEvent) {
+	filename := filepath.Base(fe.Path)
+	// Only these files are relayed; the others (transcript.jsonl,
+	// request.json, ...) are not single JSON values and are left alone. The
+	// runner writes each file to a hidden temp file first and renames it
+	// into place, so the temp file matches none of them.
+	var topic string
+	switch {
+	case filename == "result.json":
+		// Final result
+		topic = eventbus.TopicAgentRunCompleted
+	case filename == "status.json":
+		// Status update
+		topic = "agent.status.update"
+	case strings.HasPrefix(filename, "stream-"):
+		// Streaming chunk
+		topic = eventbus.TopicAgentStreamChunk
+	default:
+		return
+	}
 	// fsnotify fires both Create and Write for the same file; deduplicate.
 	if _, loaded := b.processedFiles.LoadOrStore(fe.Path, true); loaded {
 		return
 	}
 
-	data, err := os.ReadFile(fe.Path)
+	data, err := ipcfile.Read(fe.Path)
+	if errors.Is(err, ipcfile.ErrCorrupt) {
+		// Not retried: the runner replaces files atomically, so this one
+		// will not be completed.
+		b.Log.Error(err, "corrupt output file", "path", fe.Path)
+		if filename == "result.json" {
+			b.signalAgentDone()
+		}
+		return
+	}
 	if err != nil {
 		b.Log.Error(err, "failed to read output file", "path", fe.Path)
 		b.processedFiles.Delete(fe.Path) // allow retry on read error
 		return
 	}
 
-	filename := filepath.Base(fe.Path)
 	metadata := map[string]string{
 		"agentRunID":   b.AgentRunID,
 		"instanceName": b.InstanceName,
 	}
+	event, _ := eventbus.NewEvent(topic, metadata, json.RawMessage(data))
+	if err := b.EventBus.Publish(ctx, topic, event); err != nil {
+		b.Log.Error(err, "failed to publish output event", "topic", topic, "path", fe.Path)
+	}
+	if filename == "result.json" {
+		b.signalAgentDone()
+	}
+}
 
-	switch {
-	case filename == "result.json":
-		// Final result
-		event, _ := eventbus.NewEvent(eventbus.TopicAgentRunCompleted, metadata, json.RawMessage(data))
-		if err := b.EventBus.Publish(ctx, eventbus.TopicAgentRunCompleted, event); err != nil {
-			b.Log.Error(err, "failed to publish completion event")
-		}
-		// Signal that the agent is done so the bridge can exit.
-		select {
-		case b.agentDone <- struct{}{}:
-		default:
-		}
-
-	case filename == "status.json":
-		// Status update
-		event, _ := eventbus.NewEvent("agent.status.update", metadata, json.RawMessage(data))
-		if err := b.EventBus.Publish(ctx, "agent.status.update", event); err != nil {
-			b.Log.Error(err, "failed to publish status event")
-		}
-
-	case len(filename) > 7 && filename[:7] == "stream-":
-		// Streaming chunk
-		event, _ := eventbus.NewEvent(eventbus.TopicAgentStreamChunk, metadata, json.RawMessage(data))
-		if err := b.EventBus.Publish(ctx, eventbus.TopicAgentStreamChunk, event); err != nil {
-			b.Log.Error(err, "failed to publish stream chunk")
-		}
+// signalAgentDone tells Run that the agent is done so the bridge can exit.
+func (b *Bridge) signalAgentDone() {
+	select {
+	case b.agentDone <- struct{}{}:
+	default:
 	}
 }
 
@@ -0,0 +1,75 @@
+package ipc
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-logr/logr/funcr"
+
+	"github.com/alexsjones/sympozium/internal/eventbus"
+)
+
+// recordingBus records the topics published to it.
+type recordingBus struct {
+	mu     sync.Mutex
+	topics []string
+}
+
+func (r *recordingBus) Publish(_ context.Context, topic string, _ *eventbus.Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.topics = append(r.topics, topic)
+	return nil
+}
+
+func (r *recordingBus) Subscribe(context.Context, string) (<-chan *eventbus.Event, error) {
+	return nil, nil
+}
+
+func (r *recordingBus) Close() error { return nil }
+
+func TestHandleOutputFile(t *testing.T) {
+	dir := t.TempDir()
+	var logged []string
+	log := funcr.New(func(prefix, args string) { logged = append(logged, args) }, funcr.Options{})
+	bus := &recordingBus{}
+	b := NewBridge(dir, "run-1", "inst", bus, log)
+
+	write := func(name, data string) FileEvent {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return FileEvent{Path: path, Op: "create"}
+	}
+
+	// The transcript starts empty and is JSON Lines after that; neither is
+	// an error, nor are other files the bridge does not relay.
+	ctx := context.Background()
+	b.handleOutputFile(ctx, write("transcript.jsonl", ""))
+	b.handleOutputFile(ctx, write("transcript.jsonl", "{\"a\":1}\n{\"b\":2}\n"))
+	b.handleOutputFile(ctx, write("request.json", ""))
+	b.handleOutputFile(ctx, write(".result.json.123.tmp", "{"))
+	if len(logged) != 0 || len(bus.topics) != 0 {
+		t.Errorf("unrelayed files: logged %q, published %v", logged, bus.topics)
+	}
+
+	b.handleOutputFile(ctx, write("stream-0.json", `{"type":"text"}`))
+	b.handleOutputFile(ctx, write("status.json", `{"status":"`))
+	if len(logged) != 1 || !strings.Contains(logged[0], "corrupt output file") {
+		t.Errorf("corrupt status.json: logged %q", logged)
+	}
+
+	go b.handleOutputFile(ctx, write("result.json", `{"status":"success"}`))
+	<-b.agentDone
+	want := eventbus.TopicAgentStreamChunk + "," + eventbus.TopicAgentRunCompleted
+	bus.mu.Lock()
+	defer bus.mu.Unlock()
+	if got := strings.Join(bus.topics, ","); got != want {
+		t.Errorf("published %q, want %q", got, want)
+	}
+}
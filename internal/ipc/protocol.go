@@ -23,11 +23,15 @@ type ModelConfig struct {
 }
 
 // AgentResult is written to /ipc/output/result.json by the agent on completion.
+// It holds the fields every schema version has; see
+// docs/agent-runner-output.md for the rest.
 type AgentResult struct {
-	Status   string `json:"status"` // "success" or "error"
-	Response string `json:"response,omitempty"`
-	Error    string `json:"error,omitempty"`
-	Metrics  struct {
+	// SchemaVersion is absent (0) in results of version 1.
+	SchemaVersion int    `json:"schemaVersion,omitempty"`
+	Status        string `json:"status"` // "success", "error" or "cancelled"
+	Response      string `json:"response,omitempty"`
+	Error         string `json:"error,omitempty"`
+	Metrics       struct {
 		DurationMs     int64 `json:"durationMs"`
 		InputTokens    int   `json:"inputTokens"`
 		OutputTokens   int   `json:"outputTokens"`
@@ -38,10 +42,12 @@ type AgentResult struct {
 
 // StreamChunk is written to /ipc/output/stream-*.json for streaming responses.
 type StreamChunk struct {
-	Type    string `json:"type"` // "text", "thinking", "tool_use", "tool_result"
-	Content string `json:"content"`
-	ToolID  string `json:"toolId,omitempty"`
-	Index   int    `json:"index"`
+	// SchemaVersion is absent (0) in chunks before version 3.
+	SchemaVersion int    `json:"schemaVersion,omitempty"`
+	Type          string `json:"type"` // "text", "thinking", "tool_use", "tool_result", "iteration"
+	Content       string `json:"content"`
+	ToolID        string `json:"toolId,omitempty"`
+	Index         int    `json:"index"`
 }
 
 // SpawnRequest is written to /ipc/spawn/request-*.json to request sub-agent creation.
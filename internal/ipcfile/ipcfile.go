@@ -0,0 +1,138 @@
+// Package ipcfile writes and reads the JSON files agent pods exchange under
+// /ipc. Writers replace a file atomically, so a pod killed mid-write leaves
+// the previous version or nothing, never a truncated file; readers report a
+// file that is still corrupt (written by an older runner, or damaged on
+// disk) distinctly from one that is missing or unreadable.
+//
+// It depends only on the standard library so the agent-runner can use it
+// without pulling in the rest of the control plane.
+package ipcfile
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ErrCorrupt is matched by errors.Is for every *CorruptError.
+var ErrCorrupt = errors.New("corrupt IPC file")
+
+// CorruptError describes an IPC file that is empty, truncated or not JSON.
+type CorruptError struct {
+	// Path is the file, empty when the data did not come from a file.
+	Path string
+	// Size is the length of the data that was read.
+	Size int
+	Err  error
+}
+
+func (e *CorruptError) Error() string {
+	what := "data"
+	if e.Path != "" {
+		what = e.Path
+	}
+	return fmt.Sprintf("corrupt IPC file %s (%d bytes): %v", what, e.Size, e.Err)
+}
+
+func (e *CorruptError) Unwrap() []error { return []error{ErrCorrupt, e.Err} }
+
+// Write replaces path with data: it writes a hidden temp file in the same
+// directory, fsyncs it and renames it into place. Readers and watchers
+// ignore the temp file, as its name starts with a dot.
+func Write(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	f, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	if err := writeAndSync(f, data, perm); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+func writeAndSync(f *os.File, data []byte, perm os.FileMode) error {
+	_, err := f.Write(data)
+	if err == nil {
+		err = f.Chmod(perm)
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// SyncDir fsyncs dir, so the renames of Write survive a crash of the node.
+// Call it after the last write that must not be lost, such as result.json.
+func SyncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	err = d.Sync()
+	if cerr := d.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// Check returns a *CorruptError for path when data is not a single JSON
+// value.
+func Check(path string, data []byte) error {
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case len(trimmed) == 0:
+		return &CorruptError{Path: path, Size: len(data), Err: errors.New("empty")}
+	case !json.Valid(trimmed):
+		var v any
+		err := json.Unmarshal(trimmed, &v)
+		if err == nil {
+			err = errors.New("invalid JSON")
+		}
+		return &CorruptError{Path: path, Size: len(data), Err: err}
+	}
+	return nil
+}
+
+// Read returns the content of path after checking it is JSON. A missing or
+// unreadable file returns the os error; a corrupt one a *CorruptError.
+func Read(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := Check(path, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// ReadJSON reads path into v, like Read.
+func ReadJSON(path string, v any) error {
+	data, err := Read(path)
+	if err != nil {
+		return err
+	}
+	return Decode(path, data, v)
+}
+
+// Decode unmarshals data into v; malformed JSON is a *CorruptError for
+// path, while valid JSON of the wrong shape is returned as the json error.
+func Decode(path string, data []byte, v any) error {
+	if err := Check(path, data); err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
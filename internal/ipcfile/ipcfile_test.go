@@ -0,0 +1,138 @@
+package ipcfile
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// bigJSON is large enough that a write takes a while, so the child is
+// likely killed in the middle of one.
+func bigJSON(n int) []byte {
+	return []byte(fmt.Sprintf(`{"seq":%d,"response":%q}`, n, strings.Repeat("x", 4<<20)))
+}
+
+// TestHelperWriter is not a test: the interruption tests run it in a child
+// process that writes until it is killed.
+func TestHelperWriter(t *testing.T) {
+	path := os.Getenv("IPCFILE_HELPER_PATH")
+	if path == "" {
+		t.Skip("helper process")
+	}
+	inPlace := os.Getenv("IPCFILE_HELPER_IN_PLACE") == "true"
+	os.WriteFile(path+".ready", nil, 0o644)
+	for n := 0; ; n++ {
+		if inPlace {
+			f, _ := os.Create(path)
+			for _, chunk := range bytes.SplitAfter(bigJSON(n), []byte("xxxxxxxx")) {
+				f.Write(chunk)
+			}
+			f.Close()
+		} else if err := Write(path, bigJSON(n), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// killMidWrite runs TestHelperWriter on path and kills it after a moment.
+func killMidWrite(t *testing.T, path string, inPlace bool) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], "-test.run=^TestHelperWriter$")
+	cmd.Env = append(os.Environ(), "IPCFILE_HELPER_PATH="+path, fmt.Sprintf("IPCFILE_HELPER_IN_PLACE=%v", inPlace))
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		if _, err := os.Stat(path + ".ready"); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			cmd.Process.Kill()
+			t.Fatal("helper did not start")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	time.Sleep(150 * time.Millisecond)
+	cmd.Process.Kill()
+	cmd.Wait()
+}
+
+func TestWriteSurvivesKill(t *testing.T) {
+	for i := 0; i < 5; i++ {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "result.json")
+		killMidWrite(t, path, false)
+
+		var v struct{ Seq int }
+		err := ReadJSON(path, &v)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			t.Fatalf("after kill: %v", err)
+		}
+		entries, _ := os.ReadDir(dir)
+		for _, e := range entries {
+			if e.Name() != "result.json" && !strings.HasPrefix(e.Name(), ".") && !strings.HasSuffix(e.Name(), ".ready") {
+				t.Errorf("unexpected file %s", e.Name())
+			}
+		}
+	}
+}
+
+func TestReadReportsInPlaceWriteKilled(t *testing.T) {
+	// An in-place writer killed mid-write leaves a truncated file, at
+	// least in some of the attempts.
+	for i := 0; i < 20; i++ {
+		path := filepath.Join(t.TempDir(), "result.json")
+		killMidWrite(t, path, true)
+		if _, err := Read(path); errors.Is(err, ErrCorrupt) {
+			return
+		}
+	}
+	t.Skip("the in-place writer was never killed mid-write")
+}
+
+func TestRead(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "status.json")
+	if _, err := Read(path); !errors.Is(err, fs.ErrNotExist) || errors.Is(err, ErrCorrupt) {
+		t.Errorf("missing file: %v", err)
+	}
+
+	for name, data := range map[string]string{
+		"empty":     "",
+		"blank":     " \n",
+		"truncated": `{"status":"succ`,
+		"not JSON":  "done",
+	} {
+		os.WriteFile(path, []byte(data), 0o644)
+		_, err := Read(path)
+		var ce *CorruptError
+		if !errors.Is(err, ErrCorrupt) || !errors.As(err, &ce) || ce.Path != path || ce.Size != len(data) {
+			t.Errorf("%s: err = %v", name, err)
+		}
+	}
+
+	if err := Write(path, []byte(`{"phase":"done"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	var st struct{ Phase string }
+	if err := ReadJSON(path, &st); err != nil || st.Phase != "done" {
+		t.Errorf("ReadJSON = %+v, %v", st, err)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0o600 {
+		t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+	}
+	if err := SyncDir(dir); err != nil {
+		t.Errorf("SyncDir: %v", err)
+	}
+	if err := Decode("", []byte(`{"phase":1}`), &st); err == nil || errors.Is(err, ErrCorrupt) {
+		t.Errorf("wrong shape: err = %v, want a json error", err)
+	}
+}